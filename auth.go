@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -17,6 +18,9 @@ var ErrUnableToCreateToken = &errors.Error{
 	Code: errors.EInvalid,
 }
 
+// ErrTokenExpired is the error message for expired authorizations.
+const ErrTokenExpired = "token has expired"
+
 // Authorization is an authorization. 🎉
 type Authorization struct {
 	ID          platform.ID  `json:"id"`
@@ -26,6 +30,13 @@ type Authorization struct {
 	OrgID       platform.ID  `json:"orgID"`
 	UserID      platform.ID  `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+	// ExpiresAt is when the token stops being accepted. A nil ExpiresAt
+	// never expires.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// PreviousToken is the token replaced by the last rotation. It is
+	// accepted in place of Token until PreviousTokenExpiresAt.
+	PreviousToken          string     `json:"previousToken,omitempty"`
+	PreviousTokenExpiresAt *time.Time `json:"previousTokenExpiresAt,omitempty"`
 	CRUDLog
 }
 
@@ -33,6 +44,9 @@ type Authorization struct {
 type AuthorizationUpdate struct {
 	Status      *Status `json:"status,omitempty"`
 	Description *string `json:"description,omitempty"`
+	// ExpiresAt replaces the expiry of the authorization. The zero time
+	// removes it.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
 // Valid ensures that the authorization is valid.
@@ -49,6 +63,18 @@ func (a *Authorization) Valid() error {
 	return nil
 }
 
+// Expired returns an error if the authorization is expired.
+func (a *Authorization) Expired() error {
+	if a.ExpiresAt != nil && !time.Now().Before(*a.ExpiresAt) {
+		return &errors.Error{
+			Code: errors.EUnauthorized,
+			Msg:  ErrTokenExpired,
+		}
+	}
+
+	return nil
+}
+
 // PermissionSet returns the set of permissions associated with the Authorization.
 func (a *Authorization) PermissionSet() (PermissionSet, error) {
 	if !a.IsActive() {
@@ -57,6 +83,9 @@ func (a *Authorization) PermissionSet() (PermissionSet, error) {
 			Msg:  "token is inactive",
 		}
 	}
+	if err := a.Expired(); err != nil {
+		return nil, err
+	}
 
 	return a.Permissions, nil
 }
@@ -90,6 +119,7 @@ const (
 	OpCreateAuthorization      = "CreateAuthorization"
 	OpUpdateAuthorization      = "UpdateAuthorization"
 	OpDeleteAuthorization      = "DeleteAuthorization"
+	OpRotateAuthorizationToken = "RotateAuthorizationToken"
 )
 
 // AuthorizationService represents a service for managing authorization data.
@@ -114,6 +144,15 @@ type AuthorizationService interface {
 	DeleteAuthorization(ctx context.Context, id platform.ID) error
 }
 
+// AuthorizationTokenRotator is implemented by authorization services that can
+// replace the token of an authorization.
+type AuthorizationTokenRotator interface {
+	// RotateAuthorizationToken issues a new token for the authorization with
+	// the same permissions. The replaced token keeps being accepted for the
+	// grace period.
+	RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*Authorization, error)
+}
+
 // AuthorizationFilter represents a set of filter that restrict the returned results.
 type AuthorizationFilter struct {
 	Token *string
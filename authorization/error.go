@@ -1,8 +1,12 @@
 package authorization
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 )
 
@@ -38,6 +42,13 @@ var (
 		Code: errors.EConflict,
 		Msg:  "token already exists",
 	}
+
+	// ErrTokenRotationNotSupported is used when the underlying authorization
+	// service cannot rotate tokens.
+	ErrTokenRotationNotSupported = &errors.Error{
+		Code: errors.EMethodNotAllowed,
+		Msg:  "token rotation is not supported",
+	}
 )
 
 // ErrInvalidAuthIDError is used when a service was provided an invalid ID.
@@ -64,3 +75,13 @@ func UnexpectedAuthIndexError(err error) *errors.Error {
 		Msg:  fmt.Sprintf("unexpected error retrieving auth index; Err: %v", err),
 	}
 }
+
+// rotateAuthorizationToken rotates the token of an authorization using s, if
+// s supports token rotation.
+func rotateAuthorizationToken(ctx context.Context, s influxdb.AuthorizationService, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	r, ok := s.(influxdb.AuthorizationTokenRotator)
+	if !ok {
+		return nil, ErrTokenRotationNotSupported
+	}
+	return r.RotateAuthorizationToken(ctx, id, gracePeriod)
+}
@@ -3,6 +3,7 @@ package authorization
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 
@@ -10,7 +11,10 @@ import (
 	"github.com/influxdata/influxdb/v2/pkg/httpc"
 )
 
-var _ influxdb.AuthorizationService = (*AuthorizationClientService)(nil)
+var (
+	_ influxdb.AuthorizationService      = (*AuthorizationClientService)(nil)
+	_ influxdb.AuthorizationTokenRotator = (*AuthorizationClientService)(nil)
+)
 
 // AuthorizationClientService connects to Influx via HTTP using tokens to manage authorizations
 type AuthorizationClientService struct {
@@ -100,6 +104,25 @@ func (s *AuthorizationClientService) UpdateAuthorization(ctx context.Context, id
 	return res.toInfluxdb(), nil
 }
 
+// RotateAuthorizationToken issues a new token for the authorization. The
+// replaced token keeps being accepted for the grace period.
+func (s *AuthorizationClientService) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	req := rotateAuthorizationRequest{
+		GracePeriod: influxdb.Duration{Duration: gracePeriod},
+	}
+
+	var res authResponse
+	err := s.Client.
+		PostJSON(req, prefixAuthorization, id.String(), "rotate").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.toInfluxdb(), nil
+}
+
 // DeleteAuthorization removes a authorization by id.
 func (s *AuthorizationClientService) DeleteAuthorization(ctx context.Context, id platform.ID) error {
 	return s.Client.
@@ -59,6 +59,7 @@ func NewHTTPAuthHandler(log *zap.Logger, authService influxdb.AuthorizationServi
 			r.Get("/", h.handleGetAuthorization)
 			r.Patch("/", h.handleUpdateAuthorization)
 			r.Delete("/", h.handleDeleteAuthorization)
+			r.Post("/rotate", h.handleRotateAuthorization)
 		})
 	})
 
@@ -133,6 +134,7 @@ type postAuthorizationRequest struct {
 	UserID      *platform.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []influxdb.Permission `json:"permissions"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
 }
 
 type authResponse struct {
@@ -146,6 +148,7 @@ type authResponse struct {
 	User        string               `json:"user"`
 	Permissions []permissionResponse `json:"permissions"`
 	Links       map[string]string    `json:"links"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
 }
@@ -178,6 +181,7 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
 		},
+		ExpiresAt: a.ExpiresAt,
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
 	}
@@ -191,6 +195,7 @@ func (p *postAuthorizationRequest) toInfluxdb(userID platform.ID) *influxdb.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		ExpiresAt:   p.ExpiresAt,
 	}
 }
 
@@ -202,6 +207,7 @@ func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		ExpiresAt:   a.ExpiresAt,
 		CRUDLog: influxdb.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -234,6 +240,7 @@ func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		ExpiresAt:   a.ExpiresAt,
 	}
 
 	if a.UserID.Valid() {
@@ -275,6 +282,13 @@ func (p *postAuthorizationRequest) Validate() error {
 		}
 	}
 
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "expiresAt must be in the future",
+		}
+	}
+
 	if p.Status == "" {
 		p.Status = influxdb.Active
 	}
@@ -583,6 +597,62 @@ func decodeUpdateAuthorizationRequest(ctx context.Context, r *http.Request) (*up
 	}, nil
 }
 
+// handleRotateAuthorization is the HTTP handler for the POST /api/v2/authorizations/:id/rotate route that issues a new token for the authorization.
+func (h *AuthHandler) handleRotateAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeRotateAuthorizationRequest(ctx, r)
+	if err != nil {
+		h.log.Info("Failed to decode request", zap.String("handler", "rotateAuthorization"), zap.Error(err))
+		h.api.Err(w, r, err)
+		return
+	}
+
+	a, err := rotateAuthorizationToken(ctx, h.authSvc, req.ID, req.GracePeriod.Duration)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	ps, err := h.newPermissionsResponse(ctx, a.Permissions)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Auth token rotated", zap.String("authID", a.ID.String()))
+
+	resp, err := h.newAuthResponse(ctx, a, ps)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+type rotateAuthorizationRequest struct {
+	ID          platform.ID       `json:"-"`
+	GracePeriod influxdb.Duration `json:"gracePeriod"`
+}
+
+func decodeRotateAuthorizationRequest(ctx context.Context, r *http.Request) (*rotateAuthorizationRequest, error) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, err
+	}
+
+	req := &rotateAuthorizationRequest{ID: *id}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Err:  err,
+			}
+		}
+	}
+
+	return req, nil
+}
+
 // handleDeleteAuthorization is the HTTP handler for the DELETE /api/v2/authorizations/:id route.
 func (h *AuthHandler) handleDeleteAuthorization(w http.ResponseWriter, r *http.Request) {
 	id, err := platform.IDFromString(chi.URLParam(r, "id"))
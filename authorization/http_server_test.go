@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -841,6 +842,111 @@ func TestService_handleDeleteAuthorization(t *testing.T) {
 	}
 }
 
+type rotatingAuthorizationService struct {
+	*mock.AuthorizationService
+	RotateAuthorizationTokenFn func(context.Context, platform.ID, time.Duration) (*influxdb.Authorization, error)
+}
+
+func (s *rotatingAuthorizationService) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	return s.RotateAuthorizationTokenFn(ctx, id, gracePeriod)
+}
+
+func TestService_handleRotateAuthorization(t *testing.T) {
+	authID := itesting.MustIDBase16("020f755c3c082000")
+	ts := &tenantService{
+		FindUserByIDFn: func(ctx context.Context, id platform.ID) (*influxdb.User, error) {
+			return &influxdb.User{ID: id, Name: "u1"}, nil
+		},
+		FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*influxdb.Organization, error) {
+			return &influxdb.Organization{ID: id, Name: "o1"}, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		authSvc     influxdb.AuthorizationService
+		body        string
+		wantStatus  int
+		wantToken   string
+		wantGrace   time.Duration
+		gotGracePtr *time.Duration
+	}{
+		{
+			name:       "rotation not supported",
+			authSvc:    mock.NewAuthorizationService(),
+			body:       `{"gracePeriod":"1h"}`,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "invalid grace period",
+			authSvc:    &rotatingAuthorizationService{AuthorizationService: mock.NewAuthorizationService()},
+			body:       `{"gracePeriod":"soon"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "rotate with grace period",
+			body:        `{"gracePeriod":"1h"}`,
+			wantStatus:  http.StatusOK,
+			wantToken:   "new-token",
+			wantGrace:   time.Hour,
+			gotGracePtr: new(time.Duration),
+		},
+		{
+			name:        "rotate without body",
+			wantStatus:  http.StatusOK,
+			wantToken:   "new-token",
+			gotGracePtr: new(time.Duration),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authSvc := tt.authSvc
+			if authSvc == nil {
+				authSvc = &rotatingAuthorizationService{
+					AuthorizationService: mock.NewAuthorizationService(),
+					RotateAuthorizationTokenFn: func(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+						*tt.gotGracePtr = gracePeriod
+						return &influxdb.Authorization{
+							ID:     id,
+							Token:  "new-token",
+							Status: influxdb.Active,
+							OrgID:  itesting.MustIDBase16("030f755c3c082000"),
+							UserID: itesting.MustIDBase16("040f755c3c082000"),
+						}, nil
+					},
+				}
+			}
+
+			handler := NewHTTPAuthHandler(zaptest.NewLogger(t), authSvc, ts)
+			router := chi.NewRouter()
+			router.Mount(handler.Prefix(), handler)
+
+			r := httptest.NewRequest("POST", "http://any.url"+prefixAuthorization+"/"+authID.String()+"/rotate", bytes.NewReader([]byte(tt.body)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("handleRotateAuthorization() = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp authResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ID != authID || resp.Token != tt.wantToken {
+				t.Errorf("unexpected response %+v", resp)
+			}
+			if *tt.gotGracePtr != tt.wantGrace {
+				t.Errorf("grace period = %v, want %v", *tt.gotGracePtr, tt.wantGrace)
+			}
+		})
+	}
+}
+
 func jsonDiff(s1, s2 string) (diff string, err error) {
 	if s1 == s2 {
 		return "", nil
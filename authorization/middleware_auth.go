@@ -3,6 +3,7 @@ package authorization
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -16,7 +17,10 @@ type AuthedAuthorizationService struct {
 	ts TenantService
 }
 
-var _ influxdb.AuthorizationService = (*AuthedAuthorizationService)(nil)
+var (
+	_ influxdb.AuthorizationService      = (*AuthedAuthorizationService)(nil)
+	_ influxdb.AuthorizationTokenRotator = (*AuthedAuthorizationService)(nil)
+)
 
 func NewAuthedAuthorizationService(s influxdb.AuthorizationService, ts TenantService) *AuthedAuthorizationService {
 	return &AuthedAuthorizationService{
@@ -105,6 +109,20 @@ func (s *AuthedAuthorizationService) DeleteAuthorization(ctx context.Context, id
 	return s.s.DeleteAuthorization(ctx, id)
 }
 
+func (s *AuthedAuthorizationService) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	a, err := s.s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.AuthorizationsResourceType, a.ID, a.OrgID); err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWriteResource(ctx, influxdb.UsersResourceType, a.UserID); err != nil {
+		return nil, err
+	}
+	return rotateAuthorizationToken(ctx, s.s, id, gracePeriod)
+}
+
 // VerifyPermissions ensures that an authorization is allowed all of the appropriate permissions.
 func VerifyPermissions(ctx context.Context, ps []influxdb.Permission) error {
 	for _, p := range ps {
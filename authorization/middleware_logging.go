@@ -24,7 +24,10 @@ func NewAuthLogger(log *zap.Logger, s influxdb.AuthorizationService) *AuthLogger
 	}
 }
 
-var _ influxdb.AuthorizationService = (*AuthLogger)(nil)
+var (
+	_ influxdb.AuthorizationService      = (*AuthLogger)(nil)
+	_ influxdb.AuthorizationTokenRotator = (*AuthLogger)(nil)
+)
 
 func (l *AuthLogger) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) (err error) {
 	defer func(start time.Time) {
@@ -100,3 +103,16 @@ func (l *AuthLogger) DeleteAuthorization(ctx context.Context, id platform.ID) (e
 	}(time.Now())
 	return l.authService.DeleteAuthorization(ctx, id)
 }
+
+func (l *AuthLogger) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (a *influxdb.Authorization, err error) {
+	defer func(start time.Time) {
+		dur := zap.Duration("took", time.Since(start))
+		if err != nil {
+			msg := fmt.Sprintf("failed to rotate token of authorization with ID %v", id)
+			l.logger.Debug(msg, zap.Error(err), dur)
+			return
+		}
+		l.logger.Debug("authorization token rotate", dur)
+	}(time.Now())
+	return rotateAuthorizationToken(ctx, l.authService, id, gracePeriod)
+}
@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"context"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 
@@ -17,7 +18,10 @@ type AuthMetrics struct {
 	authService influxdb.AuthorizationService
 }
 
-var _ influxdb.AuthorizationService = (*AuthMetrics)(nil)
+var (
+	_ influxdb.AuthorizationService      = (*AuthMetrics)(nil)
+	_ influxdb.AuthorizationTokenRotator = (*AuthMetrics)(nil)
+)
 
 func NewAuthMetrics(reg prometheus.Registerer, s influxdb.AuthorizationService, opts ...metric.ClientOptFn) *AuthMetrics {
 	o := metric.ApplyMetricOpts(opts...)
@@ -60,3 +64,9 @@ func (m *AuthMetrics) DeleteAuthorization(ctx context.Context, id platform.ID) e
 	err := m.authService.DeleteAuthorization(ctx, id)
 	return rec(err)
 }
+
+func (m *AuthMetrics) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	rec := m.rec.Record("rotate_authorization_token")
+	a, err := rotateAuthorizationToken(ctx, m.authService, id, gracePeriod)
+	return a, rec(err)
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/influxdata/influxdb/v2/rand"
 )
 
-var _ influxdb.AuthorizationService = (*Service)(nil)
+var (
+	_ influxdb.AuthorizationService      = (*Service)(nil)
+	_ influxdb.AuthorizationTokenRotator = (*Service)(nil)
+)
 
 type Service struct {
 	store          *Store
@@ -100,9 +103,8 @@ func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*infl
 			return err
 		}
 
-		a = auth
-
-		return nil
+		a, err = authorizationForToken(auth, n)
+		return err
 	})
 
 	if err != nil {
@@ -142,8 +144,8 @@ func (s *Service) FindAuthorizations(ctx context.Context, filter influxdb.Author
 			if e != nil {
 				return e
 			}
-			auth = a
-			return nil
+			auth, e = authorizationForToken(a, *filter.Token)
+			return e
 		})
 		if err != nil {
 			return nil, 0, &errors.Error{
@@ -198,6 +200,13 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id platform.ID, upd *
 	if upd.Description != nil {
 		auth.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		auth.ExpiresAt = nil
+		if !upd.ExpiresAt.IsZero() {
+			expiresAt := *upd.ExpiresAt
+			auth.ExpiresAt = &expiresAt
+		}
+	}
 
 	auth.SetUpdatedAt(time.Now())
 
@@ -217,3 +226,83 @@ func (s *Service) DeleteAuthorization(ctx context.Context, id platform.ID) error
 		return s.store.DeleteAuthorization(ctx, tx, id)
 	})
 }
+
+// RotateAuthorizationToken issues a new token for the authorization. The
+// replaced token keeps resolving to the authorization until the grace period
+// has passed, while a token replaced by an earlier rotation stops resolving
+// immediately.
+func (s *Service) RotateAuthorizationToken(ctx context.Context, id platform.ID, gracePeriod time.Duration) (*influxdb.Authorization, error) {
+	if gracePeriod < 0 {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "grace period must not be negative",
+		}
+	}
+
+	token, err := s.tokenGenerator.Token()
+	if err != nil {
+		return nil, &errors.Error{
+			Err: err,
+		}
+	}
+
+	var auth *influxdb.Authorization
+	err = s.store.Update(ctx, func(tx kv.Tx) error {
+		a, err := s.store.GetAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if a.PreviousToken != "" {
+			if err := s.store.DeleteAuthorizationToken(ctx, tx, a.PreviousToken); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		a.PreviousToken, a.PreviousTokenExpiresAt = "", nil
+		if gracePeriod > 0 {
+			expiresAt := now.Add(gracePeriod)
+			a.PreviousToken, a.PreviousTokenExpiresAt = a.Token, &expiresAt
+		} else if err := s.store.DeleteAuthorizationToken(ctx, tx, a.Token); err != nil {
+			return err
+		}
+
+		a.Token = token
+		if err := s.store.uniqueAuthToken(ctx, tx, a); err != nil {
+			return ErrTokenAlreadyExistsError
+		}
+		a.SetUpdatedAt(now)
+
+		auth, err = s.store.UpdateAuthorization(ctx, tx, id, a)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// authorizationForToken returns the authorization a as seen by the holder of
+// token. A token replaced by a rotation is only accepted during its grace
+// period, and the authorization it resolves to expires with it.
+func authorizationForToken(a *influxdb.Authorization, token string) (*influxdb.Authorization, error) {
+	if a.Token == token {
+		return a, nil
+	}
+
+	if a.PreviousToken != token || a.PreviousTokenExpiresAt == nil || !time.Now().Before(*a.PreviousTokenExpiresAt) {
+		return nil, &errors.Error{
+			Code: errors.ENotFound,
+			Msg:  "authorization not found",
+		}
+	}
+
+	prev := *a
+	prev.Token = token
+	if prev.ExpiresAt == nil || prev.PreviousTokenExpiresAt.Before(*prev.ExpiresAt) {
+		prev.ExpiresAt = prev.PreviousTokenExpiresAt
+	}
+	return &prev, nil
+}
@@ -6,10 +6,12 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/bolt"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/kv/migration/all"
 	"github.com/influxdata/influxdb/v2/tenant"
@@ -104,3 +106,83 @@ func TestBoltAuthService(t *testing.T) {
 	t.Parallel()
 	influxdbtesting.AuthorizationService(initBoltAuthService, t)
 }
+
+func TestService_RotateAuthorizationToken(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	ts := tenant.NewService(tenant.NewStore(s))
+	storage, err := authorization.NewStore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := authorization.NewService(storage, ts)
+
+	user := &influxdb.User{Name: "user"}
+	if err := ts.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := ts.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	auth := &influxdb.Authorization{
+		OrgID:       org.ID,
+		UserID:      user.ID,
+		Permissions: influxdb.OperPermissions(),
+	}
+	if err := svc.CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+	first := auth.Token
+
+	rotator := svc.(influxdb.AuthorizationTokenRotator)
+	rotated, err := rotator.RotateAuthorizationToken(ctx, auth.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := rotated.Token
+	if second == first {
+		t.Fatal("expected a new token")
+	}
+
+	a, err := svc.FindAuthorizationByToken(ctx, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != auth.ID || a.ExpiresAt != nil {
+		t.Fatalf("unexpected authorization for new token %+v", a)
+	}
+
+	a, err = svc.FindAuthorizationByToken(ctx, first)
+	if err != nil {
+		t.Fatalf("expected replaced token to be accepted during the grace period: %v", err)
+	}
+	if a.ID != auth.ID || a.Token != first || a.ExpiresAt == nil || !a.ExpiresAt.After(time.Now()) {
+		t.Fatalf("unexpected authorization for replaced token %+v", a)
+	}
+
+	rotated, err = rotator.RotateAuthorizationToken(ctx, auth.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{first, second} {
+		if _, err := svc.FindAuthorizationByToken(ctx, token); errors2.ErrorCode(err) != errors2.ENotFound {
+			t.Fatalf("expected replaced token to be rejected, got %v", err)
+		}
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, rotated.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.DeleteAuthorization(ctx, auth.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, rotated.Token); errors2.ErrorCode(err) != errors2.ENotFound {
+		t.Fatalf("expected token of deleted authorization to be rejected, got %v", err)
+	}
+}
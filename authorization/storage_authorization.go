@@ -294,6 +294,12 @@ func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id platform.I
 		return ErrInternalServiceError(err)
 	}
 
+	if a.PreviousToken != "" {
+		if err := idx.Delete(authIndexKey(a.PreviousToken)); err != nil {
+			return ErrInternalServiceError(err)
+		}
+	}
+
 	if err := b.Delete(encodedID); err != nil {
 		return ErrInternalServiceError(err)
 	}
@@ -301,6 +307,21 @@ func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id platform.I
 	return nil
 }
 
+// DeleteAuthorizationToken removes a token from the token index, so that it no
+// longer resolves to its authorization.
+func (s *Store) DeleteAuthorizationToken(ctx context.Context, tx kv.Tx, token string) error {
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := idx.Delete(authIndexKey(token)); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return nil
+}
+
 func (s *Store) uniqueAuthToken(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
 	err := unique(ctx, tx, authIndex, authIndexKey(a.Token))
 	if err == kv.NotUniqueError {
@@ -43,3 +43,13 @@ func (b BackupService) BackupShard(ctx context.Context, w io.Writer, shardID uin
 	}
 	return b.s.BackupShard(ctx, w, shardID, since)
 }
+
+func (b BackupService) ExportShard(ctx context.Context, w io.Writer, shardID uint64, start, end time.Time) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return b.s.ExportShard(ctx, w, shardID, start, end)
+}
@@ -76,10 +76,16 @@ func (s *OrgService) CreateOrganization(ctx context.Context, o *influxdb.Organiz
 }
 
 // UpdateOrganization checks to see if the authorizer on context has write access to the organization provided.
+// Changing the query limits of an organization requires write access to all organizations.
 func (s *OrgService) UpdateOrganization(ctx context.Context, id platform.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
 	if _, _, err := AuthorizeWriteOrg(ctx, id); err != nil {
 		return nil, err
 	}
+	if upd.QueryLimits != nil {
+		if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+			return nil, err
+		}
+	}
 	return s.s.UpdateOrganization(ctx, id, upd)
 }
 
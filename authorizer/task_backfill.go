@@ -0,0 +1,89 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"go.uber.org/zap"
+)
+
+var _ taskmodel.BackfillService = (*BackfillService)(nil)
+
+// BackfillService wraps a taskmodel.BackfillService and authorizes actions
+// against the task being backfilled.
+type BackfillService struct {
+	s  taskmodel.BackfillService
+	tv *taskServiceValidator
+}
+
+// NewBackfillService constructs an instance of an authorizing backfill service.
+// Tasks are looked up in ts, without authorization, to identify their organization.
+func NewBackfillService(log *zap.Logger, ts taskmodel.TaskService, s taskmodel.BackfillService) *BackfillService {
+	return &BackfillService{
+		s:  s,
+		tv: &taskServiceValidator{TaskService: ts, log: log},
+	}
+}
+
+func (s *BackfillService) authorize(ctx context.Context, method string, taskID platform.ID, write bool) error {
+	task, err := s.tv.TaskService.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	authorize := AuthorizeRead
+	if write {
+		authorize = AuthorizeWrite
+	}
+	a, p, err := authorize(ctx, influxdb.TasksResourceType, task.ID, task.OrganizationID)
+	loggerFields := []zap.Field{zap.String("method", method), zap.Stringer("task_id", taskID)}
+	return s.tv.processPermissionError(a, p, err, loggerFields...)
+}
+
+// CreateBackfill checks to see if the authorizer on context has write access to the task.
+func (s *BackfillService) CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*taskmodel.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorize(ctx, "CreateBackfill", taskID, true); err != nil {
+		return nil, err
+	}
+	return s.s.CreateBackfill(ctx, taskID, start, stop)
+}
+
+// FindBackfillByID checks to see if the authorizer on context has read access to the task.
+func (s *BackfillService) FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*taskmodel.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorize(ctx, "FindBackfillByID", taskID, false); err != nil {
+		return nil, err
+	}
+	return s.s.FindBackfillByID(ctx, taskID, id)
+}
+
+// FindBackfills checks to see if the authorizer on context has read access to the task.
+func (s *BackfillService) FindBackfills(ctx context.Context, taskID platform.ID) ([]*taskmodel.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorize(ctx, "FindBackfills", taskID, false); err != nil {
+		return nil, err
+	}
+	return s.s.FindBackfills(ctx, taskID)
+}
+
+// CancelBackfill checks to see if the authorizer on context has write access to the task.
+func (s *BackfillService) CancelBackfill(ctx context.Context, taskID, id platform.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorize(ctx, "CancelBackfill", taskID, true); err != nil {
+		return err
+	}
+	return s.s.CancelBackfill(ctx, taskID, id)
+}
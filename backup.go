@@ -19,6 +19,10 @@ type BackupService interface {
 
 	// BackupShard downloads a backup file for a single shard.
 	BackupShard(ctx context.Context, w io.Writer, shardID uint64, since time.Time) error
+
+	// ExportShard downloads a backup file with the data of a single shard
+	// within the time range [start, end].
+	ExportShard(ctx context.Context, w io.Writer, shardID uint64, start, end time.Time) error
 }
 
 // RestoreService represents the data restore functions of InfluxDB.
@@ -37,6 +41,16 @@ type RestoreService interface {
 type Manifest struct {
 	KV    ManifestKVEntry `json:"kv"`
 	Files []ManifestEntry `json:"files"`
+
+	// Parent is the file name of the manifest an incremental backup is based on.
+	// Only shard files modified since the parent backup are included in an
+	// incremental backup. It is empty for full backups.
+	Parent string `json:"parent,omitempty"`
+
+	// Start and End bound the time range of the data in a time-range backup.
+	// They are nil for backups of all data.
+	Start *time.Time `json:"start,omitempty"`
+	End   *time.Time `json:"end,omitempty"`
 }
 
 // ManifestEntry contains the data information for a backed up shard.
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/tenant"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"go.uber.org/zap"
@@ -33,89 +36,186 @@ type Request struct {
 
 	// Path to the directory where backup files should be written.
 	Path string
+
+	// Target backup files are written to.
+	// If not set, backup files are written to the local directory at Path.
+	Target Target
+
+	// Name of the manifest of a previous backup in the target to base an
+	// incremental backup on. If set, only shard files modified since the
+	// previous backup are copied.
+	IncrementalFrom string
+
+	// Time range of the data to backup.
+	// If either bound is set, only the points of shards within the range are exported.
+	Start time.Time
+	End   time.Time
+
+	// If true, continue the latest backup in the target if it was interrupted,
+	// skipping the shards which were already backed up.
+	Resume bool
 }
 
 type backupRunner struct {
 	baseName      string
+	since         time.Time
+	start, end    time.Time
+	target        Target
 	backupSvc     influxdb.BackupService
 	tenantService influxdb.TenantService
 	metaClient    *meta.Client
 	log           *zap.Logger
 }
 
-func RunBackup(ctx context.Context, req Request, svc influxdb.BackupService, log *zap.Logger) error {
-	if err := os.MkdirAll(req.Path, 0777); err != nil {
-		return err
-	}
-
+// RunBackup writes a backup set of the data matching req to the target, and
+// returns the name of its manifest.
+func RunBackup(ctx context.Context, req Request, svc influxdb.BackupService, log *zap.Logger) (string, error) {
 	var manifest influxdb.Manifest
 	runner := backupRunner{
 		baseName:  time.Now().UTC().Format(influxdb.BackupFilenamePattern),
+		target:    req.Target,
 		backupSvc: svc,
 		log:       log,
 	}
+	if runner.target == nil {
+		runner.target = NewLocalTarget(req.Path)
+	}
+
+	if !req.Start.IsZero() || !req.End.IsZero() {
+		if req.IncrementalFrom != "" {
+			return "", fmt.Errorf("incremental backups cannot be bounded by a time range")
+		}
+
+		runner.start, runner.end = time.Unix(0, models.MinNanoTime).UTC(), time.Unix(0, models.MaxNanoTime).UTC()
+		if !req.Start.IsZero() {
+			runner.start = req.Start.UTC()
+		}
+		if !req.End.IsZero() {
+			runner.end = req.End.UTC()
+		}
+		if runner.end.Before(runner.start) {
+			return "", fmt.Errorf("backup time range end %s is before start %s", runner.end, runner.start)
+		}
+		manifest.Start, manifest.End = &runner.start, &runner.end
+	}
+
+	if req.IncrementalFrom != "" {
+		parent := filepath.Base(req.IncrementalFrom)
+		since, err := runner.parentBackupTime(ctx, parent)
+		if err != nil {
+			return "", err
+		}
+		runner.since = since
+		manifest.Parent = parent
+		log.Info("Backing up incrementally", zap.String("parent", parent), zap.Time("since", since))
+	}
+
+	var resumed bool
+	if req.Resume {
+		baseName, err := runner.findInterruptedBackup(ctx)
+		if err != nil {
+			return "", err
+		}
+		if baseName != "" {
+			log.Info("Resuming interrupted backup", zap.String("name", baseName))
+			runner.baseName, resumed = baseName, true
+		}
+	}
+
+	// The KV data is also kept on local disk, so we can inspect it.
+	kvFile, err := ioutil.TempFile("", "influx-backup-kv")
+	if err != nil {
+		return "", err
+	}
+	kvPath := kvFile.Name()
+	_ = kvFile.Close()
+	defer os.Remove(kvPath)
 
 	manifest.KV.FileName = fmt.Sprintf("%s.bolt", runner.baseName)
-	kvPath := filepath.Join(req.Path, manifest.KV.FileName)
-	if err := runner.backupKV(ctx, kvPath); err != nil {
-		return err
+	if resumed {
+		err = runner.downloadKV(ctx, manifest.KV.FileName, kvPath)
+	} else {
+		err = runner.backupKV(ctx, manifest.KV.FileName, kvPath)
+	}
+	if err != nil {
+		return "", err
 	}
 
 	fi, err := os.Stat(kvPath)
 	if err != nil {
-		return fmt.Errorf("failed to inspect local KV backup at %q: %w", kvPath, err)
+		return "", fmt.Errorf("failed to inspect local KV backup at %q: %w", kvPath, err)
 	}
 	manifest.KV.Size = fi.Size()
 
 	// Inspect the backed-up KV data so we can iterate through orgs & buckets.
 	kvStore := bolt.NewKVStore(runner.log, kvPath)
 	if err := kvStore.Open(ctx); err != nil {
-		return err
+		return "", err
 	}
 	defer kvStore.Close()
 
 	runner.tenantService = tenant.NewService(tenant.NewStore(kvStore))
 	runner.metaClient = meta.NewClient(meta.NewConfig(), kvStore)
 	if err := runner.metaClient.Open(); err != nil {
-		return err
+		return "", err
 	}
 	defer runner.metaClient.Close()
 
-	manifest.Files, err = runner.findShards(ctx, req)
+	shards, err := runner.findShards(ctx, req)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	for i := range manifest.Files {
-		if err := runner.backupShard(ctx, &manifest.Files[i], req); err != nil {
-			return err
+	for i := range shards {
+		if resumed {
+			if ok, err := runner.findShardBackup(ctx, &shards[i]); err != nil {
+				return "", err
+			} else if ok {
+				manifest.Files = append(manifest.Files, shards[i])
+				continue
+			}
+		}
+
+		if err := runner.backupShard(ctx, &shards[i]); err != nil {
+			return "", err
+		}
+		// Shards without data to backup are left out of the manifest.
+		if shards[i].FileName != "" {
+			manifest.Files = append(manifest.Files, shards[i])
 		}
 	}
 
-	manifestPath := filepath.Join(req.Path, fmt.Sprintf("%s.manifest", runner.baseName))
-	if err := runner.writeManifest(manifest, manifestPath); err != nil {
-		return fmt.Errorf("failed to write backup manfiest to %q: %w", manifestPath, err)
+	manifestName := fmt.Sprintf("%s.manifest", runner.baseName)
+	if err := runner.writeManifest(ctx, manifest, manifestName); err != nil {
+		return "", fmt.Errorf("failed to write backup manfiest %q: %w", manifestName, err)
 	}
 
-	log.Info("Backup complete", zap.String("path", req.Path))
-	return nil
+	log.Info("Backup complete", zap.String("name", runner.baseName))
+	return manifestName, nil
 }
 
-func (r *backupRunner) backupKV(ctx context.Context, path string) error {
-	r.log.Info("Backing up KV store", zap.String("path", path))
+// backupKV downloads the KV store from the server to the target and to the
+// local file at path.
+func (r *backupRunner) backupKV(ctx context.Context, name, path string) error {
+	r.log.Info("Backing up KV store", zap.String("name", name))
 	f, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to open local KV backup file at %q: %w", path, err)
 	}
+	defer f.Close()
+
+	tw, err := r.target.Create(ctx, name)
+	if err != nil {
+		return err
+	}
 
 	// Stream bolt file from server, sync, and ensure file closes correctly.
-	if err := r.backupSvc.BackupKVStore(ctx, f); err != nil {
-		_ = f.Close()
+	if err := r.backupSvc.BackupKVStore(ctx, io.MultiWriter(f, tw)); err != nil {
+		_ = tw.Abort()
 		return fmt.Errorf("failed to download KV backup: %w", err)
 	}
-	if err := f.Sync(); err != nil {
-		_ = f.Close()
-		return fmt.Errorf("failed to flush KV backup to local disk: %w", err)
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write KV backup %q: %w", name, err)
 	}
 	if err := f.Close(); err != nil {
 		return fmt.Errorf("failed to close local KV backup at %q: %w", path, err)
@@ -124,6 +224,63 @@ func (r *backupRunner) backupKV(ctx context.Context, path string) error {
 	return nil
 }
 
+// downloadKV copies the KV store backup of an interrupted backup from the
+// target to the local file at path.
+func (r *backupRunner) downloadKV(ctx context.Context, name, path string) error {
+	rc, err := r.target.Open(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to open local KV backup file at %q: %w", path, err)
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to download KV backup %q: %w", name, err)
+	}
+	return f.Close()
+}
+
+// findInterruptedBackup returns the base name of the latest backup in the
+// target if it has no manifest, because it was interrupted.
+func (r *backupRunner) findInterruptedBackup(ctx context.Context) (string, error) {
+	kvNames, err := r.target.List(ctx, ".bolt")
+	if err != nil {
+		return "", err
+	} else if len(kvNames) == 0 {
+		return "", nil
+	}
+
+	baseName := strings.TrimSuffix(kvNames[len(kvNames)-1], ".bolt")
+	if _, err := r.target.Stat(ctx, baseName+".manifest"); err == nil {
+		return "", nil
+	} else if errors.ErrorCode(err) != errors.ENotFound {
+		return "", err
+	}
+	return baseName, nil
+}
+
+// findShardBackup fills in the manifest of a shard already backed up by an
+// interrupted backup, and returns false if it was not backed up yet.
+func (r *backupRunner) findShardBackup(ctx context.Context, shardInfo *influxdb.ManifestEntry) (bool, error) {
+	name := fmt.Sprintf("%s.s%d.tar.gz", r.baseName, shardInfo.ShardID)
+	fi, err := r.target.Stat(ctx, name)
+	if errors.ErrorCode(err) == errors.ENotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	r.log.Info("Shard already backed up", zap.Uint64("id", shardInfo.ShardID), zap.String("name", name))
+	shardInfo.FileName = name
+	shardInfo.Size = fi.Size
+	shardInfo.LastModified = fi.LastModified
+	return true, nil
+}
+
 func (r *backupRunner) findShards(ctx context.Context, req Request) ([]influxdb.ManifestEntry, error) {
 	filter := influxdb.OrganizationFilter{}
 	if req.OrgID.Valid() {
@@ -180,6 +337,9 @@ func (r *backupRunner) findOrgShards(ctx context.Context, org *influxdb.Organiza
 				if sg.Deleted() {
 					continue
 				}
+				if r.bounded() && !sg.Overlaps(r.start, r.end) {
+					continue
+				}
 
 				for _, sh := range sg.Shards {
 					entries = append(entries, influxdb.ManifestEntry{
@@ -197,58 +357,125 @@ func (r *backupRunner) findOrgShards(ctx context.Context, org *influxdb.Organiza
 	return entries, nil
 }
 
-func (r *backupRunner) backupShard(ctx context.Context, shardInfo *influxdb.ManifestEntry, req Request) error {
+func (r *backupRunner) backupShard(ctx context.Context, shardInfo *influxdb.ManifestEntry) error {
 	shardInfo.FileName = fmt.Sprintf("%s.s%d.tar.gz", r.baseName, shardInfo.ShardID)
-	path := filepath.Join(req.Path, shardInfo.FileName)
-	r.log.Info("Backing up shard", zap.Uint64("id", shardInfo.ShardID), zap.String("path", path))
+	r.log.Info("Backing up shard", zap.Uint64("id", shardInfo.ShardID), zap.String("name", shardInfo.FileName))
 
-	f, err := os.Create(path)
+	tw, err := r.target.Create(ctx, shardInfo.FileName)
 	if err != nil {
-		return fmt.Errorf("failed to open local shard backup at %q: %w", path, err)
+		return err
 	}
-	gw := gzip.NewWriter(f)
-
-	// Stream file from server, sync, and ensure file closes correctly.
-	if err := r.backupSvc.BackupShard(ctx, gw, shardInfo.ShardID, time.Time{}); err != nil {
-		_ = gw.Close()
-		_ = f.Close()
+	size := &countingWriter{w: tw}
+	gw := gzip.NewWriter(size)
+	archive := &countingWriter{w: gw}
 
+	// Stream file from server and ensure file closes correctly.
+	if err := r.downloadShard(ctx, archive, shardInfo.ShardID); err != nil {
 		if errors.ErrorCode(err) == errors.ENotFound {
+			_ = tw.Abort()
 			r.log.Warn("Shard removed during backup", zap.Uint64("id", shardInfo.ShardID))
+			shardInfo.FileName = ""
 			return nil
 		}
+		suspend(tw)
 		return fmt.Errorf("failed to download shard backup: %w", err)
 	}
-	if err := gw.Close(); err != nil {
-		_ = f.Close()
-		return fmt.Errorf("failed to flush GZIP footer to local shard backup: %w", err)
+
+	// Drop incremental or time-range shard backups that don't contain any files.
+	if (!r.since.IsZero() || r.bounded()) && archive.n <= emptyTarSize {
+		r.log.Info("No shard data to backup", zap.Uint64("id", shardInfo.ShardID))
+		shardInfo.FileName = ""
+		return tw.Abort()
 	}
-	if err := f.Sync(); err != nil {
-		_ = f.Close()
-		return fmt.Errorf("failed to flush shard backup to local disk: %w", err)
+
+	if err := gw.Close(); err != nil {
+		suspend(tw)
+		return fmt.Errorf("failed to flush GZIP footer to shard backup: %w", err)
 	}
-	if err := f.Close(); err != nil {
-		return fmt.Errorf("failed to close local shard backup at %q: %w", path, err)
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write shard backup %q: %w", shardInfo.FileName, err)
 	}
 
-	// Use downloaded file's info to fill in remaining pieces of manifest.
-	fi, err := os.Stat(path)
+	// Use the stored file's info to fill in remaining pieces of manifest.
+	fi, err := r.target.Stat(ctx, shardInfo.FileName)
 	if err != nil {
-		return fmt.Errorf("failed to inspect local shard backup at %q: %w", path, err)
+		return fmt.Errorf("failed to inspect shard backup %q: %w", shardInfo.FileName, err)
 	}
-	shardInfo.Size = fi.Size()
-	shardInfo.LastModified = fi.ModTime().UTC()
+	shardInfo.Size = size.n
+	shardInfo.LastModified = fi.LastModified
 
 	return nil
 }
 
-func (r *backupRunner) writeManifest(manifest influxdb.Manifest, path string) error {
-	r.log.Info("Writing manifest", zap.String("path", path))
+// suspend stops writing a backup file after an error. If the target resumes
+// incomplete files, the data written so far is kept for a resumed backup.
+// Otherwise the file is discarded.
+func suspend(tw TargetWriter) {
+	if sw, ok := tw.(suspendableWriter); ok {
+		_ = sw.Suspend()
+		return
+	}
+	_ = tw.Abort()
+}
+
+// emptyTarSize is the size of a tar archive without any files, which only
+// consists of the two zero blocks ending an archive.
+const emptyTarSize = 2 * 512
+
+// countingWriter counts the bytes written to an underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// bounded returns true if only the data within a time range is backed up.
+func (r *backupRunner) bounded() bool {
+	return !r.start.IsZero()
+}
+
+func (r *backupRunner) downloadShard(ctx context.Context, w io.Writer, shardID uint64) error {
+	if r.bounded() {
+		return r.backupSvc.ExportShard(ctx, w, shardID, r.start, r.end)
+	}
+	return r.backupSvc.BackupShard(ctx, w, shardID, r.since)
+}
+
+// parentBackupTime returns the time the backup of the manifest called name was
+// taken, which is encoded in the name of the manifest.
+func (r *backupRunner) parentBackupTime(ctx context.Context, name string) (time.Time, error) {
+	if _, err := r.target.Stat(ctx, name); err != nil {
+		return time.Time{}, fmt.Errorf("failed to find parent manifest %q: %w", name, err)
+	}
+
+	t, err := time.Parse(influxdb.BackupFilenamePattern, strings.TrimSuffix(name, ".manifest"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse backup time from manifest name %q: %w", name, err)
+	}
+	return t, nil
+}
+
+func (r *backupRunner) writeManifest(ctx context.Context, manifest influxdb.Manifest, name string) error {
+	r.log.Info("Writing manifest", zap.String("name", name))
 
 	buf, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
 		return err
 	}
 	buf = append(buf, '\n')
-	return ioutil.WriteFile(path, buf, 0600)
+
+	tw, err := r.target.Create(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(buf); err != nil {
+		_ = tw.Abort()
+		return err
+	}
+	return tw.Close()
 }
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// Prune removes the oldest backup sets in target, keeping the latest keep
+// sets together with the backups incremental sets among them are based on.
+// It returns the names of the manifests of the removed sets.
+func Prune(ctx context.Context, target Target, keep int, log *zap.Logger) ([]string, error) {
+	if keep <= 0 {
+		return nil, fmt.Errorf("number of backup sets to keep must be positive, got %d", keep)
+	}
+
+	manifests, err := target.List(ctx, ".manifest")
+	if err != nil {
+		return nil, err
+	}
+	return pruneManifests(ctx, target, manifests, keep, log)
+}
+
+// PruneSets is like Prune, but only removes backup sets with the manifests
+// called names, keeping the latest keep of them. Other backup sets in target,
+// such as those written by other jobs, are left alone.
+func PruneSets(ctx context.Context, target Target, names []string, keep int, log *zap.Logger) ([]string, error) {
+	if keep <= 0 {
+		return nil, fmt.Errorf("number of backup sets to keep must be positive, got %d", keep)
+	}
+
+	// Sets which were already removed are skipped.
+	existing, err := target.List(ctx, ".manifest")
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var manifests []string
+	for _, name := range existing {
+		if wanted[name] {
+			manifests = append(manifests, name)
+		}
+	}
+	return pruneManifests(ctx, target, manifests, keep, log)
+}
+
+// pruneManifests removes the backup sets of the sorted manifests beyond the
+// latest keep, unless a kept set is incrementally based on them.
+func pruneManifests(ctx context.Context, target Target, manifests []string, keep int, log *zap.Logger) ([]string, error) {
+	if len(manifests) <= keep {
+		return nil, nil
+	}
+
+	// Manifest names start with the time of their backup, so the latest
+	// sets come last.
+	kept := make(map[string]bool, keep)
+	for _, name := range manifests[len(manifests)-keep:] {
+		if err := keepChain(ctx, target, name, kept); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := target.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for _, name := range manifests {
+		if kept[name] {
+			continue
+		}
+
+		// The manifest is removed last, so a set which was only partially
+		// removed is pruned again next time.
+		baseName := strings.TrimSuffix(name, ".manifest")
+		for _, f := range files {
+			if f != name && strings.HasPrefix(f, baseName+".") {
+				if err := target.Remove(ctx, f); err != nil {
+					return pruned, err
+				}
+			}
+		}
+		if err := target.Remove(ctx, name); err != nil {
+			return pruned, err
+		}
+
+		log.Info("Pruned backup", zap.String("name", baseName))
+		pruned = append(pruned, name)
+	}
+	return pruned, nil
+}
+
+// keepChain marks the manifest called name and the manifests of the backups
+// it is incrementally based on as kept.
+func keepChain(ctx context.Context, target Target, name string, kept map[string]bool) error {
+	for name != "" && !kept[name] {
+		kept[name] = true
+
+		manifest, err := readManifest(ctx, target, name)
+		if err != nil {
+			return err
+		}
+		name = manifest.Parent
+	}
+	return nil
+}
+
+func readManifest(ctx context.Context, target Target, name string) (*influxdb.Manifest, error) {
+	rc, err := target.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var manifest influxdb.Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest %q: %w", name, err)
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,95 @@
+package backup_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func writeTestBackupSet(t *testing.T, target backup.Target, baseName, parent string) {
+	t.Helper()
+
+	manifest := influxdb.Manifest{
+		KV:     influxdb.ManifestKVEntry{FileName: baseName + ".bolt"},
+		Files:  []influxdb.ManifestEntry{{ShardID: 1, FileName: baseName + ".s1.tar.gz"}},
+		Parent: parent,
+	}
+	writeTargetFile(t, target, manifest.KV.FileName, "kv")
+	writeTargetFile(t, target, manifest.Files[0].FileName, "shard")
+
+	buf, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	writeTargetFile(t, target, baseName+".manifest", string(buf))
+}
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	target := backup.NewLocalTarget(dir)
+	writeTestBackupSet(t, target, "20200101T000000Z", "")
+	writeTestBackupSet(t, target, "20200102T000000Z", "")
+	writeTestBackupSet(t, target, "20200103T000000Z", "20200102T000000Z.manifest")
+	writeTestBackupSet(t, target, "20200104T000000Z", "")
+
+	// An interrupted backup without a manifest is left alone.
+	writeTargetFile(t, target, "20200105T000000Z.bolt", "kv")
+
+	pruned, err := backup.Prune(ctx, target, 2, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200101T000000Z.manifest"}, pruned)
+
+	names, err := target.List(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"20200102T000000Z.bolt",
+		"20200102T000000Z.manifest",
+		"20200102T000000Z.s1.tar.gz",
+		"20200103T000000Z.bolt",
+		"20200103T000000Z.manifest",
+		"20200103T000000Z.s1.tar.gz",
+		"20200104T000000Z.bolt",
+		"20200104T000000Z.manifest",
+		"20200104T000000Z.s1.tar.gz",
+		"20200105T000000Z.bolt",
+	}, names)
+
+	pruned, err = backup.Prune(ctx, target, 1, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200102T000000Z.manifest", "20200103T000000Z.manifest"}, pruned)
+
+	names, err = target.List(ctx, ".manifest")
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200104T000000Z.manifest"}, names)
+}
+
+func TestPruneSets(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	target := backup.NewLocalTarget(dir)
+	writeTestBackupSet(t, target, "20200101T000000Z", "")
+	writeTestBackupSet(t, target, "20200102T000000Z", "")
+	writeTestBackupSet(t, target, "20200103T000000Z", "")
+
+	// Sets which were already removed are ignored.
+	sets := []string{"20191231T000000Z.manifest", "20200103T000000Z.manifest", "20200101T000000Z.manifest"}
+	pruned, err := backup.PruneSets(ctx, target, sets, 1, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200101T000000Z.manifest"}, pruned)
+
+	names, err := target.List(ctx, ".manifest")
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200102T000000Z.manifest", "20200103T000000Z.manifest"}, names)
+}
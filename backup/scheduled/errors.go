@@ -0,0 +1,37 @@
+package scheduled
+
+import (
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var (
+	// ErrJobNotFound is used when the specified backup job cannot be found.
+	ErrJobNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "backup job not found",
+	}
+
+	// ErrJobExists is used when creating a backup job with a name that is
+	// already in use in the organization.
+	ErrJobExists = &errors.Error{
+		Code: errors.EConflict,
+		Msg:  "backup job already exists",
+	}
+)
+
+// ErrInvalidJob is used when a backup job definition is invalid.
+func ErrInvalidJob(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "invalid backup job",
+		Err:  err,
+	}
+}
+
+// ErrInternalServiceError is used when the error comes from an internal system.
+func ErrInternalServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,105 @@
+package scheduled
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+var _ scheduler.Executor = (*Executor)(nil)
+
+// Executor runs backup jobs on behalf of the scheduler.
+type Executor struct {
+	log       *zap.Logger
+	store     *Store
+	backupSvc influxdb.BackupService
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+// NewExecutor returns an Executor which runs the backup jobs held in store,
+// reading the data to backup from backupSvc.
+func NewExecutor(log *zap.Logger, store *Store, backupSvc influxdb.BackupService) *Executor {
+	return &Executor{
+		log:       log,
+		store:     store,
+		backupSvc: backupSvc,
+		now:       time.Now,
+	}
+}
+
+// Execute runs the backup job identified by id, prunes the backup sets
+// beyond its retention count, and records the run in the job's history.
+func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	j, err := e.store.FindJobByID(ctx, platform.ID(id))
+	if err != nil {
+		return err
+	}
+
+	log := e.log.With(
+		zap.String("org_id", j.OrganizationID.String()),
+		zap.String("job_name", j.Name),
+		zap.Time("scheduled_for", scheduledFor),
+	)
+
+	run := &Run{
+		JobID:        j.ID,
+		ScheduledFor: scheduledFor.UTC(),
+		StartedAt:    e.now().UTC(),
+	}
+
+	run.Manifest, err = backup.RunBackup(ctx, backup.Request{
+		OrgID:    j.OrganizationID,
+		BucketID: j.BucketID,
+		Path:     j.Path,
+	}, e.backupSvc, log)
+	if err == nil && j.RetainCount > 0 {
+		var sets []string
+		if sets, err = e.backupSets(ctx, j); err == nil {
+			sets = append(sets, run.Manifest)
+			run.Pruned, err = backup.PruneSets(ctx, backup.NewLocalTarget(j.Path), sets, j.RetainCount, log)
+		}
+	}
+
+	run.FinishedAt = e.now().UTC()
+	run.Status = RunSuccess
+	if err != nil {
+		run.Status, run.Error = RunFailed, err.Error()
+		log.Info("Backup job failed", zap.Error(err))
+	}
+
+	if err := e.store.AddRun(ctx, run); err != nil {
+		log.Error("Failed to record backup job run", zap.Error(err))
+	}
+	return err
+}
+
+// backupSets returns the manifests of the backup sets written by the runs of
+// j which were not pruned yet. Only these sets are pruned, so that backup
+// sets written to the same path by other jobs or by hand are kept.
+func (e *Executor) backupSets(ctx context.Context, j *Job) ([]string, error) {
+	runs, err := e.store.FindRuns(ctx, j.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := make(map[string]bool)
+	for _, r := range runs {
+		for _, name := range r.Pruned {
+			pruned[name] = true
+		}
+	}
+	var sets []string
+	for _, r := range runs {
+		if r.Status == RunSuccess && r.Manifest != "" && !pruned[r.Manifest] {
+			sets = append(sets, r.Manifest)
+		}
+	}
+	return sets, nil
+}
@@ -0,0 +1,219 @@
+package scheduled
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixBackupJobs is the path prefix of the backup jobs API.
+	PrefixBackupJobs = "/api/v2/backup/jobs"
+)
+
+// Handler serves the backup jobs API. Backup jobs write to the server's
+// file system, so all requests require operator permissions.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc *Service
+}
+
+// NewHTTPHandler constructs a new http server for the backup jobs of svc.
+func NewHTTPHandler(log *zap.Logger, svc *Service) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+		h.authorize,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetJobs)
+		r.Post("/", h.handlePostJob)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetJob)
+			r.Patch("/", h.handlePatchJob)
+			r.Delete("/", h.handleDeleteJob)
+			r.Get("/runs", h.handleGetRuns)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the path prefix the handler is mounted on.
+func (h *Handler) Prefix() string {
+	return PrefixBackupJobs
+}
+
+func (h *Handler) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorizer.IsAllowedAll(r.Context(), influxdb.OperPermissions()); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type getJobsResponse struct {
+	Jobs []*Job `json:"jobs"`
+}
+
+func (h *Handler) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	var filter JobFilter
+	if orgID := r.URL.Query().Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			h.api.Err(w, r, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			})
+			return
+		}
+		filter.OrgID = id
+	}
+
+	js, err := h.svc.FindJobs(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if js == nil {
+		js = []*Job{}
+	}
+	h.api.Respond(w, r, http.StatusOK, getJobsResponse{Jobs: js})
+}
+
+type postJobRequest struct {
+	OrganizationID platform.ID `json:"orgID"`
+	BucketID       platform.ID `json:"bucketID,omitempty"`
+	Name           string      `json:"name"`
+	Cron           string      `json:"cron"`
+	Path           string      `json:"path"`
+	RetainCount    int         `json:"retainCount,omitempty"`
+}
+
+func (h *Handler) handlePostJob(w http.ResponseWriter, r *http.Request) {
+	var req postJobRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	j := &Job{
+		OrganizationID: req.OrganizationID,
+		BucketID:       req.BucketID,
+		Name:           req.Name,
+		Cron:           req.Cron,
+		Path:           req.Path,
+		RetainCount:    req.RetainCount,
+	}
+	if err := h.svc.CreateJob(r.Context(), j); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusCreated, j)
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeJobID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	j, err := h.svc.FindJobByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, j)
+}
+
+func (h *Handler) handlePatchJob(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeJobID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	var upd JobUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	j, err := h.svc.UpdateJob(r.Context(), id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, j)
+}
+
+func (h *Handler) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeJobID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.DeleteJob(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+type getRunsResponse struct {
+	Runs []*Run `json:"runs"`
+}
+
+func (h *Handler) handleGetRuns(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeJobID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	runs, err := h.svc.FindRuns(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if runs == nil {
+		runs = []*Run{}
+	}
+	h.api.Respond(w, r, http.StatusOK, getRunsResponse{Runs: runs})
+}
+
+func decodeJobID(r *http.Request) (platform.ID, error) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid backup job id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
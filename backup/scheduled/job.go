@@ -0,0 +1,152 @@
+// Package scheduled runs backups inside the server on a cron schedule. Backup
+// jobs are stored in the kv store and executed using the task scheduler. Each
+// run is recorded in the job's history, and the old backup sets written by a
+// job are pruned according to its retention policy.
+package scheduled
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+// Job is a stored backup job.
+type Job struct {
+	ID             platform.ID `json:"id"`
+	OrganizationID platform.ID `json:"orgID"`
+	// BucketID is the bucket to backup.
+	// If not set, all buckets of the organization are backed up.
+	BucketID platform.ID `json:"bucketID,omitempty"`
+	Name     string      `json:"name"`
+	// Cron is the schedule of the job, in the syntax of task schedules.
+	Cron string `json:"cron"`
+	// Path is the directory on the server backup sets are written to.
+	// Jobs only write to the local disk of the server, they don't support
+	// the S3 backup target of influx backup.
+	Path string `json:"path"`
+	// RetainCount is the number of backup sets written by the job which are
+	// kept in Path. Other backup sets in Path are never pruned.
+	// If zero, backup sets are never pruned.
+	RetainCount int `json:"retainCount,omitempty"`
+	// LatestScheduled is the last time the job was scheduled for execution.
+	LatestScheduled time.Time `json:"latestScheduled,omitempty"`
+}
+
+// JobFilter represents a set of filters that restrict the returned jobs.
+type JobFilter struct {
+	OrgID *platform.ID
+	Name  *string
+}
+
+// Match returns true if the job matches the filter.
+func (f JobFilter) Match(j *Job) bool {
+	if f.OrgID != nil && *f.OrgID != j.OrganizationID {
+		return false
+	}
+	if f.Name != nil && *f.Name != j.Name {
+		return false
+	}
+	return true
+}
+
+// JobUpdate holds the fields of a job to change.
+type JobUpdate struct {
+	Name        *string `json:"name,omitempty"`
+	Cron        *string `json:"cron,omitempty"`
+	Path        *string `json:"path,omitempty"`
+	RetainCount *int    `json:"retainCount,omitempty"`
+}
+
+// Apply changes the fields of j set in the update.
+func (u JobUpdate) Apply(j *Job) {
+	if u.Name != nil {
+		j.Name = *u.Name
+	}
+	if u.Cron != nil {
+		j.Cron = *u.Cron
+	}
+	if u.Path != nil {
+		j.Path = *u.Path
+	}
+	if u.RetainCount != nil {
+		j.RetainCount = *u.RetainCount
+	}
+}
+
+// Valid returns an error if the job cannot be scheduled.
+func (j *Job) Valid() error {
+	if !j.OrganizationID.Valid() {
+		return errors.New("backup job organization required")
+	}
+	if j.Name == "" {
+		return errors.New("backup job name required")
+	}
+	if err := scheduler.ValidateSchedule(j.Cron); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(j.Path) {
+		return errors.New("backup job path must be an absolute path on the server")
+	}
+	if j.RetainCount < 0 {
+		return errors.New("backup job retain count must not be negative")
+	}
+	// Backup sets are only pruned while their runs are in the job history.
+	if j.RetainCount >= MaxRunHistory {
+		return fmt.Errorf("backup job retain count must be less than %d", MaxRunHistory)
+	}
+	return nil
+}
+
+// RunStatus is the outcome of a run of a backup job.
+type RunStatus string
+
+const (
+	RunSuccess RunStatus = "success"
+	RunFailed  RunStatus = "failed"
+)
+
+// Run records a single execution of a backup job.
+type Run struct {
+	ID           platform.ID `json:"id"`
+	JobID        platform.ID `json:"jobID"`
+	ScheduledFor time.Time   `json:"scheduledFor"`
+	StartedAt    time.Time   `json:"startedAt"`
+	FinishedAt   time.Time   `json:"finishedAt"`
+	Status       RunStatus   `json:"status"`
+	// Manifest is the name of the manifest of the backup set written by the run.
+	Manifest string `json:"manifest,omitempty"`
+	// Pruned holds the manifests of the backup sets removed after the run.
+	Pruned []string `json:"pruned,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+var _ scheduler.Schedulable = (*schedulable)(nil)
+
+// schedulable adapts a Job to the scheduler.Schedulable interface.
+type schedulable struct {
+	id            scheduler.ID
+	schedule      scheduler.Schedule
+	lastScheduled time.Time
+}
+
+func newSchedulable(j *Job) (*schedulable, error) {
+	sch, lastScheduled, err := scheduler.NewSchedule(j.Cron, j.LatestScheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulable{
+		id:            scheduler.ID(j.ID),
+		schedule:      sch,
+		lastScheduled: lastScheduled,
+	}, nil
+}
+
+func (s *schedulable) ID() scheduler.ID             { return s.id }
+func (s *schedulable) Schedule() scheduler.Schedule { return s.schedule }
+func (s *schedulable) Offset() time.Duration        { return 0 }
+func (s *schedulable) LastScheduled() time.Time     { return s.lastScheduled }
@@ -0,0 +1,133 @@
+package scheduled
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+// Service manages backup jobs, keeping the scheduler in sync with the
+// definitions held in the Store.
+type Service struct {
+	log       *zap.Logger
+	store     *Store
+	scheduler scheduler.Scheduler
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+// NewService returns a Service which persists backup jobs in store and
+// schedules them on sch.
+func NewService(log *zap.Logger, store *Store, sch scheduler.Scheduler) *Service {
+	return &Service{
+		log:       log,
+		store:     store,
+		scheduler: sch,
+		now:       time.Now,
+	}
+}
+
+// Open schedules all existing backup jobs.
+func (s *Service) Open(ctx context.Context) error {
+	js, err := s.store.FindJobs(ctx, JobFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, j := range js {
+		if err := s.schedule(j); err != nil {
+			s.log.Error("Failed to schedule backup job",
+				zap.String("org_id", j.OrganizationID.String()),
+				zap.String("name", j.Name),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// CreateJob validates, stores and schedules a backup job.
+func (s *Service) CreateJob(ctx context.Context, j *Job) error {
+	if err := j.Valid(); err != nil {
+		return ErrInvalidJob(err)
+	}
+
+	if j.LatestScheduled.IsZero() {
+		j.LatestScheduled = s.now().UTC()
+	}
+
+	if err := s.store.CreateJob(ctx, j); err != nil {
+		return err
+	}
+
+	if err := s.schedule(j); err != nil {
+		// Do not leave behind a job which will never run.
+		if derr := s.store.DeleteJob(ctx, j.ID); derr != nil {
+			s.log.Error("Failed to remove unschedulable backup job", zap.Error(derr))
+		}
+		return ErrInvalidJob(err)
+	}
+	return nil
+}
+
+// FindJobByID returns a single backup job by ID.
+func (s *Service) FindJobByID(ctx context.Context, id platform.ID) (*Job, error) {
+	return s.store.FindJobByID(ctx, id)
+}
+
+// FindJobs returns all backup jobs matching filter.
+func (s *Service) FindJobs(ctx context.Context, filter JobFilter) ([]*Job, error) {
+	return s.store.FindJobs(ctx, filter)
+}
+
+// UpdateJob changes a backup job and reschedules it.
+func (s *Service) UpdateJob(ctx context.Context, id platform.ID, upd JobUpdate) (*Job, error) {
+	j, err := s.store.UpdateJob(ctx, id, upd, func(j *Job) error {
+		if err := j.Valid(); err != nil {
+			return ErrInvalidJob(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduler.Release(scheduler.ID(j.ID)); err != nil {
+		return nil, err
+	}
+	if err := s.schedule(j); err != nil {
+		return nil, ErrInvalidJob(err)
+	}
+	return j, nil
+}
+
+// DeleteJob unschedules and removes a backup job together with its run
+// history. Backup sets written by the job are left in place.
+func (s *Service) DeleteJob(ctx context.Context, id platform.ID) error {
+	if _, err := s.store.FindJobByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.scheduler.Release(scheduler.ID(id)); err != nil {
+		return err
+	}
+	return s.store.DeleteJob(ctx, id)
+}
+
+// FindRuns returns the run history of a backup job, latest run first.
+func (s *Service) FindRuns(ctx context.Context, id platform.ID) ([]*Run, error) {
+	if _, err := s.store.FindJobByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.store.FindRuns(ctx, id)
+}
+
+func (s *Service) schedule(j *Job) error {
+	sch, err := newSchedulable(j)
+	if err != nil {
+		return err
+	}
+	return s.scheduler.Schedule(sch)
+}
@@ -0,0 +1,319 @@
+package scheduled_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup/scheduled"
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/v1/services/meta"
+	"go.uber.org/zap/zaptest"
+)
+
+func NewTestInmemStore(t *testing.T) kv.Store {
+	t.Helper()
+
+	store := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+type fakeScheduler struct {
+	scheduled map[scheduler.ID]scheduler.Schedulable
+}
+
+func (s *fakeScheduler) Schedule(sch scheduler.Schedulable) error {
+	s.scheduled[sch.ID()] = sch
+	return nil
+}
+
+func (s *fakeScheduler) Release(id scheduler.ID) error {
+	delete(s.scheduled, id)
+	return nil
+}
+
+func TestService_CreateUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	store := scheduled.NewStore(NewTestInmemStore(t))
+	sch := &fakeScheduler{scheduled: make(map[scheduler.ID]scheduler.Schedulable)}
+	svc := scheduled.NewService(zaptest.NewLogger(t), store, sch)
+
+	orgID := platform.ID(0xff00)
+	j := &scheduled.Job{
+		OrganizationID: orgID,
+		Name:           "nightly",
+		Cron:           "0 2 * * *",
+		Path:           "/var/lib/influxdb/backups",
+		RetainCount:    7,
+	}
+	if err := svc.CreateJob(ctx, j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := sch.scheduled[scheduler.ID(j.ID)]; !ok {
+		t.Fatal("expected backup job to be scheduled")
+	}
+
+	dup := *j
+	if err := svc.CreateJob(ctx, &dup); errors.ErrorCode(err) != errors.EConflict {
+		t.Fatalf("expected conflict creating duplicate, got %v", err)
+	}
+
+	for _, invalid := range []scheduled.Job{
+		{OrganizationID: orgID, Name: "bad-cron", Cron: "every day", Path: "/backups"},
+		{OrganizationID: orgID, Name: "relative-path", Cron: "@daily", Path: "backups"},
+		{OrganizationID: orgID, Name: "negative-retention", Cron: "@daily", Path: "/backups", RetainCount: -1},
+		{OrganizationID: orgID, Name: "large-retention", Cron: "@daily", Path: "/backups", RetainCount: scheduled.MaxRunHistory},
+		{Name: "no-org", Cron: "@daily", Path: "/backups"},
+	} {
+		invalid := invalid
+		if err := svc.CreateJob(ctx, &invalid); errors.ErrorCode(err) != errors.EInvalid {
+			t.Fatalf("expected invalid error creating %q, got %v", invalid.Name, err)
+		}
+	}
+
+	js, err := svc.FindJobs(ctx, scheduled.JobFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(js) != 1 || js[0].Name != "nightly" || js[0].RetainCount != 7 {
+		t.Fatalf("unexpected backup jobs: %+v", js)
+	}
+
+	cron := "@hourly"
+	updated, err := svc.UpdateJob(ctx, j.ID, scheduled.JobUpdate{Cron: &cron})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Cron != cron || updated.Path != j.Path {
+		t.Fatalf("unexpected updated job: %+v", updated)
+	}
+	next, err := sch.scheduled[scheduler.ID(j.ID)].Schedule().Next(time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC))
+	if err != nil || !next.Equal(time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected backup job to be rescheduled hourly, next run at %s, %v", next, err)
+	}
+
+	path := "backups"
+	if _, err := svc.UpdateJob(ctx, j.ID, scheduled.JobUpdate{Path: &path}); errors.ErrorCode(err) != errors.EInvalid {
+		t.Fatalf("expected invalid error updating path, got %v", err)
+	}
+	if found, err := svc.FindJobByID(ctx, j.ID); err != nil || found.Path != j.Path {
+		t.Fatalf("expected invalid update to be discarded, got %+v, %v", found, err)
+	}
+
+	if err := svc.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := sch.scheduled[scheduler.ID(j.ID)]; ok {
+		t.Fatal("expected backup job to be released")
+	}
+	if _, err := svc.FindJobByID(ctx, j.ID); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found after delete, got %v", err)
+	}
+	if err := svc.DeleteJob(ctx, j.ID); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found deleting again, got %v", err)
+	}
+}
+
+func TestStore_RunHistory(t *testing.T) {
+	ctx := context.Background()
+	store := scheduled.NewStore(NewTestInmemStore(t))
+
+	j := &scheduled.Job{OrganizationID: 0xff00, Name: "nightly", Cron: "@daily", Path: "/backups"}
+	if err := store.CreateJob(ctx, j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < scheduled.MaxRunHistory+5; i++ {
+		run := &scheduled.Run{JobID: j.ID, ScheduledFor: start.Add(time.Duration(i) * time.Hour), Status: scheduled.RunSuccess}
+		if err := store.AddRun(ctx, run); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	runs, err := store.FindRuns(ctx, j.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != scheduled.MaxRunHistory {
+		t.Fatalf("expected %d runs, got %d", scheduled.MaxRunHistory, len(runs))
+	}
+	if want := start.Add((scheduled.MaxRunHistory + 4) * time.Hour); !runs[0].ScheduledFor.Equal(want) {
+		t.Fatalf("expected latest run first, got run scheduled for %s", runs[0].ScheduledFor)
+	}
+	if want := start.Add(5 * time.Hour); !runs[len(runs)-1].ScheduledFor.Equal(want) {
+		t.Fatalf("expected oldest runs to be dropped, got run scheduled for %s", runs[len(runs)-1].ScheduledFor)
+	}
+
+	if err := store.DeleteJob(ctx, j.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runs, err := store.FindRuns(ctx, j.ID); err != nil || len(runs) != 0 {
+		t.Fatalf("expected run history to be removed with job, got %d runs, %v", len(runs), err)
+	}
+}
+
+// kvBackupService serves a KV store backup from a bolt file. It has no shards.
+type kvBackupService struct {
+	influxdb.BackupService
+	path string
+	err  error
+}
+
+func (s *kvBackupService) BackupKVStore(_ context.Context, w io.Writer) error {
+	if s.err != nil {
+		return s.err
+	}
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func newTestKVBackup(t *testing.T, dir string, org *influxdb.Organization) string {
+	t.Helper()
+
+	ctx := context.Background()
+	path := filepath.Join(dir, "influxd.bolt")
+	kvStore := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := kvStore.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer kvStore.Close()
+
+	if err := all.Up(ctx, zaptest.NewLogger(t), kvStore); err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(tenant.NewStore(kvStore))
+	if err := ts.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the system buckets of the organization a database without shards.
+	metaClient := meta.NewClient(meta.NewConfig(), kvStore)
+	if err := metaClient.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer metaClient.Close()
+	buckets, _, err := ts.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range buckets {
+		if _, err := metaClient.CreateDatabase(b.ID.String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "backup-job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	org := &influxdb.Organization{Name: "org"}
+	backupSvc := &kvBackupService{path: newTestKVBackup(t, dir, org)}
+
+	store := scheduled.NewStore(NewTestInmemStore(t))
+	j := &scheduled.Job{
+		OrganizationID: org.ID,
+		Name:           "nightly",
+		Cron:           "@daily",
+		Path:           filepath.Join(dir, "backups"),
+		RetainCount:    1,
+	}
+	if err := store.CreateJob(ctx, j); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// An older backup set written by the job, which is pruned after the run,
+	// and a backup set written by hand, which is kept.
+	if err := os.MkdirAll(j.Path, 0777); err != nil {
+		t.Fatal(err)
+	}
+	for _, base := range []string{"20200101T000000Z", "20200102T000000Z"} {
+		old, err := json.Marshal(influxdb.Manifest{KV: influxdb.ManifestKVEntry{FileName: base + ".bolt"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string][]byte{
+			base + ".bolt":     nil,
+			base + ".manifest": old,
+		} {
+			if err := ioutil.WriteFile(filepath.Join(j.Path, name), data, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := store.AddRun(ctx, &scheduled.Run{
+		JobID:        j.ID,
+		ScheduledFor: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		Status:       scheduled.RunSuccess,
+		Manifest:     "20200102T000000Z.manifest",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exec := scheduled.NewExecutor(zaptest.NewLogger(t), store, backupSvc)
+	scheduledFor := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := exec.Execute(ctx, scheduler.ID(j.ID), scheduledFor, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	backupSvc.err = fmt.Errorf("backup failed")
+	if err := exec.Execute(ctx, scheduler.ID(j.ID), scheduledFor.Add(24*time.Hour), time.Now()); err == nil {
+		t.Fatal("expected error from failed backup")
+	}
+
+	runs, err := store.FindRuns(ctx, j.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+
+	failed, succeeded := runs[0], runs[1]
+	if failed.Status != scheduled.RunFailed || failed.Error == "" || failed.Manifest != "" {
+		t.Fatalf("unexpected failed run: %+v", failed)
+	}
+	if succeeded.Status != scheduled.RunSuccess || succeeded.Error != "" || !succeeded.ScheduledFor.Equal(scheduledFor) {
+		t.Fatalf("unexpected successful run: %+v", succeeded)
+	}
+	if len(succeeded.Pruned) != 1 || succeeded.Pruned[0] != "20200102T000000Z.manifest" {
+		t.Fatalf("expected old backup set of job to be pruned, got %v", succeeded.Pruned)
+	}
+	if _, err := os.Stat(filepath.Join(j.Path, succeeded.Manifest)); err != nil {
+		t.Fatalf("expected manifest of run to exist: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(j.Path, "20200102T000000Z.bolt")); !os.IsNotExist(err) {
+		t.Fatalf("expected old backup set of job to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(j.Path, "20200101T000000Z.bolt")); err != nil {
+		t.Fatalf("expected backup set written by hand to be kept: %v", err)
+	}
+}
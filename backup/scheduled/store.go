@@ -0,0 +1,377 @@
+package scheduled
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+var (
+	jobBucket = []byte("backupjobsv1")
+	runBucket = []byte("backupjobrunsv1")
+)
+
+// MaxRunHistory is the number of runs kept in the history of a job.
+const MaxRunHistory = 100
+
+var _ scheduler.SchedulableService = (*Store)(nil)
+
+// Store persists backup jobs and their run history in a kv.Store.
+type Store struct {
+	kvStore kv.Store
+	IDGen   platform.IDGenerator
+}
+
+// NewStore returns a Store backed by kvStore.
+func NewStore(kvStore kv.Store) *Store {
+	return &Store{
+		kvStore: kvStore,
+		IDGen:   snowflake.NewDefaultIDGenerator(),
+	}
+}
+
+// CreateJob stores a new backup job, assigning it an ID.
+// The name of the job must be unique within its organization.
+func (s *Store) CreateJob(ctx context.Context, j *Job) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		j.ID = s.IDGen.ID()
+		if err := uniqueJobName(tx, j); err != nil {
+			return err
+		}
+		return putJob(tx, j)
+	})
+}
+
+// FindJobByID returns a single backup job by ID.
+func (s *Store) FindJobByID(ctx context.Context, id platform.ID) (*Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var j *Job
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		var err error
+		j, err = findJobByID(tx, id)
+		return err
+	})
+	return j, err
+}
+
+// FindJobs returns all backup jobs matching filter.
+func (s *Store) FindJobs(ctx context.Context, filter JobFilter) ([]*Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var js []*Job
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		var err error
+		js, err = findJobs(tx, filter)
+		return err
+	})
+	return js, err
+}
+
+// UpdateJob applies upd to the backup job identified by id, validating the
+// result with valid before storing it.
+func (s *Store) UpdateJob(ctx context.Context, id platform.ID, upd JobUpdate, valid func(*Job) error) (*Job, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var j *Job
+	err := s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		var err error
+		if j, err = findJobByID(tx, id); err != nil {
+			return err
+		}
+
+		upd.Apply(j)
+		if err := valid(j); err != nil {
+			return err
+		}
+		if upd.Name != nil {
+			if err := uniqueJobName(tx, j); err != nil {
+				return err
+			}
+		}
+		return putJob(tx, j)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// DeleteJob removes a backup job and its run history by ID.
+func (s *Store) DeleteJob(ctx context.Context, id platform.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		if _, err := findJobByID(tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidJob(err)
+		}
+		b, err := tx.Bucket(jobBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		keys, err := runKeys(tx, id)
+		if err != nil {
+			return err
+		}
+		return deleteRuns(tx, keys)
+	})
+}
+
+// UpdateLastScheduled records the last time a backup job was scheduled for
+// execution.
+func (s *Store) UpdateLastScheduled(ctx context.Context, id scheduler.ID, t time.Time) error {
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		j, err := findJobByID(tx, platform.ID(id))
+		if err != nil {
+			return err
+		}
+		j.LatestScheduled = t.UTC()
+		return putJob(tx, j)
+	})
+}
+
+// AddRun records a run in the history of its job, assigning it an ID.
+// Only the latest MaxRunHistory runs of a job are kept.
+func (s *Store) AddRun(ctx context.Context, r *Run) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		r.ID = s.IDGen.ID()
+		key, err := runKey(r.JobID, r.ID)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(r)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		b, err := tx.Bucket(runBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		if err := b.Put(key, v); err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		keys, err := runKeys(tx, r.JobID)
+		if err != nil {
+			return err
+		}
+		if len(keys) > MaxRunHistory {
+			return deleteRuns(tx, keys[:len(keys)-MaxRunHistory])
+		}
+		return nil
+	})
+}
+
+// FindRuns returns the run history of a job, latest run first.
+func (s *Store) FindRuns(ctx context.Context, jobID platform.ID) ([]*Run, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var runs []*Run
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		prefix, err := jobID.Encode()
+		if err != nil {
+			return ErrInvalidJob(err)
+		}
+		b, err := tx.Bucket(runBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			r := &Run{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return ErrInternalServiceError(err)
+			}
+			runs = append(runs, r)
+		}
+		if err := cur.Err(); err != nil {
+			return ErrInternalServiceError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Run IDs increase over time, so the runs were read oldest first.
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs, nil
+}
+
+func uniqueJobName(tx kv.Tx, j *Job) error {
+	existing, err := findJobs(tx, JobFilter{
+		OrgID: &j.OrganizationID,
+		Name:  &j.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if e.ID != j.ID {
+			return ErrJobExists
+		}
+	}
+	return nil
+}
+
+func findJobByID(tx kv.Tx, id platform.ID) (*Job, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidJob(err)
+	}
+
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	j := &Job{}
+	if err := json.Unmarshal(v, j); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return j, nil
+}
+
+func findJobs(tx kv.Tx, filter JobFilter) ([]*Job, error) {
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	defer cur.Close()
+
+	var js []*Job
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		j := &Job{}
+		if err := json.Unmarshal(v, j); err != nil {
+			return nil, ErrInternalServiceError(err)
+		}
+		if filter.Match(j) {
+			js = append(js, j)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return js, nil
+}
+
+func putJob(tx kv.Tx, j *Job) error {
+	encodedID, err := j.ID.Encode()
+	if err != nil {
+		return ErrInvalidJob(err)
+	}
+
+	v, err := json.Marshal(j)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	b, err := tx.Bucket(jobBucket)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalServiceError(err)
+	}
+	return nil
+}
+
+// runKey returns the key of a run, which is prefixed by the ID of its job.
+func runKey(jobID, runID platform.ID) ([]byte, error) {
+	encodedJobID, err := jobID.Encode()
+	if err != nil {
+		return nil, ErrInvalidJob(err)
+	}
+	encodedRunID, err := runID.Encode()
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return append(encodedJobID, encodedRunID...), nil
+}
+
+// runKeys returns the keys of the runs of a job, oldest first.
+func runKeys(tx kv.Tx, jobID platform.ID) ([][]byte, error) {
+	prefix, err := jobID.Encode()
+	if err != nil {
+		return nil, ErrInvalidJob(err)
+	}
+
+	b, err := tx.Bucket(runBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	cur, err := b.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	defer cur.Close()
+
+	var keys [][]byte
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	if err := cur.Err(); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return keys, nil
+}
+
+func deleteRuns(tx kv.Tx, keys [][]byte) error {
+	b, err := tx.Bucket(runBucket)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return ErrInternalServiceError(err)
+		}
+	}
+	return nil
+}
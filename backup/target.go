@@ -0,0 +1,158 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// Target is the storage backup files are written to and restored from.
+type Target interface {
+	// Create returns a writer for a new backup file called name.
+	Create(ctx context.Context, name string) (TargetWriter, error)
+
+	// Open returns a reader for the backup file called name.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Stat returns information about the backup file called name.
+	// A not found error is returned if the file does not exist.
+	Stat(ctx context.Context, name string) (TargetFileInfo, error)
+
+	// List returns the sorted names of the backup files ending in suffix.
+	List(ctx context.Context, suffix string) ([]string, error)
+
+	// Remove deletes the backup file called name.
+	// Removing a file which does not exist is not an error.
+	Remove(ctx context.Context, name string) error
+}
+
+// TargetWriter writes a single backup file to a Target.
+type TargetWriter interface {
+	io.Writer
+
+	// Close commits the backup file to the target.
+	// The file is not visible in the target before Close returns.
+	Close() error
+
+	// Abort discards the backup file.
+	Abort() error
+}
+
+// suspendableWriter is implemented by TargetWriters whose incomplete files
+// are resumed by a later Create of the same file.
+type suspendableWriter interface {
+	// Suspend stops writing the backup file, keeping the data written so far
+	// to be resumed. The file is not visible in the target.
+	Suspend() error
+}
+
+// TargetFileInfo describes a backup file in a Target.
+type TargetFileInfo struct {
+	Size         int64
+	LastModified time.Time
+}
+
+func errTargetFileNotFound(name string) error {
+	return &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  fmt.Sprintf("backup file %q not found", name),
+	}
+}
+
+// LocalTarget is a Target storing backup files in a local directory.
+type LocalTarget struct {
+	Path string
+}
+
+var _ Target = (*LocalTarget)(nil)
+
+// NewLocalTarget returns a Target storing backup files in the directory at path.
+func NewLocalTarget(path string) *LocalTarget {
+	return &LocalTarget{Path: path}
+}
+
+func (t *LocalTarget) Create(_ context.Context, name string) (TargetWriter, error) {
+	if err := os.MkdirAll(t.Path, 0777); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(t.Path, name)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local backup file at %q: %w", path, err)
+	}
+	return &localWriter{File: f, path: path}, nil
+}
+
+func (t *LocalTarget) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(t.Path, name))
+	if os.IsNotExist(err) {
+		return nil, errTargetFileNotFound(name)
+	}
+	return f, err
+}
+
+func (t *LocalTarget) Stat(_ context.Context, name string) (TargetFileInfo, error) {
+	fi, err := os.Stat(filepath.Join(t.Path, name))
+	if os.IsNotExist(err) || err == nil && fi.IsDir() {
+		return TargetFileInfo{}, errTargetFileNotFound(name)
+	} else if err != nil {
+		return TargetFileInfo{}, err
+	}
+	return TargetFileInfo{Size: fi.Size(), LastModified: fi.ModTime().UTC()}, nil
+}
+
+func (t *LocalTarget) List(_ context.Context, suffix string) ([]string, error) {
+	fis, err := ioutil.ReadDir(t.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list backup files at %q: %w", t.Path, err)
+	}
+
+	var names []string
+	for _, fi := range fis {
+		if !fi.IsDir() && strings.HasSuffix(fi.Name(), suffix) {
+			names = append(names, fi.Name())
+		}
+	}
+	return names, nil
+}
+
+func (t *LocalTarget) Remove(_ context.Context, name string) error {
+	if err := os.Remove(filepath.Join(t.Path, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove local backup file %q: %w", name, err)
+	}
+	return nil
+}
+
+// localWriter writes a backup file to a temporary file, which is renamed to
+// the backup file when closed.
+type localWriter struct {
+	*os.File
+	path string
+}
+
+func (w *localWriter) Close() error {
+	if err := w.File.Sync(); err != nil {
+		_ = w.Abort()
+		return fmt.Errorf("failed to flush backup file to local disk: %w", err)
+	}
+	if err := w.File.Close(); err != nil {
+		_ = os.Remove(w.File.Name())
+		return fmt.Errorf("failed to close local backup file at %q: %w", w.path, err)
+	}
+	return os.Rename(w.File.Name(), w.path)
+}
+
+func (w *localWriter) Abort() error {
+	_ = w.File.Close()
+	return os.Remove(w.File.Name())
+}
@@ -0,0 +1,406 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// DefaultS3PartSize is the default size of the parts of multipart uploads.
+const DefaultS3PartSize = 16 << 20
+
+// S3Config configures a Target storing backup files in an S3-compatible
+// object store.
+type S3Config struct {
+	// Bucket and key prefix the backup files are stored under.
+	Bucket string
+	Prefix string
+
+	// Endpoint of the object store. If not set, AWS S3 is used.
+	Endpoint string
+	Region   string
+
+	// ForcePathStyle addresses buckets in the path of request URLs rather
+	// than the host name, as required by most self-hosted object stores.
+	ForcePathStyle bool
+
+	// Static credentials. If not set, credentials are read from the
+	// environment or the shared credentials file.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// PartSize is the size of the parts backup files are uploaded in.
+	// Files up to this size are uploaded in a single request.
+	PartSize int64
+}
+
+// S3Target is a Target storing backup files in an S3-compatible object store.
+// Backup files are streamed to the store with multipart uploads, so no local
+// disk space is needed for them. The multipart uploads of interrupted backups
+// are resumed, so the parts already uploaded are not uploaded again.
+//
+// Multipart uploads of backups which are never resumed are left in the
+// bucket, so it should have a lifecycle rule aborting incomplete uploads.
+type S3Target struct {
+	client   s3iface.S3API
+	bucket   string
+	prefix   string
+	partSize int64
+}
+
+var _ Target = (*S3Target)(nil)
+
+// NewS3Target returns a Target storing backup files in the bucket of an
+// S3-compatible object store.
+func NewS3Target(c S3Config) (*S3Target, error) {
+	if c.Bucket == "" {
+		return nil, fmt.Errorf("bucket of S3 backup target is required")
+	}
+
+	cfg := aws.NewConfig().
+		WithS3ForcePathStyle(c.ForcePathStyle)
+	if c.Endpoint != "" {
+		cfg = cfg.WithEndpoint(c.Endpoint)
+	}
+	if c.Region != "" {
+		cfg = cfg.WithRegion(c.Region)
+	}
+	if c.AccessKeyID != "" {
+		cfg = cfg.WithCredentials(credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, ""))
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
+	}
+
+	partSize := c.PartSize
+	if partSize <= 0 {
+		partSize = DefaultS3PartSize
+	}
+
+	return &S3Target{
+		client:   s3.New(sess),
+		bucket:   c.Bucket,
+		prefix:   strings.Trim(c.Prefix, "/"),
+		partSize: partSize,
+	}, nil
+}
+
+func (t *S3Target) key(name string) string {
+	return path.Join(t.prefix, name)
+}
+
+// Create returns a writer uploading the backup file called name. If an
+// interrupted backup left behind an incomplete multipart upload of the file,
+// the latest one is resumed and the others are aborted.
+func (t *S3Target) Create(ctx context.Context, name string) (TargetWriter, error) {
+	w := &s3Writer{
+		ctx:    ctx,
+		target: t,
+		key:    t.key(name),
+		buf:    make([]byte, 0, t.partSize),
+	}
+	if err := w.resumeUpload(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (t *S3Target) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	out, err := t.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(name)),
+	})
+	if isS3NotFound(err) {
+		return nil, errTargetFileNotFound(name)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to download backup file %q: %w", name, err)
+	}
+	return out.Body, nil
+}
+
+func (t *S3Target) Stat(ctx context.Context, name string) (TargetFileInfo, error) {
+	out, err := t.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(name)),
+	})
+	if isS3NotFound(err) {
+		return TargetFileInfo{}, errTargetFileNotFound(name)
+	} else if err != nil {
+		return TargetFileInfo{}, fmt.Errorf("failed to inspect backup file %q: %w", name, err)
+	}
+	return TargetFileInfo{
+		Size:         aws.Int64Value(out.ContentLength),
+		LastModified: aws.TimeValue(out.LastModified).UTC(),
+	}, nil
+}
+
+// List returns the names of the backup files ending in suffix directly under
+// the prefix of the target, as listed by the object store.
+func (t *S3Target) List(ctx context.Context, suffix string) ([]string, error) {
+	prefix := t.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var names []string
+	in := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(t.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}
+	err := t.client.ListObjectsV2PagesWithContext(ctx, in, func(out *s3.ListObjectsV2Output, _ bool) bool {
+		for _, o := range out.Contents {
+			name := strings.TrimPrefix(aws.StringValue(o.Key), prefix)
+			if name != "" && !strings.Contains(name, "/") && strings.HasSuffix(name, suffix) {
+				names = append(names, name)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backup files: %w", err)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func (t *S3Target) Remove(ctx context.Context, name string) error {
+	_, err := t.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(t.bucket),
+		Key:    aws.String(t.key(name)),
+	})
+	if err != nil && !isS3NotFound(err) {
+		return fmt.Errorf("failed to remove backup file %q: %w", name, err)
+	}
+	return nil
+}
+
+func isS3NotFound(err error) bool {
+	if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchKey, s3.ErrCodeNoSuchUpload, "NotFound":
+			return true
+		}
+	}
+	return false
+}
+
+// s3Writer uploads a backup file in parts of the target's part size. Files
+// smaller than a single part are uploaded in one request when closed.
+type s3Writer struct {
+	ctx    context.Context
+	target *S3Target
+	key    string
+
+	buf      []byte
+	uploadID *string
+	parts    []*s3.CompletedPart
+
+	// uploaded holds the parts of a resumed upload by part number.
+	uploaded map[int64]*s3.Part
+}
+
+// resumeUpload continues the latest incomplete multipart upload of the file,
+// if any, and aborts the others.
+func (w *s3Writer) resumeUpload() error {
+	t := w.target
+	in := &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(t.bucket),
+		Prefix: aws.String(w.key),
+	}
+	var uploads []*s3.MultipartUpload
+	err := t.client.ListMultipartUploadsPagesWithContext(w.ctx, in, func(out *s3.ListMultipartUploadsOutput, _ bool) bool {
+		for _, u := range out.Uploads {
+			if aws.StringValue(u.Key) == w.key {
+				uploads = append(uploads, u)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list incomplete uploads of %q: %w", w.key, err)
+	}
+	if len(uploads) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(uploads, func(i, j int) bool {
+		return aws.TimeValue(uploads[i].Initiated).Before(aws.TimeValue(uploads[j].Initiated))
+	})
+	for _, u := range uploads[:len(uploads)-1] {
+		_, err := t.client.AbortMultipartUploadWithContext(w.ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(t.bucket),
+			Key:      u.Key,
+			UploadId: u.UploadId,
+		})
+		if err != nil && !isS3NotFound(err) {
+			return fmt.Errorf("failed to abort incomplete upload of %q: %w", w.key, err)
+		}
+	}
+
+	latest := uploads[len(uploads)-1]
+	uploaded := make(map[int64]*s3.Part)
+	err = t.client.ListPartsPagesWithContext(w.ctx, &s3.ListPartsInput{
+		Bucket:   aws.String(t.bucket),
+		Key:      latest.Key,
+		UploadId: latest.UploadId,
+	}, func(out *s3.ListPartsOutput, _ bool) bool {
+		for _, p := range out.Parts {
+			uploaded[aws.Int64Value(p.PartNumber)] = p
+		}
+		return true
+	})
+	if isS3NotFound(err) {
+		// the upload was completed or aborted in the meantime
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to list uploaded parts of %q: %w", w.key, err)
+	}
+
+	w.uploadID, w.uploaded = latest.UploadId, uploaded
+	return nil
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		free := int(w.target.partSize) - len(w.buf)
+		if free > len(p) {
+			free = len(p)
+		}
+		w.buf = append(w.buf, p[:free]...)
+		p = p[free:]
+
+		if int64(len(w.buf)) == w.target.partSize {
+			if err := w.uploadPart(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+// uploadPart uploads the buffered data as the next part of the multipart upload,
+// starting the upload if needed.
+func (w *s3Writer) uploadPart() error {
+	t := w.target
+	if w.uploadID == nil {
+		out, err := t.client.CreateMultipartUploadWithContext(w.ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(t.bucket),
+			Key:    aws.String(w.key),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start upload of %q: %w", w.key, err)
+		}
+		w.uploadID = out.UploadId
+	}
+
+	num := aws.Int64(int64(len(w.parts) + 1))
+
+	// Parts of a resumed upload holding the same data are not uploaded again.
+	// Parts holding other data are replaced, as the data streamed to the
+	// file may have changed since the upload was interrupted.
+	if p, ok := w.uploaded[*num]; ok && aws.Int64Value(p.Size) == int64(len(w.buf)) {
+		sum := md5.Sum(w.buf)
+		if strings.Trim(aws.StringValue(p.ETag), `"`) == hex.EncodeToString(sum[:]) {
+			w.parts = append(w.parts, &s3.CompletedPart{ETag: p.ETag, PartNumber: num})
+			w.buf = w.buf[:0]
+			return nil
+		}
+	}
+
+	out, err := t.client.UploadPartWithContext(w.ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(t.bucket),
+		Key:        aws.String(w.key),
+		UploadId:   w.uploadID,
+		PartNumber: num,
+		Body:       bytes.NewReader(w.buf),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d of %q: %w", *num, w.key, err)
+	}
+
+	w.parts = append(w.parts, &s3.CompletedPart{ETag: out.ETag, PartNumber: num})
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *s3Writer) Close() error {
+	t := w.target
+	if len(w.parts) == 0 {
+		// A resumed upload is not needed for a file fitting in a single part.
+		if err := w.Abort(); err != nil && !isS3NotFound(err) {
+			return fmt.Errorf("failed to abort incomplete upload of %q: %w", w.key, err)
+		}
+
+		_, err := t.client.PutObjectWithContext(w.ctx, &s3.PutObjectInput{
+			Bucket: aws.String(t.bucket),
+			Key:    aws.String(w.key),
+			Body:   bytes.NewReader(w.buf),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upload %q: %w", w.key, err)
+		}
+		return nil
+	}
+
+	// The upload is kept on errors, so the parts uploaded are resumed.
+	if len(w.buf) > 0 {
+		if err := w.uploadPart(); err != nil {
+			return err
+		}
+	}
+
+	_, err := t.client.CompleteMultipartUploadWithContext(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(t.bucket),
+		Key:             aws.String(w.key),
+		UploadId:        w.uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: w.parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete upload of %q: %w", w.key, err)
+	}
+	return nil
+}
+
+// Suspend keeps the parts uploaded so far in the multipart upload, so a
+// later Create of the file resumes it. Data not filling a whole part yet is
+// dropped.
+func (w *s3Writer) Suspend() error {
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *s3Writer) Abort() error {
+	if w.uploadID == nil {
+		return nil
+	}
+
+	t := w.target
+	_, err := t.client.AbortMultipartUploadWithContext(w.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(t.bucket),
+		Key:      aws.String(w.key),
+		UploadId: w.uploadID,
+	})
+	return err
+}
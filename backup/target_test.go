@@ -0,0 +1,420 @@
+package backup_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/backup"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeS3Bucket = "backups"
+
+// fakeS3 is an in-memory stand-in for an S3-compatible object store, serving
+// the path-style requests made by the S3 backup target.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	uploads map[string]*fakeUpload
+	nextID  int
+
+	// partUploads counts the uploaded parts.
+	partUploads int
+}
+
+type fakeUpload struct {
+	key       string
+	initiated time.Time
+	parts     map[int][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects: make(map[string][]byte),
+		uploads: make(map[string]*fakeUpload),
+	}
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, "/"+fakeS3Bucket) {
+		s.writeError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+fakeS3Bucket), "/")
+	q := r.URL.Query()
+	_, uploads := q["uploads"]
+	uploadID := q.Get("uploadId")
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && uploads:
+		type upload struct {
+			Key       string
+			UploadId  string
+			Initiated string
+		}
+		var out struct {
+			XMLName xml.Name `xml:"ListMultipartUploadsResult"`
+			Bucket  string
+			Upload  []upload
+		}
+		out.Bucket = fakeS3Bucket
+		for id, u := range s.uploads {
+			if strings.HasPrefix(u.key, q.Get("prefix")) {
+				out.Upload = append(out.Upload, upload{Key: u.key, UploadId: id, Initiated: u.initiated.UTC().Format(time.RFC3339)})
+			}
+		}
+		s.writeXML(w, out)
+
+	case r.Method == http.MethodGet && uploadID != "":
+		u, ok := s.uploads[uploadID]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		type part struct {
+			PartNumber int
+			ETag       string
+			Size       int
+		}
+		var out struct {
+			XMLName  xml.Name `xml:"ListPartsResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+			Part     []part
+		}
+		out.Bucket, out.Key, out.UploadId = fakeS3Bucket, u.key, uploadID
+		for num, body := range u.parts {
+			out.Part = append(out.Part, part{PartNumber: num, ETag: fakeETag(body), Size: len(body)})
+		}
+		sort.Slice(out.Part, func(i, j int) bool { return out.Part[i].PartNumber < out.Part[j].PartNumber })
+		s.writeXML(w, out)
+
+	case key == "" && r.Method == http.MethodGet:
+		type content struct {
+			Key          string
+			Size         int
+			LastModified string
+		}
+		var out struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Name     string
+			Prefix   string
+			Contents []content
+		}
+		out.Name, out.Prefix = fakeS3Bucket, q.Get("prefix")
+		for k, v := range s.objects {
+			if strings.HasPrefix(k, out.Prefix) {
+				out.Contents = append(out.Contents, content{Key: k, Size: len(v), LastModified: time.Now().UTC().Format(time.RFC3339)})
+			}
+		}
+		sort.Slice(out.Contents, func(i, j int) bool { return out.Contents[i].Key < out.Contents[j].Key })
+		s.writeXML(w, out)
+
+	case r.Method == http.MethodPost && uploads:
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &fakeUpload{key: key, initiated: time.Now(), parts: make(map[int][]byte)}
+		s.writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: fakeS3Bucket, Key: key, UploadId: id})
+
+	case r.Method == http.MethodPut && uploadID != "":
+		u, ok := s.uploads[uploadID]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		num, _ := strconv.Atoi(q.Get("partNumber"))
+		body, _ := ioutil.ReadAll(r.Body)
+		u.parts[num] = body
+		s.partUploads++
+		w.Header().Set("ETag", fakeETag(body))
+
+	case r.Method == http.MethodPost && uploadID != "":
+		u, ok := s.uploads[uploadID]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		var in struct {
+			Part []struct {
+				PartNumber int
+			}
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&in); err != nil {
+			s.writeError(w, http.StatusBadRequest, "MalformedXML")
+			return
+		}
+		var data []byte
+		for _, p := range in.Part {
+			data = append(data, u.parts[p.PartNumber]...)
+		}
+		s.objects[u.key] = data
+		delete(s.uploads, uploadID)
+		s.writeXML(w, struct {
+			XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+			Bucket  string
+			Key     string
+		}{Bucket: fakeS3Bucket, Key: u.key})
+
+	case r.Method == http.MethodDelete && uploadID != "":
+		if _, ok := s.uploads[uploadID]; !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		delete(s.uploads, uploadID)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		s.objects[key] = body
+
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := s.objects[key]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+
+	default:
+		s.writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// fakeETag returns the ETag of a part, the quoted MD5 digest of its data.
+func fakeETag(body []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(body))
+}
+
+func (s *fakeS3) writeXML(w http.ResponseWriter, v interface{}) {
+	buf, err := xml.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write(buf)
+}
+
+func (s *fakeS3) writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func newTestS3Target(t *testing.T, partSize int64) (*backup.S3Target, *fakeS3) {
+	t.Helper()
+
+	fake := newFakeS3()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	target, err := backup.NewS3Target(backup.S3Config{
+		Bucket:          fakeS3Bucket,
+		Prefix:          "nightly",
+		Endpoint:        srv.URL,
+		Region:          "us-east-1",
+		ForcePathStyle:  true,
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		PartSize:        partSize,
+	})
+	require.NoError(t, err)
+	return target, fake
+}
+
+func writeTargetFile(t *testing.T, target backup.Target, name string, chunks ...string) {
+	t.Helper()
+
+	w, err := target.Create(context.Background(), name)
+	require.NoError(t, err)
+	for _, c := range chunks {
+		_, err := w.Write([]byte(c))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func readTargetFile(t *testing.T, target backup.Target, name string) string {
+	t.Helper()
+
+	rc, err := target.Open(context.Background(), name)
+	require.NoError(t, err)
+	defer rc.Close()
+	buf, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestS3Target(t *testing.T) {
+	ctx := context.Background()
+	target, fake := newTestS3Target(t, 8)
+
+	t.Run("single request upload", func(t *testing.T) {
+		writeTargetFile(t, target, "small.bolt", "hello")
+		require.Equal(t, "hello", string(fake.objects["nightly/small.bolt"]))
+		require.Equal(t, "hello", readTargetFile(t, target, "small.bolt"))
+
+		fi, err := target.Stat(ctx, "small.bolt")
+		require.NoError(t, err)
+		require.Equal(t, int64(5), fi.Size)
+	})
+
+	t.Run("multipart upload", func(t *testing.T) {
+		writeTargetFile(t, target, "large.tar.gz", "0123456789", "abcdefghij", "k")
+		require.Equal(t, "0123456789abcdefghijk", string(fake.objects["nightly/large.tar.gz"]))
+		require.Empty(t, fake.uploads)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		w, err := target.Create(ctx, "aborted.tar.gz")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("0123456789"))
+		require.NoError(t, err)
+		require.Len(t, fake.uploads, 1)
+
+		require.NoError(t, w.Abort())
+		require.Empty(t, fake.uploads)
+
+		_, err = target.Stat(ctx, "aborted.tar.gz")
+		require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+		_, err = target.Open(ctx, "aborted.tar.gz")
+		require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+	})
+
+	t.Run("resume interrupted uploads", func(t *testing.T) {
+		now := time.Now()
+		fake.uploads["older"] = &fakeUpload{key: "nightly/resumed.tar.gz", initiated: now.Add(-time.Hour), parts: map[int][]byte{1: []byte("01234567")}}
+		fake.uploads["interrupted"] = &fakeUpload{key: "nightly/resumed.tar.gz", initiated: now, parts: map[int][]byte{
+			1: []byte("01234567"),
+			2: []byte("stale..."),
+			4: []byte("extra"),
+		}}
+		fake.uploads["other"] = &fakeUpload{key: "nightly/other.tar.gz", parts: map[int][]byte{}}
+		fake.partUploads = 0
+
+		writeTargetFile(t, target, "resumed.tar.gz", "0123456789", "abcdefghij", "k")
+		require.Equal(t, "0123456789abcdefghijk", string(fake.objects["nightly/resumed.tar.gz"]))
+		// part 1 was already uploaded, only the stale part 2 and part 3 are
+		require.Equal(t, 2, fake.partUploads)
+		require.NotContains(t, fake.uploads, "older")
+		require.NotContains(t, fake.uploads, "interrupted")
+		require.Contains(t, fake.uploads, "other")
+	})
+
+	t.Run("resume interrupted upload of small file", func(t *testing.T) {
+		fake.uploads["small"] = &fakeUpload{key: "nightly/small.tar.gz", parts: map[int][]byte{1: []byte("stale")}}
+
+		writeTargetFile(t, target, "small.tar.gz", "fresh")
+		require.Equal(t, "fresh", string(fake.objects["nightly/small.tar.gz"]))
+		require.NotContains(t, fake.uploads, "small")
+		delete(fake.objects, "nightly/small.tar.gz")
+	})
+
+	t.Run("resume suspended upload", func(t *testing.T) {
+		// Streaming the file fails after two parts and some more data.
+		w, err := target.Create(ctx, "suspended.tar.gz")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("0123456789abcdef012"))
+		require.NoError(t, err)
+		require.NoError(t, w.(interface{ Suspend() error }).Suspend())
+		// the upload is kept, besides the one of other.tar.gz
+		require.Len(t, fake.uploads, 2)
+		_, err = target.Stat(ctx, "suspended.tar.gz")
+		require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+		fake.partUploads = 0
+		writeTargetFile(t, target, "suspended.tar.gz", "0123456789abcdef0123")
+		require.Equal(t, "0123456789abcdef0123", string(fake.objects["nightly/suspended.tar.gz"]))
+		// the two parts uploaded before the error are not uploaded again
+		require.Equal(t, 1, fake.partUploads)
+		require.Len(t, fake.uploads, 1)
+		delete(fake.objects, "nightly/suspended.tar.gz")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		fake.objects["nightly/20200101T000000Z.manifest"] = []byte("{}")
+		fake.objects["nightly/old/20190101T000000Z.manifest"] = []byte("{}")
+		fake.objects["weekly/20200101T000000Z.manifest"] = []byte("{}")
+
+		names, err := target.List(ctx, ".tar.gz")
+		require.NoError(t, err)
+		require.Equal(t, []string{"large.tar.gz", "resumed.tar.gz"}, names)
+
+		names, err = target.List(ctx, ".manifest")
+		require.NoError(t, err)
+		require.Equal(t, []string{"20200101T000000Z.manifest"}, names)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, target.Remove(ctx, "small.bolt"))
+		require.NotContains(t, fake.objects, "nightly/small.bolt")
+		require.NoError(t, target.Remove(ctx, "small.bolt"))
+	})
+}
+
+func TestLocalTarget(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	target := backup.NewLocalTarget(dir)
+	names, err := target.List(ctx, ".manifest")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	writeTargetFile(t, target, "20200101T000000Z.manifest", "{", "}")
+	require.Equal(t, "{}", readTargetFile(t, target, "20200101T000000Z.manifest"))
+
+	fi, err := target.Stat(ctx, "20200101T000000Z.manifest")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), fi.Size)
+
+	w, err := target.Create(ctx, "20200101T000000Z.s1.tar.gz")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, w.Abort())
+
+	_, err = target.Stat(ctx, "20200101T000000Z.s1.tar.gz")
+	require.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+	names, err = target.List(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"20200101T000000Z.manifest"}, names)
+
+	require.NoError(t, target.Remove(ctx, "20200101T000000Z.manifest"))
+	require.NoError(t, target.Remove(ctx, "20200101T000000Z.manifest"))
+	names, err = target.List(ctx, "")
+	require.NoError(t, err)
+	require.Empty(t, names)
+}
@@ -40,6 +40,7 @@ type Bucket struct {
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
 	ShardGroupDuration  time.Duration `json:"shardGroupDuration"`
+	SchemaType          SchemaType    `json:"schemaType,omitempty"`
 	CRUDLog
 }
 
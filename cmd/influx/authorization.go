@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"time"
 
 	platform2 "github.com/influxdata/influxdb/v2/kit/platform"
 
@@ -20,6 +22,7 @@ type token struct {
 	UserName    string       `json:"userName"`
 	UserID      platform2.ID `json:"userID"`
 	Permissions []string     `json:"permissions"`
+	ExpiresAt   *time.Time   `json:"expiresAt,omitempty"`
 }
 
 func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -34,6 +37,7 @@ func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 		authDeleteCmd(f, opt),
 		authFindCmd(f, opt),
 		authInactiveCmd(f, opt),
+		authRotateCmd(f, opt),
 	)
 
 	return cmd
@@ -49,6 +53,7 @@ var authCreateFlags struct {
 	user        string
 	description string
 	org         organization
+	expiresIn   time.Duration
 
 	writeUserPermission bool
 	readUserPermission  bool
@@ -96,6 +101,7 @@ func authCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 
 	cmd.Flags().StringVarP(&authCreateFlags.description, "description", "d", "", "Token description")
 	cmd.Flags().StringVarP(&authCreateFlags.user, "user", "u", "", "The user name")
+	cmd.Flags().DurationVarP(&authCreateFlags.expiresIn, "expires-in", "", 0, "Duration after which the token expires, e.g. 720h; the token never expires if not set")
 	registerPrintOptions(opt.viper, cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
 
 	cmd.Flags().BoolVarP(&authCreateFlags.writeUserPermission, "write-user", "", false, "Grants the permission to perform mutative actions against organization users")
@@ -260,6 +266,14 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		OrgID:       orgID,
 	}
 
+	if authCreateFlags.expiresIn < 0 {
+		return fmt.Errorf("expires-in must not be negative")
+	}
+	if authCreateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authCreateFlags.expiresIn).UTC()
+		authorization.ExpiresAt = &expiresAt
+	}
+
 	if userName := authCreateFlags.user; userName != "" {
 		user, err := userSvc.FindUser(context.Background(), platform.UserFilter{
 			Name: &userName,
@@ -300,6 +314,7 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 			UserName:    user.Name,
 			UserID:      user.ID,
 			Permissions: ps,
+			ExpiresAt:   authorization.ExpiresAt,
 		},
 	})
 }
@@ -394,6 +409,7 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 			UserName:    user.Name,
 			UserID:      a.UserID,
 			Permissions: permissions,
+			ExpiresAt:   a.ExpiresAt,
 		})
 	}
 
@@ -607,6 +623,76 @@ func authorizationInactiveF(cmd *cobra.Command, args []string) error {
 	})
 }
 
+var authRotateFlags struct {
+	gracePeriod time.Duration
+}
+
+func authRotateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Issue a new token for an authorization",
+		Long: `Issue a new token for an authorization, keeping its permissions. The
+replaced token keeps working for the grace period, so that clients can be
+switched over to the new token without downtime.`,
+		RunE: checkSetupRunEMiddleware(&flags)(authorizationRotateF),
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
+	cmd.Flags().StringVarP(&authCRUDFlags.id, "id", "i", "", "The authorization ID (required)")
+	cmd.Flags().DurationVarP(&authRotateFlags.gracePeriod, "grace-period", "", 0, "How long the replaced token remains valid, e.g. 24h")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func authorizationRotateF(cmd *cobra.Command, args []string) error {
+	s, err := newAuthorizationService()
+	if err != nil {
+		return err
+	}
+
+	us, err := newUserService()
+	if err != nil {
+		return err
+	}
+
+	var id platform2.ID
+	if err := id.DecodeFromString(authCRUDFlags.id); err != nil {
+		return err
+	}
+
+	a, err := s.RotateAuthorizationToken(context.Background(), id, authRotateFlags.gracePeriod)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.FindUserByID(context.Background(), a.UserID)
+	if err != nil {
+		return err
+	}
+
+	ps := make([]string, 0, len(a.Permissions))
+	for _, p := range a.Permissions {
+		ps = append(ps, p.String())
+	}
+
+	return writeTokens(cmd.OutOrStdout(), tokenPrintOpt{
+		jsonOut:     authCRUDFlags.json,
+		hideHeaders: authCRUDFlags.hideHeaders,
+		token: token{
+			ID:          a.ID,
+			Description: a.Description,
+			Token:       a.Token,
+			Status:      string(a.Status),
+			UserName:    user.Name,
+			UserID:      user.ID,
+			Permissions: ps,
+			ExpiresAt:   a.ExpiresAt,
+		},
+	})
+}
+
 type tokenPrintOpt struct {
 	jsonOut     bool
 	deleted     bool
@@ -664,7 +750,7 @@ func writeTokens(w io.Writer, printOpts tokenPrintOpt) error {
 	return nil
 }
 
-func newAuthorizationService() (platform.AuthorizationService, error) {
+func newAuthorizationService() (*authorization.AuthorizationClientService, error) {
 	httpClient, err := newHTTPClient()
 	if err != nil {
 		return nil, err
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 
@@ -20,10 +23,15 @@ type cmdBackupBuilder struct {
 	genericCLIOpts
 	*globalFlags
 
-	bucketID   string
-	bucketName string
-	org        organization
-	path       string
+	bucketID        string
+	bucketName      string
+	end             string
+	incrementalFrom string
+	org             organization
+	path            string
+	resume          bool
+	start           string
+	target          backupTargetFlags
 }
 
 func newCmdBackupBuilder(f *globalFlags, opts genericCLIOpts) *cmdBackupBuilder {
@@ -38,6 +46,11 @@ func (b *cmdBackupBuilder) cmdBackup() *cobra.Command {
 	b.org.register(b.viper, cmd, true)
 	cmd.Flags().StringVar(&b.bucketID, "bucket-id", "", "The ID of the bucket to backup")
 	cmd.Flags().StringVarP(&b.bucketName, "bucket", "b", "", "The name of the bucket to backup")
+	cmd.Flags().StringVar(&b.incrementalFrom, "incremental-from", "", "Path to the manifest of a previous backup in the same directory; only data changed since that backup is copied")
+	cmd.Flags().StringVar(&b.start, "start", "", "Only backup data after this time, in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	cmd.Flags().StringVar(&b.end, "end", "", "Only backup data before this time, in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	cmd.Flags().BoolVar(&b.resume, "resume", false, "Continue the latest backup at path if it was interrupted")
+	b.target.register(cmd)
 	cmd.Use = "backup [flags] path"
 	cmd.Args = func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -50,11 +63,23 @@ func (b *cmdBackupBuilder) cmdBackup() *cobra.Command {
 	}
 	cmd.Short = "Backup database"
 	cmd.Long = `
-Backs up InfluxDB to a directory.
+Backs up InfluxDB to a directory, or to an S3-compatible object store when
+path is an s3://bucket/prefix URL. S3 credentials are read from the
+AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables or the
+shared AWS credentials file.
 
 Examples:
 	# backup all data
 	influx backup /path/to/backup
+
+	# backup data changed since a previous backup
+	influx backup --incremental-from /path/to/backup/20200101T000000Z.manifest /path/to/backup
+
+	# backup the data of a bucket within a time range
+	influx backup -b my-bucket --start 2020-01-01T00:00:00Z --end 2020-01-02T00:00:00Z /path/to/backup
+
+	# backup all data to a MinIO server
+	influx backup --s3-endpoint http://localhost:9000 --s3-path-style s3://backups/influxdb
 `
 	return cmd
 }
@@ -88,18 +113,84 @@ func (b *cmdBackupBuilder) backupRunE(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
-	req := backup.Request{
-		OrgID:    orgID,
-		Org:      b.org.name,
-		BucketID: bucketID,
-		Bucket:   b.bucketName,
-		Path:     b.path,
+	start, end, err := parseTimeRange(b.start, b.end)
+	if err != nil {
+		return err
 	}
 
-	if err := backup.RunBackup(context.Background(), req, backupService, log); err != nil {
+	target, err := b.target.target(b.path)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	req := backup.Request{
+		OrgID:           orgID,
+		Org:             b.org.name,
+		BucketID:        bucketID,
+		Bucket:          b.bucketName,
+		Path:            b.path,
+		Target:          target,
+		IncrementalFrom: b.incrementalFrom,
+		Start:           start,
+		End:             end,
+		Resume:          b.resume,
+	}
+
+	_, err = backup.RunBackup(context.Background(), req, backupService, log)
+	return err
+}
+
+// backupTargetFlags configure the storage of backup files.
+type backupTargetFlags struct {
+	s3Endpoint  string
+	s3Region    string
+	s3PathStyle bool
+}
+
+func (f *backupTargetFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.s3Endpoint, "s3-endpoint", "", "Endpoint of the S3-compatible object store for s3:// paths; defaults to AWS S3")
+	cmd.Flags().StringVar(&f.s3Region, "s3-region", "us-east-1", "Region of the S3 bucket for s3:// paths")
+	cmd.Flags().BoolVar(&f.s3PathStyle, "s3-path-style", false, "Address the S3 bucket in the URL path, as required by most self-hosted object stores")
+}
+
+// target returns the backup target at path, which is either a local directory
+// or an s3://bucket/prefix URL.
+func (f *backupTargetFlags) target(path string) (backup.Target, error) {
+	if !strings.HasPrefix(path, "s3://") {
+		return backup.NewLocalTarget(path), nil
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 path %q: %v", path, err)
+	}
+	return backup.NewS3Target(backup.S3Config{
+		Bucket:         u.Host,
+		Prefix:         u.Path,
+		Endpoint:       f.s3Endpoint,
+		Region:         f.s3Region,
+		ForcePathStyle: f.s3PathStyle,
+	})
+}
+
+// parseTimeRange parses the optional RFC3339Nano bounds of a time range.
+func parseTimeRange(start, end string) (time.Time, time.Time, error) {
+	var s, e time.Time
+	if start != "" {
+		t, err := time.Parse(time.RFC3339Nano, start)
+		if err != nil {
+			return s, e, fmt.Errorf("invalid start time %q: %v", start, err)
+		}
+		s = t
+	}
+	if end != "" {
+		t, err := time.Parse(time.RFC3339Nano, end)
+		if err != nil {
+			return s, e, fmt.Errorf("invalid end time %q: %v", end, err)
+		}
+		e = t
+	}
+	return s, e, nil
 }
 
 func (b *cmdBackupBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
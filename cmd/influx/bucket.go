@@ -33,6 +33,7 @@ type cmdBucketBuilder struct {
 	org                organization
 	retention          string
 	shardGroupDuration string
+	schemaType         string
 }
 
 func newCmdBucketBuilder(svcsFn bucketSVCsFn, f *globalFlags, opts genericCLIOpts) *cmdBucketBuilder {
@@ -78,6 +79,8 @@ func (b *cmdBucketBuilder) cmdCreate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.retention, "retention", "r", "", "Duration bucket will retain data. 0 is infinite. Default is 0.")
 	cmd.Flags().StringVarP(&b.shardGroupDuration, "shard-group-duration", "", "",
 		"Shard group duration used internally by the storage engine. Not supported by InfluxDB Cloud.")
+	cmd.Flags().StringVarP(&b.schemaType, "schema-type", "", "",
+		"Schema type of the bucket, implicit or explicit. Explicit buckets only accept data matching their measurement schemas. Default is implicit.")
 	b.org.register(b.viper, cmd, false)
 	b.registerPrintFlags(cmd)
 
@@ -104,11 +107,17 @@ func (b *cmdBucketBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 		return err
 	}
 
+	schemaType := influxdb.SchemaType(b.schemaType)
+	if err := schemaType.Valid(); err != nil {
+		return err
+	}
+
 	bkt := &influxdb.Bucket{
 		Name:               b.name,
 		Description:        b.description,
 		RetentionPeriod:    dur,
 		ShardGroupDuration: shardGroupDuration,
+		SchemaType:         schemaType,
 	}
 	bkt.OrgID, err = b.org.getID(orgSVC)
 	if err != nil {
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/spf13/cobra"
+)
+
+type bucketSchemaSVCsFn func() (influxdb.MeasurementSchemaService, influxdb.BucketService, influxdb.OrganizationService, error)
+
+func cmdBucketSchema(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdBucketSchemaBuilder(newBucketSchemaSVCs, f, opt)
+	return builder.cmd()
+}
+
+type cmdBucketSchemaBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn bucketSchemaSVCsFn
+
+	bucketID    string
+	bucketName  string
+	id          string
+	name        string
+	columnsFile string
+	hideHeaders bool
+	json        bool
+	org         organization
+}
+
+func newCmdBucketSchemaBuilder(svcsFn bucketSchemaSVCsFn, f *globalFlags, opts genericCLIOpts) *cmdBucketSchemaBuilder {
+	return &cmdBucketSchemaBuilder{
+		globalFlags:    f,
+		genericCLIOpts: opts,
+		svcFn:          svcsFn,
+	}
+}
+
+func (b *cmdBucketSchemaBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("bucket-schema", nil)
+	cmd.Short = "Bucket measurement schema management commands"
+	cmd.TraverseChildren = true
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdCreate(),
+		b.cmdList(),
+		b.cmdUpdate(),
+	)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdCreate() *cobra.Command {
+	cmd := b.newCmd("create", b.cmdCreateRunEFn)
+	cmd.Short = "Create a measurement schema for a bucket"
+
+	b.registerBucketFlags(cmd)
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "Name of the measurement (required)")
+	cmd.Flags().StringVarP(&b.columnsFile, "columns-file", "", "", "Path to a JSON file with an array of the columns of the measurement (required)")
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("columns-file")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
+	schemaSVC, bktSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	columns, err := b.readColumns()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	bucketID, err := b.findBucketID(ctx, bktSVC, orgSVC)
+	if err != nil {
+		return err
+	}
+
+	m := &influxdb.MeasurementSchema{
+		BucketID: bucketID,
+		Name:     b.name,
+		Columns:  columns,
+	}
+	if err := schemaSVC.CreateMeasurementSchema(ctx, m); err != nil {
+		return fmt.Errorf("failed to create measurement schema: %v", err)
+	}
+
+	return b.printMeasurementSchemas(m)
+}
+
+func (b *cmdBucketSchemaBuilder) cmdList() *cobra.Command {
+	cmd := b.newCmd("list", b.cmdListRunEFn)
+	cmd.Short = "List the measurement schemas of a bucket"
+	cmd.Aliases = []string{"find", "ls"}
+
+	b.registerBucketFlags(cmd)
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "Name of a single measurement to list")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdListRunEFn(*cobra.Command, []string) error {
+	schemaSVC, bktSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	bucketID, err := b.findBucketID(ctx, bktSVC, orgSVC)
+	if err != nil {
+		return err
+	}
+
+	filter := influxdb.MeasurementSchemaFilter{BucketID: bucketID}
+	if b.name != "" {
+		filter.Name = &b.name
+	}
+	ms, err := schemaSVC.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve measurement schemas: %v", err)
+	}
+
+	return b.printMeasurementSchemas(ms...)
+}
+
+func (b *cmdBucketSchemaBuilder) cmdUpdate() *cobra.Command {
+	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
+	cmd.Short = "Add columns to a measurement schema of a bucket"
+
+	b.registerBucketFlags(cmd)
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The measurement schema ID, required if name isn't provided")
+	cmd.Flags().StringVarP(&b.name, "name", "n", "", "Name of the measurement, required if id isn't provided")
+	cmd.Flags().StringVarP(&b.columnsFile, "columns-file", "", "", "Path to a JSON file with an array of all the columns of the measurement (required)")
+	cmd.MarkFlagRequired("columns-file")
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) cmdUpdateRunEFn(*cobra.Command, []string) error {
+	if (b.id == "") == (b.name == "") {
+		return fmt.Errorf("must specify measurement schema id, or name not both")
+	}
+
+	schemaSVC, bktSVC, orgSVC, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	columns, err := b.readColumns()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	bucketID, err := b.findBucketID(ctx, bktSVC, orgSVC)
+	if err != nil {
+		return err
+	}
+
+	var id platform.ID
+	if b.id != "" {
+		if err := id.DecodeFromString(b.id); err != nil {
+			return fmt.Errorf("failed to decode measurement schema id %q: %v", b.id, err)
+		}
+	} else {
+		ms, err := schemaSVC.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{
+			BucketID: bucketID,
+			Name:     &b.name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve measurement schema %q: %v", b.name, err)
+		}
+		if len(ms) == 0 {
+			return fmt.Errorf("measurement schema %q not found", b.name)
+		}
+		id = ms[0].ID
+	}
+
+	m, err := schemaSVC.UpdateMeasurementSchema(ctx, bucketID, id, columns)
+	if err != nil {
+		return fmt.Errorf("failed to update measurement schema: %v", err)
+	}
+
+	return b.printMeasurementSchemas(m)
+}
+
+func (b *cmdBucketSchemaBuilder) newCmd(use string, runE func(*cobra.Command, []string) error) *cobra.Command {
+	cmd := b.genericCLIOpts.newCmd(use, runE, true)
+	b.globalFlags.registerFlags(b.viper, cmd)
+	return cmd
+}
+
+func (b *cmdBucketSchemaBuilder) registerBucketFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&b.bucketID, "bucket-id", "", "", "The bucket ID, required if bucket name isn't provided")
+	cmd.Flags().StringVarP(&b.bucketName, "bucket", "b", "", "The bucket name, org or org-id will be required by choosing this")
+	b.org.register(b.viper, cmd, false)
+}
+
+func (b *cmdBucketSchemaBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(b.viper, cmd, &b.hideHeaders, &b.json)
+}
+
+// findBucketID resolves the bucket selected by the bucket flags.
+func (b *cmdBucketSchemaBuilder) findBucketID(ctx context.Context, bktSVC influxdb.BucketService, orgSVC influxdb.OrganizationService) (platform.ID, error) {
+	if (b.bucketID == "") == (b.bucketName == "") {
+		return 0, fmt.Errorf("must specify bucket-id, or bucket name not both")
+	}
+
+	if b.bucketID != "" {
+		var id platform.ID
+		if err := id.DecodeFromString(b.bucketID); err != nil {
+			return 0, fmt.Errorf("failed to decode bucket id %q: %v", b.bucketID, err)
+		}
+		return id, nil
+	}
+
+	if err := b.org.validOrgFlags(b.globalFlags); err != nil {
+		return 0, err
+	}
+	orgID, err := b.org.getID(orgSVC)
+	if err != nil {
+		return 0, err
+	}
+	bkt, err := bktSVC.FindBucketByName(ctx, orgID, b.bucketName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find bucket %q: %v", b.bucketName, err)
+	}
+	return bkt.ID, nil
+}
+
+// readColumns reads the JSON array of columns in the columns file.
+func (b *cmdBucketSchemaBuilder) readColumns() ([]influxdb.MeasurementSchemaColumn, error) {
+	f, err := os.Open(b.columnsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open columns file: %v", err)
+	}
+	defer f.Close()
+
+	var columns []influxdb.MeasurementSchemaColumn
+	if err := json.NewDecoder(f).Decode(&columns); err != nil {
+		return nil, fmt.Errorf("failed to decode columns file %q: %v", b.columnsFile, err)
+	}
+	return columns, nil
+}
+
+func (b *cmdBucketSchemaBuilder) printMeasurementSchemas(ms ...*influxdb.MeasurementSchema) error {
+	if b.json {
+		if len(ms) == 1 {
+			return b.writeJSON(ms[0])
+		}
+		return b.writeJSON(ms)
+	}
+
+	w := b.newTabWriter()
+	defer w.Flush()
+
+	w.HideHeaders(b.hideHeaders)
+	w.WriteHeaders("ID", "Measurement Name", "Column Name", "Column Type", "Column Data Type", "Bucket ID")
+
+	for _, m := range ms {
+		for _, c := range m.Columns {
+			var dataType string
+			if c.DataType != nil {
+				dataType = string(*c.DataType)
+			}
+			w.Write(map[string]interface{}{
+				"ID":               m.ID.String(),
+				"Measurement Name": m.Name,
+				"Column Name":      c.Name,
+				"Column Type":      string(c.Type),
+				"Column Data Type": dataType,
+				"Bucket ID":        m.BucketID.String(),
+			})
+		}
+	}
+
+	return nil
+}
+
+func newBucketSchemaSVCs() (influxdb.MeasurementSchemaService, influxdb.BucketService, influxdb.OrganizationService, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &tenant.MeasurementSchemaClientService{Client: httpClient},
+		&tenant.BucketClientService{Client: httpClient},
+		&tenant.OrgClientService{Client: httpClient},
+		nil
+}
@@ -327,6 +327,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdAuth,
 		cmdBackup,
 		cmdBucket,
+		cmdBucketSchema,
 		cmdConfig,
 		cmdDashboard,
 		cmdDelete,
@@ -337,6 +338,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdRestore,
 		cmdSecret,
 		cmdSetup,
+		cmdSilence,
 		cmdStack,
 		cmdTask,
 		cmdTelegraf,
@@ -25,10 +25,13 @@ type cmdRestoreBuilder struct {
 	full          bool
 	bucketID      string
 	bucketName    string
+	end           string
 	newBucketName string
 	newOrgName    string
 	org           organization
 	path          string
+	start         string
+	source        backupTargetFlags
 }
 
 func newCmdRestoreBuilder(f *globalFlags, opts genericCLIOpts) *cmdRestoreBuilder {
@@ -47,6 +50,9 @@ func (b *cmdRestoreBuilder) cmdRestore() *cobra.Command {
 	cmd.Flags().StringVar(&b.newBucketName, "new-bucket", "", "The name of the bucket to restore to")
 	cmd.Flags().StringVar(&b.newOrgName, "new-org", "", "The name of the organization to restore to")
 	cmd.Flags().StringVar(&b.path, "input", "", "Local backup data path (required)")
+	cmd.Flags().StringVar(&b.start, "start", "", "Only restore data after this time into the existing bucket, in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	cmd.Flags().StringVar(&b.end, "end", "", "Only restore data before this time into the existing bucket, in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
+	b.source.register(cmd)
 	cmd.Use = "restore [flags] path"
 	cmd.Args = func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -64,6 +70,15 @@ Restore influxdb.
 Examples:
 	# restore all data
 	influx restore /path/to/restore
+
+	# write the data of a bucket within a time range into the existing bucket
+	influx restore -b my-bucket --start 2020-01-01T00:00:00Z --end 2020-01-02T00:00:00Z /path/to/restore
+
+	# restore all data from a MinIO server
+	influx restore --s3-endpoint http://localhost:9000 --s3-path-style s3://backups/influxdb
+
+Incremental backups found in the backup directory are restored on top of the
+full backup they are based on, in the order they were taken.
 `
 	return cmd
 }
@@ -97,6 +112,11 @@ func (b *cmdRestoreBuilder) restoreRunE(cmd *cobra.Command, args []string) (err
 		},
 		OrgService:    &tenant.OrgClientService{Client: client},
 		BucketService: &tenant.BucketClientService{Client: client},
+		WriteService: &http.WriteService{
+			Addr:               ac.Host,
+			Token:              ac.Token,
+			InsecureSkipVerify: flags.skipVerify,
+		},
 	}
 
 	var orgID platform.ID
@@ -113,6 +133,16 @@ func (b *cmdRestoreBuilder) restoreRunE(cmd *cobra.Command, args []string) (err
 		}
 	}
 
+	start, end, err := parseTimeRange(b.start, b.end)
+	if err != nil {
+		return err
+	}
+
+	source, err := b.source.target(b.path)
+	if err != nil {
+		return err
+	}
+
 	request := restore.Request{
 		OrgID:         orgID,
 		Org:           b.org.name,
@@ -121,7 +151,10 @@ func (b *cmdRestoreBuilder) restoreRunE(cmd *cobra.Command, args []string) (err
 		Bucket:        b.bucketName,
 		NewBucketName: b.newBucketName,
 		Path:          b.path,
+		Source:        source,
 		Full:          b.full,
+		Start:         start,
+		End:           end,
 	}
 
 	return restore.RunRestore(context.Background(), request, services, logger)
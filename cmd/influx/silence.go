@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/notification/silence"
+	"github.com/spf13/cobra"
+)
+
+func cmdSilence(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("silence", nil, false)
+	cmd.Short = "Silence management commands"
+	cmd.Long = `Silences mute the notifications of the statuses they match for a window of
+time. A silence matches the statuses of the given checks, handled by the given
+notification rules, with all of the given tags, or whose check or rule carries
+one of the given labels. When several matchers are given, all of them must
+match. Silenced statuses are recorded in the _monitoring bucket instead of
+being sent.`
+	cmd.Run = seeHelp
+
+	cmd.AddCommand(
+		silenceCreateCmd(f, opt),
+		silenceFindCmd(f, opt),
+		silenceUpdateCmd(f, opt),
+		silenceDeleteCmd(f, opt),
+	)
+
+	return cmd
+}
+
+var silenceCRUDFlags struct {
+	json        bool
+	hideHeaders bool
+}
+
+type silenceFlags struct {
+	Name        string
+	Description string
+	Start       string
+	End         string
+	Duration    time.Duration
+	CheckIDs    []string
+	RuleIDs     []string
+	Tags        []string
+	LabelIDs    []string
+}
+
+func (s *silenceFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.Name, "name", "n", "", "Name of the silence")
+	cmd.Flags().StringVarP(&s.Description, "description", "d", "", "Description of the silence")
+	cmd.Flags().StringVar(&s.Start, "start", "", "Start of the silence, RFC3339 (defaults to now)")
+	cmd.Flags().StringVar(&s.End, "end", "", "End of the silence, RFC3339")
+	cmd.Flags().DurationVar(&s.Duration, "duration", 0, "Length of the silence from its start, an alternative to --end")
+	cmd.Flags().StringSliceVar(&s.CheckIDs, "check-id", nil, "Silence the statuses of the check with this ID (can be repeated)")
+	cmd.Flags().StringSliceVar(&s.RuleIDs, "rule-id", nil, "Silence the notification rule with this ID (can be repeated)")
+	cmd.Flags().StringSliceVar(&s.Tags, "tag", nil, "Silence the statuses with this tag, in the form key:value (can be repeated)")
+	cmd.Flags().StringSliceVar(&s.LabelIDs, "label-id", nil, "Silence the checks and notification rules with the label with this ID (can be repeated)")
+}
+
+// apply sets the fields of sl given on the command line.
+func (s *silenceFlags) apply(cmd *cobra.Command, sl *influxdb.Silence) error {
+	if s.Name != "" {
+		sl.Name = s.Name
+	}
+	if cmd.Flags().Changed("description") {
+		sl.Description = s.Description
+	}
+
+	if s.Start != "" {
+		start, err := time.Parse(time.RFC3339, s.Start)
+		if err != nil {
+			return fmt.Errorf("error parsing start: %s", err)
+		}
+		sl.StartTime = start
+	}
+	if s.End != "" && s.Duration != 0 {
+		return fmt.Errorf("only one of --end and --duration can be given")
+	}
+	if s.End != "" {
+		end, err := time.Parse(time.RFC3339, s.End)
+		if err != nil {
+			return fmt.Errorf("error parsing end: %s", err)
+		}
+		sl.EndTime = end
+	}
+	if s.Duration != 0 {
+		sl.EndTime = sl.StartTime.Add(s.Duration)
+	}
+
+	var err error
+	if cmd.Flags().Changed("check-id") {
+		if sl.CheckIDs, err = parseIDs("check-id", s.CheckIDs); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("rule-id") {
+		if sl.RuleIDs, err = parseIDs("rule-id", s.RuleIDs); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("label-id") {
+		if sl.LabelIDs, err = parseIDs("label-id", s.LabelIDs); err != nil {
+			return err
+		}
+	}
+	if cmd.Flags().Changed("tag") {
+		sl.Tags = nil
+		for _, t := range s.Tags {
+			tag, err := influxdb.NewTag(t)
+			if err != nil {
+				return fmt.Errorf("invalid tag %q: %s", t, err)
+			}
+			sl.Tags = append(sl.Tags, tag)
+		}
+	}
+	return nil
+}
+
+func parseIDs(flag string, ss []string) ([]platform.ID, error) {
+	var ids []platform.ID
+	for _, s := range ss {
+		id, err := platform.IDFromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %s", flag, s, err)
+		}
+		ids = append(ids, *id)
+	}
+	return ids, nil
+}
+
+var silenceCreateFlags struct {
+	Org organization
+	silenceFlags
+}
+
+func silenceCreateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a silence",
+		RunE:  checkSetupRunEMiddleware(&flags)(silenceCreateF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &silenceCRUDFlags.hideHeaders, &silenceCRUDFlags.json)
+	silenceCreateFlags.Org.register(opt.viper, cmd, false)
+	silenceCreateFlags.silenceFlags.register(cmd)
+	_ = cmd.MarkFlagRequired("name")
+	return cmd
+}
+
+func silenceCreateF(cmd *cobra.Command, _ []string) error {
+	if err := silenceCreateFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	if silenceCreateFlags.End == "" && silenceCreateFlags.Duration == 0 {
+		return fmt.Errorf("one of --end or --duration must be given")
+	}
+
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := silenceCreateFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	sl := &influxdb.Silence{
+		OrgID:     orgID,
+		StartTime: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := silenceCreateFlags.silenceFlags.apply(cmd, sl); err != nil {
+		return err
+	}
+
+	s, err := newSilenceService()
+	if err != nil {
+		return err
+	}
+	if err := s.CreateSilence(context.Background(), sl); err != nil {
+		return err
+	}
+	return writeSilences(cmd.OutOrStdout(), silencePrintOpt{
+		jsonOut:     silenceCRUDFlags.json,
+		hideHeaders: silenceCRUDFlags.hideHeaders,
+		silence:     sl,
+	})
+}
+
+var silenceFindFlags struct {
+	ID  platform.ID
+	Org organization
+}
+
+func silenceFindCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List silences",
+		Aliases: []string{"find", "ls"},
+		RunE:    checkSetupRunEMiddleware(&flags)(silenceFindF),
+		Args:    cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &silenceCRUDFlags.hideHeaders, &silenceCRUDFlags.json)
+	silenceFindFlags.Org.register(opt.viper, cmd, false)
+	cli.IDVarP(cmd.Flags(), &silenceFindFlags.ID, "id", "i", 0, "Limit results to a single silence")
+	return cmd
+}
+
+func silenceFindF(cmd *cobra.Command, _ []string) error {
+	s, err := newSilenceService()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if silenceFindFlags.ID.Valid() {
+		sl, err := s.FindSilenceByID(ctx, silenceFindFlags.ID)
+		if err != nil {
+			return err
+		}
+		return writeSilences(cmd.OutOrStdout(), silencePrintOpt{
+			jsonOut:     silenceCRUDFlags.json,
+			hideHeaders: silenceCRUDFlags.hideHeaders,
+			silence:     sl,
+		})
+	}
+
+	if err := silenceFindFlags.Org.validOrgFlags(&flags); err != nil {
+		return err
+	}
+	orgSvc, err := newOrganizationService()
+	if err != nil {
+		return err
+	}
+	orgID, err := silenceFindFlags.Org.getID(orgSvc)
+	if err != nil {
+		return err
+	}
+
+	sls, _, err := s.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &orgID}, influxdb.FindOptions{Limit: influxdb.MaxPageSize})
+	if err != nil {
+		return err
+	}
+	return writeSilences(cmd.OutOrStdout(), silencePrintOpt{
+		jsonOut:     silenceCRUDFlags.json,
+		hideHeaders: silenceCRUDFlags.hideHeaders,
+		silences:    sls,
+	})
+}
+
+var silenceUpdateFlags struct {
+	ID platform.ID
+	silenceFlags
+}
+
+func silenceUpdateCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update a silence",
+		Long: `Update a silence. Only the fields given on the command line are changed; a
+repeated matcher flag replaces all of the matchers of that kind.`,
+		RunE: checkSetupRunEMiddleware(&flags)(silenceUpdateF),
+		Args: cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &silenceCRUDFlags.hideHeaders, &silenceCRUDFlags.json)
+	cli.IDVarP(cmd.Flags(), &silenceUpdateFlags.ID, "id", "i", 0, "The ID of the silence to update")
+	_ = cmd.MarkFlagRequired("id")
+	silenceUpdateFlags.silenceFlags.register(cmd)
+	return cmd
+}
+
+func silenceUpdateF(cmd *cobra.Command, _ []string) error {
+	s, err := newSilenceService()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sl, err := s.FindSilenceByID(ctx, silenceUpdateFlags.ID)
+	if err != nil {
+		return err
+	}
+	if err := silenceUpdateFlags.silenceFlags.apply(cmd, sl); err != nil {
+		return err
+	}
+
+	updated, err := s.UpdateSilence(ctx, silenceUpdateFlags.ID, sl)
+	if err != nil {
+		return err
+	}
+	return writeSilences(cmd.OutOrStdout(), silencePrintOpt{
+		jsonOut:     silenceCRUDFlags.json,
+		hideHeaders: silenceCRUDFlags.hideHeaders,
+		silence:     updated,
+	})
+}
+
+var silenceDeleteFlags struct {
+	ID platform.ID
+}
+
+func silenceDeleteCmd(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a silence",
+		RunE:  checkSetupRunEMiddleware(&flags)(silenceDeleteF),
+		Args:  cobra.NoArgs,
+	}
+
+	f.registerFlags(opt.viper, cmd)
+	registerPrintOptions(opt.viper, cmd, &silenceCRUDFlags.hideHeaders, &silenceCRUDFlags.json)
+	cli.IDVarP(cmd.Flags(), &silenceDeleteFlags.ID, "id", "i", 0, "The ID of the silence to delete")
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func silenceDeleteF(cmd *cobra.Command, _ []string) error {
+	s, err := newSilenceService()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	sl, err := s.FindSilenceByID(ctx, silenceDeleteFlags.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.DeleteSilence(ctx, silenceDeleteFlags.ID); err != nil {
+		return err
+	}
+	return writeSilences(cmd.OutOrStdout(), silencePrintOpt{
+		jsonOut:     silenceCRUDFlags.json,
+		hideHeaders: silenceCRUDFlags.hideHeaders,
+		silence:     sl,
+		deleted:     true,
+	})
+}
+
+type silencePrintOpt struct {
+	jsonOut     bool
+	hideHeaders bool
+	deleted     bool
+	silence     *influxdb.Silence
+	silences    []*influxdb.Silence
+}
+
+func writeSilences(w io.Writer, printOpts silencePrintOpt) error {
+	if printOpts.jsonOut {
+		var v interface{} = printOpts.silences
+		if printOpts.silences == nil {
+			v = printOpts.silence
+		}
+		return writeJSON(w, v)
+	}
+
+	tabW := internal.NewTabWriter(w)
+	defer tabW.Flush()
+
+	tabW.HideHeaders(printOpts.hideHeaders)
+
+	headers := []string{
+		"ID",
+		"Name",
+		"Start",
+		"End",
+		"Matchers",
+		"Organization ID",
+	}
+	if printOpts.deleted {
+		headers = append(headers, "Deleted")
+	}
+	tabW.WriteHeaders(headers...)
+
+	if printOpts.silence != nil {
+		printOpts.silences = append(printOpts.silences, printOpts.silence)
+	}
+
+	for _, sl := range printOpts.silences {
+		m := map[string]interface{}{
+			"ID":              sl.ID.String(),
+			"Name":            sl.Name,
+			"Start":           sl.StartTime.Format(time.RFC3339),
+			"End":             sl.EndTime.Format(time.RFC3339),
+			"Matchers":        silenceMatchers(sl),
+			"Organization ID": sl.OrgID.String(),
+		}
+		if printOpts.deleted {
+			m["Deleted"] = true
+		}
+		tabW.Write(m)
+	}
+
+	return nil
+}
+
+// silenceMatchers returns a one line summary of the matchers of sl.
+func silenceMatchers(sl *influxdb.Silence) string {
+	var ms []string
+	for _, id := range sl.CheckIDs {
+		ms = append(ms, "check="+id.String())
+	}
+	for _, id := range sl.RuleIDs {
+		ms = append(ms, "rule="+id.String())
+	}
+	for _, t := range sl.Tags {
+		ms = append(ms, t.Key+"="+t.Value)
+	}
+	for _, id := range sl.LabelIDs {
+		ms = append(ms, "label="+id.String())
+	}
+	return strings.Join(ms, ",")
+}
+
+func newSilenceService() (*silence.Client, error) {
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return silence.NewClient(httpClient), nil
+}
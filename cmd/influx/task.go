@@ -44,6 +44,7 @@ type cmdTaskBuilder struct {
 	taskRerunFailedFlags taskRerunFailedFlags
 	taskUpdateFlags      taskUpdateFlags
 	taskRunFindFlags     taskRunFindFlags
+	taskBackfillFlags    taskBackfillFlags
 	org                  organization
 }
 
@@ -68,6 +69,7 @@ func (b *cmdTaskBuilder) cmd() *cobra.Command {
 		b.taskFindCmd(),
 		b.taskUpdateCmd(),
 		b.taskRetryFailedCmd(),
+		b.taskBackfillCmd(),
 	)
 
 	return cmd
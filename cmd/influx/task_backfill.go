@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"github.com/spf13/cobra"
+)
+
+// backfillPollInterval is how often the progress of a backfill is checked
+// while waiting for it to finish.
+var backfillPollInterval = time.Second
+
+type taskBackfillFlags struct {
+	start      string
+	stop       string
+	backfillID string
+	detach     bool
+}
+
+func (b *cmdTaskBuilder) taskBackfillCmd() *cobra.Command {
+	cmd := b.newCmd("backfill", b.taskBackfillF)
+	cmd.Short = "Run a task over a historical time range"
+	cmd.Long = `Run a task for every time its schedule triggers between start and stop, both
+inclusive. No more runs are in progress at once than the task's concurrency
+option allows. The command waits for the backfill to finish, reporting its
+progress, unless --detach is given.`
+
+	registerPrintOptions(b.opts.viper, cmd, &b.taskPrintFlags.hideHeaders, &b.taskPrintFlags.json)
+	cmd.Flags().StringVarP(&b.taskID, "id", "i", "", "task ID (required)")
+	cmd.Flags().StringVar(&b.taskBackfillFlags.start, "start", "", "start of the time range, RFC3339 (required)")
+	cmd.Flags().StringVar(&b.taskBackfillFlags.stop, "stop", "", "stop of the time range, RFC3339 (required)")
+	cmd.Flags().BoolVar(&b.taskBackfillFlags.detach, "detach", false, "return once the backfill is started, without waiting for it to finish")
+	cmd.MarkFlagRequired("id")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("stop")
+
+	cmd.AddCommand(
+		b.taskBackfillListCmd(),
+		b.taskBackfillCancelCmd(),
+	)
+	return cmd
+}
+
+func (b *cmdTaskBuilder) taskBackfillF(*cobra.Command, []string) error {
+	svc, taskID, err := b.backfillSvc()
+	if err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.RFC3339, b.taskBackfillFlags.start)
+	if err != nil {
+		return fmt.Errorf("error parsing start: %s", err)
+	}
+	stop, err := time.Parse(time.RFC3339, b.taskBackfillFlags.stop)
+	if err != nil {
+		return fmt.Errorf("error parsing stop: %s", err)
+	}
+
+	ctx := context.Background()
+	bf, err := svc.CreateBackfill(ctx, taskID, start, stop)
+	if err != nil {
+		return err
+	}
+
+	for !b.taskBackfillFlags.detach && !bf.Done() {
+		if !b.taskPrintFlags.json {
+			fmt.Fprintf(b.opts.w, "Backfill %s %s: %d of %d runs finished, %d failed\n",
+				bf.ID, bf.Status, bf.Succeeded+bf.Failed, bf.Total, bf.Failed)
+		}
+		time.Sleep(backfillPollInterval)
+
+		if bf, err = svc.FindBackfillByID(ctx, taskID, bf.ID); err != nil {
+			return err
+		}
+	}
+
+	return b.printBackfills(backfillPrintOpts{backfill: bf})
+}
+
+func (b *cmdTaskBuilder) taskBackfillListCmd() *cobra.Command {
+	cmd := b.newCmd("list", b.taskBackfillListF)
+	cmd.Short = "List the backfills of a task"
+	cmd.Aliases = []string{"find", "ls"}
+
+	registerPrintOptions(b.opts.viper, cmd, &b.taskPrintFlags.hideHeaders, &b.taskPrintFlags.json)
+	cmd.Flags().StringVarP(&b.taskID, "id", "i", "", "task ID (required)")
+	cmd.MarkFlagRequired("id")
+	return cmd
+}
+
+func (b *cmdTaskBuilder) taskBackfillListF(*cobra.Command, []string) error {
+	svc, taskID, err := b.backfillSvc()
+	if err != nil {
+		return err
+	}
+
+	bfs, err := svc.FindBackfills(context.Background(), taskID)
+	if err != nil {
+		return err
+	}
+	return b.printBackfills(backfillPrintOpts{backfills: bfs})
+}
+
+func (b *cmdTaskBuilder) taskBackfillCancelCmd() *cobra.Command {
+	cmd := b.newCmd("cancel", b.taskBackfillCancelF)
+	cmd.Short = "Cancel a backfill of a task"
+
+	cmd.Flags().StringVarP(&b.taskID, "id", "i", "", "task ID (required)")
+	cmd.Flags().StringVar(&b.taskBackfillFlags.backfillID, "backfill-id", "", "backfill ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.MarkFlagRequired("backfill-id")
+	return cmd
+}
+
+func (b *cmdTaskBuilder) taskBackfillCancelF(*cobra.Command, []string) error {
+	svc, taskID, err := b.backfillSvc()
+	if err != nil {
+		return err
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(b.taskBackfillFlags.backfillID); err != nil {
+		return err
+	}
+	if err := svc.CancelBackfill(context.Background(), taskID, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b.opts.w, "Backfill %s of task %s canceled.\n", id, taskID)
+	return nil
+}
+
+// backfillSvc returns the backfill service and the ID of the task given by
+// the flags.
+func (b *cmdTaskBuilder) backfillSvc() (taskmodel.BackfillService, platform.ID, error) {
+	var taskID platform.ID
+	if err := taskID.DecodeFromString(b.taskID); err != nil {
+		return nil, 0, err
+	}
+
+	tskSvc, _, err := b.svcFn()
+	if err != nil {
+		return nil, 0, err
+	}
+	svc, ok := tskSvc.(taskmodel.BackfillService)
+	if !ok {
+		return nil, 0, fmt.Errorf("task service does not support backfills")
+	}
+	return svc, taskID, nil
+}
+
+type backfillPrintOpts struct {
+	backfill  *taskmodel.Backfill
+	backfills []*taskmodel.Backfill
+}
+
+func (b *cmdTaskBuilder) printBackfills(printOpts backfillPrintOpts) error {
+	if b.taskPrintFlags.json {
+		var v interface{} = printOpts.backfills
+		if printOpts.backfill != nil {
+			v = printOpts.backfill
+		} else if printOpts.backfills == nil {
+			// guarantee we never return a null value from CLI
+			v = make([]*taskmodel.Backfill, 0)
+		}
+		return b.opts.writeJSON(v)
+	}
+
+	tabW := b.opts.newTabWriter()
+	defer tabW.Flush()
+
+	tabW.HideHeaders(b.taskPrintFlags.hideHeaders)
+
+	tabW.WriteHeaders(
+		"ID",
+		"TaskID",
+		"Status",
+		"Start",
+		"Stop",
+		"Total",
+		"Succeeded",
+		"Failed",
+	)
+
+	if printOpts.backfill != nil {
+		printOpts.backfills = append(printOpts.backfills, printOpts.backfill)
+	}
+
+	for _, bf := range printOpts.backfills {
+		tabW.Write(map[string]interface{}{
+			"ID":        bf.ID,
+			"TaskID":    bf.TaskID,
+			"Status":    bf.Status,
+			"Start":     bf.Start.Format(time.RFC3339),
+			"Stop":      bf.Stop.Format(time.RFC3339),
+			"Total":     bf.Total,
+			"Succeeded": bf.Succeeded,
+			"Failed":    bf.Failed,
+		})
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
@@ -97,6 +98,61 @@ func TestCmdTask(t *testing.T) {
 
 	})
 
+	t.Run("backfill", func(t *testing.T) {
+		defer func(d time.Duration) { backfillPollInterval = d }(backfillPollInterval)
+		backfillPollInterval = 0
+
+		svc := &fakeBackfillTaskService{TaskService: mock.NewTaskService()}
+		var stdout bytes.Buffer
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(&stdout),
+		)
+		cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			return newCmdTaskBuilder(fakeSVCFn(svc), g, opt).cmd()
+		})
+		cmd.SetArgs([]string{"task", "backfill",
+			"--id=0000000000000001",
+			"--start=2021-01-01T00:00:00Z",
+			"--stop=2021-01-01T02:00:00Z",
+		})
+		require.NoError(t, cmd.Execute())
+
+		require.Len(t, svc.created, 1)
+		require.Equal(t, platform.ID(1), svc.created[0].TaskID)
+		require.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), svc.created[0].Start)
+		require.Equal(t, time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC), svc.created[0].Stop)
+		require.Contains(t, stdout.String(), "running: 0 of 3 runs finished")
+		require.Contains(t, stdout.String(), "success")
+	})
+
 	// todo: add tests for task subcommands
 
 }
+
+// fakeBackfillTaskService completes a backfill the first time its progress
+// is checked.
+type fakeBackfillTaskService struct {
+	*mock.TaskService
+	created []*taskmodel.Backfill
+}
+
+func (s *fakeBackfillTaskService) CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*taskmodel.Backfill, error) {
+	b := &taskmodel.Backfill{ID: 2, TaskID: taskID, Start: start, Stop: stop, Status: taskmodel.BackfillRunning, Total: 3}
+	s.created = append(s.created, b)
+	return b, nil
+}
+
+func (s *fakeBackfillTaskService) FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*taskmodel.Backfill, error) {
+	b := *s.created[0]
+	b.Status, b.Succeeded = taskmodel.BackfillSuccess, b.Total
+	return &b, nil
+}
+
+func (s *fakeBackfillTaskService) FindBackfills(ctx context.Context, taskID platform.ID) ([]*taskmodel.Backfill, error) {
+	return s.created, nil
+}
+
+func (s *fakeBackfillTaskService) CancelBackfill(ctx context.Context, taskID, id platform.ID) error {
+	return nil
+}
@@ -1,3 +0,0 @@
-package tsm_test
-
-// TODO: write some tests
@@ -0,0 +1,335 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	influxlogger "github.com/influxdata/influxdb/v2/logger"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultBuildTSIBatchSize = 10000
+
+// NewBuildTSICommand builds the `build-tsi` subcommand of `influxd inspect`.
+func NewBuildTSICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `build-tsi`,
+		Short: "Rebuilds the TSI index from TSM and WAL files",
+		Long: `
+This command will rebuild the TSI index of every shard, of the
+shards of a bucket, or of a single shard, from the series keys
+of their TSM and WAL files and the series file of their bucket.
+
+The index of each shard is built in a temporary directory and
+only replaces the existing index once it is complete.
+
+influxd must be stopped while this command runs.`,
+		Args: cobra.NoArgs,
+	}
+
+	b := tsiBuilder{
+		concurrency:    runtime.GOMAXPROCS(0),
+		maxLogFileSize: tsdb.DefaultMaxIndexLogFileSize,
+		maxCacheSize:   tsdb.DefaultCacheMaxMemorySize,
+		batchSize:      defaultBuildTSIBatchSize,
+	}
+	var (
+		enginePath string
+		bucketID   string
+		shardID    uint64
+		verbose    bool
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().StringVar(&bucketID, "bucket-id", "", "Only rebuild the index of the shards of this bucket")
+	cmd.Flags().Uint64Var(&shardID, "shard-id", 0, "Only rebuild the index of this shard, requires --bucket-id")
+	cmd.Flags().IntVarP(&b.concurrency, "concurrency", "c", b.concurrency, "Number of shards to index concurrently")
+	cmd.Flags().Int64Var(&b.maxLogFileSize, "max-log-file-size", b.maxLogFileSize, "Size in bytes at which an index log file is compacted into an index file")
+	cmd.Flags().Uint64Var(&b.maxCacheSize, "max-cache-size", b.maxCacheSize, "Maximum size in bytes of the cache used to load the WAL files of a shard")
+	cmd.Flags().IntVar(&b.batchSize, "batch-size", b.batchSize, "Number of series to add to the index at once")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log the progress of every shard")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if shardID != 0 && bucketID == "" {
+			return errors.New("--shard-id requires --bucket-id")
+		}
+		if b.concurrency < 1 {
+			return errors.New("--concurrency must be at least 1")
+		}
+		if b.batchSize < 1 {
+			return errors.New("--batch-size must be at least 1")
+		}
+
+		config := influxlogger.NewConfig()
+		config.Level = zapcore.WarnLevel
+		if verbose {
+			config.Level = zapcore.InfoLevel
+		}
+		log, err := config.New(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		b.log = log
+		b.dataPath = filepath.Join(enginePath, "data")
+		b.walPath = filepath.Join(enginePath, "wal")
+
+		var buckets []string
+		if bucketID != "" {
+			id, err := platform.IDFromString(bucketID)
+			if err != nil {
+				return err
+			}
+			buckets = []string{id.String()}
+		} else {
+			fis, err := ioutil.ReadDir(b.dataPath)
+			if err != nil {
+				return err
+			}
+			for _, fi := range fis {
+				if fi.IsDir() {
+					buckets = append(buckets, fi.Name())
+				}
+			}
+		}
+
+		for _, bucket := range buckets {
+			if err := b.buildBucket(bucket, shardID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// tsiBuilder rebuilds the TSI index of shards.
+type tsiBuilder struct {
+	dataPath string
+	walPath  string
+
+	concurrency    int
+	maxLogFileSize int64
+	maxCacheSize   uint64
+	batchSize      int
+
+	log *zap.Logger
+}
+
+// buildBucket rebuilds the index of the shards of a bucket, or only of the
+// shard with id shardID if it isn't 0.
+func (b *tsiBuilder) buildBucket(bucket string, shardID uint64) error {
+	log := b.log.With(zap.String("bucket_id", bucket))
+
+	// TSM is stored under `<data>/<bucket-id>/<rp>/<shard-id>/*.tsm`
+	rps, err := ioutil.ReadDir(filepath.Join(b.dataPath, bucket))
+	if err != nil {
+		return err
+	}
+	var shards []string
+	for _, rp := range rps {
+		if !rp.IsDir() || rp.Name() == tsdb.SeriesFileDirectory {
+			continue
+		}
+		fis, err := ioutil.ReadDir(filepath.Join(b.dataPath, bucket, rp.Name()))
+		if err != nil {
+			return err
+		}
+		for _, fi := range fis {
+			id, err := strconv.ParseUint(fi.Name(), 10, 64)
+			if err != nil || !fi.IsDir() {
+				continue
+			}
+			if shardID != 0 && id != shardID {
+				continue
+			}
+			shards = append(shards, filepath.Join(bucket, rp.Name(), fi.Name()))
+		}
+	}
+	if shardID != 0 && len(shards) == 0 {
+		return fmt.Errorf("shard %d not found in bucket %s", shardID, bucket)
+	}
+
+	sfile := tsdb.NewSeriesFile(filepath.Join(b.dataPath, bucket, tsdb.SeriesFileDirectory))
+	sfile.Logger = log
+	if err := sfile.Open(); err != nil {
+		return err
+	}
+	defer sfile.Close()
+
+	log.Info("Rebuilding bucket index", zap.Int("shards", len(shards)))
+
+	var g errgroup.Group
+	sem := make(chan struct{}, b.concurrency)
+	for _, shard := range shards {
+		shard := shard
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			if err := b.buildShard(sfile, shard); err != nil {
+				return fmt.Errorf("error rebuilding index of shard %s: %w", shard, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// buildShard rebuilds the index of the shard at the relative path shard.
+func (b *tsiBuilder) buildShard(sfile *tsdb.SeriesFile, shard string) error {
+	log := b.log.With(zap.String("shard", shard))
+	log.Info("Rebuilding shard index")
+
+	shardPath := filepath.Join(b.dataPath, shard)
+	indexPath := filepath.Join(shardPath, "index")
+	tmpPath := filepath.Join(shardPath, ".index")
+
+	// Remove what a previous interrupted run left behind.
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+
+	idx := tsi1.NewIndex(sfile, "",
+		tsi1.WithPath(tmpPath),
+		tsi1.WithMaximumLogFileSize(b.maxLogFileSize),
+		tsi1.DisableFsync(),
+		// Each series in a batch is logged in its own entry.
+		tsi1.WithLogFileBufferSize(b.batchSize*12),
+	)
+	idx.WithLogger(log)
+	if err := idx.Open(); err != nil {
+		return err
+	}
+	defer idx.Close()
+
+	tsmPaths, err := filepath.Glob(filepath.Join(shardPath, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	for _, path := range tsmPaths {
+		if err := b.indexTSMFile(idx, path); err != nil {
+			return err
+		}
+	}
+
+	walPaths, err := filepath.Glob(filepath.Join(b.walPath, shard, "*."+tsm1.WALFileExtension))
+	if err != nil {
+		return err
+	}
+	if len(walPaths) > 0 {
+		if err := b.indexWALFiles(idx, walPaths, log); err != nil {
+			return err
+		}
+	}
+
+	// Compact the log files into index files before swapping the indexes.
+	log.Info("Compacting index")
+	idx.Compact()
+	idx.Wait()
+	if err := idx.Close(); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(indexPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		return err
+	}
+	log.Info("Rebuilt shard index")
+	return nil
+}
+
+func (b *tsiBuilder) indexTSMFile(idx *tsi1.Index, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return fmt.Errorf("error opening TSM file %s: %w", path, err)
+	}
+	defer r.Close()
+
+	batch := newSeriesBatch(b.batchSize)
+	for i := 0; i < r.KeyCount(); i++ {
+		key, _ := r.KeyAt(i)
+		if err := batch.add(idx, key); err != nil {
+			return err
+		}
+	}
+	return batch.flush(idx)
+}
+
+func (b *tsiBuilder) indexWALFiles(idx *tsi1.Index, paths []string, log *zap.Logger) error {
+	cache := tsm1.NewCache(b.maxCacheSize)
+	loader := tsm1.NewCacheLoader(paths)
+	loader.WithLogger(log)
+	if err := loader.Load(cache); err != nil {
+		return err
+	}
+
+	batch := newSeriesBatch(b.batchSize)
+	for _, key := range cache.Keys() {
+		if err := batch.add(idx, key); err != nil {
+			return err
+		}
+	}
+	return batch.flush(idx)
+}
+
+// seriesBatch accumulates the series of TSM composite keys so they are
+// added to an index in bulk.
+type seriesBatch struct {
+	size  int
+	keys  [][]byte
+	names [][]byte
+	tags  []models.Tags
+}
+
+func newSeriesBatch(size int) *seriesBatch {
+	return &seriesBatch{
+		size:  size,
+		keys:  make([][]byte, 0, size),
+		names: make([][]byte, 0, size),
+		tags:  make([]models.Tags, 0, size),
+	}
+}
+
+func (b *seriesBatch) add(idx *tsi1.Index, key []byte) error {
+	seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+	name, tags := models.ParseKeyBytes(seriesKey)
+	b.keys = append(b.keys, seriesKey)
+	b.names = append(b.names, name)
+	b.tags = append(b.tags, tags)
+
+	if len(b.keys) >= b.size {
+		return b.flush(idx)
+	}
+	return nil
+}
+
+func (b *seriesBatch) flush(idx *tsi1.Index) error {
+	if len(b.keys) == 0 {
+		return nil
+	}
+	if err := idx.CreateSeriesListIfNotExists(b.keys, b.names, b.tags); err != nil {
+		return err
+	}
+	b.keys, b.names, b.tags = b.keys[:0], b.names[:0], b.tags[:0]
+	return nil
+}
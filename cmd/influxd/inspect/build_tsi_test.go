@@ -0,0 +1,84 @@
+package inspect
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+func Test_tsiBuilder(t *testing.T) {
+	enginePath, err := ioutil.TempDir("", "build-tsi-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(enginePath)
+
+	const (
+		bucket = "0000000000000001"
+		shard  = "1"
+	)
+	shardPath := filepath.Join(enginePath, "data", bucket, "autogen", shard)
+	walPath := filepath.Join(enginePath, "wal", bucket, "autogen", shard)
+	for _, path := range []string{shardPath, walPath} {
+		if err := os.MkdirAll(path, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tsmFile, err := writeCorpusToTSMFile(basicCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsmFile.Close()
+	if err := os.Rename(tsmFile.Name(), filepath.Join(shardPath, "000000001-000000001.tsm")); err != nil {
+		t.Fatal(err)
+	}
+	walFile, err := writeCorpusToWALFile(escapeStringCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walFile.Close()
+	if err := os.Rename(walFile.Name(), filepath.Join(walPath, "_00001.wal")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stale index must be replaced.
+	if err := os.MkdirAll(filepath.Join(shardPath, "index", "stale"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	b := tsiBuilder{
+		dataPath:       filepath.Join(enginePath, "data"),
+		walPath:        filepath.Join(enginePath, "wal"),
+		concurrency:    2,
+		maxLogFileSize: tsdb.DefaultMaxIndexLogFileSize,
+		maxCacheSize:   tsdb.DefaultCacheMaxMemorySize,
+		batchSize:      2,
+		log:            zaptest.NewLogger(t),
+	}
+	if err := b.buildBucket(bucket, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(shardPath, "index", "stale")); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale index to be removed, got %v", err)
+	}
+
+	r, err := reportTSI(filepath.Join(enginePath, "data", bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := len(basicCorpus) + len(escapeStringCorpus); r.series.Cardinality() != uint64(exp) {
+		t.Fatalf("unexpected series: got %d, exp %d", r.series.Cardinality(), exp)
+	}
+	if r.measurements["floats"] == nil {
+		t.Fatalf("expected measurement floats to be indexed, got %v", r.measurements)
+	}
+
+	if err := b.buildBucket(bucket, 2); err == nil {
+		t.Fatal("expected an error rebuilding a missing shard")
+	}
+}
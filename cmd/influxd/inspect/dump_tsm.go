@@ -0,0 +1,322 @@
+package inspect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpTSMCommand builds the `dump-tsm` subcommand of `influxd inspect`.
+func NewDumpTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `dump-tsm`,
+		Short: "Dumps low-level details about a TSM file",
+		Long: `
+This command will print a summary and statistics of a TSM file,
+and optionally its index entries and the details of its blocks.`,
+		Args: cobra.NoArgs,
+	}
+
+	var flags dumpTSMFlags
+	cmd.Flags().StringVar(&flags.path, "file-path", "", "Path to the TSM file")
+	cmd.Flags().BoolVar(&flags.index, "index", false, "Dump the raw index entries")
+	cmd.Flags().BoolVar(&flags.blocks, "blocks", false, "Dump the raw block details")
+	cmd.Flags().BoolVar(&flags.all, "all", false, "Dump the index entries and the block details")
+	cmd.Flags().StringVar(&flags.filterKey, "filter-key", "", "Only dump index entries and blocks of keys containing this value")
+	_ = cmd.MarkFlagRequired("file-path")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return dumpTSM(cmd.OutOrStdout(), flags)
+	}
+
+	return cmd
+}
+
+type dumpTSMFlags struct {
+	path      string
+	index     bool
+	blocks    bool
+	all       bool
+	filterKey string
+}
+
+func dumpTSM(out io.Writer, flags dumpTSMFlags) error {
+	f, err := os.Open(flags.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return fmt.Errorf("error opening TSM file: %w", err)
+	}
+	defer r.Close()
+
+	minTime, maxTime := r.TimeRange()
+	keyCount := r.KeyCount()
+
+	fmt.Fprintln(out, "Summary:")
+	fmt.Fprintf(out, "  File: %s\n", flags.path)
+	fmt.Fprintf(out, "  Time Range: %s - %s\n",
+		time.Unix(0, minTime).UTC().Format(time.RFC3339Nano),
+		time.Unix(0, maxTime).UTC().Format(time.RFC3339Nano),
+	)
+	fmt.Fprintf(out, "  Duration: %s\n", time.Unix(0, maxTime).Sub(time.Unix(0, minTime)))
+	fmt.Fprintf(out, "  Series: %d\n", keyCount)
+	fmt.Fprintf(out, "  File Size: %d\n", fi.Size())
+	fmt.Fprintln(out)
+
+	tw := tabwriter.NewWriter(out, 8, 8, 1, '\t', 0)
+
+	if flags.index || flags.all {
+		fmt.Fprintln(out, "Index:")
+		fmt.Fprintln(tw, "  "+strings.Join([]string{"Pos", "Min Time", "Max Time", "Ofs", "Size", "Key", "Field"}, "\t"))
+		var pos int
+		for i := 0; i < keyCount; i++ {
+			key, _ := r.KeyAt(i)
+			if !strings.Contains(string(key), flags.filterKey) {
+				continue
+			}
+			series, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+			for _, e := range r.Entries(key) {
+				pos++
+				fmt.Fprintln(tw, "  "+strings.Join([]string{
+					fmt.Sprint(pos),
+					time.Unix(0, e.MinTime).UTC().Format(time.RFC3339Nano),
+					time.Unix(0, e.MaxTime).UTC().Format(time.RFC3339Nano),
+					fmt.Sprint(e.Offset),
+					fmt.Sprint(e.Size),
+					string(series),
+					string(field),
+				}, "\t"))
+			}
+		}
+		tw.Flush()
+		fmt.Fprintln(out)
+	}
+
+	if flags.blocks || flags.all {
+		fmt.Fprintln(out, "Blocks:")
+		fmt.Fprintln(tw, "  "+strings.Join([]string{"Blk", "Chk", "Ofs", "Len", "Type", "Min Time", "Points", "Enc [T/V]", "Len [T/V]"}, "\t"))
+	}
+
+	var (
+		stats   blockStats
+		blockN  int
+		buf     []byte
+		entries []tsm1.IndexEntry
+	)
+	for i := 0; i < keyCount; i++ {
+		key, _ := r.KeyAt(i)
+		if !strings.Contains(string(key), flags.filterKey) {
+			continue
+		}
+		entries = r.ReadEntries(key, &entries)
+		for _, e := range entries {
+			checksum, block, err := r.ReadBytes(&e, buf)
+			if err != nil {
+				return fmt.Errorf("error reading block %d of key %q: %w", blockN, key, err)
+			}
+			buf = block
+
+			info, err := decodeBlockInfo(block)
+			if err != nil {
+				return fmt.Errorf("error decoding block %d of key %q: %w", blockN, key, err)
+			}
+			stats.add(info, len(block))
+
+			if flags.blocks || flags.all {
+				fmt.Fprintln(tw, "  "+strings.Join([]string{
+					fmt.Sprint(blockN),
+					fmt.Sprint(checksum),
+					fmt.Sprint(e.Offset),
+					fmt.Sprint(len(block)),
+					info.typeName(),
+					time.Unix(0, e.MinTime).UTC().Format(time.RFC3339Nano),
+					fmt.Sprint(info.points),
+					info.timestampEncodingName() + "/" + info.valuesEncodingName(),
+					fmt.Sprintf("%d/%d", info.timestampsLen, info.valuesLen),
+				}, "\t"))
+			}
+			blockN++
+		}
+	}
+
+	if flags.blocks || flags.all {
+		tw.Flush()
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintln(out, "Statistics")
+	fmt.Fprintln(out, "  Blocks:")
+	fmt.Fprintf(out, "    Total: %d Size: %d Min: %d Max: %d Avg: %d\n", stats.blocks, stats.size, stats.minSize, stats.maxSize, stats.avgSize())
+	fmt.Fprintln(out, "  Index:")
+	fmt.Fprintf(out, "    Total: %d Size: %d\n", blockN, r.IndexSize())
+	fmt.Fprintln(out, "  Points:")
+	fmt.Fprintf(out, "    Total: %d\n", stats.points)
+	fmt.Fprintln(out, "  Encoding:")
+	stats.writeEncodings(out, "    ")
+	fmt.Fprintln(out, "  Compression:")
+	if stats.points > 0 {
+		fmt.Fprintf(out, "    Per block: %0.2f bytes/point\n", float64(stats.size)/float64(stats.points))
+		fmt.Fprintf(out, "    Total: %0.2f bytes/point\n", float64(fi.Size())/float64(stats.points))
+	}
+	return nil
+}
+
+var (
+	blockTypeNames     = []string{"float64", "int64", "bool", "string", "unsigned"}
+	timestampEncodings = []string{"none", "s8b", "rle"}
+	valueEncodings     = [][]string{
+		{"none", "gor"},        // float64
+		{"none", "s8b", "rle"}, // int64
+		{"none", "bp"},         // bool
+		{"none", "snpy"},       // string
+		{"none", "s8b", "rle"}, // unsigned
+	}
+)
+
+// blockInfo describes how the values of a TSM block are encoded.
+type blockInfo struct {
+	typ               byte
+	timestampEncoding byte
+	valuesEncoding    byte
+	timestampsLen     int
+	valuesLen         int
+	points            int
+}
+
+// decodeBlockInfo reads the header of the timestamps and the values of a
+// TSM block.
+func decodeBlockInfo(block []byte) (blockInfo, error) {
+	var info blockInfo
+
+	typ, err := tsm1.BlockType(block)
+	if err != nil {
+		return info, err
+	}
+	info.typ = typ
+
+	tsLen, n := binary.Uvarint(block[1:])
+	if n <= 0 || 1+n+int(tsLen) > len(block) {
+		return info, errors.New("unable to read timestamp block length")
+	}
+	ts, values := block[1+n:1+n+int(tsLen)], block[1+n+int(tsLen):]
+	if len(ts) == 0 || len(values) == 0 {
+		return info, errors.New("empty timestamp or value block")
+	}
+	info.timestampEncoding = ts[0] >> 4
+	info.valuesEncoding = values[0] >> 4
+	info.timestampsLen = len(ts)
+	info.valuesLen = len(values)
+
+	if info.points, err = tsm1.BlockCount(block); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+func (b blockInfo) typeName() string {
+	return blockTypeNames[b.typ]
+}
+
+func (b blockInfo) timestampEncodingName() string {
+	return encodingName(timestampEncodings, b.timestampEncoding)
+}
+
+func (b blockInfo) valuesEncodingName() string {
+	return encodingName(valueEncodings[b.typ], b.valuesEncoding)
+}
+
+func encodingName(names []string, enc byte) string {
+	if int(enc) < len(names) {
+		return names[enc]
+	}
+	return fmt.Sprintf("unknown(%d)", enc)
+}
+
+// blockStats accumulates the size, points and encodings of TSM blocks.
+type blockStats struct {
+	blocks  int
+	size    int
+	minSize int
+	maxSize int
+	points  int
+
+	// timestamps counts blocks by timestamp encoding, values by block type
+	// and values encoding.
+	timestamps map[string]int
+	values     map[string]map[string]int
+}
+
+func (s *blockStats) add(info blockInfo, size int) {
+	if s.timestamps == nil {
+		s.timestamps = make(map[string]int)
+		s.values = make(map[string]map[string]int)
+	}
+
+	if s.blocks == 0 || size < s.minSize {
+		s.minSize = size
+	}
+	if size > s.maxSize {
+		s.maxSize = size
+	}
+	s.blocks++
+	s.size += size
+	s.points += info.points
+
+	s.timestamps[info.timestampEncodingName()]++
+	byEnc := s.values[info.typeName()]
+	if byEnc == nil {
+		byEnc = make(map[string]int)
+		s.values[info.typeName()] = byEnc
+	}
+	byEnc[info.valuesEncodingName()]++
+}
+
+func (s *blockStats) avgSize() int {
+	if s.blocks == 0 {
+		return 0
+	}
+	return s.size / s.blocks
+}
+
+// writeEncodings writes the share of blocks using each encoding, timestamps
+// first and then the values of each block type.
+func (s *blockStats) writeEncodings(w io.Writer, indent string) {
+	writeShares := func(label string, names []string, counts map[string]int) {
+		if len(counts) == 0 {
+			return
+		}
+		var total int
+		for _, n := range counts {
+			total += n
+		}
+		fmt.Fprintf(w, "%s%s:", indent, label)
+		for _, name := range names {
+			if n := counts[name]; n > 0 {
+				fmt.Fprintf(w, " %s: %d (%d%%)", name, n, n*100/total)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	writeShares("Timestamp", timestampEncodings, s.timestamps)
+	for i, typ := range blockTypeNames {
+		writeShares(typ, valueEncodings[i], s.values[typ])
+	}
+}
@@ -0,0 +1,42 @@
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_dumpTSM(t *testing.T) {
+	tsmFile, err := writeCorpusToTSMFile(basicCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tsmFile.Name())
+
+	var out bytes.Buffer
+	if err := dumpTSM(&out, dumpTSMFlags{path: tsmFile.Name(), all: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{
+		"  Series: 5",
+		"    Total: 5 Size:",
+		"    Total: 10",
+		"    float64: gor: 1 (100%)",
+		"    string: snpy: 1 (100%)",
+	} {
+		if !strings.Contains(out.String(), exp) {
+			t.Fatalf("expected %q to be in dumped output:\n%s", exp, out.String())
+		}
+	}
+
+	// Only the filtered keys are dumped.
+	out.Reset()
+	if err := dumpTSM(&out, dumpTSMFlags{path: tsmFile.Name(), blocks: true, filterKey: "floats"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "    Total: 1 Size:") {
+		t.Fatalf("expected a single block to be dumped:\n%s", out.String())
+	}
+}
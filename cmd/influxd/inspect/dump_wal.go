@@ -0,0 +1,120 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewDumpWALCommand builds the `dump-wal` subcommand of `influxd inspect`.
+func NewDumpWALCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `dump-wal <wal-file>...`,
+		Short: "Dumps the entries of WAL files",
+		Long: `
+This command will print every write, delete and delete range
+entry of the given WAL files. With --find-duplicates, it only
+prints the keys having duplicate or out of order timestamps.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	var findDuplicates bool
+	cmd.Flags().BoolVar(&findDuplicates, "find-duplicates", false, "Only print the keys having duplicate or out of order timestamps")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		for _, path := range args {
+			if err := dumpWAL(cmd.OutOrStdout(), path, findDuplicates); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+func dumpWAL(out io.Writer, path string, findDuplicates bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	reader := tsm1.NewWALSegmentReader(f)
+	defer reader.Close()
+
+	fmt.Fprintf(out, "File: %s\n", path)
+
+	// keys with duplicate or out of order timestamps
+	duplicates := make(map[string]struct{})
+	for reader.Next() {
+		entry, err := reader.Read()
+		if err != nil {
+			fmt.Fprintf(out, "corrupt entry at position %d: %v\n", reader.Count(), err)
+			break
+		}
+
+		switch t := entry.(type) {
+		case *tsm1.WriteWALEntry:
+			keys := make([]string, 0, len(t.Values))
+			for k := range t.Values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			if !findDuplicates {
+				fmt.Fprintf(out, "[write] sz=%d\n", t.MarshalSize())
+			}
+			for _, k := range keys {
+				values := t.Values[k]
+				for i, v := range values {
+					if findDuplicates {
+						if i > 0 && v.UnixNano() <= values[i-1].UnixNano() {
+							duplicates[k] = struct{}{}
+						}
+						continue
+					}
+					fmt.Fprintf(out, "%s %v %d\n", k, v.Value(), v.UnixNano())
+				}
+			}
+
+		case *tsm1.DeleteWALEntry:
+			if findDuplicates {
+				continue
+			}
+			fmt.Fprintf(out, "[delete] sz=%d\n", t.MarshalSize())
+			for _, k := range t.Keys {
+				fmt.Fprintln(out, string(k))
+			}
+
+		case *tsm1.DeleteRangeWALEntry:
+			if findDuplicates {
+				continue
+			}
+			fmt.Fprintf(out, "[delete-range %d - %d] sz=%d\n", t.Min, t.Max, t.MarshalSize())
+			for _, k := range t.Keys {
+				fmt.Fprintln(out, string(k))
+			}
+		}
+	}
+
+	if findDuplicates {
+		keys := make([]string, 0, len(duplicates))
+		for k := range duplicates {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		if len(keys) == 0 {
+			fmt.Fprintln(out, "No duplicates or out of order timestamps found")
+		} else {
+			fmt.Fprintln(out, "Duplicate or out of order timestamps found in:")
+		}
+		for _, k := range keys {
+			fmt.Fprintln(out, k)
+		}
+	}
+	return nil
+}
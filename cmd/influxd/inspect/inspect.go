@@ -1,6 +1,9 @@
 package inspect
 
 import (
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/v2/internal/fs"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,6 +25,24 @@ func NewCommand(v *viper.Viper) (*cobra.Command, error) {
 	}
 	base.AddCommand(exportLp)
 	base.AddCommand(NewExportIndexCommand())
+	base.AddCommand(NewVerifyTSMCommand())
+	base.AddCommand(NewVerifyTombstoneCommand())
+	base.AddCommand(NewVerifySeriesFileCommand())
+	base.AddCommand(NewVerifyWALCommand())
+	base.AddCommand(NewDumpTSMCommand())
+	base.AddCommand(NewDumpWALCommand())
+	base.AddCommand(NewReportTSMCommand())
+	base.AddCommand(NewReportTSICommand())
+	base.AddCommand(NewBuildTSICommand())
 
 	return base, nil
 }
+
+// defaultEnginePath returns the engine path influxd uses by default.
+func defaultEnginePath() string {
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		return "engine"
+	}
+	return filepath.Join(dir, "engine")
+}
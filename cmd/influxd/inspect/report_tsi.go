@@ -0,0 +1,272 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+	"github.com/spf13/cobra"
+)
+
+// NewReportTSICommand builds the `report-tsi` subcommand of `influxd inspect`.
+func NewReportTSICommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `report-tsi`,
+		Short: "Reports the measurements and tags with the highest cardinality",
+		Long: `
+This command will read the TSI index of every shard of a bucket
+and report the measurements with the most series, and for each
+of them the tag keys with the most distinct values.`,
+		Args: cobra.NoArgs,
+	}
+
+	var (
+		enginePath string
+		bucketID   string
+		topN       int
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().StringVar(&bucketID, "bucket-id", "", "Bucket to report the cardinality of")
+	cmd.Flags().IntVarP(&topN, "top", "t", 10, "Number of measurements and tag keys to report, 0 reports all of them")
+	_ = cmd.MarkFlagRequired("bucket-id")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		id, err := platform.IDFromString(bucketID)
+		if err != nil {
+			return err
+		}
+		bucketPath := filepath.Join(enginePath, "data", id.String())
+
+		r, err := reportTSI(bucketPath)
+		if err != nil {
+			return err
+		}
+		r.write(cmd.OutOrStdout(), topN)
+		return nil
+	}
+
+	return cmd
+}
+
+// measurementCardinality is the cardinality of a measurement across the
+// shards of a bucket.
+type measurementCardinality struct {
+	name   string
+	series *tsdb.SeriesIDSet
+
+	// tagValues estimates the distinct values of each tag key.
+	tagValues map[string]estimator.Sketch
+}
+
+type tsiReport struct {
+	series       *tsdb.SeriesIDSet
+	shards       int
+	measurements map[string]*measurementCardinality
+}
+
+// reportTSI reads the index of every shard below bucketPath.
+func reportTSI(bucketPath string) (*tsiReport, error) {
+	sfile := tsdb.NewSeriesFile(filepath.Join(bucketPath, tsdb.SeriesFileDirectory))
+	if err := sfile.Open(); err != nil {
+		return nil, err
+	}
+	defer sfile.Close()
+
+	// TSI is stored under `<bucket>/<rp>/<shard-id>/index`
+	indexPaths, err := filepath.Glob(filepath.Join(bucketPath, "*", "*", "index"))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &tsiReport{
+		series:       tsdb.NewSeriesIDSet(),
+		measurements: make(map[string]*measurementCardinality),
+	}
+	for _, path := range indexPaths {
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			continue
+		}
+		if err := r.addIndex(sfile, path); err != nil {
+			return nil, fmt.Errorf("error reading index %s: %w", path, err)
+		}
+	}
+	return r, nil
+}
+
+func (r *tsiReport) addIndex(sfile *tsdb.SeriesFile, path string) error {
+	idx := tsi1.NewIndex(sfile, "", tsi1.WithPath(path), tsi1.DisableCompactions())
+	if err := idx.Open(); err != nil {
+		return err
+	}
+	defer idx.Close()
+	r.shards++
+
+	itr, err := idx.MeasurementIterator()
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		name, err := itr.Next()
+		if err != nil {
+			return err
+		} else if name == nil {
+			return nil
+		}
+
+		m := r.measurements[string(name)]
+		if m == nil {
+			m = &measurementCardinality{
+				name:      string(name),
+				series:    tsdb.NewSeriesIDSet(),
+				tagValues: make(map[string]estimator.Sketch),
+			}
+			r.measurements[m.name] = m
+		}
+		if err := m.addSeries(idx, r.series); err != nil {
+			return err
+		}
+		if err := m.addTags(idx); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *measurementCardinality) addSeries(idx *tsi1.Index, all *tsdb.SeriesIDSet) error {
+	itr, err := idx.MeasurementSeriesIDIterator([]byte(m.name))
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return err
+		} else if e.SeriesID == 0 {
+			return nil
+		}
+		m.series.Add(e.SeriesID)
+		all.Add(e.SeriesID)
+	}
+}
+
+func (m *measurementCardinality) addTags(idx *tsi1.Index) error {
+	keys, err := idx.TagKeyIterator([]byte(m.name))
+	if err != nil {
+		return err
+	} else if keys == nil {
+		return nil
+	}
+	defer keys.Close()
+
+	for {
+		key, err := keys.Next()
+		if err != nil {
+			return err
+		} else if key == nil {
+			return nil
+		}
+
+		sketch := m.tagValues[string(key)]
+		if sketch == nil {
+			sketch = hll.NewDefaultPlus()
+			m.tagValues[string(key)] = sketch
+		}
+		if err := addTagValues(idx, []byte(m.name), key, sketch); err != nil {
+			return err
+		}
+	}
+}
+
+func addTagValues(idx *tsi1.Index, name, key []byte, sketch estimator.Sketch) error {
+	itr, err := idx.TagValueIterator(name, key)
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		value, err := itr.Next()
+		if err != nil {
+			return err
+		} else if value == nil {
+			return nil
+		}
+		sketch.Add(value)
+	}
+}
+
+func (r *tsiReport) write(out io.Writer, topN int) {
+	measurements := make([]*measurementCardinality, 0, len(r.measurements))
+	for _, m := range r.measurements {
+		measurements = append(measurements, m)
+	}
+	sort.Slice(measurements, func(i, j int) bool {
+		ci, cj := measurements[i].series.Cardinality(), measurements[j].series.Cardinality()
+		if ci != cj {
+			return ci > cj
+		}
+		return measurements[i].name < measurements[j].name
+	})
+	if topN > 0 && len(measurements) > topN {
+		measurements = measurements[:topN]
+	}
+
+	fmt.Fprintf(out, "Shards: %d, Measurements: %d, Series: %d\n\n", r.shards, len(r.measurements), r.series.Cardinality())
+
+	tw := tabwriter.NewWriter(out, 8, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, "Measurement\tSeries\tSeries %")
+	for _, m := range measurements {
+		var share float64
+		if total := r.series.Cardinality(); total > 0 {
+			share = float64(m.series.Cardinality()) * 100 / float64(total)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\n", m.name, m.series.Cardinality(), share)
+	}
+	tw.Flush()
+
+	for _, m := range measurements {
+		type tagCardinality struct {
+			key    string
+			values uint64
+		}
+		tags := make([]tagCardinality, 0, len(m.tagValues))
+		for k, sketch := range m.tagValues {
+			tags = append(tags, tagCardinality{key: k, values: sketch.Count()})
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			if tags[i].values != tags[j].values {
+				return tags[i].values > tags[j].values
+			}
+			return tags[i].key < tags[j].key
+		})
+		if topN > 0 && len(tags) > topN {
+			tags = tags[:topN]
+		}
+
+		fmt.Fprintf(out, "\nMeasurement %q:\n", m.name)
+		fmt.Fprintln(tw, "  Tag Key\tValues")
+		for _, t := range tags {
+			fmt.Fprintf(tw, "  %s\t%d\n", t.key, t.values)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(out, "\nTag value counts are estimates.")
+}
@@ -0,0 +1,77 @@
+package inspect
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
+)
+
+func Test_reportTSI(t *testing.T) {
+	bucketPath, err := ioutil.TempDir("", "report-tsi-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(bucketPath)
+
+	sfile := tsdb.NewSeriesFile(filepath.Join(bucketPath, tsdb.SeriesFileDirectory))
+	if err := sfile.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Shards 1 and 2 share the series of host0, so they are only counted once.
+	for shard, hosts := range map[string][]int{"1": {0, 1, 2}, "2": {0, 3}} {
+		idx := tsi1.NewIndex(sfile, "", tsi1.WithPath(filepath.Join(bucketPath, "autogen", shard, "index")))
+		if err := idx.Open(); err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range hosts {
+			for _, name := range []string{"cpu", "mem"} {
+				if name == "mem" && h > 0 {
+					continue
+				}
+				tags := models.NewTags(map[string]string{"host": fmt.Sprintf("host%d", h), "region": "west"})
+				if err := idx.CreateSeriesIfNotExists(models.MakeKey([]byte(name), tags), []byte(name), tags); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := idx.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := reportTSI(bucketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.shards != 2 {
+		t.Errorf("unexpected shards: got %d, exp 2", r.shards)
+	}
+	if got := r.series.Cardinality(); got != 5 {
+		t.Errorf("unexpected series: got %d, exp 5", got)
+	}
+	cpu := r.measurements["cpu"]
+	if cpu == nil || cpu.series.Cardinality() != 4 {
+		t.Fatalf("unexpected cardinality of cpu: %+v", cpu)
+	}
+	if got := cpu.tagValues["host"].Count(); got != 4 {
+		t.Errorf("unexpected values of tag host: got %d, exp 4", got)
+	}
+
+	var out bytes.Buffer
+	r.write(&out, 1)
+	if !strings.Contains(out.String(), `Measurement "cpu"`) || strings.Contains(out.String(), `Measurement "mem"`) {
+		t.Errorf("expected only the top measurement to be reported:\n%s", out.String())
+	}
+}
@@ -0,0 +1,242 @@
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// NewReportTSMCommand builds the `report-tsm` subcommand of `influxd inspect`.
+func NewReportTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `report-tsm`,
+		Short: "Reports the disk usage and block encodings of measurements",
+		Long: `
+This command will read the blocks of the TSM files of every
+bucket, or of a single bucket, and report for each measurement
+its series, points and disk usage, and how its blocks are
+encoded.`,
+		Args: cobra.NoArgs,
+	}
+
+	var (
+		enginePath string
+		bucketID   string
+		exact      bool
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().StringVar(&bucketID, "bucket-id", "", "Only report the measurements of this bucket")
+	cmd.Flags().BoolVar(&exact, "exact", false, "Count series exactly instead of estimating them, requires more memory")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		dataPath := filepath.Join(enginePath, "data")
+		dir := dataPath
+		if bucketID != "" {
+			id, err := platform.IDFromString(bucketID)
+			if err != nil {
+				return err
+			}
+			dir = filepath.Join(dataPath, id.String())
+		}
+
+		files, err := loadFiles(dir, tsm1.TSMFileExtension)
+		if err != nil {
+			return err
+		}
+
+		r := newTSMReport(exact)
+		for _, path := range files {
+			// TSM is stored under `<engine>/data/<bucket-id>/<rp>/<shard-id>/*.tsm`
+			rel, err := filepath.Rel(dataPath, path)
+			if err != nil {
+				return err
+			}
+			bucket := strings.Split(rel, string(filepath.Separator))[0]
+			if err := r.addFile(bucket, path); err != nil {
+				return err
+			}
+		}
+		r.write(cmd.OutOrStdout())
+		return nil
+	}
+
+	return cmd
+}
+
+// measurementUsage is the disk usage of a measurement of a bucket.
+type measurementUsage struct {
+	bucket      string
+	measurement string
+	series      estimator.Sketch
+	stats       blockStats
+
+	// encodings counts blocks by type, timestamp and values encoding.
+	encodings map[[3]string]int
+}
+
+type tsmReport struct {
+	exact        bool
+	measurements map[[2]string]*measurementUsage
+	files        int
+}
+
+func newTSMReport(exact bool) *tsmReport {
+	return &tsmReport{
+		exact:        exact,
+		measurements: make(map[[2]string]*measurementUsage),
+	}
+}
+
+func (r *tsmReport) usage(bucket, measurement string) *measurementUsage {
+	k := [2]string{bucket, measurement}
+	u := r.measurements[k]
+	if u == nil {
+		u = &measurementUsage{
+			bucket:      bucket,
+			measurement: measurement,
+			encodings:   make(map[[3]string]int),
+		}
+		if r.exact {
+			u.series = newExactSketch()
+		} else {
+			u.series = hll.NewDefaultPlus()
+		}
+		r.measurements[k] = u
+	}
+	return u
+}
+
+func (r *tsmReport) addFile(bucket, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		// TSM files can disappear if we're reading the engine dir of a live DB,
+		// and compactions run between our path-lookup and read steps.
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	reader, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return fmt.Errorf("error opening TSM file %s: %w", path, err)
+	}
+	defer reader.Close()
+	r.files++
+
+	var (
+		buf     []byte
+		entries []tsm1.IndexEntry
+	)
+	for i := 0; i < reader.KeyCount(); i++ {
+		key, _ := reader.KeyAt(i)
+		series, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		u := r.usage(bucket, string(models.ParseName(series)))
+		u.series.Add(series)
+
+		entries = reader.ReadEntries(key, &entries)
+		for _, e := range entries {
+			_, block, err := reader.ReadBytes(&e, buf)
+			if err != nil {
+				return fmt.Errorf("error reading block of key %q in %s: %w", key, path, err)
+			}
+			buf = block
+
+			info, err := decodeBlockInfo(block)
+			if err != nil {
+				return fmt.Errorf("error decoding block of key %q in %s: %w", key, path, err)
+			}
+			u.stats.add(info, len(block))
+			u.encodings[[3]string{info.typeName(), info.timestampEncodingName(), info.valuesEncodingName()}]++
+		}
+	}
+	return nil
+}
+
+func (r *tsmReport) write(out io.Writer) {
+	usages := make([]*measurementUsage, 0, len(r.measurements))
+	var total blockStats
+	for _, u := range r.measurements {
+		usages = append(usages, u)
+		total.size += u.stats.size
+		total.blocks += u.stats.blocks
+		total.points += u.stats.points
+	}
+	// Largest measurements first.
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].stats.size != usages[j].stats.size {
+			return usages[i].stats.size > usages[j].stats.size
+		}
+		if usages[i].bucket != usages[j].bucket {
+			return usages[i].bucket < usages[j].bucket
+		}
+		return usages[i].measurement < usages[j].measurement
+	})
+
+	tw := tabwriter.NewWriter(out, 8, 2, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Bucket", "Measurement", "Series", "Blocks", "Points", "Size", "Size %"}, "\t"))
+	for _, u := range usages {
+		var share float64
+		if total.size > 0 {
+			share = float64(u.stats.size) * 100 / float64(total.size)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%.1f%%\n",
+			u.bucket, u.measurement, u.series.Count(), u.stats.blocks, u.stats.points, u.stats.size, share)
+	}
+	fmt.Fprintf(tw, "Total\t\t\t%d\t%d\t%d\t\n", total.blocks, total.points, total.size)
+	tw.Flush()
+
+	fmt.Fprintf(out, "\nBlock encodings:\n")
+	fmt.Fprintln(tw, strings.Join([]string{"Bucket", "Measurement", "Type", "Timestamps", "Values", "Blocks"}, "\t"))
+	for _, u := range usages {
+		encodings := make([][3]string, 0, len(u.encodings))
+		for enc := range u.encodings {
+			encodings = append(encodings, enc)
+		}
+		sort.Slice(encodings, func(i, j int) bool {
+			return strings.Join(encodings[i][:], "/") < strings.Join(encodings[j][:], "/")
+		})
+		for _, enc := range encodings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", u.bucket, u.measurement, enc[0], enc[1], enc[2], u.encodings[enc])
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(out, "\nFiles: %d\n", r.files)
+	if !r.exact {
+		fmt.Fprintln(out, "Series counts are estimates, use --exact to count them exactly.")
+	}
+}
+
+// exactSketch is an estimator.Sketch counting distinct values exactly.
+type exactSketch map[string]struct{}
+
+func newExactSketch() exactSketch {
+	return make(exactSketch)
+}
+
+func (s exactSketch) Add(v []byte)  { s[string(v)] = struct{}{} }
+func (s exactSketch) Count() uint64 { return uint64(len(s)) }
+func (s exactSketch) Merge(estimator.Sketch) error {
+	return fmt.Errorf("exact sketches can't be merged")
+}
+func (s exactSketch) Bytes() int              { return 0 }
+func (s exactSketch) Clone() estimator.Sketch { return s }
+func (s exactSketch) MarshalBinary() ([]byte, error) {
+	return nil, fmt.Errorf("exact sketches can't be marshaled")
+}
+func (s exactSketch) UnmarshalBinary(data []byte) error {
+	return fmt.Errorf("exact sketches can't be unmarshaled")
+}
@@ -0,0 +1,47 @@
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func Test_tsmReport(t *testing.T) {
+	tsmFile, err := writeCorpusToTSMFile(basicCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tsmFile.Name())
+
+	for _, exact := range []bool{false, true} {
+		r := newTSMReport(exact)
+		if err := r.addFile("bucket", tsmFile.Name()); err != nil {
+			t.Fatal(err)
+		}
+		// Adding the same file twice counts blocks twice, but not series.
+		if err := r.addFile("bucket", tsmFile.Name()); err != nil {
+			t.Fatal(err)
+		}
+
+		u := r.measurements[[2]string{"bucket", "floats"}]
+		if u == nil {
+			t.Fatalf("expected usage of measurement floats, got %v", r.measurements)
+		}
+		if got := u.series.Count(); got != 1 {
+			t.Errorf("unexpected series count: got %d, exp 1", got)
+		}
+		if u.stats.blocks != 2 || u.stats.points != 4 {
+			t.Errorf("unexpected stats: got %d blocks and %d points, exp 2 and 4", u.stats.blocks, u.stats.points)
+		}
+		if got := u.encodings[[3]string{"float64", "rle", "gor"}]; got != 2 {
+			t.Errorf("unexpected encodings: got %v", u.encodings)
+		}
+
+		var out bytes.Buffer
+		r.write(&out)
+		if !strings.Contains(out.String(), "Files: 2") {
+			t.Errorf("expected file count in report:\n%s", out.String())
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package seriesfile
+package inspect
 
 import (
 	"fmt"
@@ -9,27 +9,115 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
+// NewVerifySeriesFileCommand builds the `verify-seriesfile` subcommand of `influxd inspect`.
+func NewVerifySeriesFileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `verify-seriesfile`,
+		Short: "Verifies the integrity of series files",
+		Long: `
+This command will verify the segments and the index of the
+series files of every bucket, or of a single bucket or
+series file when one is given.`,
+		Args: cobra.NoArgs,
+	}
+
+	var (
+		enginePath     string
+		bucketID       string
+		seriesFilePath string
+		verbose        bool
+		concurrent     int
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().StringVar(&bucketID, "bucket-id", "", "Only verify the series file of this bucket")
+	cmd.Flags().StringVar(&seriesFilePath, "series-path", "", "Path to a series file, overrides --engine-path and --bucket-id")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log the progress of the verification")
+	cmd.Flags().IntVarP(&concurrent, "concurrency", "c", runtime.GOMAXPROCS(0), "Number of partitions to verify concurrently")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		config := influxlogger.NewConfig()
+		config.Level = zapcore.WarnLevel
+		if verbose {
+			config.Level = zapcore.InfoLevel
+		}
+		log, err := config.New(cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+
+		v := newSeriesFileVerifier()
+		v.Concurrent = concurrent
+		v.Logger = log
+
+		var paths []string
+		switch {
+		case seriesFilePath != "":
+			paths = []string{seriesFilePath}
+		case bucketID != "":
+			id, err := platform.IDFromString(bucketID)
+			if err != nil {
+				return err
+			}
+			paths = []string{filepath.Join(enginePath, "data", id.String(), tsdb.SeriesFileDirectory)}
+		default:
+			buckets, err := ioutil.ReadDir(filepath.Join(enginePath, "data"))
+			if err != nil {
+				return err
+			}
+			for _, b := range buckets {
+				if b.IsDir() {
+					paths = append(paths, filepath.Join(enginePath, "data", b.Name(), tsdb.SeriesFileDirectory))
+				}
+			}
+		}
+
+		var invalid int
+		for _, path := range paths {
+			valid, err := v.VerifySeriesFile(path)
+			if err != nil {
+				return err
+			}
+			if valid {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: healthy\n", path)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: corrupt\n", path)
+				invalid++
+			}
+		}
+		if invalid > 0 {
+			return fmt.Errorf("verify-seriesfile: %d of %d series files failed verification", invalid, len(paths))
+		}
+		return nil
+	}
+
+	return cmd
+}
+
 // verifyResult contains the result of a Verify... call
 type verifyResult struct {
 	valid bool
 	err   error
 }
 
-// Verify contains configuration for running verification of series files.
-type Verify struct {
+// seriesFileVerifier contains configuration for running verification of series files.
+type seriesFileVerifier struct {
 	Concurrent int
 	Logger     *zap.Logger
 
 	done chan struct{}
 }
 
-// NewVerify constructs a Verify with good defaults.
-func NewVerify() Verify {
-	return Verify{
+// newSeriesFileVerifier constructs a seriesFileVerifier with good defaults.
+func newSeriesFileVerifier() seriesFileVerifier {
+	return seriesFileVerifier{
 		Concurrent: runtime.GOMAXPROCS(0),
 		Logger:     zap.NewNop(),
 	}
@@ -37,7 +125,7 @@ func NewVerify() Verify {
 
 // VerifySeriesFile performs verifications on a series file. The error is only returned
 // if there was some fatal problem with operating, not if there was a problem with the series file.
-func (v Verify) VerifySeriesFile(filePath string) (valid bool, err error) {
+func (v seriesFileVerifier) VerifySeriesFile(filePath string) (valid bool, err error) {
 	v.Logger = v.Logger.With(zap.String("path", filePath))
 	v.Logger.Info("Verifying series file")
 
@@ -98,7 +186,7 @@ func (v Verify) VerifySeriesFile(filePath string) (valid bool, err error) {
 	for range partitionInfos {
 		result := <-out
 		if result.err != nil {
-			return false, err
+			return false, result.err
 		} else if !result.valid {
 			return false, nil
 		}
@@ -109,7 +197,7 @@ func (v Verify) VerifySeriesFile(filePath string) (valid bool, err error) {
 
 // VerifyPartition performs verifications on a partition of a series file. The error is only returned
 // if there was some fatal problem with operating, not if there was a problem with the partition.
-func (v Verify) VerifyPartition(partitionPath string) (valid bool, err error) {
+func (v seriesFileVerifier) VerifyPartition(partitionPath string) (valid bool, err error) {
 	v.Logger = v.Logger.With(zap.String("partition", filepath.Base(partitionPath)))
 	v.Logger.Info("Verifying partition")
 
@@ -126,7 +214,7 @@ func (v Verify) VerifyPartition(partitionPath string) (valid bool, err error) {
 	}
 
 	segments := make([]*tsdb.SeriesSegment, 0, len(segmentInfos))
-	ids := make(map[uint64]IDData)
+	ids := make(map[uint64]seriesIDData)
 
 	// check every segment
 	for _, segmentInfo := range segmentInfos {
@@ -170,8 +258,8 @@ func (v Verify) VerifyPartition(partitionPath string) (valid bool, err error) {
 	return true, nil
 }
 
-// IDData keeps track of data about a series ID.
-type IDData struct {
+// seriesIDData keeps track of data about a series ID.
+type seriesIDData struct {
 	Offset  int64
 	Key     []byte
 	Deleted bool
@@ -180,7 +268,7 @@ type IDData struct {
 // VerifySegment performs verifications on a segment of a series file. The error is only returned
 // if there was some fatal problem with operating, not if there was a problem with the partition.
 // The ids map is populated with information about the ids stored in the segment.
-func (v Verify) VerifySegment(segmentPath string, ids map[uint64]IDData) (valid bool, err error) {
+func (v seriesFileVerifier) VerifySegment(segmentPath string, ids map[uint64]seriesIDData) (valid bool, err error) {
 	segmentName := filepath.Base(segmentPath)
 	v.Logger = v.Logger.With(zap.String("segment", segmentName))
 	v.Logger.Info("Verifying segment")
@@ -196,7 +284,7 @@ func (v Verify) VerifySegment(segmentPath string, ids map[uint64]IDData) (valid
 		return false, nil
 	}
 	defer segment.Close()
-	buf := newBuffer(segment.Data())
+	buf := newSegmentBuffer(segment.Data())
 
 	defer func() {
 		if rec := recover(); rec != nil {
@@ -245,7 +333,7 @@ entries:
 				keyCopy := make([]byte, len(key))
 				copy(keyCopy, key)
 
-				ids[id] = IDData{
+				ids[id] = seriesIDData{
 					Offset: tsdb.JoinSeriesOffset(segment.ID(), uint32(buf.offset)),
 					Key:    keyCopy,
 				}
@@ -311,8 +399,8 @@ entries:
 // VerifyIndex performs verification on an index in a series file. The error is only returned
 // if there was some fatal problem with operating, not if there was a problem with the partition.
 // The ids map must be built from verifying the passed in segments.
-func (v Verify) VerifyIndex(indexPath string, segments []*tsdb.SeriesSegment,
-	ids map[uint64]IDData) (valid bool, err error) {
+func (v seriesFileVerifier) VerifyIndex(indexPath string, segments []*tsdb.SeriesSegment,
+	ids map[uint64]seriesIDData) (valid bool, err error) {
 	v.Logger.Info("Verifying index")
 
 	defer func() {
@@ -351,31 +439,31 @@ func (v Verify) VerifyIndex(indexPath string, segments []*tsdb.SeriesSegment,
 			return false, nil
 		}
 
-		IDData := ids[id]
+		idData := ids[id]
 
-		if gotDeleted := index.IsDeleted(id); gotDeleted != IDData.Deleted {
+		if gotDeleted := index.IsDeleted(id); gotDeleted != idData.Deleted {
 			v.Logger.Error("Index inconsistency",
 				zap.Uint64("id", id),
 				zap.Bool("got_deleted", gotDeleted),
-				zap.Bool("expected_deleted", IDData.Deleted))
+				zap.Bool("expected_deleted", idData.Deleted))
 			return false, nil
 		}
 
 		// do not perform any other checks if the id is deleted.
-		if IDData.Deleted {
+		if idData.Deleted {
 			continue
 		}
 
 		// otherwise, check both that the offset is right and that we get the right id for the key
-		if gotOffset := index.FindOffsetByID(id); gotOffset != IDData.Offset {
+		if gotOffset := index.FindOffsetByID(id); gotOffset != idData.Offset {
 			v.Logger.Error("Index inconsistency",
 				zap.Uint64("id", id),
 				zap.Int64("got_offset", gotOffset),
-				zap.Int64("expected_offset", IDData.Offset))
+				zap.Int64("expected_offset", idData.Offset))
 			return false, nil
 		}
 
-		if gotID := index.FindIDBySeriesKey(segments, IDData.Key); gotID != id {
+		if gotID := index.FindIDBySeriesKey(segments, idData.Key); gotID != id {
 			v.Logger.Error("Index inconsistency",
 				zap.Uint64("id", id),
 				zap.Uint64("got_id", gotID),
@@ -387,15 +475,15 @@ func (v Verify) VerifyIndex(indexPath string, segments []*tsdb.SeriesSegment,
 	return true, nil
 }
 
-// buffer allows one to safely advance a byte slice and keep track of how many bytes were advanced.
-type buffer struct {
+// segmentBuffer allows one to safely advance a byte slice and keep track of how many bytes were advanced.
+type segmentBuffer struct {
 	offset int64
 	data   []byte
 }
 
-// newBuffer constructs a buffer with the provided data.
-func newBuffer(data []byte) *buffer {
-	return &buffer{
+// newSegmentBuffer constructs a buffer with the provided data.
+func newSegmentBuffer(data []byte) *segmentBuffer {
+	return &segmentBuffer{
 		offset: 0,
 		data:   data,
 	}
@@ -403,7 +491,7 @@ func newBuffer(data []byte) *buffer {
 
 // advance will consume n bytes from the data slice and return an error if there is not enough
 // data to do so.
-func (b *buffer) advance(n int64) error {
+func (b *segmentBuffer) advance(n int64) error {
 	if int64(len(b.data)) < n {
 		return fmt.Errorf("unable to advance %d bytes: %d remaining", n, len(b.data))
 	}
@@ -1,4 +1,4 @@
-package seriesfile_test
+package inspect
 
 import (
 	"fmt"
@@ -9,17 +9,16 @@ import (
 	"testing"
 	"time"
 
-	"github.com/influxdata/influxdb/v2/cmd/influx_inspect/verify/seriesfile"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"go.uber.org/zap"
 )
 
-func TestVerifies_Valid(t *testing.T) {
-	test := NewTest(t)
+func TestVerifySeriesFile_Valid(t *testing.T) {
+	test := newSeriesFileTest(t)
 	defer test.Close()
 
-	verify := seriesfile.NewVerify()
+	verify := newSeriesFileVerifier()
 	if testing.Verbose() {
 		verify.Logger, _ = zap.NewDevelopment()
 	}
@@ -28,8 +27,8 @@ func TestVerifies_Valid(t *testing.T) {
 	test.Assert(passed)
 }
 
-func TestVerifies_Invalid(t *testing.T) {
-	test := NewTest(t)
+func TestVerifySeriesFile_Invalid(t *testing.T) {
+	test := newSeriesFileTest(t)
 	defer test.Close()
 
 	test.AssertNoError(filepath.Walk(test.Path, func(path string, info os.FileInfo, err error) error {
@@ -51,7 +50,7 @@ func TestVerifies_Invalid(t *testing.T) {
 		test.AssertNoError(err)
 		test.AssertNoError(fh.Close())
 
-		passed, err := seriesfile.NewVerify().VerifySeriesFile(test.Path)
+		passed, err := newSeriesFileVerifier().VerifySeriesFile(test.Path)
 		test.AssertNoError(err)
 		test.Assert(!passed)
 
@@ -63,12 +62,12 @@ func TestVerifies_Invalid(t *testing.T) {
 // helpers
 //
 
-type Test struct {
+type seriesFileTest struct {
 	*testing.T
 	Path string
 }
 
-func NewTest(t *testing.T) *Test {
+func newSeriesFileTest(t *testing.T) *seriesFileTest {
 	t.Helper()
 
 	dir, err := ioutil.TempDir("", "verify-seriesfile-")
@@ -129,24 +128,24 @@ func NewTest(t *testing.T) *Test {
 		t.Fatal(err)
 	}
 
-	return &Test{
+	return &seriesFileTest{
 		T:    t,
 		Path: dir,
 	}
 }
 
-func (t *Test) Close() {
+func (t *seriesFileTest) Close() {
 	os.RemoveAll(t.Path)
 }
 
-func (t *Test) AssertNoError(err error) {
+func (t *seriesFileTest) AssertNoError(err error) {
 	t.Helper()
 	if err != nil {
 		t.Fatal("unexpected error:", err)
 	}
 }
 
-func (t *Test) Assert(x bool) {
+func (t *seriesFileTest) Assert(x bool) {
 	t.Helper()
 	if !x {
 		t.Fatal("unexpected condition")
@@ -154,7 +153,7 @@ func (t *Test) Assert(x bool) {
 }
 
 // Backup makes a copy of the path for a later Restore.
-func (t *Test) Backup(path string) {
+func (t *seriesFileTest) Backup(path string) {
 	in, err := os.Open(path)
 	t.AssertNoError(err)
 	defer in.Close()
@@ -168,6 +167,6 @@ func (t *Test) Backup(path string) {
 }
 
 // Restore restores the file at the path to the time when Backup was called last.
-func (t *Test) Restore(path string) {
+func (t *seriesFileTest) Restore(path string) {
 	t.AssertNoError(os.Rename(path+".backup", path))
 }
@@ -1,9 +1,7 @@
-// Package tombstone verifies integrity of tombstones.
-package tombstone
+package inspect
 
 import (
 	"errors"
-	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -11,48 +9,46 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/spf13/cobra"
 )
 
-// Command represents the program execution for "influx_inspect verify-tombstone".
-type Command struct {
-	Stderr io.Writer
-	Stdout io.Writer
-}
-
-// NewCommand returns a new instance of Command.
-func NewCommand() *Command {
-	return &Command{
-		Stderr: os.Stderr,
-		Stdout: os.Stdout,
-	}
-}
-
-// Run executes the command.
-func (cmd *Command) Run(args ...string) error {
-	runner := verifier{w: cmd.Stdout}
-	fs := flag.NewFlagSet("verify-tombstone", flag.ExitOnError)
-	fs.StringVar(&runner.path, "path", os.Getenv("HOME")+"/.influxdb", "path to find tombstone files")
-	v := fs.Bool("v", false, "verbose: emit periodic progress")
-	vv := fs.Bool("vv", false, "very verbose: emit every tombstone entry key and time range")
-	vvv := fs.Bool("vvv", false, "very very verbose: emit every tombstone entry key and RFC3339Nano time range")
-
-	fs.SetOutput(cmd.Stdout)
-
-	if err := fs.Parse(args); err != nil {
-		return err
+// NewVerifyTombstoneCommand builds the `verify-tombstone` subcommand of `influxd inspect`.
+func NewVerifyTombstoneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `verify-tombstone`,
+		Short: "Verifies the integrity of tombstone files",
+		Long: `
+This command will walk every entry of the tombstone files
+found in the engine data directory.`,
+		Args: cobra.NoArgs,
 	}
 
-	if *v {
-		runner.verbosity = verbose
-	}
-	if *vv {
-		runner.verbosity = veryVerbose
-	}
-	if *vvv {
-		runner.verbosity = veryVeryVerbose
+	var (
+		enginePath string
+		v, vv, vvv bool
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().BoolVarP(&v, "verbose", "v", false, "Emit periodic progress")
+	cmd.Flags().BoolVar(&vv, "vv", false, "Emit every tombstone entry key and time range")
+	cmd.Flags().BoolVar(&vvv, "vvv", false, "Emit every tombstone entry key and RFC3339Nano time range")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		runner := tombstoneVerifier{
+			path: filepath.Join(enginePath, "data"),
+			w:    cmd.OutOrStdout(),
+		}
+		switch {
+		case vvv:
+			runner.verbosity = veryVeryVerbose
+		case vv:
+			runner.verbosity = veryVerbose
+		case v:
+			runner.verbosity = verbose
+		}
+		return runner.Run()
 	}
 
-	return runner.Run()
+	return cmd
 }
 
 const (
@@ -62,7 +58,7 @@ const (
 	veryVeryVerbose
 )
 
-type verifier struct {
+type tombstoneVerifier struct {
 	path      string
 	verbosity int
 
@@ -71,7 +67,7 @@ type verifier struct {
 	f     string
 }
 
-func (v *verifier) loadFiles() error {
+func (v *tombstoneVerifier) loadFiles() error {
 	return filepath.Walk(v.path, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -83,7 +79,7 @@ func (v *verifier) loadFiles() error {
 	})
 }
 
-func (v *verifier) Next() bool {
+func (v *tombstoneVerifier) Next() bool {
 	if len(v.files) == 0 {
 		return false
 	}
@@ -92,7 +88,7 @@ func (v *verifier) Next() bool {
 	return true
 }
 
-func (v *verifier) Run() error {
+func (v *tombstoneVerifier) Run() error {
 	if err := v.loadFiles(); err != nil {
 		return err
 	}
@@ -106,7 +102,7 @@ func (v *verifier) Run() error {
 
 		tombstoner := tsm1.NewTombstoner(v.f, nil)
 		if !tombstoner.HasTombstones() {
-			fmt.Fprintf(v.w, "%s has no tombstone entries", v.f)
+			fmt.Fprintf(v.w, "%s has no tombstone entries\n", v.f)
 			continue
 		}
 
@@ -119,10 +115,10 @@ func (v *verifier) Run() error {
 				var min interface{} = t.Min
 				var max interface{} = t.Max
 				if v.verbosity > veryVerbose {
-					min = time.Unix(0, t.Min)
-					max = time.Unix(0, t.Max)
+					min = time.Unix(0, t.Min).UTC().Format(time.RFC3339Nano)
+					max = time.Unix(0, t.Max).UTC().Format(time.RFC3339Nano)
 				}
-				fmt.Printf("key: %q, min: %v, max: %v\n", t.Key, min, max)
+				fmt.Fprintf(v.w, "key: %q, min: %v, max: %v\n", t.Key, min, max)
 			}
 			return nil
 		})
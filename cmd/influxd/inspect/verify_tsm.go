@@ -1,8 +1,6 @@
-// Package tsm verifies integrity of TSM files.
-package tsm
+package inspect
 
 import (
-	"flag"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -14,68 +12,47 @@ import (
 
 	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
 	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
 )
 
-// Command represents the program execution for "influx_inspect verify".
-type Command struct {
-	Stderr io.Writer
-	Stdout io.Writer
-}
-
-// NewCommand returns a new instance of Command.
-func NewCommand() *Command {
-	return &Command{
-		Stderr: os.Stderr,
-		Stdout: os.Stdout,
+// NewVerifyTSMCommand builds the `verify-tsm` subcommand of `influxd inspect`.
+func NewVerifyTSMCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   `verify-tsm`,
+		Short: "Verifies the integrity of TSM files",
+		Long: `
+This command will verify the checksum of every block of
+the TSM files found in the engine data directory.`,
+		Args: cobra.NoArgs,
 	}
-}
-
-// Run executes the command.
-func (cmd *Command) Run(args ...string) error {
-	var path string
-	fs := flag.NewFlagSet("verify", flag.ExitOnError)
-	fs.StringVar(&path, "dir", os.Getenv("HOME")+"/.influxdb", "Root storage path. [$HOME/.influxdb]")
-
-	var checkUTF8 bool
-	fs.BoolVar(&checkUTF8, "check-utf8", false, "Verify series keys are valid UTF-8")
 
-	fs.SetOutput(cmd.Stdout)
-	fs.Usage = cmd.printUsage
-
-	if err := fs.Parse(args); err != nil {
+	var (
+		enginePath string
+		checkUTF8  bool
+	)
+	cmd.Flags().StringVar(&enginePath, "engine-path", defaultEnginePath(), "Path to the persistent engine files")
+	cmd.Flags().BoolVar(&checkUTF8, "check-utf8", false, "Verify series keys are valid UTF-8. This check skips verification of block checksums")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		dataPath := filepath.Join(enginePath, "data")
+		tw := tabwriter.NewWriter(cmd.OutOrStdout(), 16, 8, 0, '\t', 0)
+
+		var runner tsmVerifier
+		if checkUTF8 {
+			runner = &verifyUTF8{}
+		} else {
+			runner = &verifyChecksums{}
+		}
+		err := runner.Run(tw, dataPath)
+		tw.Flush()
 		return err
 	}
 
-	dataPath := filepath.Join(path, "data")
-	tw := tabwriter.NewWriter(cmd.Stdout, 16, 8, 0, '\t', 0)
-
-	var runner verifier
-	if checkUTF8 {
-		runner = &verifyUTF8{}
-	} else {
-		runner = &verifyChecksums{}
-	}
-	err := runner.Run(tw, dataPath)
-	tw.Flush()
-	return err
+	return cmd
 }
 
-// printUsage prints the usage message to STDERR.
-func (cmd *Command) printUsage() {
-	usage := fmt.Sprintf(`Verifies the integrity of TSM files.
-
-Usage: influx_inspect verify [flags]
-
-    -dir <path>
-            The root storage path.
-            Must be changed if you are using a non-default storage directory.
-            Defaults to "%[1]s/.influxdb".
-    -check-utf8 
-            Verify series keys are valid UTF-8.
-            This check skips verification of block checksums.
- `, os.Getenv("HOME"))
-
-	fmt.Fprint(cmd.Stdout, usage)
+type tsmVerifier interface {
+	Run(w io.Writer, dataPath string) error
 }
 
 type verifyTSM struct {
@@ -97,7 +74,7 @@ func (v *verifyTSM) loadFiles(dataPath string) error {
 	})
 
 	if err != nil {
-		return errors.Wrap(err, "could not load storage files (use -dir for custom storage root)")
+		return errors.Wrap(err, "could not load storage files (use --engine-path for custom storage root)")
 	}
 
 	return nil
@@ -180,6 +157,9 @@ func (v *verifyChecksums) Run(w io.Writer, dataPath string) error {
 	}
 
 	fmt.Fprintf(w, "Broken Blocks: %d / %d, in %vs\n", v.totalErrors, v.total, v.Elapsed().Seconds())
+	if v.totalErrors > 0 && v.err == nil {
+		v.err = errors.New("verify-tsm: failed")
+	}
 
 	return v.err
 }
@@ -217,6 +197,7 @@ func (v *verifyUTF8) Run(w io.Writer, dataPath string) error {
 		if fileErrors == 0 {
 			fmt.Fprintf(w, "%s: healthy\n", f)
 		}
+		reader.Close()
 	}
 
 	fmt.Fprintf(w, "Invalid Keys: %d / %d, in %vs\n", v.totalErrors, v.total, v.Elapsed().Seconds())
@@ -226,7 +207,3 @@ func (v *verifyUTF8) Run(w io.Writer, dataPath string) error {
 
 	return v.err
 }
-
-type verifier interface {
-	Run(w io.Writer, dataPath string) error
-}
@@ -0,0 +1,34 @@
+package inspect
+
+import (
+	"os"
+	"testing"
+)
+
+func Test_verifyWALFile(t *testing.T) {
+	walFile, err := writeCorpusToWALFile(basicCorpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(walFile.Name())
+
+	res, err := verifyWALFile(walFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.err != nil || res.entries != 1 {
+		t.Fatalf("expected a healthy file with 1 entry, got %+v", res)
+	}
+
+	// Corrupt the compressed entry.
+	if _, err := walFile.WriteAt([]byte("BOGUS"), 10); err != nil {
+		t.Fatal(err)
+	}
+	res, err = verifyWALFile(walFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.err == nil {
+		t.Fatalf("expected a corrupt file, got %+v", res)
+	}
+}
@@ -154,6 +154,8 @@ type InfluxdOpts struct {
 	NoTasks      bool
 	FeatureFlags map[string]string
 
+	NotificationSMTPEnabled bool
+
 	// Query options.
 	ConcurrencyQuota                int32
 	InitialMemoryBytesQuotaPerQuery int64
@@ -205,6 +207,8 @@ func NewOpts(viper *viper.Viper) *InfluxdOpts {
 
 		NoTasks: false,
 
+		NotificationSMTPEnabled: false,
+
 		ConcurrencyQuota:                1024,
 		InitialMemoryBytesQuotaPerQuery: 0,
 		MemoryBytesQuotaPerQuery:        MaxInt,
@@ -401,6 +405,12 @@ func (o *InfluxdOpts) BindCliOpts() []cli.Opt {
 			Default: o.NoTasks,
 			Desc:    "disables the task scheduler",
 		},
+		{
+			DestP:   &o.NotificationSMTPEnabled,
+			Flag:    "notification-smtp-enabled",
+			Default: o.NotificationSMTPEnabled,
+			Desc:    "allows SMTP notification rules to send email. As they send it with http.post to smtp:// URLs, any flux query may then send email from the server",
+		},
 		{
 			DestP:   &o.ConcurrencyQuota,
 			Flag:    "query-concurrency",
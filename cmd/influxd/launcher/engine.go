@@ -176,6 +176,10 @@ func (t *TemporaryEngine) BackupShard(ctx context.Context, w io.Writer, shardID
 	return t.engine.BackupShard(ctx, w, shardID, since)
 }
 
+func (t *TemporaryEngine) ExportShard(ctx context.Context, w io.Writer, shardID uint64, start, end time.Time) error {
+	return t.engine.ExportShard(ctx, w, shardID, start, end)
+}
+
 func (t *TemporaryEngine) RestoreShard(ctx context.Context, shardID uint64, r io.Reader) error {
 	return t.engine.RestoreShard(ctx, shardID, r)
 }
@@ -19,6 +19,7 @@ import (
 	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/backup/scheduled"
 	"github.com/influxdata/influxdb/v2/bolt"
 	"github.com/influxdata/influxdb/v2/checks"
 	"github.com/influxdata/influxdb/v2/chronograf/server"
@@ -46,11 +47,13 @@ import (
 	notebookTransport "github.com/influxdata/influxdb/v2/notebooks/transport"
 	endpointservice "github.com/influxdata/influxdb/v2/notification/endpoint/service"
 	ruleservice "github.com/influxdata/influxdb/v2/notification/rule/service"
+	"github.com/influxdata/influxdb/v2/notification/silence"
 	"github.com/influxdata/influxdb/v2/pkger"
 	infprom "github.com/influxdata/influxdb/v2/prometheus"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	queryregistry "github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/v2/secret"
 	"github.com/influxdata/influxdb/v2/session"
@@ -60,6 +63,7 @@ import (
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
 	taskbackend "github.com/influxdata/influxdb/v2/task/backend"
+	"github.com/influxdata/influxdb/v2/task/backend/backfill"
 	"github.com/influxdata/influxdb/v2/task/backend/coordinator"
 	"github.com/influxdata/influxdb/v2/task/backend/executor"
 	"github.com/influxdata/influxdb/v2/task/backend/middleware"
@@ -76,6 +80,7 @@ import (
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
 	authv1 "github.com/influxdata/influxdb/v2/v1/authorization"
 	iqlcoordinator "github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	storage2 "github.com/influxdata/influxdb/v2/v1/services/storage"
 	"github.com/influxdata/influxdb/v2/vault"
@@ -124,6 +129,8 @@ type Launcher struct {
 	natsPort   int
 
 	scheduler          stoppingScheduler
+	cqScheduler        stoppingScheduler
+	backupScheduler    stoppingScheduler
 	executor           *executor.Executor
 	taskControlService taskbackend.TaskControlService
 
@@ -174,6 +181,15 @@ func (m *Launcher) Shutdown(ctx context.Context) error {
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
+	m.executor.Close()
+
+	m.log.Info("Stopping", zap.String("service", "continuous-querier"))
+
+	m.cqScheduler.Stop()
+
+	m.log.Info("Stopping", zap.String("service", "backup-scheduler"))
+
+	m.backupScheduler.Stop()
 
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
@@ -343,6 +359,21 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		authSvc = authorization.NewService(authStore, ts)
 	}
 
+	var (
+		passwordV1 platform.PasswordsService
+		authSvcV1  *authv1.Service
+	)
+	{
+		authStore, err := authv1.NewStore(m.kvStore)
+		if err != nil {
+			m.log.Error("Failed creating new authorization store", zap.Error(err))
+			return err
+		}
+
+		authSvcV1 = authv1.NewService(authStore, ts)
+		passwordV1 = authv1.NewCachingPasswordsService(authSvcV1)
+	}
+
 	secretStore, err := secret.NewStore(m.kvStore)
 	if err != nil {
 		m.log.Error("Failed creating new meta store", zap.Error(err))
@@ -416,8 +447,20 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		restoreService platform.RestoreService = m.engine
 	)
 
+	// Points written to buckets with an explicit schema must match the
+	// schema of their measurement. The schemas it caches are invalidated
+	// by the services managing buckets and measurement schemas.
+	schemaPointsWriter := &storage.SchemaPointsWriter{
+		Underlying:               pointsWriter,
+		BucketFinder:             ts.BucketService,
+		MeasurementSchemaService: ts.MeasurementSchemaService,
+	}
+	pointsWriter = schemaPointsWriter
+	ts.MeasurementSchemaService = schemaPointsWriter.WrapMeasurementSchemaService(ts.MeasurementSchemaService)
+
+	readsStore := storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())
 	deps, err := influxdb.NewDependencies(
-		storageflux.NewReader(storage2.NewStore(m.engine.TSDBStore(), m.engine.MetaClient())),
+		storageflux.NewReader(readsStore),
 		m.engine,
 		authorizer.NewBucketService(ts.BucketService),
 		authorizer.NewOrgService(ts.OrganizationService),
@@ -428,12 +471,19 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		m.log.Error("Failed to get query controller dependencies", zap.Error(err))
 		return err
 	}
+	if opts.NotificationSMTPEnabled {
+		deps = deps.WithSMTP()
+	}
 
 	dependencyList := []flux.Dependency{deps}
 	if opts.Testing {
 		dependencyList = append(dependencyList, testing.FrameworkConfig{})
 	}
 
+	// Running Flux and InfluxQL queries are tracked together so that they
+	// can be listed and killed through either language or the HTTP API.
+	queryRegistry := queryregistry.New()
+
 	m.queryController, err = control.New(control.Config{
 		ConcurrencyQuota:                opts.ConcurrencyQuota,
 		InitialMemoryBytesQuotaPerQuery: opts.InitialMemoryBytesQuotaPerQuery,
@@ -441,6 +491,8 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		MaxMemoryBytes:                  opts.MaxMemoryBytes,
 		QueueSize:                       opts.QueueSize,
 		ExecutorDependencies:            dependencyList,
+		Registry:                        queryRegistry,
+		OrganizationService:             ts.OrganizationService,
 	}, m.log.With(zap.String("service", "storage-reads")))
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
@@ -450,7 +502,10 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
-	var taskSvc taskmodel.TaskService
+	var (
+		taskSvc     taskmodel.TaskService
+		backfillSvc taskmodel.BackfillService
+	)
 	{
 		// create the task stack
 		combinedTaskService := taskbackend.NewAnalyticalStorage(
@@ -472,6 +527,12 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
+		backfillSvc = backfill.NewService(
+			m.log.With(zap.String("service", "task-backfill")),
+			combinedTaskService,
+			executor,
+			fluxlang.DefaultService,
+		)
 		schLogger := m.log.With(zap.String("service", "task-scheduler"))
 
 		var sch stoppingScheduler = &scheduler.NoopScheduler{}
@@ -538,11 +599,85 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		zap.Int("max_select_buckets", opts.CoordinatorConfig.MaxSelectBucketsN))
 
 	qe := iqlquery.NewExecutor(m.log, cm)
+	qe.Registry = queryRegistry
+
+	var cqSvc *continuous_querier.Service
+	{
+		cqLogger := m.log.With(zap.String("service", "continuous-querier"))
+		cqStore := continuous_querier.NewStore(m.kvStore)
+		cqExecutor := continuous_querier.NewExecutor(cqLogger, cqStore, qe, continuous_querier.AuthorizationFinders{authSvcV1, authSvc})
+
+		var sch stoppingScheduler = &scheduler.NoopScheduler{}
+		if !opts.NoTasks {
+			var (
+				tsch *scheduler.TreeScheduler
+				err  error
+			)
+			tsch, _, err = scheduler.NewScheduler(
+				cqExecutor,
+				cqStore,
+				scheduler.WithOnErrorFn(func(ctx context.Context, cqID scheduler.ID, scheduledAt time.Time, err error) {
+					cqLogger.Info(
+						"error in continuous query run",
+						zap.String("continuousQueryID", platform2.ID(cqID).String()),
+						zap.Time("scheduledAt", scheduledAt),
+						zap.Error(err))
+				}),
+			)
+			if err != nil {
+				m.log.Fatal("could not start continuous query scheduler", zap.Error(err))
+			}
+			sch = tsch
+		}
+		m.cqScheduler = sch
+
+		cqSvc = continuous_querier.NewService(cqLogger, cqStore, sch)
+		if err := cqSvc.Open(ctx); err != nil {
+			m.log.Error("Failed to schedule existing continuous queries", zap.Error(err))
+		}
+	}
+
+	var backupJobSvc *scheduled.Service
+	{
+		backupLogger := m.log.With(zap.String("service", "backup-scheduler"))
+		backupJobStore := scheduled.NewStore(m.kvStore)
+		backupExecutor := scheduled.NewExecutor(backupLogger, backupJobStore, backupService)
+
+		var sch stoppingScheduler = &scheduler.NoopScheduler{}
+		if !opts.NoTasks {
+			tsch, _, err := scheduler.NewScheduler(
+				backupExecutor,
+				backupJobStore,
+				scheduler.WithOnErrorFn(func(ctx context.Context, jobID scheduler.ID, scheduledAt time.Time, err error) {
+					backupLogger.Info(
+						"error in backup job run",
+						zap.String("backupJobID", platform2.ID(jobID).String()),
+						zap.Time("scheduledAt", scheduledAt),
+						zap.Error(err))
+				}),
+			)
+			if err != nil {
+				m.log.Fatal("could not start backup scheduler", zap.Error(err))
+			}
+			sch = tsch
+		}
+		m.backupScheduler = sch
+
+		backupJobSvc = scheduled.NewService(backupLogger, backupJobStore, sch)
+		if err := backupJobSvc.Open(ctx); err != nil {
+			m.log.Error("Failed to schedule existing backup jobs", zap.Error(err))
+		}
+	}
+
 	se := &iqlcoordinator.StatementExecutor{
 		MetaClient:        metaClient,
 		TSDBStore:         m.engine.TSDBStore(),
 		ShardMapper:       mapper,
 		DBRP:              dbrpSvc,
+		PointsWriter:      pointsWriter,
+		ContinuousQueries: cqSvc,
+		DeleteService:     deleteService,
+		Queries:           queryRegistry,
 		MaxSelectPointN:   opts.CoordinatorConfig.MaxSelectPointN,
 		MaxSelectSeriesN:  opts.CoordinatorConfig.MaxSelectSeriesN,
 		MaxSelectBucketsN: opts.CoordinatorConfig.MaxSelectBucketsN,
@@ -562,17 +697,33 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		notificationEndpointSvc = endpointservice.New(endpointservice.NewStore(m.kvStore), secretSvc)
 	}
 
-	var notificationRuleSvc platform.NotificationRuleStore
+	var labelSvc platform.LabelService
+	{
+		labelsStore, err := label.NewStore(m.kvStore)
+		if err != nil {
+			m.log.Error("Failed creating new labels store", zap.Error(err))
+			return err
+		}
+		labelSvc = label.NewService(labelsStore)
+	}
+
+	var (
+		notificationRuleSvc platform.NotificationRuleStore
+		silenceSvc          platform.SilenceService
+	)
 	{
 		coordinator := coordinator.NewCoordinator(m.log, m.scheduler, m.executor)
-		notificationRuleSvc, err = ruleservice.New(m.log, m.kvStore, m.kvService, ts.OrganizationService, notificationEndpointSvc)
+		silenceStore := silence.NewStore(m.kvStore)
+		ruleSvc, err := ruleservice.New(m.log, m.kvStore, m.kvService, ts.OrganizationService, notificationEndpointSvc,
+			ruleservice.WithSilences(silence.NewResolver(silenceStore, labelSvc, checkSvc)))
 		if err != nil {
 			return err
 		}
+		silenceSvc = silence.NewService(m.log.With(zap.String("service", "silences")), silenceStore, ruleSvc)
 
 		// tasks service notification middleware which keeps task service up to date
 		// with persisted changes to notification rules.
-		notificationRuleSvc = middleware.NewNotificationRuleStore(notificationRuleSvc, m.kvService, coordinator)
+		notificationRuleSvc = middleware.NewNotificationRuleStore(ruleSvc, m.kvService, coordinator)
 	}
 
 	var telegrafSvc platform.TelegrafConfigStore
@@ -648,18 +799,13 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		sessionSvc = session.NewSessionLogger(m.log.With(zap.String("service", "session")), sessionSvc)
 	}
 
-	var labelSvc platform.LabelService
-	{
-		labelsStore, err := label.NewStore(m.kvStore)
-		if err != nil {
-			m.log.Error("Failed creating new labels store", zap.Error(err))
-			return err
-		}
-		labelSvc = label.NewService(labelsStore)
-	}
-
 	ts.BucketService = storage.NewBucketService(m.log, ts.BucketService, m.engine)
 	ts.BucketService = dbrp.NewBucketService(m.log, ts.BucketService, dbrpSvc)
+	ts.BucketService = schemaPointsWriter.WrapBucketService(ts.BucketService)
+
+	// InfluxQL DDL statements manage buckets through the fully wrapped service
+	// so that storage and DBRP mappings are kept in sync.
+	se.BucketService = authorizer.NewBucketService(ts.BucketService)
 
 	onboardingLogger := m.log.With(zap.String("handler", "onboard"))
 	onboardOpts := []tenant.OnboardServiceOptionFn{tenant.WithOnboardingLogger(onboardingLogger)}
@@ -672,21 +818,6 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 	onboardSvc = tenant.NewOnboardingMetrics(m.reg, onboardSvc, metric.WithSuffix("new")) // with metrics
 	onboardSvc = tenant.NewOnboardingLogger(onboardingLogger, onboardSvc)                 // with logging
 
-	var (
-		passwordV1 platform.PasswordsService
-		authSvcV1  *authv1.Service
-	)
-	{
-		authStore, err := authv1.NewStore(m.kvStore)
-		if err != nil {
-			m.log.Error("Failed creating new authorization store", zap.Error(err))
-			return err
-		}
-
-		authSvcV1 = authv1.NewService(authStore, ts)
-		passwordV1 = authv1.NewCachingPasswordsService(authSvcV1)
-	}
-
 	var (
 		dashboardSvc    platform.DashboardService
 		dashboardLogSvc platform.DashboardOperationLogService
@@ -728,6 +859,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 			BucketFinder:  ts.BucketService,
 			LogBucketName: platform.MonitoringSystemBucketName,
 		},
+		ReadsStore:             readsStore,
 		DeleteService:          deleteService,
 		BackupService:          backupService,
 		RestoreService:         restoreService,
@@ -763,6 +895,7 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		FluxService:                     storageQueryService,
 		FluxLanguageService:             fluxlang.DefaultService,
 		TaskService:                     taskSvc,
+		BackfillService:                 backfillSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
 		NotificationEndpointService:     notificationEndpointSvc,
@@ -899,6 +1032,12 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 
 	notebookServer := notebookTransport.NewNotebookHandler(m.log.With(zap.String("handler", "notebooks")))
 
+	queriesHTTPServer := queryregistry.NewHTTPHandler(m.log.With(zap.String("handler", "queries")), queryRegistry)
+
+	backupJobsHTTPServer := scheduled.NewHTTPHandler(m.log.With(zap.String("handler", "backup_jobs")), backupJobSvc)
+
+	silencesHTTPServer := silence.NewHTTPHandler(m.log.With(zap.String("handler", "silences")), silence.NewAuthorizedService(silenceSvc))
+
 	platformHandler := http.NewPlatformHandler(
 		m.apibackend,
 		http.WithResourceHandler(stacksHTTPServer),
@@ -915,6 +1054,9 @@ func (m *Launcher) run(ctx context.Context, opts *InfluxdOpts) (err error) {
 		http.WithResourceHandler(v1AuthHTTPServer),
 		http.WithResourceHandler(dashboardServer),
 		http.WithResourceHandler(notebookServer),
+		http.WithResourceHandler(queriesHTTPServer),
+		http.WithResourceHandler(backupJobsHTTPServer),
+		http.WithResourceHandler(silencesHTTPServer),
 	)
 
 	httpLogger := m.log.With(zap.String("service", "http"))
@@ -359,7 +359,8 @@ func (tl *TestLauncher) BackupOrFail(tb testing.TB, ctx context.Context, req bac
 
 func (tl *TestLauncher) Backup(tb testing.TB, ctx context.Context, req backup.Request) error {
 	tb.Helper()
-	return backup.RunBackup(ctx, req, tl.BackupService(tb), tl.log)
+	_, err := backup.RunBackup(ctx, req, tl.BackupService(tb), tl.log)
+	return err
 }
 
 func (tl *TestLauncher) RestoreOrFail(tb testing.TB, ctx context.Context, req restore.Request) {
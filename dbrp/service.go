@@ -428,6 +428,7 @@ func (s *Service) Update(ctx context.Context, dbrp *influxdb.DBRPMappingV2) erro
 	dbrp.OrganizationID = oldDBRP.OrganizationID
 	dbrp.BucketID = oldDBRP.BucketID
 	dbrp.Database = oldDBRP.Database
+	dbrp.OwnsBucket = oldDBRP.OwnsBucket
 
 	// If a dbrp with this orgID, db, and rp exists an error is returned.
 	if err := s.isDBRPUnique(ctx, *dbrp); err != nil {
@@ -38,6 +38,10 @@ type DBRPMappingV2 struct {
 
 	OrganizationID platform.ID `json:"orgID"`
 	BucketID       platform.ID `json:"bucketID"`
+
+	// OwnsBucket indicates the bucket was created together with this mapping
+	// by InfluxQL, and is deleted with the last mapping to it.
+	OwnsBucket bool `json:"ownsBucket,omitempty"`
 }
 
 // Validate reports any validation errors for the mapping.
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -62,6 +63,7 @@ type APIBackend struct {
 	AlgoWProxy FeatureProxyHandler
 
 	PointsWriter                    storage.PointsWriter
+	ReadsStore                      reads.Store
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
 	RestoreService                  influxdb.RestoreService
@@ -90,6 +92,7 @@ type APIBackend struct {
 	FluxService                     query.ProxyQueryService
 	FluxLanguageService             fluxlang.FluxLanguageService
 	TaskService                     taskmodel.TaskService
+	BackfillService                 taskmodel.BackfillService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
@@ -183,6 +186,7 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	taskLogger := b.Logger.With(zap.String("handler", "bucket"))
 	taskBackend := NewTaskBackend(taskLogger, b)
 	taskBackend.TaskService = authorizer.NewTaskService(taskLogger, b.TaskService)
+	taskBackend.BackfillService = authorizer.NewBackfillService(taskLogger, b.TaskService, b.BackfillService)
 	taskHandler := NewTaskHandler(b.Logger, taskBackend)
 	h.Mount(prefixTasks, taskHandler)
 
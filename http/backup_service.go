@@ -14,6 +14,7 @@ import (
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
 	"go.uber.org/zap"
 )
 
@@ -92,8 +93,39 @@ func (h *BackupHandler) handleBackupShard(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	qp := r.URL.Query()
+	if qp.Get("start") != "" || qp.Get("end") != "" {
+		if qp.Get("since") != "" {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "since cannot be combined with start or end",
+			}, w)
+			return
+		}
+
+		start, end := time.Unix(0, models.MinNanoTime).UTC(), time.Unix(0, models.MaxNanoTime).UTC()
+		if s := qp.Get("start"); s != "" {
+			if start, err = time.ParseInLocation(time.RFC3339Nano, s, time.UTC); err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+		if s := qp.Get("end"); s != "" {
+			if end, err = time.ParseInLocation(time.RFC3339Nano, s, time.UTC); err != nil {
+				h.HandleHTTPError(ctx, err, w)
+				return
+			}
+		}
+
+		if err := h.BackupService.ExportShard(ctx, w, shardID, start, end); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		return
+	}
+
 	var since time.Time
-	if s := r.URL.Query().Get("since"); s != "" {
+	if s := qp.Get("since"); s != "" {
 		if since, err = time.ParseInLocation(time.RFC3339, s, time.UTC); err != nil {
 			h.HandleHTTPError(ctx, err, w)
 			return
@@ -151,13 +183,30 @@ func (s *BackupService) BackupShard(ctx context.Context, w io.Writer, shardID ui
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	var params url.Values
+	if !since.IsZero() {
+		params = url.Values{"since": {since.UTC().Format(time.RFC3339)}}
+	}
+	return s.downloadShard(ctx, w, shardID, params)
+}
+
+func (s *BackupService) ExportShard(ctx context.Context, w io.Writer, shardID uint64, start, end time.Time) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	params := url.Values{
+		"start": {start.UTC().Format(time.RFC3339Nano)},
+		"end":   {end.UTC().Format(time.RFC3339Nano)},
+	}
+	return s.downloadShard(ctx, w, shardID, params)
+}
+
+func (s *BackupService) downloadShard(ctx context.Context, w io.Writer, shardID uint64, params url.Values) error {
 	u, err := NewURL(s.Addr, fmt.Sprintf(prefixBackup+"/shards/%d", shardID))
 	if err != nil {
 		return err
 	}
-	if !since.IsZero() {
-		u.RawQuery = (url.Values{"since": {since.UTC().Format(time.RFC3339)}}).Encode()
-	}
+	u.RawQuery = params.Encode()
 
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
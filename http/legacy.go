@@ -15,6 +15,7 @@ func newLegacyBackend(b *APIBackend) *legacy.Backend {
 		OrganizationService:   b.OrganizationService,
 		BucketService:         b.BucketService,
 		PointsWriter:          b.PointsWriter,
+		ReadsStore:            b.ReadsStore,
 		DBRPMappingServiceV2:  b.DBRPService,
 		ProxyQueryService:     b.InfluxQLService,
 		InfluxqldQueryService: b.InfluxqldService,
@@ -34,6 +35,9 @@ func newLegacyHandler(b *legacy.Backend, config legacy.HandlerConfig) *legacy.Ha
 	influxqlBackend := legacy.NewInfluxQLBackend(b)
 	h.InfluxQLHandler = legacy.NewInfluxQLHandler(influxqlBackend, config)
 
+	promBackend := legacy.NewPromBackend(b)
+	h.PromHandler = legacy.NewPromHandler(promBackend, legacy.WithPromMaxBatchSizeBytes(b.MaxBatchSizeBytes))
+
 	h.PingHandler = legacy.NewPingHandler(config.Version)
 	return h
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/cli"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -21,6 +22,7 @@ type Handler struct {
 	PointsWriterHandler *WriteHandler
 	PingHandler         *PingHandler
 	InfluxQLHandler     *InfluxqlHandler
+	PromHandler         *PromHandler
 }
 
 type Backend struct {
@@ -33,6 +35,7 @@ type Backend struct {
 	OrganizationService   influxdb.OrganizationService
 	BucketService         influxdb.BucketService
 	PointsWriter          storage.PointsWriter
+	ReadsStore            reads.Store
 	DBRPMappingServiceV2  influxdb.DBRPMappingServiceV2
 	ProxyQueryService     query.ProxyQueryService
 	InfluxqldQueryService influxql.ProxyQueryService
@@ -77,6 +80,11 @@ func (h *Handler) ServeHTTP(w http2.ResponseWriter, r *http2.Request) {
 		return
 	}
 
+	if r.URL.Path == PromWritePath || r.URL.Path == PromReadPath {
+		h.PromHandler.ServeHTTP(w, r)
+		return
+	}
+
 	w.WriteHeader(http2.StatusNotFound)
 }
 
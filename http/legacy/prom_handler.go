@@ -0,0 +1,351 @@
+package legacy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http/metric"
+	"github.com/influxdata/influxdb/v2/http/points"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+var _ http.Handler = (*PromHandler)(nil)
+
+const (
+	opPromWriteHandler = "http/v1PromWriteHandler"
+	opPromReadHandler  = "http/v1PromReadHandler"
+
+	// PromWritePath is the path of the Prometheus remote write endpoint.
+	PromWritePath = "/api/v1/prom/write"
+	// PromReadPath is the path of the Prometheus remote read endpoint.
+	PromReadPath = "/api/v1/prom/read"
+)
+
+// PromBackend contains all the services needed to run a PromHandler.
+type PromBackend struct {
+	errors.HTTPErrorHandler
+	Logger *zap.Logger
+
+	EventRecorder      metric.EventRecorder
+	BucketService      influxdb.BucketService
+	PointsWriter       storage.PointsWriter
+	ReadsStore         reads.Store
+	DBRPMappingService influxdb.DBRPMappingServiceV2
+}
+
+// NewPromBackend creates a new backend for the Prometheus remote endpoints.
+func NewPromBackend(b *Backend) *PromBackend {
+	return &PromBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		Logger:             b.Logger.With(zap.String("handler", "prometheus")),
+		EventRecorder:      b.WriteEventRecorder,
+		BucketService:      b.BucketService,
+		PointsWriter:       b.PointsWriter,
+		ReadsStore:         b.ReadsStore,
+		DBRPMappingService: b.DBRPMappingServiceV2,
+	}
+}
+
+// PromHandler serves the Prometheus remote read and write protocol.
+//
+// Both endpoints resolve their bucket from the db and rp query parameters,
+// like the v1 write endpoint. The optional measurement query parameter
+// selects how series are mapped to points, see remote.Mapping.
+type PromHandler struct {
+	errors.HTTPErrorHandler
+	EventRecorder      metric.EventRecorder
+	BucketService      influxdb.BucketService
+	PointsWriter       storage.PointsWriter
+	ReadsStore         reads.Store
+	DBRPMappingService influxdb.DBRPMappingServiceV2
+
+	router            *httprouter.Router
+	logger            *zap.Logger
+	maxBatchSizeBytes int64
+}
+
+// NewPromHandler returns a new instance of PromHandler.
+func NewPromHandler(b *PromBackend, opts ...PromHandlerOption) *PromHandler {
+	h := &PromHandler{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		EventRecorder:      b.EventRecorder,
+		BucketService:      b.BucketService,
+		PointsWriter:       b.PointsWriter,
+		ReadsStore:         b.ReadsStore,
+		DBRPMappingService: b.DBRPMappingService,
+
+		router: NewRouter(b.HTTPErrorHandler),
+		logger: b.Logger,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.router.HandlerFunc(http.MethodPost, PromWritePath, h.handleWrite)
+	h.router.HandlerFunc(http.MethodPost, PromReadPath, h.handleRead)
+
+	return h
+}
+
+// PromHandlerOption is a functional option for a *PromHandler
+type PromHandlerOption func(*PromHandler)
+
+// WithPromMaxBatchSizeBytes configures the maximum size for a
+// (compressed) remote request body allowed by the handler
+func WithPromMaxBatchSizeBytes(n int64) PromHandlerOption {
+	return func(h *PromHandler) {
+		h.maxBatchSizeBytes = n
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *PromHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.router.ServeHTTP(w, r)
+}
+
+// handleWrite handles requests for the Prometheus remote write endpoint.
+func (h *PromHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := getAuthorization(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	sw := kithttp.NewStatusResponseWriter(w)
+	recorder := newWriteUsageRecorder(sw, h.EventRecorder)
+	var requestBytes int
+	defer func() {
+		// Close around the requestBytes variable to placate the linter.
+		recorder.Record(ctx, requestBytes, auth.OrgID, r.URL.Path)
+	}()
+
+	bucket, mapping, err := h.decodeTarget(ctx, r, auth.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+	span.LogKV("bucket_id", bucket.ID)
+
+	if err := checkBucketWritePermissions(auth, bucket.OrgID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+
+	var req remote.WriteRequest
+	if err := h.decodeBody(r, &req, opPromWriteHandler); err != nil {
+		h.HandleHTTPError(ctx, err, sw)
+		return
+	}
+
+	pts, dropped, err := mapping.WriteRequestToPoints(&req)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInvalid,
+			Op:   opPromWriteHandler,
+			Msg:  "unable to convert samples to points",
+			Err:  err,
+		}, sw)
+		return
+	}
+	if dropped > 0 {
+		h.logger.Debug("Dropped samples with values that can't be stored",
+			zap.Int("dropped", dropped), zap.Stringer("bucket_id", bucket.ID))
+	}
+
+	if err := h.PointsWriter.WritePoints(ctx, auth.OrgID, bucket.ID, pts); err != nil {
+		if partialErr, ok := err.(tsdb.PartialWriteError); ok {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EUnprocessableEntity,
+				Op:   opPromWriteHandler,
+				Msg:  "failure writing points to database",
+				Err:  partialErr,
+			}, sw)
+			return
+		}
+
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromWriteHandler,
+			Msg:  "unexpected error writing points to database",
+			Err:  err,
+		}, sw)
+		return
+	}
+
+	sw.WriteHeader(http.StatusNoContent)
+}
+
+// handleRead handles requests for the Prometheus remote read endpoint.
+func (h *PromHandler) handleRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PromHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	auth, err := getAuthorization(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if h.ReadsStore == nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.ENotImplemented,
+			Op:   opPromReadHandler,
+			Msg:  "remote read is not supported by this server",
+		}, w)
+		return
+	}
+
+	bucket, mapping, err := h.decodeTarget(ctx, r, auth.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	span.LogKV("bucket_id", bucket.ID)
+
+	if err := checkBucketReadPermissions(auth, bucket.OrgID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req remote.ReadRequest
+	if err := h.decodeBody(r, &req, opPromReadHandler); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	src, err := types.MarshalAny(h.ReadsStore.GetSource(uint64(bucket.OrgID), uint64(bucket.ID)))
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  "unable to create read source",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	resp := remote.ReadResponse{Results: make([]*remote.QueryResult, len(req.Queries))}
+	for i, q := range req.Queries {
+		readReq, err := mapping.QueryToReadFilterRequest(q)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInvalid,
+				Op:   opPromReadHandler,
+				Msg:  "unable to translate query",
+				Err:  err,
+			}, w)
+			return
+		}
+		readReq.ReadSource = src
+
+		rs, err := h.ReadsStore.ReadFilter(ctx, readReq)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInternal,
+				Op:   opPromReadHandler,
+				Msg:  "unexpected error reading series",
+				Err:  err,
+			}, w)
+			return
+		}
+
+		series, err := mapping.ResultSetToTimeSeries(rs)
+		if err != nil {
+			h.HandleHTTPError(ctx, &errors.Error{
+				Code: errors.EInternal,
+				Op:   opPromReadHandler,
+				Msg:  "unexpected error reading series",
+				Err:  err,
+			}, w)
+			return
+		}
+		resp.Results[i] = &remote.QueryResult{Timeseries: series}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.WriteHeader(http.StatusOK)
+	if err := remote.Encode(w, &resp); err != nil {
+		h.logger.Info("Error writing remote read response", zap.Error(err))
+	}
+}
+
+// decodeTarget resolves the bucket and the mapping of a remote request from
+// its query parameters.
+func (h *PromHandler) decodeTarget(ctx context.Context, r *http.Request, orgID platform.ID) (*influxdb.Bucket, remote.Mapping, error) {
+	qp := r.URL.Query()
+	db := qp.Get("db")
+	if db == "" {
+		return nil, remote.Mapping{}, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "missing db",
+		}
+	}
+
+	bucket, err := findBucket(ctx, h.DBRPMappingService, h.BucketService, orgID, db, qp.Get("rp"))
+	if err != nil {
+		return nil, remote.Mapping{}, err
+	}
+	return bucket, remote.Mapping{Measurement: qp.Get("measurement")}, nil
+}
+
+// decodeBody reads the snappy compressed protobuf message of r into m.
+func (h *PromHandler) decodeBody(r *http.Request, m proto.Message, op string) error {
+	body, err := points.BatchReadCloser(r.Body, "", h.maxBatchSizeBytes)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if err := remote.Decode(body, m); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Op:   op,
+			Msg:  "unable to decode remote request",
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+// checkBucketReadPermissions checks an Authorizer for read permissions to a
+// specific Bucket.
+func checkBucketReadPermissions(auth influxdb.Authorizer, orgID, bucketID platform.ID) error {
+	p, err := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInternal,
+			Op:   opPromReadHandler,
+			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+			Err:  err,
+		}
+	}
+	if pset, err := auth.PermissionSet(); err != nil || !pset.Allowed(*p) {
+		return &errors.Error{
+			Code: errors.EForbidden,
+			Op:   opPromReadHandler,
+			Msg:  "insufficient permissions for read",
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package legacy
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/http/mocks"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	v1storage "github.com/influxdata/influxdb/v2/v1/services/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPromHandler_Write(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		// Mocked Services
+		eventRecorder  = mocks.NewMockEventRecorder(ctrl)
+		dbrpMappingSvc = mocks.NewMockDBRPMappingServiceV2(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+		pointsWriter   = mocks.NewMockPointsWriter(ctrl)
+
+		// Found Resources
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{
+			ID:                  generator.ID(),
+			OrgID:               orgID,
+			Name:                "prometheus/autogen",
+			RetentionPolicyName: "autogen",
+			RetentionPeriod:     72 * time.Hour,
+		}
+		mapping = &influxdb.DBRPMappingV2{
+			OrganizationID:  orgID,
+			BucketID:        bucket.ID,
+			Database:        "prometheus",
+			RetentionPolicy: "autogen",
+			Default:         true,
+		}
+	)
+
+	findAutogenMapping := dbrpMappingSvc.
+		EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{
+			OrgID:    &mapping.OrganizationID,
+			Database: &mapping.Database,
+			Default:  &mapping.Default,
+		}).Return([]*influxdb.DBRPMappingV2{mapping}, 1, nil)
+
+	findBucketByID := bucketService.
+		EXPECT().
+		FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil)
+
+	points := parseLineProtocol(t, "cpu_seconds,host=db1 value=1.5 1000000000")
+	writePoints := pointsWriter.
+		EXPECT().
+		WritePoints(gomock.Any(), orgID, bucket.ID, pointsMatcher{points}).Return(nil)
+
+	recordWriteEvent := eventRecorder.EXPECT().
+		Record(gomock.Any(), gomock.Any())
+
+	gomock.InOrder(
+		findAutogenMapping,
+		findBucketByID,
+		writePoints,
+		recordWriteEvent,
+	)
+
+	perms := newPermissions(influxdb.WriteAction, influxdb.BucketsResourceType, &orgID, nil)
+	auth := newAuthorization(orgID, perms...)
+	ctx := pcontext.SetAuthorizer(context.Background(), auth)
+	r := newPromRequest(ctx, t, PromWritePath+"?db=prometheus", &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{
+			Labels: []*remote.Label{
+				{Name: "__name__", Value: "cpu_seconds"},
+				{Name: "host", Value: "db1"},
+			},
+			Samples: []*remote.Sample{{Value: 1.5, Timestamp: 1000}},
+		}},
+	})
+
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   DefaultErrorHandler,
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrpMappingSvc,
+		PointsWriter:       pointsWriter,
+		EventRecorder:      eventRecorder,
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "", w.Body.String())
+}
+
+func TestPromHandler_Read(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		// Mocked Services
+		dbrpMappingSvc = mocks.NewMockDBRPMappingServiceV2(ctrl)
+		bucketService  = mocks.NewMockBucketService(ctrl)
+
+		// Found Resources
+		orgID  = generator.ID()
+		bucket = &influxdb.Bucket{
+			ID:    generator.ID(),
+			OrgID: orgID,
+			Name:  "prometheus/autogen",
+		}
+		mapping = &influxdb.DBRPMappingV2{
+			OrganizationID: orgID,
+			BucketID:       bucket.ID,
+			Database:       "prometheus",
+			Default:        true,
+		}
+	)
+
+	dbrpMappingSvc.
+		EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).Return([]*influxdb.DBRPMappingV2{mapping}, 1, nil).
+		AnyTimes()
+	bucketService.
+		EXPECT().
+		FindBucketByID(gomock.Any(), bucket.ID).Return(bucket, nil).
+		AnyTimes()
+
+	store := &promTestStore{}
+	handler := NewPromHandler(&PromBackend{
+		HTTPErrorHandler:   DefaultErrorHandler,
+		Logger:             zaptest.NewLogger(t),
+		BucketService:      bucketService,
+		DBRPMappingService: dbrpMappingSvc,
+		ReadsStore:         store,
+	})
+	req := &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchEqual, Name: "__name__", Value: "cpu_seconds"},
+			},
+		}},
+	}
+
+	t.Run("without read permission", func(t *testing.T) {
+		perms := newPermissions(influxdb.WriteAction, influxdb.BucketsResourceType, &orgID, nil)
+		ctx := pcontext.SetAuthorizer(context.Background(), newAuthorization(orgID, perms...))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newPromRequest(ctx, t, PromReadPath+"?db=prometheus", req))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("with read permission", func(t *testing.T) {
+		perms := newPermissions(influxdb.ReadAction, influxdb.BucketsResourceType, &orgID, nil)
+		ctx := pcontext.SetAuthorizer(context.Background(), newAuthorization(orgID, perms...))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newPromRequest(ctx, t, PromReadPath+"?db=prometheus", req))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "snappy", w.Header().Get("Content-Encoding"))
+
+		var resp remote.ReadResponse
+		require.NoError(t, remote.Decode(w.Body, &resp))
+		assert.Len(t, resp.Results, 1)
+
+		require.NotNil(t, store.req)
+		assert.Equal(t, `'_field' = "value" AND '_measurement' = "cpu_seconds"`, reads.PredicateToExprString(store.req.Predicate))
+		assert.Equal(t, int64(time.Second), store.req.Range.Start)
+		assert.NotNil(t, store.req.ReadSource)
+	})
+}
+
+func newPromRequest(ctx context.Context, t *testing.T, target string, m proto.Message) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	require.NoError(t, remote.Encode(&body, m))
+	return httptest.NewRequest(http.MethodPost, "http://localhost:9999"+target, &body).WithContext(ctx)
+}
+
+// promTestStore records the filter request it is asked to read and returns
+// no series.
+type promTestStore struct {
+	reads.Store
+	req *datatypes.ReadFilterRequest
+}
+
+func (s *promTestStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.req = req
+	return nil, nil
+}
+
+func (s *promTestStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &v1storage.ReadSource{Database: "prometheus"}
+}
@@ -170,12 +170,18 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 // findBucket finds a bucket for the specified database and
 // retention policy combination.
 func (h *WriteHandler) findBucket(ctx context.Context, orgID platform.ID, db, rp string) (*influxdb.Bucket, error) {
-	mapping, err := h.findMapping(ctx, orgID, db, rp)
+	return findBucket(ctx, h.DBRPMappingService, h.BucketService, orgID, db, rp)
+}
+
+// findBucket finds a bucket for the specified database and retention policy
+// combination through the DBRP mappings of the organization.
+func findBucket(ctx context.Context, dbrps influxdb.DBRPMappingServiceV2, buckets influxdb.BucketService, orgID platform.ID, db, rp string) (*influxdb.Bucket, error) {
+	mapping, err := findMapping(ctx, dbrps, orgID, db, rp)
 	if err != nil {
 		return nil, err
 	}
 
-	return h.BucketService.FindBucketByID(ctx, mapping.BucketID)
+	return buckets.FindBucketByID(ctx, mapping.BucketID)
 }
 
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
@@ -203,7 +209,7 @@ func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID platf
 
 // findMapping finds a DBRPMappingV2 for the database and retention policy
 // combination.
-func (h *WriteHandler) findMapping(ctx context.Context, orgID platform.ID, db, rp string) (*influxdb.DBRPMappingV2, error) {
+func findMapping(ctx context.Context, dbrps influxdb.DBRPMappingServiceV2, orgID platform.ID, db, rp string) (*influxdb.DBRPMappingV2, error) {
 	filter := influxdb.DBRPMappingFilterV2{
 		OrgID:    &orgID,
 		Database: &db,
@@ -215,7 +221,7 @@ func (h *WriteHandler) findMapping(ctx context.Context, orgID platform.ID, db, r
 		filter.Default = &b
 	}
 
-	mappings, count, err := h.DBRPMappingService.FindMany(ctx, filter)
+	mappings, count, err := dbrps.FindMany(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +55,9 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// TODO(affo): change this to be mounted prefixes: https://github.com/influxdata/idpe/issues/6689.
 	if r.URL.Path == "/write" ||
 		r.URL.Path == "/query" ||
-		r.URL.Path == "/ping" {
+		r.URL.Path == "/ping" ||
+		r.URL.Path == legacy.PromWritePath ||
+		r.URL.Path == legacy.PromReadPath {
 		h.LegacyHandler.ServeHTTP(w, r)
 		return
 	}
@@ -0,0 +1,201 @@
+package points
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/csv2lp"
+)
+
+// Format is the format of the points of a write request body.
+type Format int
+
+const (
+	// FormatLineProtocol is line protocol.
+	FormatLineProtocol Format = iota
+	// FormatCSV is annotated CSV, as converted by csv2lp.
+	FormatCSV
+	// FormatJSON is a JSON array of points, see parseJSONPoints.
+	FormatJSON
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatCSV:
+		return "csv"
+	case FormatJSON:
+		return "json"
+	default:
+		return "line protocol"
+	}
+}
+
+// FormatFromContentType returns the format of a body with Content-Type ct.
+// Line protocol is assumed for any other content type, as clients have long
+// been able to send line protocol without setting it.
+func FormatFromContentType(ct string) Format {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return FormatLineProtocol
+	}
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return FormatCSV
+	case "application/json":
+		return FormatJSON
+	default:
+		return FormatLineProtocol
+	}
+}
+
+// csvToLineProtocol converts annotated CSV to line protocol.
+func csvToLineProtocol(data []byte) ([]byte, error) {
+	return ioutil.ReadAll(csv2lp.CsvToLineProtocol(bytes.NewReader(data)))
+}
+
+// jsonPoint is a point of the JSON format, for example:
+//
+//	[
+//	  {
+//	    "measurement": "cpu",
+//	    "tags": {"host": "server01"},
+//	    "fields": {"usage": 0.64, "cores": {"type": "integer", "value": 8}, "model": "x86", "up": true},
+//	    "time": 1600000000000000000
+//	  }
+//	]
+//
+// Numbers are float fields, strings are string fields and booleans are
+// boolean fields. A field of any other type is an object with the "type" of
+// the field and its "value". The time is optional; it is either a unix
+// timestamp in the precision of the request, or an RFC3339 string.
+type jsonPoint struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        interface{}            `json:"time"`
+}
+
+// parseJSONPoints parses a JSON array of points. Points without a time are
+// given defaultTime.
+func parseJSONPoints(data []byte, defaultTime time.Time, precision string) ([]models.Point, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var jsonPoints []jsonPoint
+	if err := dec.Decode(&jsonPoints); err != nil {
+		return nil, fmt.Errorf("unable to parse JSON points: %v", err)
+	}
+
+	points := make([]models.Point, 0, len(jsonPoints))
+	for i, jp := range jsonPoints {
+		pt, err := jp.toPoint(defaultTime, precision)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse point %d: %v", i+1, err)
+		}
+		points = append(points, pt)
+	}
+	return points, nil
+}
+
+func (jp *jsonPoint) toPoint(defaultTime time.Time, precision string) (models.Point, error) {
+	if jp.Measurement == "" {
+		return nil, errors.New("missing measurement")
+	}
+
+	fields := make(models.Fields, len(jp.Fields))
+	for k, v := range jp.Fields {
+		value, err := jsonFieldValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", k, err)
+		}
+		fields[k] = value
+	}
+
+	t := defaultTime
+	switch v := jp.Time.(type) {
+	case nil:
+		t = t.Truncate(time.Duration(models.GetPrecisionMultiplier(precision)))
+	case json.Number:
+		ts, err := strconv.ParseInt(v.String(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %s: %v", v, err)
+		}
+		if t, err = models.SafeCalcTime(ts, precision); err != nil {
+			return nil, err
+		}
+	case string:
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			return nil, fmt.Errorf("invalid time %q: %v", v, err)
+		}
+		t = t.UTC()
+	default:
+		return nil, fmt.Errorf("invalid time %v: must be a number or an RFC3339 string", v)
+	}
+
+	// Sort the tags so the series key is always the same.
+	keys := make([]string, 0, len(jp.Tags))
+	for k := range jp.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make(models.Tags, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, models.NewTag([]byte(k), []byte(jp.Tags[k])))
+	}
+
+	return models.NewPoint(jp.Measurement, tags, fields, t)
+}
+
+// jsonFieldValue converts a field value of the JSON format to a field value of
+// a models.Point.
+func jsonFieldValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return strconv.ParseFloat(v.String(), 64)
+	case string, bool:
+		return v, nil
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		value, ok := v["value"]
+		if !ok {
+			return nil, errors.New(`typed values must have a "value"`)
+		}
+		switch typ {
+		case "float", "integer", "unsigned":
+			n, ok := value.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("%s value %v is not a number", typ, value)
+			}
+			switch typ {
+			case "integer":
+				return strconv.ParseInt(n.String(), 10, 64)
+			case "unsigned":
+				return strconv.ParseUint(n.String(), 10, 64)
+			default:
+				return strconv.ParseFloat(n.String(), 64)
+			}
+		case "string":
+			if s, ok := value.(string); ok {
+				return s, nil
+			}
+		case "boolean":
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown type %q, must be one of float, integer, unsigned, string and boolean", typ)
+		}
+		return nil, fmt.Errorf("%s value %v has the wrong type", typ, value)
+	default:
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+}
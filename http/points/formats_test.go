@@ -0,0 +1,197 @@
+package points
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+func TestFormatFromContentType(t *testing.T) {
+	for ct, want := range map[string]Format{
+		"":                                FormatLineProtocol,
+		"text/plain; charset=utf-8":       FormatLineProtocol,
+		"text/csv":                        FormatCSV,
+		"application/csv":                 FormatCSV,
+		"application/json":                FormatJSON,
+		"application/json; charset=utf-8": FormatJSON,
+		"not a content type;;":            FormatLineProtocol,
+	} {
+		if got := FormatFromContentType(ct); got != want {
+			t.Errorf("FormatFromContentType(%q) = %s, want %s", ct, got, want)
+		}
+	}
+}
+
+func TestParser_Parse_Formats(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		precision string
+		body      string
+		want      []string
+		wantErr   string
+	}{
+		{
+			name:   "line protocol",
+			format: FormatLineProtocol,
+			body:   "cpu,host=a usage=1.5 1\n",
+			want:   []string{"cpu,host=a usage=1.5 1"},
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			body: "#datatype measurement,tag,double,long,dateTime:number\n" +
+				"m,host,usage,cores,time\n" +
+				"cpu,a,1.5,8,1\n" +
+				"cpu,b,2,4,2\n",
+			want: []string{
+				"cpu,host=a usage=1.5,cores=8i 1",
+				"cpu,host=b usage=2,cores=4i 2",
+			},
+		},
+		{
+			name:      "csv nanosecond precision",
+			format:    FormatCSV,
+			precision: "ns",
+			body: "#datatype measurement,double,dateTime:RFC3339\n" +
+				"m,usage,time\n" +
+				"cpu,1.5,1970-01-01T00:00:03Z\n",
+			want: []string{"cpu usage=1.5 3000000000"},
+		},
+		{
+			name:      "csv other precision",
+			format:    FormatCSV,
+			precision: "s",
+			body:      "#datatype measurement,double,dateTime:number\nm,usage,time\ncpu,1.5,3\n",
+			wantErr:   "precision s is not supported for csv",
+		},
+		{
+			name:    "csv without measurement",
+			format:  FormatCSV,
+			body:    "#datatype tag,double\nhost,usage\na,1.5\n",
+			wantErr: "measurement",
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			body: `[
+				{"measurement": "cpu", "tags": {"z": "1", "host": "a"}, "fields": {"usage": 1.5}, "time": 1},
+				{"measurement": "cpu", "fields": {
+					"f": {"type": "float", "value": 2},
+					"i": {"type": "integer", "value": -3},
+					"u": {"type": "unsigned", "value": 4},
+					"s": {"type": "string", "value": "x"},
+					"b": {"type": "boolean", "value": true},
+					"n": "y",
+					"t": false
+				}, "time": "1970-01-01T00:00:02Z"}
+			]`,
+			want: []string{
+				"cpu,host=a,z=1 usage=1.5 1",
+				`cpu b=true,f=2,i=-3i,n="y",s="x",t=false,u=4u 2000000000`,
+			},
+		},
+		{
+			name:      "json precision",
+			format:    FormatJSON,
+			precision: "s",
+			body:      `[{"measurement": "cpu", "fields": {"usage": 1}, "time": 3}]`,
+			want:      []string{"cpu usage=1 3000000000"},
+		},
+		{
+			name:    "json missing measurement",
+			format:  FormatJSON,
+			body:    `[{"fields": {"usage": 1}}]`,
+			wantErr: "unable to parse point 1: missing measurement",
+		},
+		{
+			name:    "json missing fields",
+			format:  FormatJSON,
+			body:    `[{"measurement": "cpu"}]`,
+			wantErr: "unable to parse point 1",
+		},
+		{
+			name:    "json unknown type",
+			format:  FormatJSON,
+			body:    `[{"measurement": "cpu", "fields": {"v": {"type": "duration", "value": 1}}}]`,
+			wantErr: `field "v": unknown type "duration"`,
+		},
+		{
+			name:    "json wrong value type",
+			format:  FormatJSON,
+			body:    `[{"measurement": "cpu", "fields": {"v": {"type": "integer", "value": "1"}}}]`,
+			wantErr: `field "v": integer value 1 is not a number`,
+		},
+		{
+			name:    "json integer overflow",
+			format:  FormatJSON,
+			body:    `[{"measurement": "cpu", "fields": {"v": {"type": "integer", "value": 1.5}}}]`,
+			wantErr: `field "v"`,
+		},
+		{
+			name:    "json invalid time",
+			format:  FormatJSON,
+			body:    `[{"measurement": "cpu", "fields": {"v": 1}, "time": "yesterday"}]`,
+			wantErr: `invalid time "yesterday"`,
+		},
+		{
+			name:    "json not an array",
+			format:  FormatJSON,
+			body:    `{"measurement": "cpu"}`,
+			wantErr: "unable to parse JSON points",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Parser{Precision: tt.precision, Format: tt.format}
+			parsed, err := p.Parse(context.Background(), platform.ID(1), platform.ID(2), ioutil.NopCloser(strings.NewReader(tt.body)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.RawSize != len(tt.body) {
+				t.Errorf("RawSize = %d, want %d", parsed.RawSize, len(tt.body))
+			}
+			if len(parsed.Points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d: %v", len(parsed.Points), len(tt.want), parsed.Points)
+			}
+			for i, pt := range parsed.Points {
+				if got := pt.String(); got != tt.want[i] {
+					t.Errorf("point %d = %s, want %s", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParser_Parse_JSONDefaultTime(t *testing.T) {
+	before := time.Now().UTC().Truncate(time.Second)
+	p := &Parser{Precision: "s", Format: FormatJSON}
+	parsed, err := p.Parse(context.Background(), platform.ID(1), platform.ID(2),
+		ioutil.NopCloser(strings.NewReader(`[{"measurement": "cpu", "fields": {"usage": 1}}]`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Points[0].Time(); got.Before(before) || got.Nanosecond() != 0 {
+		t.Errorf("unexpected default time %v", got)
+	}
+}
+
+func TestParser_ParseLines_Formats(t *testing.T) {
+	for _, format := range []Format{FormatCSV, FormatJSON} {
+		p := &Parser{Format: format}
+		_, err := p.ParseLines(context.Background(), platform.ID(1), platform.ID(2), ioutil.NopCloser(strings.NewReader("x")))
+		if err == nil || !strings.Contains(err.Error(), "partial writes are not supported") {
+			t.Errorf("%s: unexpected error %v", format, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"time"
@@ -37,11 +38,17 @@ const (
 type ParsedPoints struct {
 	Points  models.Points
 	RawSize int
+
+	// Lines holds the line number of each point and Rejected an error for
+	// each line which could not be parsed, when parsed with ParseLines.
+	Lines    []int
+	Rejected []*models.LineError
 }
 
 // Parser parses batches of Points.
 type Parser struct {
 	Precision string
+	Format    Format
 	//ParserOptions []models.ParserOption
 }
 
@@ -52,35 +59,71 @@ func (pw *Parser) Parse(ctx context.Context, orgID, bucketID platform.ID, rc io.
 	return pw.parsePoints(ctx, orgID, bucketID, rc)
 }
 
-func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID, rc io.ReadCloser) (*ParsedPoints, error) {
-	data, err := readAll(ctx, rc)
-	if err != nil {
-		code := errors2.EInternal
-		if errors.Is(err, ErrMaxBatchSizeExceeded) {
-			code = errors2.ETooLarge
-		} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
-			code = errors2.EInvalid
-		}
+// ParseLines parses the points from an io.ReadCloser for a specific Bucket.
+// Unlike Parse, lines which can't be parsed don't fail the whole batch, they
+// are reported in the Rejected lines of the result. The body must be line
+// protocol.
+func (pw *Parser) ParseLines(ctx context.Context, orgID, bucketID platform.ID, rc io.ReadCloser) (*ParsedPoints, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "write points")
+	defer span.Finish()
+
+	if pw.Format != FormatLineProtocol {
 		return nil, &errors2.Error{
-			Code: code,
+			Code: errors2.EInvalid,
 			Op:   opPointsWriter,
-			Msg:  msgUnableToReadData,
-			Err:  err,
+			Msg:  fmt.Sprintf("partial writes are not supported for %s", pw.Format),
 		}
 	}
 
-	requestBytes := len(data)
-	if requestBytes == 0 {
+	data, err := readBody(ctx, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	parseSpan, _ := tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
+	points, lines, rejected := models.ParsePointsWithLines(data, time.Now().UTC(), pw.Precision)
+	parseSpan.LogKV("values_total", len(points), "rejected_total", len(rejected))
+	parseSpan.Finish()
+
+	return &ParsedPoints{
+		Points:   points,
+		RawSize:  len(data),
+		Lines:    lines,
+		Rejected: rejected,
+	}, nil
+}
+
+func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID, rc io.ReadCloser) (*ParsedPoints, error) {
+	// csv2lp always writes nanosecond timestamps, so the timestamps of CSV
+	// points can't be in any other precision.
+	if pw.Format == FormatCSV && pw.Precision != "" && pw.Precision != "ns" {
+		_ = rc.Close()
 		return nil, &errors2.Error{
-			Op:   opPointsWriter,
 			Code: errors2.EInvalid,
-			Msg:  msgWritingRequiresPoints,
+			Op:   opPointsWriter,
+			Msg:  fmt.Sprintf("precision %s is not supported for %s, its timestamps are in nanoseconds", pw.Precision, pw.Format),
 		}
 	}
 
+	data, err := readBody(ctx, rc)
+	if err != nil {
+		return nil, err
+	}
+	requestBytes := len(data)
+
 	span, _ := tracing.StartSpanFromContextWithOperationName(ctx, "encoding and parsing")
 
-	points, err := models.ParsePointsWithPrecision(data, time.Now().UTC(), pw.Precision)
+	var points []models.Point
+	switch pw.Format {
+	case FormatCSV:
+		if data, err = csvToLineProtocol(data); err == nil {
+			points, err = models.ParsePointsWithPrecision(data, time.Now().UTC(), "ns")
+		}
+	case FormatJSON:
+		points, err = parseJSONPoints(data, time.Now().UTC(), pw.Precision)
+	default:
+		points, err = models.ParsePointsWithPrecision(data, time.Now().UTC(), pw.Precision)
+	}
 	span.LogKV("values_total", len(points))
 	span.Finish()
 	if err != nil {
@@ -108,6 +151,34 @@ func (pw *Parser) parsePoints(ctx context.Context, orgID, bucketID platform.ID,
 	}, nil
 }
 
+// readBody reads the whole request body, which must not be empty.
+func readBody(ctx context.Context, rc io.ReadCloser) ([]byte, error) {
+	data, err := readAll(ctx, rc)
+	if err != nil {
+		code := errors2.EInternal
+		if errors.Is(err, ErrMaxBatchSizeExceeded) {
+			code = errors2.ETooLarge
+		} else if errors.Is(err, gzip.ErrHeader) || errors.Is(err, gzip.ErrChecksum) {
+			code = errors2.EInvalid
+		}
+		return nil, &errors2.Error{
+			Code: code,
+			Op:   opPointsWriter,
+			Msg:  msgUnableToReadData,
+			Err:  err,
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, &errors2.Error{
+			Op:   opPointsWriter,
+			Code: errors2.EInvalid,
+			Msg:  msgWritingRequiresPoints,
+		}
+	}
+	return data, nil
+}
+
 func readAll(ctx context.Context, rc io.ReadCloser) (data []byte, err error) {
 	defer func() {
 		if cerr := rc.Close(); cerr != nil && err == nil {
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+)
+
+var _ taskmodel.BackfillService = (*TaskService)(nil)
+
+type backfillsResponse struct {
+	Backfills []*taskmodel.Backfill `json:"backfills"`
+}
+
+type postBackfillRequest struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
+func (h *TaskHandler) handlePostBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, _, err := decodeBackfillParams(ctx, false)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req postBackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "failed to decode request",
+		}, w)
+		return
+	}
+	if req.Start.IsZero() || req.Stop.IsZero() {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Code: errors2.EInvalid,
+			Msg:  "start and stop are required",
+		}, w)
+		return
+	}
+
+	b, err := h.BackfillService.CreateBackfill(ctx, taskID, req.Start, req.Stop)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err: err,
+			Msg: "failed to create backfill",
+		}, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, b); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfills(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, _, err := decodeBackfillParams(ctx, false)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	bs, err := h.BackfillService.FindBackfills(ctx, taskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err: err,
+			Msg: "failed to find backfills",
+		}, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, backfillsResponse{Backfills: bs}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, id, err := decodeBackfillParams(ctx, true)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.FindBackfillByID(ctx, taskID, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err: err,
+			Msg: "failed to find backfill",
+		}, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, b); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleCancelBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, id, err := decodeBackfillParams(ctx, true)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BackfillService.CancelBackfill(ctx, taskID, id); err != nil {
+		h.HandleHTTPError(ctx, &errors2.Error{
+			Err: err,
+			Msg: "failed to cancel backfill",
+		}, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBackfillParams returns the task ID, and the backfill ID when withID
+// is set, from the path of a backfill request.
+func decodeBackfillParams(ctx context.Context, withID bool) (platform.ID, platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+
+	var taskID, id platform.ID
+	if err := taskID.DecodeFromString(params.ByName("id")); err != nil {
+		return 0, 0, &errors2.Error{
+			Err:  err,
+			Code: errors2.EInvalid,
+			Msg:  "you must provide a valid task ID",
+		}
+	}
+	if withID {
+		if err := id.DecodeFromString(params.ByName("bid")); err != nil {
+			return 0, 0, &errors2.Error{
+				Err:  err,
+				Code: errors2.EInvalid,
+				Msg:  "you must provide a valid backfill ID",
+			}
+		}
+	}
+	return taskID, id, nil
+}
+
+// CreateBackfill starts running a task over a historical time range.
+func (t TaskService) CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*taskmodel.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b taskmodel.Backfill
+	err := t.Client.
+		PostJSON(postBackfillRequest{Start: start.UTC(), Stop: stop.UTC()}, taskIDBackfillPath(taskID)).
+		DecodeJSON(&b).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// FindBackfillByID returns a single backfill of a task.
+func (t TaskService) FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*taskmodel.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b taskmodel.Backfill
+	err := t.Client.
+		Get(taskIDBackfillPath(taskID), id.String()).
+		DecodeJSON(&b).
+		Do(ctx)
+	if err != nil {
+		if errors2.ErrorCode(err) == errors2.ENotFound {
+			return nil, taskmodel.ErrBackfillNotFound
+		}
+		return nil, err
+	}
+	return &b, nil
+}
+
+// FindBackfills returns the backfills of a task, latest first.
+func (t TaskService) FindBackfills(ctx context.Context, taskID platform.ID) ([]*taskmodel.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp backfillsResponse
+	err := t.Client.
+		Get(taskIDBackfillPath(taskID)).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Backfills, nil
+}
+
+// CancelBackfill stops a backfill of a task.
+func (t TaskService) CancelBackfill(ctx context.Context, taskID, id platform.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return t.Client.
+		Delete(taskIDBackfillPath(taskID), id.String()).
+		Do(ctx)
+}
+
+func taskIDBackfillPath(id platform.ID) string {
+	return path.Join(prefixTasks, id.String(), "backfill")
+}
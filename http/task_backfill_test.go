@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	pcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeBackfillService struct {
+	taskmodel.BackfillService
+	created []*taskmodel.Backfill
+}
+
+func (s *fakeBackfillService) CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*taskmodel.Backfill, error) {
+	b := &taskmodel.Backfill{
+		ID:        platform.ID(len(s.created) + 1),
+		TaskID:    taskID,
+		Start:     start,
+		Stop:      stop,
+		Status:    taskmodel.BackfillRunning,
+		Total:     3,
+		CreatedAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	s.created = append(s.created, b)
+	return b, nil
+}
+
+func (s *fakeBackfillService) FindBackfills(ctx context.Context, taskID platform.ID) ([]*taskmodel.Backfill, error) {
+	return s.created, nil
+}
+
+func newBackfillRequest(t *testing.T, method, body string) *http.Request {
+	r := httptest.NewRequest(method, "http://any.url", bytes.NewBufferString(body))
+	r = r.WithContext(context.WithValue(
+		context.Background(),
+		httprouter.ParamsKey,
+		httprouter.Params{{Key: "id", Value: platform.ID(1).String()}},
+	))
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{Permissions: influxdb.OperPermissions()}))
+}
+
+func TestTaskHandler_Backfill(t *testing.T) {
+	svc := &fakeBackfillService{}
+	taskBackend := NewMockTaskBackend(t)
+	taskBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	taskBackend.BackfillService = svc
+	h := NewTaskHandler(zaptest.NewLogger(t), taskBackend)
+
+	w := httptest.NewRecorder()
+	h.handlePostBackfill(w, newBackfillRequest(t, "POST", `{"start": "2021-01-01T00:00:00Z", "stop": "2021-01-01T02:00:00Z"}`))
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	require.Equal(t, http.StatusCreated, res.StatusCode, string(body))
+	eq, diff, err := jsonEqual(string(body), `{
+  "id": "0000000000000001",
+  "taskID": "0000000000000001",
+  "start": "2021-01-01T00:00:00Z",
+  "stop": "2021-01-01T02:00:00Z",
+  "status": "running",
+  "total": 3,
+  "succeeded": 0,
+  "failed": 0,
+  "createdAt": "2021-01-02T00:00:00Z"
+}`)
+	require.NoError(t, err)
+	assert.True(t, eq, diff)
+
+	w = httptest.NewRecorder()
+	h.handlePostBackfill(w, newBackfillRequest(t, "POST", `{"start": "2021-01-01T00:00:00Z"}`))
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+	w = httptest.NewRecorder()
+	h.handleGetBackfills(w, newBackfillRequest(t, "GET", ""))
+	res = w.Result()
+	body, _ = ioutil.ReadAll(res.Body)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(body))
+	assert.Contains(t, string(body), `"backfills"`)
+	assert.Len(t, svc.created, 1)
+}
@@ -32,6 +32,7 @@ type TaskBackend struct {
 
 	AlgoWProxy                 FeatureProxyHandler
 	TaskService                taskmodel.TaskService
+	BackfillService            taskmodel.BackfillService
 	AuthorizationService       influxdb.AuthorizationService
 	OrganizationService        influxdb.OrganizationService
 	UserResourceMappingService influxdb.UserResourceMappingService
@@ -47,6 +48,7 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		log:                        log,
 		AlgoWProxy:                 b.AlgoWProxy,
 		TaskService:                b.TaskService,
+		BackfillService:            b.BackfillService,
 		AuthorizationService:       b.AuthorizationService,
 		OrganizationService:        b.OrganizationService,
 		UserResourceMappingService: b.UserResourceMappingService,
@@ -63,6 +65,7 @@ type TaskHandler struct {
 	log *zap.Logger
 
 	TaskService                taskmodel.TaskService
+	BackfillService            taskmodel.BackfillService
 	AuthorizationService       influxdb.AuthorizationService
 	OrganizationService        influxdb.OrganizationService
 	UserResourceMappingService influxdb.UserResourceMappingService
@@ -85,6 +88,8 @@ const (
 	tasksIDRunsIDRetryPath = "/api/v2/tasks/:id/runs/:rid/retry"
 	tasksIDLabelsPath      = "/api/v2/tasks/:id/labels"
 	tasksIDLabelsIDPath    = "/api/v2/tasks/:id/labels/:lid"
+	tasksIDBackfillPath    = "/api/v2/tasks/:id/backfill"
+	tasksIDBackfillIDPath  = "/api/v2/tasks/:id/backfill/:bid"
 )
 
 // NewTaskHandler returns a new instance of TaskHandler.
@@ -95,6 +100,7 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		log:              log,
 
 		TaskService:                b.TaskService,
+		BackfillService:            b.BackfillService,
 		AuthorizationService:       b.AuthorizationService,
 		OrganizationService:        b.OrganizationService,
 		UserResourceMappingService: b.UserResourceMappingService,
@@ -143,6 +149,11 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	h.HandlerFunc("GET", tasksIDBackfillPath, h.handleGetBackfills)
+	h.HandlerFunc("POST", tasksIDBackfillPath, h.handlePostBackfill)
+	h.HandlerFunc("GET", tasksIDBackfillIDPath, h.handleGetBackfill)
+	h.HandlerFunc("DELETE", tasksIDBackfillIDPath, h.handleCancelBackfill)
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -170,6 +181,8 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          string                 `json:"offset,omitempty"`
+	Retry           int64                  `json:"retry,omitempty"`
+	DependsOn       []platform.ID          `json:"dependsOn,omitempty"`
 	LatestCompleted string                 `json:"latestCompleted,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
@@ -215,6 +228,8 @@ func NewFrontEndTask(t taskmodel.Task) Task {
 		Every:           t.Every,
 		Cron:            t.Cron,
 		Offset:          offset,
+		Retry:           t.Retry,
+		DependsOn:       t.DependsOn,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
@@ -263,6 +278,8 @@ func convertTask(t Task) *taskmodel.Task {
 		Every:           t.Every,
 		Cron:            t.Cron,
 		Offset:          offset,
+		Retry:           t.Retry,
+		DependsOn:       t.DependsOn,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
@@ -396,6 +413,7 @@ type httpRun struct {
 	FinishedAt   *time.Time      `json:"finishedAt,omitempty"`
 	RequestedAt  *time.Time      `json:"requestedAt,omitempty"`
 	Log          []taskmodel.Log `json:"log,omitempty"`
+	Retry        int             `json:"retry,omitempty"`
 }
 
 func newRunResponse(r taskmodel.Run) runResponse {
@@ -405,6 +423,7 @@ func newRunResponse(r taskmodel.Run) runResponse {
 		Status:       r.Status,
 		Log:          r.Log,
 		ScheduledFor: &r.ScheduledFor,
+		Retry:        r.Retry,
 	}
 
 	if !r.StartedAt.IsZero() {
@@ -434,6 +453,7 @@ func convertRun(r httpRun) *taskmodel.Run {
 		TaskID: r.TaskID,
 		Status: r.Status,
 		Log:    r.Log,
+		Retry:  r.Retry,
 	}
 
 	if r.StartedAt != nil {
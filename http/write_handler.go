@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
@@ -88,6 +90,7 @@ const (
 	prefixWrite          = "/api/v2/write"
 	msgInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
 	msgInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
+	msgInvalidPartial    = "invalid partial; valid values are true and false"
 
 	opWriteHandler = "http/writeHandler"
 )
@@ -180,10 +183,16 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Partial {
+		requestBytes = h.writePartial(ctx, sw, r, org.ID, bucket.ID, req)
+		return
+	}
+
 	// TODO: Backport?
 	//opts := append([]models.ParserOption{}, h.parserOptions...)
 	//opts = append(opts, models.WithParserPrecision(req.Precision))
-	parsed, err := points.NewParser(req.Precision).Parse(ctx, org.ID, bucket.ID, req.Body)
+	parser := &points.Parser{Precision: req.Precision, Format: req.Format}
+	parsed, err := parser.Parse(ctx, org.ID, bucket.ID, req.Body)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, sw)
 		return
@@ -213,6 +222,93 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	sw.WriteHeader(http.StatusNoContent)
 }
 
+// partialWriteResponse is the body of a partial write which rejected lines.
+type partialWriteResponse struct {
+	Code     string         `json:"code,omitempty"`
+	Message  string         `json:"message,omitempty"`
+	Written  int            `json:"written"`
+	Rejected []rejectedLine `json:"rejected"`
+}
+
+// rejectedLine is a line of a partial write which was not written. Line is 0
+// when points were dropped without knowing which.
+type rejectedLine struct {
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// writePartial writes the valid lines of the request body, and reports the
+// lines which could not be parsed or were dropped by the points writer. It
+// returns the size of the request body.
+func (h *WriteHandler) writePartial(ctx context.Context, w http.ResponseWriter, r *http.Request, orgID, bucketID platform.ID, req *writeRequest) int {
+	parser := &points.Parser{Precision: req.Precision, Format: req.Format}
+	parsed, err := parser.ParseLines(ctx, orgID, bucketID, req.Body)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return 0
+	}
+
+	res := partialWriteResponse{
+		Written:  len(parsed.Points),
+		Rejected: make([]rejectedLine, 0, len(parsed.Rejected)),
+	}
+	for _, lineErr := range parsed.Rejected {
+		res.Rejected = append(res.Rejected, rejectedLine{Line: lineErr.Line, Reason: lineErr.Err.Error()})
+	}
+
+	if len(parsed.Points) > 0 {
+		// Points writers may reorder points, so remember their line before writing.
+		lines := make(map[models.Point]int, len(parsed.Points))
+		for i, p := range parsed.Points {
+			lines[p] = parsed.Lines[i]
+		}
+
+		if err := h.PointsWriter.WritePoints(ctx, orgID, bucketID, parsed.Points); err != nil {
+			partialErr, ok := err.(tsdb.PartialWriteError)
+			if !ok {
+				h.HandleHTTPError(ctx, &errors.Error{
+					Code: errors.EInternal,
+					Op:   opWriteHandler,
+					Msg:  "unexpected error writing points to database",
+					Err:  err,
+				}, w)
+				return parsed.RawSize
+			}
+
+			for _, p := range partialErr.DroppedPoints {
+				res.Rejected = append(res.Rejected, rejectedLine{Line: lines[p.Point], Reason: p.Reason})
+			}
+			if n := partialErr.Dropped - len(partialErr.DroppedPoints); n > 0 {
+				res.Rejected = append(res.Rejected, rejectedLine{Reason: fmt.Sprintf("%s dropped=%d", partialErr.Reason, n)})
+			}
+			res.Written -= partialErr.Dropped
+		}
+	}
+
+	if len(res.Rejected) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return parsed.RawSize
+	}
+
+	// Lines dropped without knowing which go last.
+	sort.SliceStable(res.Rejected, func(i, j int) bool {
+		li, lj := res.Rejected[i].Line, res.Rejected[j].Line
+		return li != 0 && (lj == 0 || li < lj)
+	})
+
+	code := http.StatusOK
+	if res.Written <= 0 {
+		res.Written = 0
+		res.Code = errors.EInvalid
+		res.Message = "no points were written"
+		code = http.StatusBadRequest
+	}
+	if err := encodeResponse(ctx, w, code, res); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+	return parsed.RawSize
+}
+
 // checkBucketWritePermissions checks an Authorizer for write permissions to a
 // specific Bucket.
 func checkBucketWritePermissions(auth influxdb.Authorizer, orgID, bucketID platform.ID) error {
@@ -242,7 +338,11 @@ type writeRequest struct {
 	Org       string
 	Bucket    string
 	Precision string
+	Format    points.Format
 	Body      io.ReadCloser
+
+	// Partial writes the valid lines of Body even if some are rejected.
+	Partial bool
 }
 
 // decodeWriteRequest extracts information from an http.Request object to
@@ -262,6 +362,18 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		}
 	}
 
+	var partial bool
+	if s := qp.Get("partial"); s != "" {
+		var err error
+		if partial, err = strconv.ParseBool(s); err != nil {
+			return nil, &errors.Error{
+				Code: errors.EInvalid,
+				Op:   "http/newWriteRequest",
+				Msg:  msgInvalidPartial,
+			}
+		}
+	}
+
 	bucket := qp.Get("bucket")
 	if bucket == "" {
 		return nil, &errors.Error{
@@ -281,7 +393,9 @@ func decodeWriteRequest(ctx context.Context, r *http.Request, maxBatchSizeBytes
 		Bucket:    qp.Get("bucket"),
 		Org:       qp.Get("org"),
 		Precision: precision,
+		Format:    points.FormatFromContentType(r.Header.Get("Content-Type")),
 		Body:      body,
+		Partial:   partial,
 	}, nil
 }
 
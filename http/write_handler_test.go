@@ -19,6 +19,7 @@ import (
 	httpmock "github.com/influxdata/influxdb/v2/http/mock"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
 	influxtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/stretchr/testify/require"
@@ -146,12 +147,13 @@ func TestWriteService_WriteTo(t *testing.T) {
 func TestWriteHandler_handleWrite(t *testing.T) {
 	// state is the internal state of org and bucket services
 	type state struct {
-		org       *influxdb.Organization // org to return in org service
-		orgErr    error                  // err to return in org service
-		bucket    *influxdb.Bucket       // bucket to return in bucket service
-		bucketErr error                  // err to return in bucket service
-		writeErr  error                  // err to return from the points writer
-		opts      []WriteHandlerOption   // write handle configured options
+		org       *influxdb.Organization     // org to return in org service
+		orgErr    error                      // err to return in org service
+		bucket    *influxdb.Bucket           // bucket to return in bucket service
+		bucketErr error                      // err to return in bucket service
+		writeErr  error                      // err to return from the points writer
+		writeFn   func([]models.Point) error // replaces writeErr when set
+		opts      []WriteHandlerOption       // write handle configured options
 	}
 
 	// want is the expected output of the HTTP endpoint
@@ -162,10 +164,12 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 
 	// request is sent to the HTTP endpoint
 	type request struct {
-		auth   influxdb.Authorizer
-		org    string
-		bucket string
-		body   string
+		auth        influxdb.Authorizer
+		org         string
+		bucket      string
+		body        string
+		contentType string
+		partial     string
 	}
 
 	tests := []struct {
@@ -376,6 +380,173 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"request too large","message":"unable to read data: points batch is too large"}`,
 			},
 		},
+		{
+			name: "partial write of valid body is accepted",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1,t1=v1 f1=1\nm1,t1=v1 f1=2",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: "true",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "partial write reports invalid lines",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1,t1=v1 f1=1\ninvalid\nm1,t1=v1 f1=2",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: "true",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 200,
+				body: `{"written":2,"rejected":[{"line":2,"reason":"unable to parse 'invalid': missing fields"}]}` + "\n",
+			},
+		},
+		{
+			name: "partial write reports dropped points",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1,t1=v1 f1=1\nm1,t1=v1 f1=\"one\"\nm1,t1=v1 f1=2",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: "true",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeFn: func(points []models.Point) error {
+					return tsdb.PartialWriteError{
+						Reason:        "field type conflict",
+						Dropped:       2,
+						DroppedPoints: []tsdb.DroppedPoint{{Point: points[1], Reason: "field type conflict"}},
+					}
+				},
+			},
+			wants: wants{
+				code: 200,
+				body: `{"written":1,"rejected":[{"line":2,"reason":"field type conflict"},{"reason":"field type conflict dropped=1"}]}` + "\n",
+			},
+		},
+		{
+			name: "partial write without any valid line returns 400",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "invalid",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: "true",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"no points were written","written":0,"rejected":[{"line":1,"reason":"unable to parse 'invalid': missing fields"}]}` + "\n",
+			},
+		},
+		{
+			name: "invalid partial parameter returns 400",
+			request: request{
+				org:     "043e0780ee2b1000",
+				bucket:  "04504b356e23b000",
+				body:    "m1,t1=v1 f1=1",
+				auth:    bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial: "sometimes",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"invalid partial; valid values are true and false"}`,
+			},
+		},
+		{
+			name: "json body is accepted",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        `[{"measurement": "m1", "tags": {"t1": "v1"}, "fields": {"f1": 1}}]`,
+				contentType: "application/json",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "csv body is accepted",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        "#datatype measurement,tag,double\nm,t1,f1\nm1,v1,1\n",
+				contentType: "text/csv",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "invalid json body returns 400",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        `[{"fields": {"f1": 1}}]`,
+				contentType: "application/json",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"unable to parse point 1: missing measurement"}`,
+			},
+		},
+		{
+			name: "partial write of json body returns 400",
+			request: request{
+				org:         "043e0780ee2b1000",
+				bucket:      "04504b356e23b000",
+				body:        `[{"measurement": "m1", "fields": {"f1": 1}}]`,
+				contentType: "application/json",
+				auth:        bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+				partial:     "true",
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 400,
+				body: `{"code":"invalid","message":"partial writes are not supported for json"}`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -396,6 +567,13 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				PointsWriter:        &mock.PointsWriter{Err: tt.state.writeErr},
 				WriteEventRecorder:  &metric.NopEventRecorder{},
 			}
+			if tt.state.writeFn != nil {
+				b.PointsWriter = &mock.PointsWriter{
+					WritePointsFn: func(_ context.Context, _, _ platform.ID, points []models.Point) error {
+						return tt.state.writeFn(points)
+					},
+				}
+			}
 			writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b), tt.state.opts...)
 			handler := httpmock.NewAuthMiddlewareHandler(writeHandler, tt.request.auth)
 
@@ -404,10 +582,16 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				"http://localhost:8086/api/v2/write",
 				strings.NewReader(tt.request.body),
 			)
+			if tt.request.contentType != "" {
+				r.Header.Set("Content-Type", tt.request.contentType)
+			}
 
 			params := r.URL.Query()
 			params.Set("org", tt.request.org)
 			params.Set("bucket", tt.request.bucket)
+			if tt.request.partial != "" {
+				params.Set("partial", tt.request.partial)
+			}
 			r.URL.RawQuery = params.Encode()
 
 			w := httptest.NewRecorder()
@@ -9,7 +9,9 @@ import (
 	"strconv"
 	"time"
 
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/query/registry"
 
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/control"
@@ -173,6 +175,10 @@ type Executor struct {
 
 	Metrics *control.ControllerMetrics
 
+	// Registry, if set, records running queries so they can be listed
+	// and killed.
+	Registry *registry.Registry
+
 	log *zap.Logger
 }
 
@@ -194,10 +200,39 @@ func (e *Executor) Close() error {
 func (e *Executor) ExecuteQuery(ctx context.Context, query *influxql.Query, opt ExecutionOptions) (<-chan *Result, *iql.Statistics) {
 	results := make(chan *Result)
 	statistics := new(iql.Statistics)
-	go e.executeQuery(ctx, query, opt, results, statistics)
+	if e.Registry == nil {
+		go e.executeQuery(ctx, query, opt, results, statistics)
+		return results, statistics
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	info := registry.QueryInfo{
+		Language: registry.LanguageInfluxQL,
+		OrgID:    opt.OrgID,
+		Query:    query.String(),
+		Database: opt.Database,
+	}
+	if userID, err := icontext.GetUserID(ctx); err == nil {
+		info.UserID = userID
+	}
+	_, unregister := e.Registry.Register(info, runningQuery(cancel))
+	go func() {
+		defer cancel()
+		defer unregister()
+		e.executeQuery(ctx, query, opt, results, statistics)
+	}()
 	return results, statistics
 }
 
+// runningQuery adapts the cancellation of an InfluxQL query to the
+// registry.RunningQuery interface. Memory use is not tracked for InfluxQL
+// queries.
+type runningQuery context.CancelFunc
+
+func (q runningQuery) Cancel()            { q() }
+func (q runningQuery) MemoryBytes() int64 { return 0 }
+func (q runningQuery) Status() string     { return "running" }
+
 func (e *Executor) executeQuery(ctx context.Context, query *influxql.Query, opt ExecutionOptions, results chan *Result, statistics *iql.Statistics) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer func() {
@@ -0,0 +1,12 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var continuousQueryBucket = []byte("continuousqueriesv1")
+
+// Migration0016_AddContinuousQueryBuckets creates the buckets necessary for the
+// continuous query service to operate.
+var Migration0016_AddContinuousQueryBuckets = migration.CreateBuckets(
+	"create continuous query buckets",
+	continuousQueryBucket,
+)
@@ -0,0 +1,16 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	measurementSchemaBucket = []byte("measurementschemasv1")
+	measurementSchemaIndex  = []byte("measurementschemaindexv1")
+)
+
+// Migration0017_AddMeasurementSchemaBuckets creates the buckets necessary for
+// buckets with explicit measurement schemas.
+var Migration0017_AddMeasurementSchemaBuckets = migration.CreateBuckets(
+	"create measurement schema buckets",
+	measurementSchemaBucket,
+	measurementSchemaIndex,
+)
@@ -0,0 +1,16 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var (
+	backupJobBucket    = []byte("backupjobsv1")
+	backupJobRunBucket = []byte("backupjobrunsv1")
+)
+
+// Migration0018_AddBackupJobBuckets creates the buckets necessary for
+// scheduled backup jobs and their run history.
+var Migration0018_AddBackupJobBuckets = migration.CreateBuckets(
+	"create backup job buckets",
+	backupJobBucket,
+	backupJobRunBucket,
+)
@@ -0,0 +1,12 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var silenceBucket = []byte("silencesv1")
+
+// Migration0019_AddSilenceBucket creates the bucket necessary for
+// notification silences.
+var Migration0019_AddSilenceBucket = migration.CreateBuckets(
+	"create silence bucket",
+	silenceBucket,
+)
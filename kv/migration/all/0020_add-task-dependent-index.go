@@ -0,0 +1,12 @@
+package all
+
+import "github.com/influxdata/influxdb/v2/kv/migration"
+
+var taskDependentIndexBucket = []byte("taskDependentIndexv1")
+
+// Migration0020_AddTaskDependentIndex creates the bucket indexing the tasks
+// depending on each task.
+var Migration0020_AddTaskDependentIndex = migration.CreateBuckets(
+	"add task dependent index",
+	taskDependentIndexBucket,
+)
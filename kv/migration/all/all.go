@@ -37,5 +37,15 @@ var Migrations = [...]migration.Spec{
 	Migration0014_ReindexDBRPs,
 	// record shard group durations in bucket metadata
 	Migration0015_RecordShardGroupDurationsInBucketMetadata,
+	// add continuous query buckets
+	Migration0016_AddContinuousQueryBuckets,
+	// add measurement schema buckets
+	Migration0017_AddMeasurementSchemaBuckets,
+	// add backup job buckets
+	Migration0018_AddBackupJobBuckets,
+	// add silence bucket
+	Migration0019_AddSilenceBucket,
+	// add task dependent index
+	Migration0020_AddTaskDependentIndex,
 	// {{ do_not_edit . }}
 }
@@ -25,6 +25,8 @@ import (
 //   <taskID>/latestCompleted: run data for the latest completed run of a task
 // taskIndexBucket
 //   <orgID>/<taskID>: index for tasks by org
+// taskDependentIndexBucket
+//   <taskID>/<dependentID>: index for the tasks depending on a task
 
 // We may want to add a <taskName>/<taskID> index to allow us to look up tasks by task name.
 
@@ -32,6 +34,8 @@ var (
 	taskBucket      = []byte("tasksv1")
 	taskRunBucket   = []byte("taskRunsv1")
 	taskIndexBucket = []byte("taskIndexsv1")
+
+	taskDependentIndexBucket = []byte("taskDependentIndexv1")
 )
 
 var _ taskmodel.TaskService = (*Service)(nil)
@@ -48,6 +52,8 @@ type kvTask struct {
 	Flux            string                 `json:"flux"`
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
+	Retry           int64                  `json:"retry,omitempty"`
+	DependsOn       []platform.ID          `json:"dependsOn,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
 	Offset          influxdb.Duration      `json:"offset,omitempty"`
@@ -73,6 +79,8 @@ func kvToInfluxTask(k *kvTask) *taskmodel.Task {
 		Flux:            k.Flux,
 		Every:           k.Every,
 		Cron:            k.Cron,
+		Retry:           k.Retry,
+		DependsOn:       k.DependsOn,
 		LastRunStatus:   k.LastRunStatus,
 		LastRunError:    k.LastRunError,
 		Offset:          k.Offset.Duration,
@@ -508,6 +516,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 		Flux:            tc.Flux,
 		Every:           opts.Every.String(),
 		Cron:            opts.Cron,
+		Retry:           *opts.Retry,
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
@@ -522,6 +531,13 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 
 	}
 
+	if task.DependsOn, err = taskDependencies(opts.DependsOn); err != nil {
+		return nil, err
+	}
+	if err := s.validateDependencies(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
 		return nil, taskmodel.ErrUnexpectedTaskBucketErr(err)
@@ -559,6 +575,11 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 		return nil, taskmodel.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// write the dependent index
+	if err := s.indexTaskDependencies(tx, task.ID, nil, task.DependsOn); err != nil {
+		return nil, err
+	}
+
 	uid, _ := icontext.GetUserID(ctx)
 	if err := s.audit.Log(resource.Change{
 		Type:           resource.Create,
@@ -575,6 +596,178 @@ func (s *Service) createTask(ctx context.Context, tx Tx, org *influxdb.Organizat
 	return task, nil
 }
 
+// taskDependencies converts the dependsOn task option into task IDs.
+func taskDependencies(dependsOn []string) ([]platform.ID, error) {
+	var ids []platform.ID
+	for _, d := range dependsOn {
+		id, err := platform.IDFromString(d)
+		if err != nil {
+			return nil, taskmodel.ErrTaskOptionParse(err)
+		}
+		ids = append(ids, *id)
+	}
+	return ids, nil
+}
+
+// indexTaskDependencies replaces the dependencies from of the task id with
+// the dependencies to in the dependent index.
+func (s *Service) indexTaskDependencies(tx Tx, id platform.ID, from, to []platform.ID) error {
+	b, err := tx.Bucket(taskDependentIndexBucket)
+	if err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	for _, dep := range from {
+		key, err := taskDependentKey(dep, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(key); err != nil {
+			return taskmodel.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+
+	value, err := taskKey(id)
+	if err != nil {
+		return err
+	}
+	for _, dep := range to {
+		key, err := taskDependentKey(dep, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, value); err != nil {
+			return taskmodel.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
+// deleteDependentIndex removes the tasks depending on the task id from the
+// dependent index.
+func (s *Service) deleteDependentIndex(tx Tx, id platform.ID) error {
+	b, err := tx.Bucket(taskDependentIndexBucket)
+	if err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	prefix, err := taskDependentPrefix(id)
+	if err != nil {
+		return err
+	}
+
+	c, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+	var keys [][]byte
+	for k, _ := c.Next(); k != nil; k, _ = c.Next() {
+		keys = append(keys, k)
+	}
+	if err := c.Err(); err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+	if err := c.Close(); err != nil {
+		return taskmodel.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return taskmodel.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
+// FindDependentTasks returns the tasks which depend on the task id.
+func (s *Service) FindDependentTasks(ctx context.Context, id platform.ID) ([]*taskmodel.Task, error) {
+	var ts []*taskmodel.Task
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(taskDependentIndexBucket)
+		if err != nil {
+			return taskmodel.ErrUnexpectedTaskBucketErr(err)
+		}
+
+		prefix, err := taskDependentPrefix(id)
+		if err != nil {
+			return err
+		}
+
+		c, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+		if err != nil {
+			return taskmodel.ErrUnexpectedTaskBucketErr(err)
+		}
+		defer c.Close()
+
+		for k, v := c.Next(); k != nil; k, v = c.Next() {
+			depID, err := platform.IDFromString(string(v))
+			if err != nil {
+				return taskmodel.ErrInvalidTaskID
+			}
+
+			t, err := s.findTaskByID(ctx, tx, *depID)
+			if err != nil {
+				if err == taskmodel.ErrTaskNotFound {
+					continue
+				}
+				return err
+			}
+			ts = append(ts, t)
+		}
+		return c.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// validateDependencies ensures the tasks a task depends on exist in the same
+// organization, and that following the dependencies never leads back to the
+// task itself.
+func (s *Service) validateDependencies(ctx context.Context, tx Tx, task *taskmodel.Task) error {
+	for _, id := range task.DependsOn {
+		if id == task.ID {
+			return taskmodel.ErrTaskDependencyCycle
+		}
+		dep, err := s.findTaskByID(ctx, tx, id)
+		if err == taskmodel.ErrTaskNotFound {
+			return taskmodel.ErrInvalidTaskDependency(id, "task not found")
+		}
+		if err != nil {
+			return err
+		}
+		if dep.OrganizationID != task.OrganizationID {
+			return taskmodel.ErrInvalidTaskDependency(id, "task belongs to another organization")
+		}
+	}
+
+	visited := map[platform.ID]bool{}
+	pending := append([]platform.ID(nil), task.DependsOn...)
+	for len(pending) > 0 {
+		id := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if id == task.ID {
+			return taskmodel.ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		dep, err := s.findTaskByID(ctx, tx, id)
+		if err == taskmodel.ErrTaskNotFound {
+			// Tasks deleted since they were depended on cannot be part of a cycle.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		pending = append(pending, dep.DependsOn...)
+	}
+	return nil
+}
+
 // UpdateTask updates a single task with changeset.
 func (s *Service) UpdateTask(ctx context.Context, id platform.ID, upd taskmodel.TaskUpdate) (*taskmodel.Task, error) {
 	var t *taskmodel.Task
@@ -616,6 +809,7 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 		task.Name = opts.Name
 		task.Every = opts.Every.String()
 		task.Cron = opts.Cron
+		task.Retry = *opts.Retry
 
 		var off time.Duration
 		if opts.Offset != nil {
@@ -625,6 +819,17 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id platform.ID, upd tas
 			}
 		}
 		task.Offset = off
+
+		dependsOn := task.DependsOn
+		if task.DependsOn, err = taskDependencies(opts.DependsOn); err != nil {
+			return nil, err
+		}
+		if err := s.validateDependencies(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		if err := s.indexTaskDependencies(tx, task.ID, dependsOn, task.DependsOn); err != nil {
+			return nil, err
+		}
 		task.UpdatedAt = updatedAt
 	}
 
@@ -780,6 +985,14 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id platform.ID) error {
 		return taskmodel.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// remove the dependent index of the task and of the tasks depending on it
+	if err := s.indexTaskDependencies(tx, task.ID, task.DependsOn, nil); err != nil {
+		return err
+	}
+	if err := s.deleteDependentIndex(tx, task.ID); err != nil {
+		return err
+	}
+
 	// remove latest completed
 	lastCompletedKey, err := taskLatestCompletedKey(task.ID)
 	if err != nil {
@@ -1172,7 +1385,7 @@ func (s *Service) forceRun(ctx context.Context, tx Tx, taskID platform.ID, sched
 func (s *Service) CreateRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
 	var r *taskmodel.Run
 	err := s.kv.Update(ctx, func(tx Tx) error {
-		run, err := s.createRun(ctx, tx, taskID, scheduledFor, runAt)
+		run, err := s.createRun(ctx, tx, taskID, scheduledFor, runAt, 0)
 		if err != nil {
 			return err
 		}
@@ -1181,7 +1394,22 @@ func (s *Service) CreateRun(ctx context.Context, taskID platform.ID, scheduledFo
 	})
 	return r, err
 }
-func (s *Service) createRun(ctx context.Context, tx Tx, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
+
+// CreateRetryRun creates a run retrying a failed run with the same scheduled for time.
+func (s *Service) CreateRetryRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error) {
+	var r *taskmodel.Run
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		run, err := s.createRun(ctx, tx, taskID, scheduledFor, runAt, retry)
+		if err != nil {
+			return err
+		}
+		r = run
+		return nil
+	})
+	return r, err
+}
+
+func (s *Service) createRun(ctx context.Context, tx Tx, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error) {
 	id := s.IDGenerator.ID()
 	t := time.Unix(scheduledFor.Unix(), 0).UTC()
 
@@ -1192,6 +1420,7 @@ func (s *Service) createRun(ctx context.Context, tx Tx, taskID platform.ID, sche
 		RunAt:        runAt,
 		Status:       taskmodel.RunScheduled.String(),
 		Log:          []taskmodel.Log{},
+		Retry:        retry,
 	}
 
 	b, err := tx.Bucket(taskRunBucket)
@@ -1588,6 +1817,28 @@ func taskOrgKey(orgID, taskID platform.ID) ([]byte, error) {
 	return []byte(string(encodedOrgID) + "/" + string(encodedID)), nil
 }
 
+func taskDependentPrefix(taskID platform.ID) ([]byte, error) {
+	encodedID, err := taskID.Encode()
+	if err != nil {
+		return nil, taskmodel.ErrInvalidTaskID
+	}
+
+	return []byte(string(encodedID) + "/"), nil
+}
+
+func taskDependentKey(taskID, dependentID platform.ID) ([]byte, error) {
+	prefix, err := taskDependentPrefix(taskID)
+	if err != nil {
+		return nil, err
+	}
+	encodedDependentID, err := dependentID.Encode()
+	if err != nil {
+		return nil, taskmodel.ErrInvalidTaskID
+	}
+
+	return append(prefix, encodedDependentID...), nil
+}
+
 func taskRunKey(taskID, runID platform.ID) ([]byte, error) {
 	encodedID, err := taskID.Encode()
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	icontext "github.com/influxdata/influxdb/v2/context"
 	_ "github.com/influxdata/influxdb/v2/fluxinit/static"
 	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 	"github.com/influxdata/influxdb/v2/kv"
 	"github.com/influxdata/influxdb/v2/query/fluxlang"
 	"github.com/influxdata/influxdb/v2/task/options"
@@ -266,6 +269,82 @@ func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	}
 }
 
+func TestService_TaskDependencies(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	create := func(name string, dependsOn ...platform.ID) (*taskmodel.Task, error) {
+		return ts.Service.CreateTask(ctx, taskmodel.TaskCreate{
+			Flux:           dependentFlux(name, dependsOn...),
+			OrganizationID: ts.Org.ID,
+			OwnerID:        ts.User.ID,
+		})
+	}
+
+	a, err := create("a")
+	require.NoError(t, err)
+	b, err := create("b", a.ID)
+	require.NoError(t, err)
+	require.Equal(t, []platform.ID{a.ID}, b.DependsOn)
+
+	found, err := ts.Service.FindTaskByID(ctx, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, []platform.ID{a.ID}, found.DependsOn)
+
+	// dependencies must exist
+	_, err = create("c", platform.ID(1))
+	require.Error(t, err)
+	require.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+
+	// a -> b -> a is a cycle
+	flux := dependentFlux("a", b.ID)
+	_, err = ts.Service.UpdateTask(ctx, a.ID, taskmodel.TaskUpdate{Flux: &flux})
+	require.Equal(t, taskmodel.ErrTaskDependencyCycle, err)
+
+	// a -> a is a cycle
+	flux = dependentFlux("a", a.ID)
+	_, err = ts.Service.UpdateTask(ctx, a.ID, taskmodel.TaskUpdate{Flux: &flux})
+	require.Equal(t, taskmodel.ErrTaskDependencyCycle, err)
+
+	// dependents are indexed by the tasks they depend on
+	dependentIDs := func(id platform.ID) []platform.ID {
+		dependents, err := ts.Service.FindDependentTasks(ctx, id)
+		require.NoError(t, err)
+		var ids []platform.ID
+		for _, d := range dependents {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+	c, err := create("c", a.ID, b.ID)
+	require.NoError(t, err)
+	require.Equal(t, []platform.ID{b.ID, c.ID}, dependentIDs(a.ID))
+	require.Equal(t, []platform.ID{c.ID}, dependentIDs(b.ID))
+
+	flux = dependentFlux("c", b.ID)
+	_, err = ts.Service.UpdateTask(ctx, c.ID, taskmodel.TaskUpdate{Flux: &flux})
+	require.NoError(t, err)
+	require.Equal(t, []platform.ID{b.ID}, dependentIDs(a.ID))
+	require.Equal(t, []platform.ID{c.ID}, dependentIDs(b.ID))
+
+	require.NoError(t, ts.Service.DeleteTask(ctx, b.ID))
+	require.Empty(t, dependentIDs(a.ID))
+	require.Empty(t, dependentIDs(b.ID))
+}
+
+func dependentFlux(name string, dependsOn ...platform.ID) string {
+	var deps []string
+	for _, id := range dependsOn {
+		deps = append(deps, fmt.Sprintf("%q", id))
+	}
+	return fmt.Sprintf(`option task = {name: %q, every: 1h, dependsOn: [%s]} from(bucket:"test") |> range(start:-1h)`, name, strings.Join(deps, ", "))
+}
+
 func TestTaskRunCancellation(t *testing.T) {
 	store, close, err := NewTestBoltStore(t)
 	if err != nil {
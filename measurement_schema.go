@@ -0,0 +1,231 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// SchemaType determines which data a bucket accepts.
+type SchemaType string
+
+const (
+	// SchemaTypeImplicit buckets accept any measurement, tag and field. It
+	// is the schema type of buckets which do not specify one.
+	SchemaTypeImplicit SchemaType = "implicit"
+	// SchemaTypeExplicit buckets only accept data described by one of their
+	// measurement schemas.
+	SchemaTypeExplicit SchemaType = "explicit"
+)
+
+// Valid returns an error if the schema type is unknown. The empty schema
+// type is valid and means implicit.
+func (t SchemaType) Valid() error {
+	switch t {
+	case "", SchemaTypeImplicit, SchemaTypeExplicit:
+		return nil
+	default:
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("unknown schema type %q; must be %q or %q", t, SchemaTypeImplicit, SchemaTypeExplicit),
+		}
+	}
+}
+
+// SemanticColumnType is the role of a column in a measurement schema.
+type SemanticColumnType string
+
+const (
+	SemanticColumnTypeTimestamp SemanticColumnType = "timestamp"
+	SemanticColumnTypeTag       SemanticColumnType = "tag"
+	SemanticColumnTypeField     SemanticColumnType = "field"
+)
+
+// SchemaColumnDataType is the data type of a field column.
+type SchemaColumnDataType string
+
+const (
+	SchemaColumnDataTypeFloat    SchemaColumnDataType = "float"
+	SchemaColumnDataTypeInteger  SchemaColumnDataType = "integer"
+	SchemaColumnDataTypeUnsigned SchemaColumnDataType = "unsigned"
+	SchemaColumnDataTypeString   SchemaColumnDataType = "string"
+	SchemaColumnDataTypeBoolean  SchemaColumnDataType = "boolean"
+)
+
+// Ptr returns a pointer to the data type.
+func (t SchemaColumnDataType) Ptr() *SchemaColumnDataType {
+	return &t
+}
+
+func (t SchemaColumnDataType) valid() bool {
+	switch t {
+	case SchemaColumnDataTypeFloat, SchemaColumnDataTypeInteger, SchemaColumnDataTypeUnsigned,
+		SchemaColumnDataTypeString, SchemaColumnDataTypeBoolean:
+		return true
+	default:
+		return false
+	}
+}
+
+// MeasurementSchemaColumn is a column of a measurement schema.
+type MeasurementSchemaColumn struct {
+	Name     string                `json:"name"`
+	Type     SemanticColumnType    `json:"type"`
+	DataType *SchemaColumnDataType `json:"dataType,omitempty"`
+}
+
+func (c MeasurementSchemaColumn) equal(o MeasurementSchemaColumn) bool {
+	if c.Name != o.Name || c.Type != o.Type {
+		return false
+	}
+	if c.DataType == nil || o.DataType == nil {
+		return c.DataType == o.DataType
+	}
+	return *c.DataType == *o.DataType
+}
+
+// MeasurementSchema describes the columns of a measurement in a bucket with
+// an explicit schema.
+type MeasurementSchema struct {
+	ID       platform.ID               `json:"id,omitempty"`
+	OrgID    platform.ID               `json:"orgID"`
+	BucketID platform.ID               `json:"bucketID"`
+	Name     string                    `json:"name"`
+	Columns  []MeasurementSchemaColumn `json:"columns"`
+	CRUDLog
+}
+
+// Validate ensures that the measurement schema has a name, a single time
+// column, at least one field and well-formed, unique columns.
+func (m *MeasurementSchema) Validate() error {
+	if m.Name == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "measurement schema name is required",
+		}
+	}
+	return ValidateMeasurementSchemaColumns(m.Columns)
+}
+
+// ValidateMeasurementSchemaColumns ensures that there is a single timestamp
+// column named time, at least one field column and that every column is
+// well-formed and has a unique name.
+func ValidateMeasurementSchemaColumns(columns []MeasurementSchemaColumn) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf(format, args...),
+		}
+	}
+
+	var timestamps, fields int
+	names := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if c.Name == "" {
+			return invalid("column name is required")
+		}
+		if _, ok := names[c.Name]; ok {
+			return invalid("duplicate column name %q", c.Name)
+		}
+		names[c.Name] = struct{}{}
+
+		switch c.Type {
+		case SemanticColumnTypeTimestamp:
+			timestamps++
+			if c.Name != "time" {
+				return invalid("timestamp column must be named \"time\", got %q", c.Name)
+			}
+			if c.DataType != nil {
+				return invalid("timestamp column %q cannot have a data type", c.Name)
+			}
+		case SemanticColumnTypeTag:
+			if c.DataType != nil {
+				return invalid("tag column %q cannot have a data type", c.Name)
+			}
+		case SemanticColumnTypeField:
+			fields++
+			if c.DataType == nil {
+				return invalid("field column %q requires a data type", c.Name)
+			}
+			if !c.DataType.valid() {
+				return invalid("field column %q has unknown data type %q", c.Name, *c.DataType)
+			}
+		default:
+			return invalid("column %q has unknown type %q", c.Name, c.Type)
+		}
+	}
+
+	if timestamps != 1 {
+		return invalid("measurement schema requires exactly one timestamp column")
+	}
+	if fields == 0 {
+		return invalid("measurement schema requires at least one field column")
+	}
+	return nil
+}
+
+// ValidateMeasurementSchemaUpdate ensures that columns are a valid extension
+// of the existing columns. Columns can be added but not changed or removed,
+// because data may already have been written with them.
+func ValidateMeasurementSchemaUpdate(existing, columns []MeasurementSchemaColumn) error {
+	if err := ValidateMeasurementSchemaColumns(columns); err != nil {
+		return err
+	}
+
+	byName := make(map[string]MeasurementSchemaColumn, len(columns))
+	for _, c := range columns {
+		byName[c.Name] = c
+	}
+	for _, c := range existing {
+		n, ok := byName[c.Name]
+		if !ok {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("column %q cannot be removed from the measurement schema", c.Name),
+			}
+		}
+		if !n.equal(c) {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("column %q of the measurement schema cannot be changed", c.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// ops for measurement schema errors.
+var (
+	OpFindMeasurementSchemaByID = "FindMeasurementSchemaByID"
+	OpFindMeasurementSchemas    = "FindMeasurementSchemas"
+	OpCreateMeasurementSchema   = "CreateMeasurementSchema"
+	OpUpdateMeasurementSchema   = "UpdateMeasurementSchema"
+)
+
+// MeasurementSchemaFilter selects the measurement schemas of a bucket.
+type MeasurementSchemaFilter struct {
+	BucketID platform.ID
+	Name     *string
+}
+
+// MeasurementSchemaService manages the measurement schemas of buckets with an
+// explicit schema.
+type MeasurementSchemaService interface {
+	// FindMeasurementSchemaByID returns a single measurement schema of a
+	// bucket by ID.
+	FindMeasurementSchemaByID(ctx context.Context, bucketID, id platform.ID) (*MeasurementSchema, error)
+
+	// FindMeasurementSchemas returns the measurement schemas of a bucket,
+	// ordered by name.
+	FindMeasurementSchemas(ctx context.Context, filter MeasurementSchemaFilter) ([]*MeasurementSchema, error)
+
+	// CreateMeasurementSchema creates a measurement schema and sets m.ID with
+	// the new identifier.
+	CreateMeasurementSchema(ctx context.Context, m *MeasurementSchema) error
+
+	// UpdateMeasurementSchema replaces the columns of a measurement schema of
+	// a bucket. Existing columns cannot be changed or removed.
+	UpdateMeasurementSchema(ctx context.Context, bucketID, id platform.ID, columns []MeasurementSchemaColumn) (*MeasurementSchema, error)
+}
@@ -131,18 +131,23 @@ func (s *TaskService) ForceRun(ctx context.Context, taskID platform.ID, schedule
 }
 
 type TaskControlService struct {
-	CreateRunFn        func(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error)
-	CurrentlyRunningFn func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
-	ManualRunsFn       func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
-	StartManualRunFn   func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
-	FinishRunFn        func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
-	UpdateRunStateFn   func(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error
-	AddRunLogFn        func(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
+	CreateRunFn          func(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error)
+	CreateRetryRunFn     func(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error)
+	CurrentlyRunningFn   func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
+	ManualRunsFn         func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
+	StartManualRunFn     func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
+	FinishRunFn          func(ctx context.Context, taskID, runID platform.ID) (*taskmodel.Run, error)
+	UpdateRunStateFn     func(ctx context.Context, taskID, runID platform.ID, when time.Time, state taskmodel.RunStatus) error
+	AddRunLogFn          func(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
+	FindDependentTasksFn func(ctx context.Context, taskID platform.ID) ([]*taskmodel.Task, error)
 }
 
 func (tcs *TaskControlService) CreateRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
 	return tcs.CreateRunFn(ctx, taskID, scheduledFor, runAt)
 }
+func (tcs *TaskControlService) CreateRetryRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error) {
+	return tcs.CreateRetryRunFn(ctx, taskID, scheduledFor, runAt, retry)
+}
 func (tcs *TaskControlService) CurrentlyRunning(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error) {
 	return tcs.CurrentlyRunningFn(ctx, taskID)
 }
@@ -161,3 +166,6 @@ func (tcs *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID
 func (tcs *TaskControlService) AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error {
 	return tcs.AddRunLogFn(ctx, taskID, runID, when, log)
 }
+func (tcs *TaskControlService) FindDependentTasks(ctx context.Context, taskID platform.ID) ([]*taskmodel.Task, error) {
+	return tcs.FindDependentTasksFn(ctx, taskID)
+}
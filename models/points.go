@@ -357,20 +357,85 @@ func ValidPrecision(precision string) bool {
 // This can have the unintended effect preventing buf from being garbage collected.
 func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision string) ([]Point, error) {
 	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
+	var failed []string
+	scanLines(buf, func(_ int, block []byte) {
+		pt, err := parsePoint(block, defaultTime, precision)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block), err))
+		} else {
+			points = append(points, pt)
+		}
+	})
+	if len(failed) > 0 {
+		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
+	}
+	return points, nil
+
+}
+
+// LineError is an error parsing a line of line protocol.
+type LineError struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ParsePointsWithLines is similar to ParsePointsWithPrecision, but it also
+// returns the line number of each point, and an error for each line which
+// could not be parsed rather than a single error.
+func ParsePointsWithLines(buf []byte, defaultTime time.Time, precision string) ([]Point, []int, []*LineError) {
+	points := make([]Point, 0, bytes.Count(buf, []byte{'\n'})+1)
+	lines := make([]int, 0, cap(points))
+	var failed []*LineError
+	scanLines(buf, func(line int, block []byte) {
+		pt, err := parsePoint(block, defaultTime, precision)
+		if err != nil {
+			failed = append(failed, &LineError{
+				Line: line,
+				Err:  fmt.Errorf("unable to parse '%s': %v", string(block), err),
+			})
+		} else {
+			points = append(points, pt)
+			lines = append(lines, line)
+		}
+	})
+	return points, lines, failed
+}
+
+// scanLines calls fn with every line of buf which isn't empty or a comment,
+// along with its line number. Leading whitespace and the trailing newline
+// are stripped.
+func scanLines(buf []byte, fn func(line int, block []byte)) {
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		pos   int
+		block []byte
+		line  = 1
 	)
 	for pos < len(buf) {
+		start := pos
 		pos, block = scanLine(buf, pos)
 		pos++
 
+		blockLine := line
+		if pos <= len(buf) {
+			line += bytes.Count(buf[start:pos], []byte{'\n'})
+		} else {
+			line += bytes.Count(buf[start:], []byte{'\n'})
+		}
+
 		if len(block) == 0 {
 			continue
 		}
 
-		start := skipWhitespace(block, 0)
+		start = skipWhitespace(block, 0)
 
 		// If line is all whitespace, just skip it
 		if start >= len(block) {
@@ -387,19 +452,8 @@ func ParsePointsWithPrecision(buf []byte, defaultTime time.Time, precision strin
 			block = block[:len(block)-1]
 		}
 
-		pt, err := parsePoint(block[start:], defaultTime, precision)
-		if err != nil {
-			failed = append(failed, fmt.Sprintf("unable to parse '%s': %v", string(block[start:]), err))
-		} else {
-			points = append(points, pt)
-		}
-
+		fn(blockLine, block[start:])
 	}
-	if len(failed) > 0 {
-		return points, fmt.Errorf("%s", strings.Join(failed, "\n"))
-	}
-	return points, nil
-
 }
 
 func parsePoint(buf []byte, defaultTime time.Time, precision string) (Point, error) {
@@ -1841,6 +1841,38 @@ func TestParsePointsWithPrecision(t *testing.T) {
 	}
 }
 
+func TestParsePointsWithLines(t *testing.T) {
+	buf := "# comment\n" +
+		"cpu value=1 1\n" +
+		"\n" +
+		"cpu value=\n" +
+		"cpu,host=a value=\"multi\nline\" 2\n" +
+		"   \n" +
+		"invalid\n" +
+		"mem value=3 3"
+
+	pts, lines, errs := models.ParsePointsWithLines([]byte(buf), time.Now().UTC(), "ns")
+	if got, exp := lines, []int{2, 5, 9}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected lines: got %v, exp %v", got, exp)
+	}
+	if len(pts) != len(lines) {
+		t.Fatalf("unexpected points: got %d, exp %d", len(pts), len(lines))
+	}
+	if got, exp := pts[2].String(), "mem value=3 3"; got != exp {
+		t.Errorf("unexpected point: got %q, exp %q", got, exp)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if errs[0].Line != 4 || errs[1].Line != 8 {
+		t.Errorf("unexpected error lines: got %d and %d, exp 4 and 8", errs[0].Line, errs[1].Line)
+	}
+	if got, exp := errs[1].Error(), "line 8: unable to parse 'invalid': missing fields"; got != exp {
+		t.Errorf("unexpected error: got %q, exp %q", got, exp)
+	}
+}
+
 func TestParsePointsWithPrecisionNoTime(t *testing.T) {
 	line := `cpu,host=serverA,region=us-east value=1.0`
 	tm, _ := time.Parse(time.RFC3339Nano, "2000-01-01T12:34:56.789012345Z")
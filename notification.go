@@ -34,6 +34,7 @@ type NotificationRule interface {
 	GetLimit() *Limit
 	GenerateFlux(NotificationEndpoint) (string, error)
 	MatchesTags(tags []Tag) bool
+	SetSilences(silences []Silence)
 }
 
 // NotificationRuleStore represents a service for managing notification rule.
@@ -0,0 +1,373 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+)
+
+var _ influxdb.Check = (*Anomaly)(nil)
+
+// Anomaly detection methods.
+const (
+	// AnomalyMethodStdDev compares the latest values against the baseline
+	// right before them.
+	AnomalyMethodStdDev = "stddev"
+	// AnomalyMethodSeasonal compares the latest values against the baseline
+	// one season ago, i.e. the same window last week.
+	AnomalyMethodSeasonal = "seasonal"
+)
+
+// defaultSeason is the season of seasonal anomaly checks that don't set one.
+var defaultSeason = notification.Duration{Values: []ast.Duration{{Magnitude: 1, Unit: "w"}}}
+
+// Anomaly is the anomaly detection check. It flags the latest values of a
+// series when they are more than a number of standard deviations away from
+// the mean of a baseline window of the same series.
+type Anomaly struct {
+	Base
+	Method string `json:"method"`
+	// Baseline is the length of the window the latest values are compared against.
+	Baseline *notification.Duration `json:"baseline,omitempty"`
+	// Season is how long ago the baseline window of the seasonal method ends,
+	// it defaults to 1w.
+	Season     *notification.Duration `json:"season,omitempty"`
+	Deviations []AnomalyDeviation     `json:"deviations"`
+}
+
+// AnomalyDeviation is the level of an anomaly check and the number of
+// standard deviations that trigger it.
+type AnomalyDeviation struct {
+	Level   notification.CheckLevel `json:"level"`
+	StdDevs float64                 `json:"stdDevs"`
+}
+
+// Type returns the type of the check.
+func (a Anomaly) Type() string {
+	return "anomaly"
+}
+
+// Valid returns error if something is invalid.
+func (a Anomaly) Valid(lang fluxlang.FluxLanguageService) error {
+	if err := a.Base.Valid(lang); err != nil {
+		return err
+	}
+	switch a.Method {
+	case AnomalyMethodStdDev, AnomalyMethodSeasonal:
+	default:
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("anomaly check method %q is invalid, must be one of stddev or seasonal", a.Method),
+		}
+	}
+	if a.Baseline == nil || len(a.Baseline.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly check Baseline can't be empty",
+		}
+	}
+	if a.Baseline.TimeDuration() <= a.Every.TimeDuration() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly check Baseline must be greater than the interval",
+		}
+	}
+	if a.Season != nil && len(a.Season.Values) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly check Season can't be empty",
+		}
+	}
+	if len(a.Deviations) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly check must have at least one deviation",
+		}
+	}
+	levels := make(map[notification.CheckLevel]bool)
+	for _, d := range a.Deviations {
+		if err := d.Valid(); err != nil {
+			return err
+		}
+		if levels[d.Level] {
+			return &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  fmt.Sprintf("anomaly check has more than one deviation for level %s", d.Level),
+			}
+		}
+		levels[d.Level] = true
+	}
+	return nil
+}
+
+// Valid returns error if something is invalid.
+func (d AnomalyDeviation) Valid() error {
+	if d.Level == notification.Unknown {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly deviation level is invalid",
+		}
+	}
+	if d.StdDevs <= 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "anomaly deviation stdDevs must be greater than 0",
+		}
+	}
+	return nil
+}
+
+// GenerateFlux returns a flux script for the anomaly check provided. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (a Anomaly) GenerateFlux(lang fluxlang.FluxLanguageService) (string, error) {
+	p, err := a.GenerateFluxAST(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST returns a flux AST for the anomaly check provided. The query
+// of the check is used twice: once for the latest values, as the threshold
+// check does, and once for the baseline they are compared against. If there
+// are any errors in the flux that the user provided the function will return
+// an error for each error found when the script is parsed.
+func (a Anomaly) GenerateFluxAST(lang fluxlang.FluxLanguageService) (*ast.Package, error) {
+	p, err := query.Parse(lang, a.Query.Text)
+	if p == nil {
+		return nil, err
+	}
+	replaceDurationsWithEvery(p, a.Every)
+	removeStopFromRange(p)
+	addCreateEmptyFalseToAggregateWindow(p)
+
+	bp, err := query.Parse(lang, a.Query.Text)
+	if bp == nil {
+		return nil, err
+	}
+	replaceDurationsWithEvery(bp, a.Every)
+	addCreateEmptyFalseToAggregateWindow(bp)
+	start, stop := a.baselineRange()
+	replaceRange(bp, start, stop)
+
+	errs := ast.GetErrors(p)
+	errs = append(errs, ast.GetErrors(bp)...)
+	if len(errs) != 0 {
+		return nil, multiError(errs)
+	}
+
+	// TODO(desa): this is a hack that we had to do as a result of https://github.com/influxdata/flux/issues/1701
+	// when it is fixed we should use a separate file and not manipulate the existing one.
+	if len(p.Files) != 1 || len(bp.Files) != 1 {
+		return nil, fmt.Errorf("expect a single file to be returned from query parsing got %d", len(p.Files))
+	}
+
+	fields := getFields(p)
+	if len(fields) != 1 {
+		return nil, fmt.Errorf("expected a single field but got: %s", fields)
+	}
+
+	f := p.Files[0]
+	assignPipelineToData(f)
+
+	bf := bp.Files[0]
+	if err := assignPipelineToData(bf); err != nil {
+		return nil, err
+	}
+	baseline := bf.Body[0].(*ast.VariableAssignment)
+	baseline.ID = flux.Identifier("baseline")
+
+	f.Imports = append(f.Imports, flux.Imports("influxdata/influxdb/monitor", "influxdata/influxdb/v1", "math")...)
+	f.Body = append(f.Body, a.generateFluxASTBody(baseline, fields[0])...)
+
+	return p, nil
+}
+
+// baselineRange returns the start and stop of the baseline window. It ends
+// right before the latest values, or one season ago for the seasonal method.
+func (a Anomaly) baselineRange() (start, stop ast.Expression) {
+	end := a.Every
+	if a.Method == AnomalyMethodSeasonal {
+		end = a.Season
+		if end == nil {
+			end = &defaultSeason
+		}
+	}
+
+	startDur := &ast.DurationLiteral{
+		Values: append(append([]ast.Duration{}, a.Baseline.Values...), end.Values...),
+	}
+	stopDur := &ast.DurationLiteral{
+		Values: append([]ast.Duration{}, end.Values...),
+	}
+	return flux.Negative(startDur), flux.Negative(stopDur)
+}
+
+func replaceRange(pkg *ast.Package, start, stop ast.Expression) {
+	ast.Visit(pkg, func(n ast.Node) {
+		if call, ok := n.(*ast.CallExpression); ok {
+			if id, ok := call.Callee.(*ast.Identifier); ok && id.Name == "range" {
+				for _, args := range call.Arguments {
+					if obj, ok := args.(*ast.ObjectExpression); ok {
+						obj.Properties = []*ast.Property{
+							flux.Property("start", start),
+							flux.Property("stop", stop),
+						}
+					}
+				}
+			}
+		}
+	})
+}
+
+func (a Anomaly) generateFluxASTBody(baseline ast.Statement, field string) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, a.generateTaskOption())
+	statements = append(statements, a.generateFluxASTCheckDefinition("anomaly"))
+	statements = append(statements, baseline)
+	statements = append(statements, a.generateFluxASTColumns("data", false))
+	statements = append(statements, a.generateFluxASTColumns("baseline", true))
+	statements = append(statements, a.generateFluxASTDeviationFunctions()...)
+	statements = append(statements, a.generateFluxASTMessageFunction())
+	statements = append(statements, a.generateFluxASTChecksFunction(field))
+	return statements
+}
+
+// generateFluxASTColumns pivots the fields of table into columns, and marks
+// whether its rows are part of the baseline.
+func (a Anomaly) generateFluxASTColumns(table string, isBaseline bool) ast.Statement {
+	mark := flux.Function(flux.FunctionParams("r"), flux.ObjectWith("r", flux.Property("_baseline", flux.Bool(isBaseline))))
+	return flux.DefineVariable(table+"_cols", flux.Pipe(
+		flux.Identifier(table),
+		flux.Call(flux.Member("v1", "fieldsAsCols"), flux.Object()),
+		flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", mark))),
+	))
+}
+
+func (a Anomaly) generateFluxASTDeviationFunctions() []ast.Statement {
+	statements := make([]ast.Statement, len(a.Deviations))
+	for k, d := range a.Deviations {
+		fnBody := flux.GreaterThan(flux.Member("r", "deviations"), flux.Float(d.StdDevs))
+		fn := flux.Function(flux.FunctionParams("r"), fnBody)
+		statements[k] = flux.DefineVariable(strings.ToLower(d.Level.String()), fn)
+	}
+	return statements
+}
+
+// generateFluxASTChecksFunction compares the latest values of every series
+// with its baseline. The baseline and latest values are unioned and reduced
+// to the count, sum and sum of squares of the baseline, which give its mean
+// and standard deviation, and the latest value of the field.
+func (a Anomaly) generateFluxASTChecksFunction(field string) ast.Statement {
+	union := flux.Call(flux.Identifier("union"), flux.Object(
+		flux.Property("tables", flux.Array(flux.Identifier("data_cols"), flux.Identifier("baseline_cols"))),
+	))
+	drop := flux.Call(flux.Identifier("drop"), flux.Object(
+		flux.Property("columns", flux.Array(flux.String("_start"), flux.String("_stop"))),
+	))
+
+	value := flux.Call(flux.Identifier("float"), flux.Object(flux.Property("v", flux.Member("r", field))))
+	ifBaseline := func(acc string, then ast.Expression) *ast.Property {
+		return flux.Property(acc, flux.If(flux.Member("r", "_baseline"), then, flux.Member("accumulator", acc)))
+	}
+	ifLatest := func(acc string, then ast.Expression) *ast.Property {
+		return flux.Property(acc, flux.If(flux.Member("r", "_baseline"), flux.Member("accumulator", acc), then))
+	}
+	reducer := flux.Object(
+		ifBaseline("baseline_count", flux.Add(flux.Member("accumulator", "baseline_count"), flux.Float(1))),
+		ifBaseline("baseline_sum", flux.Add(flux.Member("accumulator", "baseline_sum"), value)),
+		ifBaseline("baseline_sum_squares", flux.Add(flux.Member("accumulator", "baseline_sum_squares"), flux.Multiply(value, value))),
+		ifLatest("latest_count", flux.Add(flux.Member("accumulator", "latest_count"), flux.Float(1))),
+		flux.Dictionary(field, flux.If(flux.Member("r", "_baseline"), flux.Member("accumulator", field), value)),
+	)
+	reduce := flux.Call(flux.Identifier("reduce"), flux.Object(
+		flux.Property("fn", flux.Function(flux.FunctionParams("r", "accumulator"), reducer)),
+		flux.Property("identity", flux.Object(
+			flux.Property("baseline_count", flux.Float(0)),
+			flux.Property("baseline_sum", flux.Float(0)),
+			flux.Property("baseline_sum_squares", flux.Float(0)),
+			flux.Property("latest_count", flux.Float(0)),
+			flux.Dictionary(field, flux.Float(0)),
+		)),
+	))
+
+	// Series without latest values, or with too few values in their
+	// baseline to have a standard deviation, can't be compared.
+	filter := flux.Call(flux.Identifier("filter"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"),
+		flux.And(
+			flux.GreaterThan(flux.Member("r", "latest_count"), flux.Float(0)),
+			flux.GreaterThan(flux.Member("r", "baseline_count"), flux.Float(1)),
+		),
+	))))
+
+	count := flux.Member("r", "baseline_count")
+	sum := flux.Member("r", "baseline_sum")
+	variance := flux.Divide(
+		flux.Subtract(flux.Member("r", "baseline_sum_squares"), flux.Divide(flux.Multiply(sum, sum), count)),
+		flux.Subtract(count, flux.Float(1)),
+	)
+	stats := flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"),
+		flux.ObjectWith("r",
+			flux.Property("_time", flux.Call(flux.Identifier("now"), flux.Object())),
+			flux.Property("baseline_mean", flux.Divide(sum, count)),
+			flux.Property("baseline_stddev", flux.Call(flux.Member("math", "sqrt"), flux.Object(flux.Property("x", variance)))),
+		),
+	))))
+
+	deviation := flux.Call(flux.Member("math", "abs"), flux.Object(flux.Property("x",
+		flux.Subtract(flux.Member("r", field), flux.Member("r", "baseline_mean")),
+	)))
+	deviations := flux.Call(flux.Identifier("map"), flux.Object(flux.Property("fn", flux.Function(flux.FunctionParams("r"),
+		flux.ObjectWith("r", flux.Property("deviations", flux.If(
+			flux.GreaterThan(flux.Member("r", "baseline_stddev"), flux.Float(0)),
+			flux.Divide(deviation, flux.Member("r", "baseline_stddev")),
+			flux.Float(0),
+		))),
+	))))
+
+	return flux.ExpressionStatement(flux.Pipe(
+		union,
+		drop,
+		reduce,
+		filter,
+		stats,
+		deviations,
+		a.generateFluxASTChecksCall(),
+	))
+}
+
+func (a Anomaly) generateFluxASTChecksCall() *ast.CallExpression {
+	objectProps := append(([]*ast.Property)(nil), flux.Property("data", flux.Identifier("check")))
+	objectProps = append(objectProps, flux.Property("messageFn", flux.Identifier("messageFn")))
+
+	for _, d := range a.Deviations {
+		lvl := strings.ToLower(d.Level.String())
+		objectProps = append(objectProps, flux.Property(lvl, flux.Identifier(lvl)))
+	}
+
+	return flux.Call(flux.Member("monitor", "check"), flux.Object(objectProps...))
+}
+
+type anomalyAlias Anomaly
+
+// MarshalJSON implement json.Marshaler interface.
+func (a Anomaly) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			anomalyAlias
+			Type string `json:"type"`
+		}{
+			anomalyAlias: anomalyAlias(a),
+			Type:         a.Type(),
+		})
+}
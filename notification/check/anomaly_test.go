@@ -0,0 +1,341 @@
+package check_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnomaly_GenerateFlux(t *testing.T) {
+	type args struct {
+		anomaly check.Anomaly
+	}
+	type wants struct {
+		script string
+	}
+
+	tests := []struct {
+		name  string
+		args  args
+		wants wants
+	}{
+		{
+			name: "rolling standard deviation",
+			args: args{
+				anomaly: check.Anomaly{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+							{Key: "bbb", Value: "vbbb"},
+						},
+						Every:                 mustDuration("5m"),
+						StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean) |> yield()`,
+						},
+					},
+					Method:   check.AnomalyMethodStdDev,
+					Baseline: mustDuration("1h"),
+					Deviations: []check.AnomalyDeviation{
+						{Level: notification.Warn, StdDevs: 2},
+						{Level: notification.Critical, StdDevs: 3},
+					},
+				},
+			},
+			wants: wants{
+				script: `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+import "math"
+
+data = from(bucket: "foo")
+	|> range(start: -5m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 5m, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 5m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "anomaly",
+	tags: {aaa: "vaaa", bbb: "vbbb"},
+}
+baseline = from(bucket: "foo")
+	|> range(start: -1h5m, stop: -5m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 5m, fn: mean, createEmpty: false)
+data_cols = data
+	|> v1["fieldsAsCols"]()
+	|> map(fn: (r) =>
+		({r with _baseline: false}))
+baseline_cols = baseline
+	|> v1["fieldsAsCols"]()
+	|> map(fn: (r) =>
+		({r with _baseline: true}))
+warn = (r) =>
+	(r["deviations"] > 2.0)
+crit = (r) =>
+	(r["deviations"] > 3.0)
+messageFn = (r) =>
+	("whoa! {r[\"usage_user\"]}")
+
+union(tables: [data_cols, baseline_cols])
+	|> drop(columns: ["_start", "_stop"])
+	|> reduce(fn: (r, accumulator) =>
+		({
+			baseline_count: if r["_baseline"] then accumulator["baseline_count"] + 1.0 else accumulator["baseline_count"],
+			baseline_sum: if r["_baseline"] then accumulator["baseline_sum"] + float(v: r["usage_user"]) else accumulator["baseline_sum"],
+			baseline_sum_squares: if r["_baseline"] then accumulator["baseline_sum_squares"] + float(v: r["usage_user"]) * float(v: r["usage_user"]) else accumulator["baseline_sum_squares"],
+			latest_count: if r["_baseline"] then accumulator["latest_count"] else accumulator["latest_count"] + 1.0,
+			"usage_user": if r["_baseline"] then accumulator["usage_user"] else float(v: r["usage_user"]),
+		}), identity: {
+		baseline_count: 0.0,
+		baseline_sum: 0.0,
+		baseline_sum_squares: 0.0,
+		latest_count: 0.0,
+		"usage_user": 0.0,
+	})
+	|> filter(fn: (r) =>
+		(r["latest_count"] > 0.0 and r["baseline_count"] > 1.0))
+	|> map(fn: (r) =>
+		({r with _time: now(), baseline_mean: r["baseline_sum"] / r["baseline_count"], baseline_stddev: math["sqrt"](x: (r["baseline_sum_squares"] - r["baseline_sum"] * r["baseline_sum"] / r["baseline_count"]) / (r["baseline_count"] - 1.0))}))
+	|> map(fn: (r) =>
+		({r with deviations: if r["baseline_stddev"] > 0.0 then math["abs"](x: r["usage_user"] - r["baseline_mean"]) / r["baseline_stddev"] else 0.0}))
+	|> monitor["check"](
+		data: check,
+		messageFn: messageFn,
+		warn: warn,
+		crit: crit,
+	)`,
+			},
+		},
+		{
+			name: "seasonal",
+			args: args{
+				anomaly: check.Anomaly{
+					Base: check.Base{
+						ID:   10,
+						Name: "moo",
+						Tags: []influxdb.Tag{
+							{Key: "aaa", Value: "vaaa"},
+							{Key: "bbb", Value: "vbbb"},
+						},
+						Every:                 mustDuration("5m"),
+						StatusMessageTemplate: "whoa! {r[\"usage_user\"]}",
+						Query: influxdb.DashboardQuery{
+							Text: `from(bucket: "foo") |> range(start: -1d, stop: now()) |> filter(fn: (r) => r._field == "usage_user") |> aggregateWindow(every: 1m, fn: mean) |> yield()`,
+						},
+					},
+					Method:   check.AnomalyMethodSeasonal,
+					Baseline: mustDuration("1h"),
+					Season:   mustDuration("1d"),
+					Deviations: []check.AnomalyDeviation{
+						{Level: notification.Warn, StdDevs: 2},
+						{Level: notification.Critical, StdDevs: 3},
+					},
+				},
+			},
+			wants: wants{
+				script: `package main
+import "influxdata/influxdb/monitor"
+import "influxdata/influxdb/v1"
+import "math"
+
+data = from(bucket: "foo")
+	|> range(start: -5m)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 5m, fn: mean, createEmpty: false)
+
+option task = {name: "moo", every: 5m}
+
+check = {
+	_check_id: "000000000000000a",
+	_check_name: "moo",
+	_type: "anomaly",
+	tags: {aaa: "vaaa", bbb: "vbbb"},
+}
+baseline = from(bucket: "foo")
+	|> range(start: -1h1d, stop: -1d)
+	|> filter(fn: (r) =>
+		(r._field == "usage_user"))
+	|> aggregateWindow(every: 5m, fn: mean, createEmpty: false)
+data_cols = data
+	|> v1["fieldsAsCols"]()
+	|> map(fn: (r) =>
+		({r with _baseline: false}))
+baseline_cols = baseline
+	|> v1["fieldsAsCols"]()
+	|> map(fn: (r) =>
+		({r with _baseline: true}))
+warn = (r) =>
+	(r["deviations"] > 2.0)
+crit = (r) =>
+	(r["deviations"] > 3.0)
+messageFn = (r) =>
+	("whoa! {r[\"usage_user\"]}")
+
+union(tables: [data_cols, baseline_cols])
+	|> drop(columns: ["_start", "_stop"])
+	|> reduce(fn: (r, accumulator) =>
+		({
+			baseline_count: if r["_baseline"] then accumulator["baseline_count"] + 1.0 else accumulator["baseline_count"],
+			baseline_sum: if r["_baseline"] then accumulator["baseline_sum"] + float(v: r["usage_user"]) else accumulator["baseline_sum"],
+			baseline_sum_squares: if r["_baseline"] then accumulator["baseline_sum_squares"] + float(v: r["usage_user"]) * float(v: r["usage_user"]) else accumulator["baseline_sum_squares"],
+			latest_count: if r["_baseline"] then accumulator["latest_count"] else accumulator["latest_count"] + 1.0,
+			"usage_user": if r["_baseline"] then accumulator["usage_user"] else float(v: r["usage_user"]),
+		}), identity: {
+		baseline_count: 0.0,
+		baseline_sum: 0.0,
+		baseline_sum_squares: 0.0,
+		latest_count: 0.0,
+		"usage_user": 0.0,
+	})
+	|> filter(fn: (r) =>
+		(r["latest_count"] > 0.0 and r["baseline_count"] > 1.0))
+	|> map(fn: (r) =>
+		({r with _time: now(), baseline_mean: r["baseline_sum"] / r["baseline_count"], baseline_stddev: math["sqrt"](x: (r["baseline_sum_squares"] - r["baseline_sum"] * r["baseline_sum"] / r["baseline_count"]) / (r["baseline_count"] - 1.0))}))
+	|> map(fn: (r) =>
+		({r with deviations: if r["baseline_stddev"] > 0.0 then math["abs"](x: r["usage_user"] - r["baseline_mean"]) / r["baseline_stddev"] else 0.0}))
+	|> monitor["check"](
+		data: check,
+		messageFn: messageFn,
+		warn: warn,
+		crit: crit,
+	)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.args.anomaly.GenerateFlux(fluxlang.DefaultService)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.wants.script, s)
+		})
+	}
+}
+
+func TestAnomaly_Valid(t *testing.T) {
+	deviations := []check.AnomalyDeviation{
+		{Level: notification.Critical, StdDevs: 3},
+	}
+
+	cases := []struct {
+		name string
+		src  check.Anomaly
+		err  error
+	}{
+		{
+			name: "valid",
+			src: check.Anomaly{
+				Base:       goodBase,
+				Method:     check.AnomalyMethodSeasonal,
+				Baseline:   mustDuration("1h"),
+				Season:     mustDuration("1d"),
+				Deviations: deviations,
+			},
+		},
+		{
+			name: "invalid method",
+			src: check.Anomaly{
+				Base:       goodBase,
+				Method:     "guess",
+				Baseline:   mustDuration("1h"),
+				Deviations: deviations,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  `anomaly check method "guess" is invalid, must be one of stddev or seasonal`,
+			},
+		},
+		{
+			name: "missing baseline",
+			src: check.Anomaly{
+				Base:       goodBase,
+				Method:     check.AnomalyMethodStdDev,
+				Deviations: deviations,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "anomaly check Baseline can't be empty",
+			},
+		},
+		{
+			name: "baseline shorter than every",
+			src: check.Anomaly{
+				Base:       goodBase,
+				Method:     check.AnomalyMethodStdDev,
+				Baseline:   mustDuration("30s"),
+				Deviations: deviations,
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "anomaly check Baseline must be greater than the interval",
+			},
+		},
+		{
+			name: "no deviations",
+			src: check.Anomaly{
+				Base:     goodBase,
+				Method:   check.AnomalyMethodStdDev,
+				Baseline: mustDuration("1h"),
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "anomaly check must have at least one deviation",
+			},
+		},
+		{
+			name: "non positive deviation",
+			src: check.Anomaly{
+				Base:     goodBase,
+				Method:   check.AnomalyMethodStdDev,
+				Baseline: mustDuration("1h"),
+				Deviations: []check.AnomalyDeviation{
+					{Level: notification.Critical},
+				},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "anomaly deviation stdDevs must be greater than 0",
+			},
+		},
+		{
+			name: "duplicate level",
+			src: check.Anomaly{
+				Base:     goodBase,
+				Method:   check.AnomalyMethodStdDev,
+				Baseline: mustDuration("1h"),
+				Deviations: []check.AnomalyDeviation{
+					{Level: notification.Critical, StdDevs: 3},
+					{Level: notification.Critical, StdDevs: 4},
+				},
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "anomaly check has more than one deviation for level CRIT",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.src.Valid(fluxlang.DefaultService)
+			influxTesting.ErrorsEqual(t, got, c.err)
+		})
+	}
+}
@@ -264,6 +264,48 @@ func TestJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "simple anomaly",
+			src: &check.Anomaly{
+				Base: check.Base{
+					ID:      influxTesting.MustIDBase16(id1),
+					Name:    "name1",
+					OwnerID: influxTesting.MustIDBase16(id2),
+					OrgID:   influxTesting.MustIDBase16(id3),
+					Every:   mustDuration("1h"),
+					Query: influxdb.DashboardQuery{
+						BuilderConfig: influxdb.BuilderConfig{
+							Buckets: []string{},
+							Tags: []struct {
+								Key                   string   `json:"key"`
+								Values                []string `json:"values"`
+								AggregateFunctionType string   `json:"aggregateFunctionType"`
+							}{},
+							Functions: []struct {
+								Name string `json:"name"`
+							}{},
+						},
+					},
+					Tags: []influxdb.Tag{
+						{
+							Key:   "k1",
+							Value: "v1",
+						},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Method:   check.AnomalyMethodSeasonal,
+				Baseline: mustDuration("1d"),
+				Season:   mustDuration("1w"),
+				Deviations: []check.AnomalyDeviation{
+					{Level: notification.Warn, StdDevs: 2},
+					{Level: notification.Critical, StdDevs: 3.5},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		fn := func(t *testing.T) {
@@ -197,6 +197,105 @@ func TestValidEndpoint(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "empty smtp host",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Port: 587,
+				From: "influxdb@example.com",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint host is empty",
+			},
+		},
+		{
+			name: "invalid smtp port",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Host: "smtp.example.com",
+				From: "influxdb@example.com",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint port 0 is invalid",
+			},
+		},
+		{
+			name: "invalid smtp from",
+			src: &endpoint.SMTP{
+				Base: goodBase,
+				Host: "smtp.example.com",
+				Port: 587,
+				From: "influxdb",
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint from address is invalid: mail: missing '@' or angle-addr",
+			},
+		},
+		{
+			name: "smtp username without password",
+			src: &endpoint.SMTP{
+				Base:     goodBase,
+				Host:     "smtp.example.com",
+				Port:     587,
+				From:     "influxdb@example.com",
+				Username: influxdb.SecretField{Key: id1.String() + "-username"},
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "smtp endpoint requires both a username and a password for authentication",
+			},
+		},
+		{
+			name: "valid smtp",
+			src: &endpoint.SMTP{
+				Base:     goodBase,
+				Host:     "smtp.example.com",
+				Port:     587,
+				From:     "InfluxDB <influxdb@example.com>",
+				Username: influxdb.SecretField{Key: id1.String() + "-username"},
+				Password: influxdb.SecretField{Key: id1.String() + "-password"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty opsgenie API key",
+			src: &endpoint.Opsgenie{
+				Base: goodBase,
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "empty opsgenie API key",
+			},
+		},
+		{
+			name: "valid opsgenie",
+			src: &endpoint.Opsgenie{
+				Base:   goodBase,
+				APIKey: influxdb.SecretField{Key: id1.String() + "-api-key"},
+			},
+			err: nil,
+		},
+		{
+			name: "empty teams URL",
+			src: &endpoint.Teams{
+				Base: goodBase,
+			},
+			err: &errors2.Error{
+				Code: errors2.EInvalid,
+				Msg:  "teams endpoint URL is empty",
+			},
+		},
+		{
+			name: "valid teams",
+			src: &endpoint.Teams{
+				Base: goodBase,
+				URL:  "https://outlook.office.com/webhook/123",
+			},
+			err: nil,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -306,6 +405,78 @@ func TestJSON(t *testing.T) {
 				Token: influxdb.SecretField{Key: "token-key-1"},
 			},
 		},
+		{
+			name: "simple smtp",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host:     "smtp.example.com",
+				Port:     465,
+				TLS:      true,
+				From:     "influxdb@example.com",
+				Username: influxdb.SecretField{Key: "username-key"},
+				Password: influxdb.SecretField{Key: "password-key"},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{Key: "api-key"},
+			},
+		},
+		{
+			name: "simple teams",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://outlook.office.com/webhook/123",
+			},
+		},
+		{
+			name: "http with content template",
+			src: &endpoint.HTTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				AuthMethod:      "none",
+				Method:          "POST",
+				URL:             "http://example.com",
+				ContentTemplate: `{"text": "${r._message}"}`,
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
@@ -479,6 +650,87 @@ func TestBackFill(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "smtp with username and password",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				Port: 587,
+				From: "influxdb@example.com",
+				Username: influxdb.SecretField{
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Value: strPtr("password1"),
+				},
+			},
+			target: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				Port: 587,
+				From: "influxdb@example.com",
+				Username: influxdb.SecretField{
+					Key:   id1.String() + "-username",
+					Value: strPtr("username1"),
+				},
+				Password: influxdb.SecretField{
+					Key:   id1.String() + "-password",
+					Value: strPtr("password1"),
+				},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Value: strPtr("api-key-value"),
+				},
+			},
+			target: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		c.src.BackfillSecretKeys()
@@ -606,6 +858,84 @@ func TestSecretFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "smtp with username and password",
+			src: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				Host: "smtp.example.com",
+				Port: 587,
+				From: "influxdb@example.com",
+				Username: influxdb.SecretField{
+					Key:   id1.String() + "-username",
+					Value: strPtr("user1"),
+				},
+				Password: influxdb.SecretField{
+					Key:   id1.String() + "-password",
+					Value: strPtr("password1"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1.String() + "-username",
+					Value: strPtr("user1"),
+				},
+				{
+					Key:   id1.String() + "-password",
+					Value: strPtr("password1"),
+				},
+			},
+		},
+		{
+			name: "simple opsgenie",
+			src: &endpoint.Opsgenie{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				APIKey: influxdb.SecretField{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+			secrets: []influxdb.SecretField{
+				{
+					Key:   id1.String() + "-api-key",
+					Value: strPtr("api-key-value"),
+				},
+			},
+		},
+		{
+			name: "teams has no secrets",
+			src: &endpoint.Teams{
+				Base: endpoint.Base{
+					ID:     id1,
+					Name:   "name1",
+					OrgID:  id3,
+					Status: influxdb.Active,
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				URL: "https://outlook.office.com/webhook/123",
+			},
+			secrets: []influxdb.SecretField{},
+		},
 	}
 	for _, c := range cases {
 		secretFields := c.src.SecretFields()
@@ -0,0 +1,120 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"strconv"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationEndpoint = &SMTP{}
+
+const (
+	smtpUsernameSuffix = "-username"
+	smtpPasswordSuffix = "-password"
+)
+
+// SMTP is the notification endpoint config of an email server.
+type SMTP struct {
+	Base
+	// Host is the host name of the SMTP server.
+	Host string `json:"host"`
+	// Port is the port of the SMTP server, usually 587, or 465 with TLS.
+	Port int `json:"port"`
+	// TLS connects to the server over TLS, otherwise STARTTLS is used
+	// when the server supports it.
+	TLS bool `json:"tls"`
+	// From is the address the emails are sent from.
+	From string `json:"from"`
+	// Username and Password authenticate with the server, when set.
+	Username influxdb.SecretField `json:"username,omitempty"`
+	Password influxdb.SecretField `json:"password,omitempty"`
+}
+
+// BackfillSecretKeys fill back the secret field key during the unmarshalling
+// if value of that secret field is not nil.
+func (s *SMTP) BackfillSecretKeys() {
+	if s.Username.Key == "" && s.Username.Value != nil {
+		s.Username.Key = s.idStr() + smtpUsernameSuffix
+	}
+	if s.Password.Key == "" && s.Password.Value != nil {
+		s.Password.Key = s.idStr() + smtpPasswordSuffix
+	}
+}
+
+// SecretFields return available secret fields.
+func (s SMTP) SecretFields() []influxdb.SecretField {
+	arr := []influxdb.SecretField{}
+	if s.Username.Key != "" {
+		arr = append(arr, s.Username)
+	}
+	if s.Password.Key != "" {
+		arr = append(arr, s.Password)
+	}
+	return arr
+}
+
+// Valid returns error if some configuration is invalid
+func (s SMTP) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.Host == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "smtp endpoint host is empty",
+		}
+	}
+	if s.Port <= 0 || s.Port > 65535 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("smtp endpoint port %d is invalid", s.Port),
+		}
+	}
+	if _, err := mail.ParseAddress(s.From); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("smtp endpoint from address is invalid: %s", err.Error()),
+		}
+	}
+	if (s.Username.Key == "") != (s.Password.Key == "") {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "smtp endpoint requires both a username and a password for authentication",
+		}
+	}
+	return nil
+}
+
+// URL returns the URL the notification rules send emails to. Flux scripts
+// send emails by posting to it.
+func (s SMTP) URL() string {
+	scheme := "smtp"
+	if s.TLS {
+		scheme = "smtps"
+	}
+	return scheme + "://" + net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// MarshalJSON implement json.Marshaler interface.
+func (s SMTP) MarshalJSON() ([]byte, error) {
+	type smtpAlias SMTP
+	return json.Marshal(
+		struct {
+			smtpAlias
+			Type string `json:"type"`
+		}{
+			smtpAlias: smtpAlias(s),
+			Type:      s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s SMTP) Type() string {
+	return SMTPType
+}
@@ -0,0 +1,66 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.NotificationEndpoint = &Teams{}
+
+// Teams is the notification endpoint config of microsoft teams.
+type Teams struct {
+	Base
+	// URL is the incoming webhook URL of a teams channel.
+	URL string `json:"url"`
+}
+
+// BackfillSecretKeys is a no-op, teams endpoints have no secret fields.
+func (s *Teams) BackfillSecretKeys() {}
+
+// SecretFields return available secret fields.
+func (s Teams) SecretFields() []influxdb.SecretField {
+	return []influxdb.SecretField{}
+}
+
+// Valid returns error if some configuration is invalid
+func (s Teams) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.URL == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "teams endpoint URL is empty",
+		}
+	}
+	if _, err := url.Parse(s.URL); err != nil {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("teams endpoint URL is invalid: %s", err.Error()),
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Teams) MarshalJSON() ([]byte, error) {
+	type teamsAlias Teams
+	return json.Marshal(
+		struct {
+			teamsAlias
+			Type string `json:"type"`
+		}{
+			teamsAlias: teamsAlias(s),
+			Type:       s.Type(),
+		})
+}
+
+// Type returns the type.
+func (s Teams) Type() string {
+	return TeamsType
+}
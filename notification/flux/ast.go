@@ -1,6 +1,10 @@
 package flux
 
-import "github.com/influxdata/flux/ast"
+import (
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
 
 // File creates a new *ast.File.
 func File(name string, imports []*ast.ImportDeclaration, body []ast.Statement) *ast.File {
@@ -38,6 +42,15 @@ func Equal(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// NotEqual returns a not equal to *ast.BinaryExpression.
+func NotEqual(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.NotEqualOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Subtract returns a subtraction *ast.BinaryExpression.
 func Subtract(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	return &ast.BinaryExpression{
@@ -56,6 +69,24 @@ func Add(lhs, rhs ast.Expression) *ast.BinaryExpression {
 	}
 }
 
+// Multiply returns a multiplication *ast.BinaryExpression.
+func Multiply(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.MultiplicationOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
+// Divide returns a division *ast.BinaryExpression.
+func Divide(lhs, rhs ast.Expression) *ast.BinaryExpression {
+	return &ast.BinaryExpression{
+		Operator: ast.DivisionOperator,
+		Left:     lhs,
+		Right:    rhs,
+	}
+}
+
 // Member returns an *ast.MemberExpression where the key is p and the values is c.
 func Member(p, c string) *ast.MemberExpression {
 	return &ast.MemberExpression{
@@ -199,6 +230,13 @@ func Negative(e ast.Expression) *ast.UnaryExpression {
 	}
 }
 
+// DateTime returns an *ast.DateTimeLiteral of t.
+func DateTime(t time.Time) *ast.DateTimeLiteral {
+	return &ast.DateTimeLiteral{
+		Value: t,
+	}
+}
+
 // DefineVariable returns an *ast.VariableAssignment of id to the e. (e.g. id = <expression>)
 func DefineVariable(id string, e ast.Expression) *ast.VariableAssignment {
 	return &ast.VariableAssignment{
@@ -266,6 +304,14 @@ func FunctionParams(args ...string) []*ast.Property {
 	return params
 }
 
+// PipeParam returns an *ast.Property for a pipe parameter of a function. (e.g. tables=<-)
+func PipeParam(arg string) *ast.Property {
+	return &ast.Property{
+		Key:   &ast.Identifier{Name: arg},
+		Value: &ast.PipeLiteral{},
+	}
+}
+
 // Imports returns a []*ast.ImportDeclaration for each package in pkgs.
 func Imports(pkgs ...string) []*ast.ImportDeclaration {
 	var is []*ast.ImportDeclaration
@@ -61,7 +61,7 @@ func (s *HTTP) generateFluxASTBody(e *endpoint.HTTP) []ast.Statement {
 	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
 	statements = append(statements, s.generateFluxASTStatuses())
 	statements = append(statements, s.generateLevelChecks()...)
-	statements = append(statements, s.generateFluxASTNotifyPipe())
+	statements = append(statements, s.generateFluxASTNotifyPipe(e))
 
 	return statements
 }
@@ -121,23 +121,30 @@ func (s *HTTP) generateFluxASTEndpoint(e *endpoint.HTTP) ast.Statement {
 	return flux.DefineVariable("endpoint", call)
 }
 
-func (s *HTTP) generateFluxASTNotifyPipe() ast.Statement {
-	endpointBody := flux.Call(
-		flux.Member("json", "encode"),
-		flux.Object(flux.Property("v", flux.Identifier("body"))),
-	)
+func (s *HTTP) generateFluxASTNotifyPipe(e *endpoint.HTTP) ast.Statement {
+	var endpointFn *ast.FunctionExpression
 	headers := flux.Property("headers", flux.Identifier("headers"))
-
-	endpointProps := []*ast.Property{
-		headers,
-		flux.Property("data", endpointBody),
+	if e.ContentTemplate != "" {
+		// the template is a flux interpolated string of the JSON body.
+		endpointBody := flux.Call(
+			flux.Identifier("bytes"),
+			flux.Object(flux.Property("v", flux.String(e.ContentTemplate))),
+		)
+		endpointFn = flux.Function(flux.FunctionParams("r"),
+			flux.Object(headers, flux.Property("data", endpointBody)),
+		)
+	} else {
+		endpointBody := flux.Call(
+			flux.Member("json", "encode"),
+			flux.Object(flux.Property("v", flux.Identifier("body"))),
+		)
+		endpointFn = flux.FuncBlock(flux.FunctionParams("r"),
+			s.generateBody(),
+			&ast.ReturnStatement{
+				Argument: flux.Object(headers, flux.Property("data", endpointBody)),
+			},
+		)
 	}
-	endpointFn := flux.FuncBlock(flux.FunctionParams("r"),
-		s.generateBody(),
-		&ast.ReturnStatement{
-			Argument: flux.Object(endpointProps...),
-		},
-	)
 
 	props := []*ast.Property{}
 	props = append(props, flux.Property("data", flux.Identifier("notification")))
@@ -0,0 +1,162 @@
+package rule
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/flux"
+)
+
+var goodOpsgeniePriority = map[string]bool{
+	"P1": true,
+	"P2": true,
+	"P3": true,
+	"P4": true,
+	"P5": true,
+}
+
+// Opsgenie is the notification rule config of opsgenie.
+type Opsgenie struct {
+	Base
+	MessageTemplate string `json:"messageTemplate"`
+	// Priority is the priority of the alerts, P1 to P5. When empty, it is P1
+	// for crit statuses, P3 for warn statuses and P5 otherwise.
+	Priority string `json:"priority,omitempty"`
+}
+
+// GenerateFlux generates a flux script for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFlux(e influxdb.NotificationEndpoint) (string, error) {
+	opsgenieEndpoint, ok := e.(*endpoint.Opsgenie)
+	if !ok {
+		return "", fmt.Errorf("endpoint provided is a %s, not an Opsgenie endpoint", e.Type())
+	}
+	p, err := s.GenerateFluxAST(opsgenieEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return ast.Format(p), nil
+}
+
+// GenerateFluxAST generates a flux AST for the opsgenie notification rule.
+func (s *Opsgenie) GenerateFluxAST(e *endpoint.Opsgenie) (*ast.Package, error) {
+	f := flux.File(
+		s.Name,
+		flux.Imports("influxdata/influxdb/monitor", "contrib/sranka/opsgenie", "influxdata/influxdb/secrets", "experimental"),
+		s.generateFluxASTBody(e),
+	)
+	return &ast.Package{Package: "main", Files: []*ast.File{f}}, nil
+}
+
+func (s *Opsgenie) generateFluxASTBody(e *endpoint.Opsgenie) []ast.Statement {
+	var statements []ast.Statement
+	statements = append(statements, s.generateTaskOption())
+	statements = append(statements, s.generateFluxASTSecrets(e))
+	statements = append(statements, s.generateFluxASTEndpoint(e))
+	statements = append(statements, s.generateFluxASTNotificationDefinition(e))
+	statements = append(statements, s.generateFluxASTStatuses())
+	statements = append(statements, s.generateLevelChecks()...)
+	statements = append(statements, s.generateFluxASTNotifyPipe())
+
+	return statements
+}
+
+func (s *Opsgenie) generateFluxASTSecrets(e *endpoint.Opsgenie) ast.Statement {
+	call := flux.Call(flux.Member("secrets", "get"), flux.Object(flux.Property("key", flux.String(e.APIKey.Key))))
+
+	return flux.DefineVariable("opsgenie_secret", call)
+}
+
+func (s *Opsgenie) generateFluxASTEndpoint(e *endpoint.Opsgenie) ast.Statement {
+	props := []*ast.Property{}
+	if e.URL != "" {
+		props = append(props, flux.Property("url", flux.String(e.URL)))
+	}
+	props = append(props, flux.Property("apiKey", flux.Identifier("opsgenie_secret")))
+	call := flux.Call(flux.Member("opsgenie", "endpoint"), flux.Object(props...))
+
+	return flux.DefineVariable("opsgenie_endpoint", call)
+}
+
+func (s *Opsgenie) generateFluxASTNotifyPipe() ast.Statement {
+	// the opsgenie endpoint requires every property of an alert.
+	endpointProps := []*ast.Property{}
+	endpointProps = append(endpointProps, flux.Property("message", flux.String(s.MessageTemplate)))
+	endpointProps = append(endpointProps, flux.Property("alias", flux.String("")))
+	endpointProps = append(endpointProps, flux.Property("description", flux.String("")))
+	endpointProps = append(endpointProps, flux.Property("priority", s.generatePriority()))
+	endpointProps = append(endpointProps, flux.Property("responders", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("tags", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("actions", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("visibleTo", flux.Array()))
+	endpointProps = append(endpointProps, flux.Property("details", flux.String("{}")))
+	endpointFn := flux.Function(flux.FunctionParams("r"), flux.Object(endpointProps...))
+
+	props := []*ast.Property{}
+	props = append(props, flux.Property("data", flux.Identifier("notification")))
+	props = append(props, flux.Property("endpoint",
+		flux.Call(flux.Identifier("opsgenie_endpoint"), flux.Object(flux.Property("mapFn", endpointFn)))))
+
+	call := flux.Call(flux.Member("monitor", "notify"), flux.Object(props...))
+
+	return flux.ExpressionStatement(flux.Pipe(flux.Identifier("all_statuses"), call))
+}
+
+func (s *Opsgenie) generatePriority() ast.Expression {
+	if s.Priority != "" {
+		return flux.String(s.Priority)
+	}
+	level := flux.Member("r", "_level")
+	return flux.If(
+		flux.Equal(level, flux.String("crit")),
+		flux.String("P1"),
+		flux.If(
+			flux.Equal(level, flux.String("warn")),
+			flux.String("P3"),
+			flux.String("P5"),
+		),
+	)
+}
+
+type opsgenieAlias Opsgenie
+
+// MarshalJSON implement json.Marshaler interface.
+func (s Opsgenie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		struct {
+			opsgenieAlias
+			Type string `json:"type"`
+		}{
+			opsgenieAlias: opsgenieAlias(s),
+			Type:          s.Type(),
+		})
+}
+
+// Valid returns where the config is valid.
+func (s Opsgenie) Valid() error {
+	if err := s.Base.valid(); err != nil {
+		return err
+	}
+	if s.MessageTemplate == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Opsgenie MessageTemplate is invalid",
+		}
+	}
+	if s.Priority != "" && !goodOpsgeniePriority[s.Priority] {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  fmt.Sprintf("Opsgenie Priority %q is invalid, must be one of P1 to P5", s.Priority),
+		}
+	}
+	return nil
+}
+
+// Type returns the type of the rule config.
+func (s Opsgenie) Type() string {
+	return "opsgenie"
+}
@@ -20,6 +20,9 @@ var typeToRule = map[string](func() influxdb.NotificationRule){
 	"pagerduty": func() influxdb.NotificationRule { return &PagerDuty{} },
 	"http":      func() influxdb.NotificationRule { return &HTTP{} },
 	"telegram":  func() influxdb.NotificationRule { return &Telegram{} },
+	"smtp":      func() influxdb.NotificationRule { return &SMTP{} },
+	"opsgenie":  func() influxdb.NotificationRule { return &Opsgenie{} },
+	"teams":     func() influxdb.NotificationRule { return &Teams{} },
 }
 
 // UnmarshalJSON will convert
@@ -61,6 +64,10 @@ type Base struct {
 	RunbookLink string                    `json:"runbookLink"`
 	TagRules    []notification.TagRule    `json:"tagRules,omitempty"`
 	StatusRules []notification.StatusRule `json:"statusRules,omitempty"`
+	// Silences are the silences which apply to the rule. They are set by
+	// the rule service before generating the flux of the rule, and are not
+	// stored with it.
+	Silences []influxdb.Silence `json:"-"`
 	*influxdb.Limit
 	influxdb.CRUDLog
 }
@@ -186,9 +193,124 @@ func (b *Base) generateLevelChecks() []ast.Statement {
 		)
 	}
 
-	stmts = append(stmts, flux.DefineVariable("all_statuses", pipe))
+	if len(b.Silences) == 0 {
+		stmts = append(stmts, flux.DefineVariable("all_statuses", pipe))
+		return stmts
+	}
 
-	return stmts
+	return append(stmts, b.generateSilences(pipe)...)
+}
+
+// generateSilences defines all_statuses as the statuses of pipe which are
+// not matched by any of the silences of the rule. The silenced statuses are
+// recorded in the notifications of the rule with _sent set to false and the
+// ID of the matching silence in _silence_id.
+func (b *Base) generateSilences(pipe *ast.PipeExpression) []ast.Statement {
+	var match ast.Expression = flux.String("")
+	for i := len(b.Silences) - 1; i >= 0; i-- {
+		s := b.Silences[i]
+		match = flux.If(generateSilenceMatch(s), flux.String(s.ID.String()), match)
+	}
+
+	silenceID := flux.Member("r", "_silence_id")
+	silenced := flux.Pipe(
+		flux.Identifier("tables"),
+		flux.Call(
+			flux.Identifier("map"),
+			flux.Object(
+				flux.Property("fn", flux.Function(
+					flux.FunctionParams("r"),
+					flux.ObjectWith("r", flux.Property("_sent", flux.String("false"))),
+				)),
+			),
+		),
+	)
+
+	return []ast.Statement{
+		flux.DefineVariable("silence", flux.Function(flux.FunctionParams("r"), match)),
+		flux.DefineVariable("silenced_endpoint", flux.Function([]*ast.Property{flux.PipeParam("tables")}, silenced)),
+		flux.DefineVariable("matched_statuses", flux.Pipe(
+			pipe,
+			flux.Call(
+				flux.Identifier("map"),
+				flux.Object(
+					flux.Property("fn", flux.Function(
+						flux.FunctionParams("r"),
+						flux.ObjectWith("r", flux.Property("_silence_id", flux.Call(
+							flux.Identifier("silence"),
+							flux.Object(flux.Property("r", flux.Identifier("r"))),
+						))),
+					)),
+				),
+			),
+		)),
+		flux.DefineVariable("all_statuses", flux.Pipe(
+			flux.Identifier("matched_statuses"),
+			flux.Call(
+				flux.Identifier("filter"),
+				flux.Object(
+					flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.Equal(silenceID, flux.String("")))),
+				),
+			),
+			flux.Call(
+				flux.Identifier("drop"),
+				flux.Object(
+					flux.Property("columns", flux.Array(flux.String("_silence_id"))),
+				),
+			),
+		)),
+		flux.ExpressionStatement(flux.Pipe(
+			flux.Identifier("matched_statuses"),
+			flux.Call(
+				flux.Identifier("filter"),
+				flux.Object(
+					flux.Property("fn", flux.Function(flux.FunctionParams("r"), flux.NotEqual(silenceID, flux.String("")))),
+				),
+			),
+			flux.Call(
+				flux.Member("monitor", "notify"),
+				flux.Object(
+					flux.Property("data", flux.Identifier("notification")),
+					flux.Property("endpoint", flux.Identifier("silenced_endpoint")),
+				),
+			),
+		)),
+	}
+}
+
+// generateSilenceMatch returns an expression that is true for the statuses
+// matched by s. The rule and label matchers of s have already been resolved
+// against the rule by the rule service, so only its time range, checks and
+// tags are left to match.
+func generateSilenceMatch(s influxdb.Silence) ast.Expression {
+	var match ast.Expression = flux.And(
+		&ast.BinaryExpression{
+			Operator: ast.GreaterThanEqualOperator,
+			Left:     flux.Member("r", "_time"),
+			Right:    flux.DateTime(s.StartTime.UTC()),
+		},
+		flux.LessThan(flux.Member("r", "_time"), flux.DateTime(s.EndTime.UTC())),
+	)
+
+	if len(s.CheckIDs) > 0 {
+		var checks ast.Expression = flux.Equal(flux.Member("r", "_check_id"), flux.String(s.CheckIDs[0].String()))
+		for _, id := range s.CheckIDs[1:] {
+			checks = flux.Or(checks, flux.Equal(flux.Member("r", "_check_id"), flux.String(id.String())))
+		}
+		match = flux.And(match, checks)
+	}
+
+	for _, t := range s.Tags {
+		// Tag keys need not be valid identifiers, so they are always
+		// accessed with a string key, as in r["host-name"].
+		tag := &ast.MemberExpression{
+			Object:   flux.Identifier("r"),
+			Property: flux.String(t.Key),
+		}
+		match = flux.And(match, flux.Equal(tag, flux.String(t.Value)))
+	}
+
+	return match
 }
 
 func (b *Base) generateLevelCheck(r notification.StatusRule) (ast.Statement, *ast.Identifier) {
@@ -335,6 +457,11 @@ func (b *Base) SetTaskID(id platform.ID) {
 	b.TaskID = id
 }
 
+// SetSilences sets the silences which apply to the rule.
+func (b *Base) SetSilences(silences []influxdb.Silence) {
+	b.Silences = silences
+}
+
 // ClearPrivateData clears the task ID from the base.
 func (b *Base) ClearPrivateData() {
 	b.TaskID = 0
@@ -252,7 +252,7 @@ func TestJSON(t *testing.T) {
 		},
 		{
 			name: "simple smtp",
-			src: &rule.PagerDuty{
+			src: &rule.SMTP{
 				Base: rule.Base{
 					ID:          influxTesting.MustIDBase16(id1),
 					Name:        "name1",
@@ -282,7 +282,9 @@ func TestJSON(t *testing.T) {
 						UpdatedAt: timeGen2.Now(),
 					},
 				},
-				MessageTemplate: "msg1",
+				To:              "ops@example.com",
+				SubjectTemplate: "subject1",
+				BodyTemplate:    "body1",
 			},
 		},
 		{
@@ -357,6 +359,56 @@ func TestJSON(t *testing.T) {
 				MessageTemplate: "blah",
 			},
 		},
+		{
+			name: "simple opsgenie",
+			src: &rule.Opsgenie{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				MessageTemplate: "msg1",
+				Priority:        "P2",
+			},
+		},
+		{
+			name: "simple teams",
+			src: &rule.Teams{
+				Base: rule.Base{
+					ID:          influxTesting.MustIDBase16(id1),
+					OwnerID:     influxTesting.MustIDBase16(id2),
+					Name:        "name1",
+					OrgID:       influxTesting.MustIDBase16(id3),
+					RunbookLink: "runbooklink1",
+					SleepUntil:  &time3,
+					Every:       mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					CRUDLog: influxdb.CRUDLog{
+						CreatedAt: timeGen1.Now(),
+						UpdatedAt: timeGen2.Now(),
+					},
+				},
+				TitleTemplate:   "title1",
+				MessageTemplate: "msg1",
+			},
+		},
 	}
 	for _, c := range cases {
 		b, err := json.Marshal(c.src)
@@ -42,13 +42,30 @@ type RuleService struct {
 	tasks     taskmodel.TaskService
 	orgs      influxdb.OrganizationService
 	endpoints influxdb.NotificationEndpointService
+	silences  SilenceResolver
 
 	idGenerator   platform.IDGenerator
 	timeGenerator influxdb.TimeGenerator
 }
 
+// SilenceResolver finds the silences which apply to a notification rule.
+type SilenceResolver interface {
+	SilencesForRule(ctx context.Context, nr influxdb.NotificationRule) ([]influxdb.Silence, error)
+}
+
+// Option configures a RuleService.
+type Option func(*RuleService)
+
+// WithSilences makes the service drop the statuses silenced by the silences
+// found by r from the tasks of the notification rules.
+func WithSilences(r SilenceResolver) Option {
+	return func(s *RuleService) {
+		s.silences = r
+	}
+}
+
 // New constructs and configures a notification rule service
-func New(logger *zap.Logger, store kv.Store, tasks taskmodel.TaskService, orgs influxdb.OrganizationService, endpoints influxdb.NotificationEndpointService) (*RuleService, error) {
+func New(logger *zap.Logger, store kv.Store, tasks taskmodel.TaskService, orgs influxdb.OrganizationService, endpoints influxdb.NotificationEndpointService, opts ...Option) (*RuleService, error) {
 	s := &RuleService{
 		log:           logger,
 		kv:            store,
@@ -58,6 +75,9 @@ func New(logger *zap.Logger, store kv.Store, tasks taskmodel.TaskService, orgs i
 		timeGenerator: influxdb.RealTimeGenerator{},
 		idGenerator:   snowflake.NewIDGenerator(),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
 
 	ctx := context.Background()
 	if err := store.Update(ctx, func(tx kv.Tx) error {
@@ -155,12 +175,7 @@ func (s *RuleService) createNotificationRule(ctx context.Context, tx kv.Tx, nr i
 }
 
 func (s *RuleService) createNotificationTask(ctx context.Context, r influxdb.NotificationRuleCreate) (*taskmodel.Task, error) {
-	ep, err := s.endpoints.FindNotificationEndpointByID(ctx, r.GetEndpointID())
-	if err != nil {
-		return nil, err
-	}
-
-	script, err := r.GenerateFlux(ep)
+	script, err := s.generateFlux(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -182,6 +197,24 @@ func (s *RuleService) createNotificationTask(ctx context.Context, r influxdb.Not
 	return t, nil
 }
 
+// generateFlux generates the flux script of the task of a notification rule.
+func (s *RuleService) generateFlux(ctx context.Context, r influxdb.NotificationRule) (string, error) {
+	ep, err := s.endpoints.FindNotificationEndpointByID(ctx, r.GetEndpointID())
+	if err != nil {
+		return "", err
+	}
+
+	if s.silences != nil {
+		silences, err := s.silences.SilencesForRule(ctx, r)
+		if err != nil {
+			return "", err
+		}
+		r.SetSilences(silences)
+	}
+
+	return r.GenerateFlux(ep)
+}
+
 // UpdateNotificationRule updates a single notification rule.
 // Returns the new notification rule after update.
 func (s *RuleService) UpdateNotificationRule(ctx context.Context, id platform.ID, nr influxdb.NotificationRuleCreate, userID platform.ID) (influxdb.NotificationRule, error) {
@@ -219,12 +252,7 @@ func (s *RuleService) UpdateNotificationRule(ctx context.Context, id platform.ID
 }
 
 func (s *RuleService) updateNotificationTask(ctx context.Context, r influxdb.NotificationRule, status *string) (*taskmodel.Task, error) {
-	ep, err := s.endpoints.FindNotificationEndpointByID(ctx, r.GetEndpointID())
-	if err != nil {
-		return nil, err
-	}
-
-	script, err := r.GenerateFlux(ep)
+	script, err := s.generateFlux(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -282,6 +310,25 @@ func (s *RuleService) PatchNotificationRule(ctx context.Context, id platform.ID,
 	return nr, nil
 }
 
+// RefreshNotificationRuleTasks regenerates the tasks of the notification
+// rules of an organization, so that they pick up changes to its silences.
+// Rules whose task can not be regenerated are logged and skipped.
+func (s *RuleService) RefreshNotificationRuleTasks(ctx context.Context, orgID platform.ID) error {
+	nrs, _, err := s.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+
+	for _, nr := range nrs {
+		if _, err := s.updateNotificationTask(ctx, nr, nil); err != nil {
+			s.log.Error("failed to refresh task for notification rule",
+				zap.String("notification_rule_id", nr.GetID().String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
 // PutNotificationRule put a notification rule to storage.
 func (s *RuleService) PutNotificationRule(ctx context.Context, nr influxdb.NotificationRuleCreate) error {
 	return s.kv.Update(ctx, func(tx kv.Tx) (err error) {
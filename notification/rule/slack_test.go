@@ -1,8 +1,10 @@
 package rule_test
 
 import (
-	"github.com/influxdata/influxdb/v2/kit/platform"
 	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/parser"
@@ -341,6 +343,91 @@ all_statuses
 				},
 			},
 		},
+		{
+			name: "with silences",
+			want: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "slack"
+import "influxdata/influxdb/secrets"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+slack_endpoint = slack["endpoint"](url: "http://localhost:7777")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses
+	|> filter(fn: (r) =>
+		(r["_level"] == "crit"))
+silence = (r) =>
+	(if r["_time"] >= 2021-01-01T00:00:00Z and r["_time"] < 2021-01-01T02:00:00Z and (r["_check_id"] == "0000000000000004" or r["_check_id"] == "0000000000000005") and r["host-name"] == "db1" and r["rack \"a\""] == "r1" then "0000000000000003" else if r["_time"] >= 2021-01-02T00:00:00Z and r["_time"] < 2021-01-02T02:00:00Z then "0000000000000006" else "")
+silenced_endpoint = (tables=<-) =>
+	(tables
+		|> map(fn: (r) =>
+			({r with _sent: "false"})))
+matched_statuses = crit
+	|> filter(fn: (r) =>
+		(r["_time"] >= experimental["subDuration"](from: now(), d: 1h)))
+	|> map(fn: (r) =>
+		({r with _silence_id: silence(r: r)}))
+all_statuses = matched_statuses
+	|> filter(fn: (r) =>
+		(r["_silence_id"] == ""))
+	|> drop(columns: ["_silence_id"])
+
+matched_statuses
+	|> filter(fn: (r) =>
+		(r["_silence_id"] != ""))
+	|> monitor["notify"](data: notification, endpoint: silenced_endpoint)
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: slack_endpoint(mapFn: (r) =>
+		({channel: "bar", text: "blah", color: if r["_level"] == "crit" then "danger" else if r["_level"] == "warn" then "warning" else "good"})))`,
+			rule: &rule.Slack{
+				Channel:         "bar",
+				MessageTemplate: "blah",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+					Silences: []influxdb.Silence{
+						{
+							ID:        3,
+							StartTime: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+							EndTime:   time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC),
+							CheckIDs:  []platform.ID{4, 5},
+							Tags: []influxdb.Tag{
+								{Key: "host-name", Value: "db1"},
+								{Key: `rack "a"`, Value: "r1"},
+							},
+						},
+						{
+							ID:        6,
+							StartTime: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+							EndTime:   time.Date(2021, 1, 2, 2, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			endpoint: &endpoint.Slack{
+				Base: endpoint.Base{
+					ID:   idPtr(2),
+					Name: "foo",
+				},
+				URL: "http://localhost:7777",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,223 @@
+package rule_test
+
+import (
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/notification"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	influxTesting "github.com/influxdata/influxdb/v2/testing"
+)
+
+var _ influxdb.NotificationRule = &rule.SMTP{}
+
+func TestSMTP_GenerateFlux(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     *rule.SMTP
+		endpoint *endpoint.SMTP
+		script   string
+	}{
+		{
+			name: "notify on crit",
+			endpoint: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:   idPtr(2),
+					Name: "foo",
+				},
+				Host:     "smtp.example.com",
+				Port:     587,
+				From:     "influxdb@example.com",
+				Username: influxdb.SecretField{Key: "2-username"},
+				Password: influxdb.SecretField{Key: "2-password"},
+			},
+			rule: &rule.SMTP{
+				To:              "ops@example.com",
+				SubjectTemplate: "${r._level} alert",
+				BodyTemplate:    "${r._message}",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+				},
+			},
+			script: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "http"
+import "experimental"
+import "influxdata/influxdb/secrets"
+
+option task = {name: "foo", every: 1h}
+
+smtp_username = secrets["get"](key: "2-username")
+smtp_password = secrets["get"](key: "2-password")
+smtp_endpoint = http["endpoint"](url: "smtp://smtp.example.com:587")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses
+	|> filter(fn: (r) =>
+		(r["_level"] == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r["_time"] >= experimental["subDuration"](from: now(), d: 1h)))
+
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: smtp_endpoint(mapFn: (r) =>
+		({headers: {
+			"Content-Type": "text/plain; charset=utf-8",
+			"From": "influxdb@example.com",
+			"To": "ops@example.com",
+			"Subject": "${r._level} alert",
+			"Authorization": http["basicAuth"](u: smtp_username, p: smtp_password),
+		}, data: bytes(v: "${r._message}")})))`,
+		},
+		{
+			name: "tls without authentication",
+			endpoint: &endpoint.SMTP{
+				Base: endpoint.Base{
+					ID:   idPtr(2),
+					Name: "foo",
+				},
+				Host: "smtp.example.com",
+				Port: 465,
+				TLS:  true,
+				From: "influxdb@example.com",
+			},
+			rule: &rule.SMTP{
+				To:              "ops@example.com",
+				SubjectTemplate: "alert",
+				BodyTemplate:    "body",
+				Base: rule.Base{
+					ID:         1,
+					EndpointID: 2,
+					Name:       "foo",
+					Every:      mustDuration("1h"),
+					StatusRules: []notification.StatusRule{
+						{
+							CurrentLevel: notification.Critical,
+						},
+					},
+				},
+			},
+			script: `package main
+// foo
+import "influxdata/influxdb/monitor"
+import "http"
+import "experimental"
+
+option task = {name: "foo", every: 1h}
+
+smtp_endpoint = http["endpoint"](url: "smtps://smtp.example.com:465")
+notification = {
+	_notification_rule_id: "0000000000000001",
+	_notification_rule_name: "foo",
+	_notification_endpoint_id: "0000000000000002",
+	_notification_endpoint_name: "foo",
+}
+statuses = monitor["from"](start: -2h)
+crit = statuses
+	|> filter(fn: (r) =>
+		(r["_level"] == "crit"))
+all_statuses = crit
+	|> filter(fn: (r) =>
+		(r["_time"] >= experimental["subDuration"](from: now(), d: 1h)))
+
+all_statuses
+	|> monitor["notify"](data: notification, endpoint: smtp_endpoint(mapFn: (r) =>
+		({headers: {
+			"Content-Type": "text/plain; charset=utf-8",
+			"From": "influxdb@example.com",
+			"To": "ops@example.com",
+			"Subject": "alert",
+		}, data: bytes(v: "body")})))`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, err := tt.rule.GenerateFlux(tt.endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := script, tt.script; got != want {
+				t.Errorf("\n\nStrings do not match:\n\n%s", diff.LineDiff(got, want))
+			}
+		})
+	}
+}
+
+func TestSMTP_Valid(t *testing.T) {
+	base := rule.Base{
+		ID:         1,
+		EndpointID: 3,
+		OwnerID:    4,
+		OrgID:      5,
+		Name:       "foo",
+		Every:      mustDuration("1h"),
+		StatusRules: []notification.StatusRule{
+			{
+				CurrentLevel: notification.Critical,
+			},
+		},
+	}
+
+	cases := []struct {
+		name string
+		rule *rule.SMTP
+		err  error
+	}{
+		{
+			name: "valid template",
+			rule: &rule.SMTP{
+				Base:            base,
+				To:              "ops@example.com, Jane <jane@example.com>",
+				SubjectTemplate: "blah",
+			},
+		},
+		{
+			name: "invalid To",
+			rule: &rule.SMTP{
+				Base:            base,
+				To:              "",
+				SubjectTemplate: "blah",
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "SMTP To is invalid: mail: no address",
+			},
+		},
+		{
+			name: "missing SubjectTemplate",
+			rule: &rule.SMTP{
+				Base: base,
+				To:   "ops@example.com",
+			},
+			err: &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "SMTP SubjectTemplate is invalid",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := c.rule.Valid()
+			influxTesting.ErrorsEqual(t, got, c.err)
+		})
+	}
+}
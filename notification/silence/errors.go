@@ -0,0 +1,28 @@
+package silence
+
+import (
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var (
+	// ErrSilenceNotFound is used when the specified silence cannot be found.
+	ErrSilenceNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "silence not found",
+	}
+
+	// ErrInvalidSilenceID is used when the service was provided
+	// an invalid ID format.
+	ErrInvalidSilenceID = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "provided silence ID has invalid format",
+	}
+)
+
+// ErrInternalServiceError is used when the error comes from an internal system.
+func ErrInternalServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,103 @@
+package silence
+
+import (
+	"context"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+var _ influxdb.SilenceService = (*Client)(nil)
+
+// Client connects to Influx via HTTP using tokens to manage silences.
+type Client struct {
+	Client *httpc.Client
+	Prefix string
+}
+
+// NewClient returns a Client for the silences API served through client.
+func NewClient(client *httpc.Client) *Client {
+	return &Client{
+		Client: client,
+		Prefix: PrefixSilences,
+	}
+}
+
+func (c *Client) silenceURL(id platform.ID) string {
+	return path.Join(c.Prefix, id.String())
+}
+
+// FindSilenceByID returns a single silence by ID.
+func (c *Client) FindSilenceByID(ctx context.Context, id platform.ID) (*influxdb.Silence, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sl influxdb.Silence
+	if err := c.Client.
+		Get(c.silenceURL(id)).
+		DecodeJSON(&sl).
+		Do(ctx); err != nil {
+		return nil, err
+	}
+	return &sl, nil
+}
+
+// FindSilences returns a list of silences that match filter.
+func (c *Client) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opts ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	params := influxdb.FindOptionParams(opts...)
+	if filter.OrgID != nil {
+		params = append(params, [2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var resp getSilencesResponse
+	if err := c.Client.
+		Get(c.Prefix).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx); err != nil {
+		return nil, 0, err
+	}
+	return resp.Silences, len(resp.Silences), nil
+}
+
+// CreateSilence creates a new silence and sets sl.ID with the new identifier.
+func (c *Client) CreateSilence(ctx context.Context, sl *influxdb.Silence) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return c.Client.
+		PostJSON(sl, c.Prefix).
+		DecodeJSON(sl).
+		Do(ctx)
+}
+
+// UpdateSilence replaces the silence identified by id with sl.
+func (c *Client) UpdateSilence(ctx context.Context, id platform.ID, sl *influxdb.Silence) (*influxdb.Silence, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var updated influxdb.Silence
+	if err := c.Client.
+		PutJSON(sl, c.silenceURL(id)).
+		DecodeJSON(&updated).
+		Do(ctx); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteSilence removes a silence by ID.
+func (c *Client) DeleteSilence(ctx context.Context, id platform.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return c.Client.
+		Delete(c.silenceURL(id)).
+		Do(ctx)
+}
@@ -0,0 +1,169 @@
+package silence
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixSilences is the path prefix of the silences API.
+	PrefixSilences = "/api/v2/silences"
+)
+
+// Handler serves the silences API.
+type Handler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc influxdb.SilenceService
+}
+
+// NewHTTPHandler constructs a new http server for the silences of svc.
+func NewHTTPHandler(log *zap.Logger, svc influxdb.SilenceService) *Handler {
+	h := &Handler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetSilences)
+		r.Post("/", h.handlePostSilence)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetSilence)
+			r.Put("/", h.handlePutSilence)
+			r.Delete("/", h.handleDeleteSilence)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the path prefix the handler is mounted on.
+func (h *Handler) Prefix() string {
+	return PrefixSilences
+}
+
+type getSilencesResponse struct {
+	Silences []*influxdb.Silence `json:"silences"`
+}
+
+func (h *Handler) handleGetSilences(w http.ResponseWriter, r *http.Request) {
+	var filter influxdb.SilenceFilter
+	if orgID := r.URL.Query().Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			h.api.Err(w, r, &errors.Error{
+				Code: errors.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			})
+			return
+		}
+		filter.OrgID = id
+	}
+
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	sls, _, err := h.svc.FindSilences(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, getSilencesResponse{Silences: sls})
+}
+
+func (h *Handler) handlePostSilence(w http.ResponseWriter, r *http.Request) {
+	var sl influxdb.Silence
+	if err := h.api.DecodeJSON(r.Body, &sl); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.CreateSilence(r.Context(), &sl); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusCreated, &sl)
+}
+
+func (h *Handler) handleGetSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeSilenceID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	sl, err := h.svc.FindSilenceByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, sl)
+}
+
+func (h *Handler) handlePutSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeSilenceID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	var sl influxdb.Silence
+	if err := h.api.DecodeJSON(r.Body, &sl); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	updated, err := h.svc.UpdateSilence(r.Context(), id, &sl)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, updated)
+}
+
+func (h *Handler) handleDeleteSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeSilenceID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.svc.DeleteSilence(r.Context(), id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func decodeSilenceID(r *http.Request) (platform.ID, error) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid silence id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
@@ -0,0 +1,95 @@
+package silence
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var _ influxdb.SilenceService = (*AuthorizedService)(nil)
+
+// AuthorizedService wraps a influxdb.SilenceService and authorizes actions
+// against it appropriately. Silences mute notification rules, so they
+// require the same permissions as the notification rules of their
+// organization.
+type AuthorizedService struct {
+	influxdb.SilenceService
+}
+
+// NewAuthorizedService constructs an instance of an authorizing silence service.
+func NewAuthorizedService(s influxdb.SilenceService) *AuthorizedService {
+	return &AuthorizedService{SilenceService: s}
+}
+
+// FindSilenceByID checks to see if the authorizer on context has read access to the silence.
+func (s *AuthorizedService) FindSilenceByID(ctx context.Context, id platform.ID) (*influxdb.Silence, error) {
+	sl, err := s.SilenceService.FindSilenceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, sl.OrgID); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// FindSilences retrieves all silences that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *AuthorizedService) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	if filter.OrgID != nil {
+		if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	sls, _, err := s.SilenceService.FindSilences(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authorized := sls[:0]
+	for _, sl := range sls {
+		if _, _, err := authorizer.AuthorizeOrgReadResource(ctx, influxdb.NotificationRuleResourceType, sl.OrgID); err != nil {
+			if errors.ErrorCode(err) != errors.EUnauthorized {
+				return nil, 0, err
+			}
+			continue
+		}
+		authorized = append(authorized, sl)
+	}
+	return authorized, len(authorized), nil
+}
+
+// CreateSilence checks to see if the authorizer on context has write access to the notification rules of the organization of the silence.
+func (s *AuthorizedService) CreateSilence(ctx context.Context, sl *influxdb.Silence) error {
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, sl.OrgID); err != nil {
+		return err
+	}
+	return s.SilenceService.CreateSilence(ctx, sl)
+}
+
+// UpdateSilence checks to see if the authorizer on context has write access to the silence.
+func (s *AuthorizedService) UpdateSilence(ctx context.Context, id platform.ID, sl *influxdb.Silence) (*influxdb.Silence, error) {
+	existing, err := s.SilenceService.FindSilenceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, existing.OrgID); err != nil {
+		return nil, err
+	}
+	return s.SilenceService.UpdateSilence(ctx, id, sl)
+}
+
+// DeleteSilence checks to see if the authorizer on context has write access to the silence.
+func (s *AuthorizedService) DeleteSilence(ctx context.Context, id platform.ID) error {
+	sl, err := s.SilenceService.FindSilenceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeOrgWriteResource(ctx, influxdb.NotificationRuleResourceType, sl.OrgID); err != nil {
+		return err
+	}
+	return s.SilenceService.DeleteSilence(ctx, id)
+}
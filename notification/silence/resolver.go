@@ -0,0 +1,164 @@
+package silence
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// Resolver finds the silences which apply to a notification rule.
+//
+// The rule and label matchers of a silence can not be evaluated by the
+// flux of a rule, so the resolver evaluates them when the task of the rule
+// is generated: rule IDs and rule labels are checked against the rule, and
+// check labels are turned into the IDs of the checks carrying them. Label
+// mappings changed afterwards are picked up the next time the rule or one
+// of the silences of its organization is updated.
+type Resolver struct {
+	store  *Store
+	labels influxdb.LabelService
+	checks influxdb.CheckService
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+// NewResolver returns a Resolver for the silences in store.
+func NewResolver(store *Store, labels influxdb.LabelService, checks influxdb.CheckService) *Resolver {
+	return &Resolver{
+		store:  store,
+		labels: labels,
+		checks: checks,
+		now:    time.Now,
+	}
+}
+
+// SilencesForRule returns the silences of the organization of nr which
+// have not ended and apply to it. The returned silences only match on
+// their time range, check IDs and tags.
+func (r *Resolver) SilencesForRule(ctx context.Context, nr influxdb.NotificationRule) ([]influxdb.Silence, error) {
+	orgID := nr.GetOrgID()
+	sls, _, err := r.store.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	var (
+		resolved   []influxdb.Silence
+		ruleLabels map[platform.ID]bool
+		checks     map[platform.ID]map[platform.ID]bool
+	)
+	for _, sl := range sls {
+		if !sl.ActiveAt(now) {
+			continue
+		}
+		if len(sl.RuleIDs) > 0 && !containsID(sl.RuleIDs, nr.GetID()) {
+			continue
+		}
+
+		checkIDs := sl.CheckIDs
+		if len(sl.LabelIDs) > 0 {
+			if ruleLabels == nil {
+				if ruleLabels, err = r.resourceLabels(ctx, nr.GetID(), influxdb.NotificationRuleResourceType); err != nil {
+					return nil, err
+				}
+			}
+			if !anyLabel(ruleLabels, sl.LabelIDs) {
+				if checks == nil {
+					if checks, err = r.checkLabels(ctx, orgID); err != nil {
+						return nil, err
+					}
+				}
+				checkIDs = labelledChecks(checks, sl.LabelIDs, sl.CheckIDs)
+				if len(checkIDs) == 0 {
+					continue
+				}
+			}
+		}
+
+		resolved = append(resolved, influxdb.Silence{
+			ID:        sl.ID,
+			OrgID:     sl.OrgID,
+			Name:      sl.Name,
+			StartTime: sl.StartTime,
+			EndTime:   sl.EndTime,
+			CheckIDs:  checkIDs,
+			Tags:      sl.Tags,
+		})
+	}
+	return resolved, nil
+}
+
+func (r *Resolver) resourceLabels(ctx context.Context, id platform.ID, rt influxdb.ResourceType) (map[platform.ID]bool, error) {
+	ls, err := r.labels.FindResourceLabels(ctx, influxdb.LabelMappingFilter{
+		ResourceID:   id,
+		ResourceType: rt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[platform.ID]bool, len(ls))
+	for _, l := range ls {
+		m[l.ID] = true
+	}
+	return m, nil
+}
+
+// checkLabels returns the labels of every check of an organization by check ID.
+func (r *Resolver) checkLabels(ctx context.Context, orgID platform.ID) (map[platform.ID]map[platform.ID]bool, error) {
+	cs, _, err := r.checks.FindChecks(ctx, influxdb.CheckFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[platform.ID]map[platform.ID]bool, len(cs))
+	for _, c := range cs {
+		ls, err := r.resourceLabels(ctx, c.GetID(), influxdb.ChecksResourceType)
+		if err != nil {
+			return nil, err
+		}
+		m[c.GetID()] = ls
+	}
+	return m, nil
+}
+
+// labelledChecks returns the IDs of the checks carrying one of labelIDs,
+// restricted to checkIDs if any are given.
+func labelledChecks(checks map[platform.ID]map[platform.ID]bool, labelIDs, checkIDs []platform.ID) []platform.ID {
+	var ids []platform.ID
+	for id, ls := range checks {
+		if !anyLabel(ls, labelIDs) {
+			continue
+		}
+		if len(checkIDs) > 0 && !containsID(checkIDs, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	// Keep the generated flux stable across refreshes.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func anyLabel(labels map[platform.ID]bool, ids []platform.ID) bool {
+	for _, id := range ids {
+		if labels[id] {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []platform.ID, id platform.ID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package silence
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"go.uber.org/zap"
+)
+
+// RuleRefresher regenerates the tasks of the notification rules of an
+// organization, so that they pick up changes to its silences.
+type RuleRefresher interface {
+	RefreshNotificationRuleTasks(ctx context.Context, orgID platform.ID) error
+}
+
+var _ influxdb.SilenceService = (*Service)(nil)
+
+// Service manages silences, keeping the tasks of the notification rules
+// they apply to up to date.
+type Service struct {
+	log   *zap.Logger
+	store *Store
+	rules RuleRefresher
+
+	idGenerator   platform.IDGenerator
+	timeGenerator influxdb.TimeGenerator
+}
+
+// NewService returns a Service which persists silences in store and
+// refreshes the notification rules of rules when they change.
+func NewService(log *zap.Logger, store *Store, rules RuleRefresher) *Service {
+	return &Service{
+		log:           log,
+		store:         store,
+		rules:         rules,
+		idGenerator:   snowflake.NewIDGenerator(),
+		timeGenerator: influxdb.RealTimeGenerator{},
+	}
+}
+
+// FindSilenceByID returns a single silence by ID.
+func (s *Service) FindSilenceByID(ctx context.Context, id platform.ID) (*influxdb.Silence, error) {
+	return s.store.FindSilenceByID(ctx, id)
+}
+
+// FindSilences returns a list of silences that match filter and the total count of matching silences.
+func (s *Service) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	return s.store.FindSilences(ctx, filter, opt...)
+}
+
+// CreateSilence creates a new silence and sets sl.ID with the new identifier.
+func (s *Service) CreateSilence(ctx context.Context, sl *influxdb.Silence) error {
+	if err := sl.Valid(); err != nil {
+		return err
+	}
+
+	now := s.timeGenerator.Now()
+	sl.ID = s.idGenerator.ID()
+	sl.SetCreatedAt(now)
+	sl.SetUpdatedAt(now)
+	if err := s.store.PutSilence(ctx, sl); err != nil {
+		return err
+	}
+
+	s.refresh(ctx, sl.OrgID)
+	return nil
+}
+
+// UpdateSilence replaces the silence identified by id with sl.
+// The ID, organization and creation time of a silence can not be updated.
+func (s *Service) UpdateSilence(ctx context.Context, id platform.ID, sl *influxdb.Silence) (*influxdb.Silence, error) {
+	existing, err := s.store.FindSilenceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sl.ID = existing.ID
+	sl.OrgID = existing.OrgID
+	sl.SetCreatedAt(existing.CreatedAt)
+	sl.SetUpdatedAt(s.timeGenerator.Now())
+	if err := sl.Valid(); err != nil {
+		return nil, err
+	}
+	if err := s.store.PutSilence(ctx, sl); err != nil {
+		return nil, err
+	}
+
+	s.refresh(ctx, sl.OrgID)
+	return sl, nil
+}
+
+// DeleteSilence removes a silence by ID.
+func (s *Service) DeleteSilence(ctx context.Context, id platform.ID) error {
+	sl, err := s.store.FindSilenceByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.store.DeleteSilence(ctx, id); err != nil {
+		return err
+	}
+
+	s.refresh(ctx, sl.OrgID)
+	return nil
+}
+
+// refresh regenerates the notification rule tasks of an organization. The
+// silence has already been stored at this point, so a failure is logged
+// rather than returned; the tasks pick the silence up on their next update.
+func (s *Service) refresh(ctx context.Context, orgID platform.ID) {
+	if err := s.rules.RefreshNotificationRuleTasks(ctx, orgID); err != nil {
+		s.log.Error("Failed to refresh notification rules after silence change",
+			zap.String("org_id", orgID.String()),
+			zap.Error(err))
+	}
+}
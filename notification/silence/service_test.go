@@ -0,0 +1,243 @@
+package silence_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/notification/check"
+	"github.com/influxdata/influxdb/v2/notification/rule"
+	"github.com/influxdata/influxdb/v2/notification/silence"
+	"go.uber.org/zap/zaptest"
+)
+
+func NewTestInmemStore(t *testing.T) kv.Store {
+	t.Helper()
+
+	store := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+type fakeRefresher struct {
+	refreshed []platform.ID
+}
+
+func (r *fakeRefresher) RefreshNotificationRuleTasks(ctx context.Context, orgID platform.ID) error {
+	r.refreshed = append(r.refreshed, orgID)
+	return nil
+}
+
+func TestService_CreateUpdateDelete(t *testing.T) {
+	ctx := context.Background()
+	rules := &fakeRefresher{}
+	svc := silence.NewService(zaptest.NewLogger(t), silence.NewStore(NewTestInmemStore(t)), rules)
+
+	orgID := platform.ID(0xff00)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	sl := &influxdb.Silence{
+		OrgID:     orgID,
+		Name:      "maintenance",
+		StartTime: start,
+		EndTime:   start.Add(2 * time.Hour),
+		CheckIDs:  []platform.ID{1},
+	}
+	if err := svc.CreateSilence(ctx, sl); err != nil {
+		t.Fatal(err)
+	}
+	if !sl.ID.Valid() {
+		t.Fatalf("expected silence to be assigned an ID")
+	}
+
+	found, err := svc.FindSilenceByID(ctx, sl.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The round trip through the store drops the location and monotonic clock reading.
+	sl.CreatedAt, sl.UpdatedAt = sl.CreatedAt.UTC(), sl.UpdatedAt.UTC()
+	if !reflect.DeepEqual(found, sl) {
+		t.Fatalf("unexpected silence, want %+v, got %+v", sl, found)
+	}
+
+	updated, err := svc.UpdateSilence(ctx, sl.ID, &influxdb.Silence{
+		OrgID:     platform.ID(0xff01),
+		Name:      "maintenance",
+		StartTime: start,
+		EndTime:   start.Add(4 * time.Hour),
+		Tags:      []influxdb.Tag{{Key: "host", Value: "db1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated.UpdatedAt = updated.UpdatedAt.UTC()
+	if updated.ID != sl.ID || updated.OrgID != orgID || !updated.CreatedAt.Equal(sl.CreatedAt) {
+		t.Fatalf("update must not change the ID, organization or creation time of a silence, got %+v", updated)
+	}
+	if updated.CheckIDs != nil || len(updated.Tags) != 1 {
+		t.Fatalf("update must replace the matchers of a silence, got %+v", updated)
+	}
+
+	sls, n, err := svc.FindSilences(ctx, influxdb.SilenceFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || !reflect.DeepEqual(sls[0], updated) {
+		t.Fatalf("unexpected silences %+v", sls)
+	}
+
+	if err := svc.DeleteSilence(ctx, sl.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSilenceByID(ctx, sl.ID); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if err := svc.DeleteSilence(ctx, sl.ID); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	if want := []platform.ID{orgID, orgID, orgID}; !reflect.DeepEqual(rules.refreshed, want) {
+		t.Fatalf("expected the rules of the organization to be refreshed on every change, got %v", rules.refreshed)
+	}
+}
+
+func TestService_CreateInvalid(t *testing.T) {
+	ctx := context.Background()
+	rules := &fakeRefresher{}
+	svc := silence.NewService(zaptest.NewLogger(t), silence.NewStore(NewTestInmemStore(t)), rules)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name    string
+		silence influxdb.Silence
+	}{
+		{
+			name: "without matchers",
+			silence: influxdb.Silence{
+				OrgID:     1,
+				Name:      "maintenance",
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+			},
+		},
+		{
+			name: "ending before it starts",
+			silence: influxdb.Silence{
+				OrgID:     1,
+				Name:      "maintenance",
+				StartTime: start,
+				EndTime:   start.Add(-time.Hour),
+				CheckIDs:  []platform.ID{1},
+			},
+		},
+		{
+			name: "with an invalid tag",
+			silence: influxdb.Silence{
+				OrgID:     1,
+				Name:      "maintenance",
+				StartTime: start,
+				EndTime:   start.Add(time.Hour),
+				Tags:      []influxdb.Tag{{Key: "host"}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.CreateSilence(ctx, &tt.silence); errors.ErrorCode(err) != errors.EInvalid {
+				t.Fatalf("expected invalid error, got %v", err)
+			}
+		})
+	}
+	if len(rules.refreshed) != 0 {
+		t.Fatalf("invalid silences must not refresh rules")
+	}
+}
+
+func TestResolver_SilencesForRule(t *testing.T) {
+	ctx := context.Background()
+	store := silence.NewStore(NewTestInmemStore(t))
+
+	orgID := platform.ID(0xff00)
+	now := time.Now().UTC().Truncate(time.Second)
+	active := func(sl influxdb.Silence) *influxdb.Silence {
+		sl.OrgID = orgID
+		sl.Name = "maintenance"
+		sl.StartTime = now.Add(-time.Hour)
+		sl.EndTime = now.Add(time.Hour)
+		return &sl
+	}
+
+	const (
+		ruleID      = platform.ID(10)
+		otherRuleID = platform.ID(11)
+		ruleLabel   = platform.ID(20)
+		checkLabel  = platform.ID(21)
+		unusedLabel = platform.ID(22)
+		labelledChk = platform.ID(30)
+		otherChk    = platform.ID(31)
+	)
+
+	silences := []*influxdb.Silence{
+		// Matches every status of the rule.
+		active(influxdb.Silence{ID: 1, RuleIDs: []platform.ID{ruleID}}),
+		// Applies to another rule.
+		active(influxdb.Silence{ID: 2, RuleIDs: []platform.ID{otherRuleID}}),
+		// Matches the rule through its label.
+		active(influxdb.Silence{ID: 3, LabelIDs: []platform.ID{ruleLabel}, Tags: []influxdb.Tag{{Key: "host", Value: "db1"}}}),
+		// Matches the checks carrying the label.
+		active(influxdb.Silence{ID: 4, LabelIDs: []platform.ID{checkLabel}}),
+		// Matches no check nor rule.
+		active(influxdb.Silence{ID: 5, LabelIDs: []platform.ID{unusedLabel}}),
+		// Has ended.
+		{ID: 6, OrgID: orgID, Name: "past", StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour), CheckIDs: []platform.ID{otherChk}},
+		// Belongs to another organization.
+		{ID: 7, OrgID: orgID + 1, Name: "other", StartTime: now, EndTime: now.Add(time.Hour), CheckIDs: []platform.ID{otherChk}},
+	}
+	for _, sl := range silences {
+		if err := store.PutSilence(ctx, sl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	labels := mock.NewLabelService()
+	labels.FindResourceLabelsFn = func(ctx context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+		switch {
+		case f.ResourceType == influxdb.NotificationRuleResourceType && f.ResourceID == ruleID:
+			return []*influxdb.Label{{ID: ruleLabel}}, nil
+		case f.ResourceType == influxdb.ChecksResourceType && f.ResourceID == labelledChk:
+			return []*influxdb.Label{{ID: checkLabel}}, nil
+		}
+		return nil, nil
+	}
+	checks := mock.NewCheckService()
+	checks.FindChecksFn = func(ctx context.Context, f influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+		cs := []influxdb.Check{
+			&check.Deadman{Base: check.Base{ID: labelledChk, OrgID: orgID}},
+			&check.Deadman{Base: check.Base{ID: otherChk, OrgID: orgID}},
+		}
+		return cs, len(cs), nil
+	}
+
+	r := silence.NewResolver(store, labels, checks)
+	got, err := r.SilencesForRule(ctx, &rule.Slack{Base: rule.Base{ID: ruleID, OrgID: orgID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []influxdb.Silence{
+		{ID: 1, OrgID: orgID, Name: "maintenance", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)},
+		{ID: 3, OrgID: orgID, Name: "maintenance", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), Tags: []influxdb.Tag{{Key: "host", Value: "db1"}}},
+		{ID: 4, OrgID: orgID, Name: "maintenance", StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), CheckIDs: []platform.ID{labelledChk}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected silences\nwant: %+v\ngot:  %+v", want, got)
+	}
+}
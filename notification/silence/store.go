@@ -0,0 +1,176 @@
+package silence
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var silenceBucket = []byte("silencesv1")
+
+// Store persists silences in a kv.Store.
+type Store struct {
+	kvStore kv.Store
+}
+
+// NewStore returns a Store backed by kvStore.
+func NewStore(kvStore kv.Store) *Store {
+	return &Store{kvStore: kvStore}
+}
+
+// FindSilenceByID returns a single silence by ID.
+func (s *Store) FindSilenceByID(ctx context.Context, id platform.ID) (*influxdb.Silence, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var sl *influxdb.Silence
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		var err error
+		sl, err = findSilenceByID(tx, id)
+		return err
+	})
+	return sl, err
+}
+
+// FindSilences returns the silences matching filter, ordered by ID.
+func (s *Store) FindSilences(ctx context.Context, filter influxdb.SilenceFilter, opt ...influxdb.FindOptions) ([]*influxdb.Silence, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var (
+		offset     int
+		limit      int
+		descending bool
+	)
+	if len(opt) > 0 {
+		offset = opt[0].Offset
+		limit = opt[0].Limit
+		descending = opt[0].Descending
+	}
+
+	sls := []*influxdb.Silence{}
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(silenceBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		direction := kv.CursorAscending
+		if descending {
+			direction = kv.CursorDescending
+		}
+		cur, err := b.ForwardCursor(nil, kv.WithCursorDirection(direction))
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		defer cur.Close()
+
+		var count int
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			sl := &influxdb.Silence{}
+			if err := json.Unmarshal(v, sl); err != nil {
+				return ErrInternalServiceError(err)
+			}
+			if filter.OrgID != nil && sl.OrgID != *filter.OrgID {
+				continue
+			}
+			if count >= offset {
+				sls = append(sls, sl)
+			}
+			count++
+			if limit > 0 && len(sls) >= limit {
+				break
+			}
+		}
+		if err := cur.Err(); err != nil {
+			return ErrInternalServiceError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return sls, len(sls), nil
+}
+
+// PutSilence stores sl, replacing any silence with the same ID.
+func (s *Store) PutSilence(ctx context.Context, sl *influxdb.Silence) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		encodedID, err := sl.ID.Encode()
+		if err != nil {
+			return ErrInvalidSilenceID
+		}
+
+		v, err := json.Marshal(sl)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		b, err := tx.Bucket(silenceBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		if err := b.Put(encodedID, v); err != nil {
+			return ErrInternalServiceError(err)
+		}
+		return nil
+	})
+}
+
+// DeleteSilence removes a silence by ID.
+func (s *Store) DeleteSilence(ctx context.Context, id platform.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		if _, err := findSilenceByID(tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidSilenceID
+		}
+		b, err := tx.Bucket(silenceBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalServiceError(err)
+		}
+		return nil
+	})
+}
+
+func findSilenceByID(tx kv.Tx, id platform.ID) (*influxdb.Silence, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidSilenceID
+	}
+
+	b, err := tx.Bucket(silenceBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrSilenceNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	sl := &influxdb.Silence{}
+	if err := json.Unmarshal(v, sl); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return sl, nil
+}
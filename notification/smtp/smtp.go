@@ -0,0 +1,226 @@
+// Package smtp sends the email of notification rules.
+//
+// Flux has no email package, so the flux of SMTP notification rules posts
+// each notification with http.post to an smtp:// or smtps:// URL. The HTTP
+// client given to flux is wrapped with a Client, which sends those requests
+// to the SMTP server as emails. The From, To and Subject headers of the
+// request become the headers of the email, the request body becomes its body
+// and basic authorization credentials are used to authenticate with the server.
+//
+// As any flux query can call http.post, the client is only given to flux when
+// influxd is started with --notification-smtp-enabled.
+package smtp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	gosmtp "net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const (
+	schemeSMTP  = "smtp"
+	schemeSMTPS = "smtps"
+
+	defaultTimeout     = 30 * time.Second
+	defaultContentType = "text/plain; charset=utf-8"
+)
+
+// Doer sends HTTP requests, as does the HTTP client of flux.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Client sends requests to smtp:// and smtps:// URLs as emails, and passes any
+// other request on to the wrapped Doer.
+type Client struct {
+	Doer
+
+	// Timeout bounds the whole exchange with the SMTP server.
+	Timeout time.Duration
+	// TLSConfig is used for smtps:// URLs and STARTTLS. When nil, the server
+	// name is taken from the URL.
+	TLSConfig *tls.Config
+}
+
+// NewClient wraps d to send emails.
+func NewClient(d Doer) *Client {
+	return &Client{
+		Doer:    d,
+		Timeout: defaultTimeout,
+	}
+}
+
+// Do sends an email for requests to smtp:// and smtps:// URLs. The response
+// has status 200 once the server accepted the email, 400 when the request is
+// not a valid email, and 502 when the server rejected it. Failing to reach the
+// server is returned as an error. Requests to other URLs are sent with the
+// wrapped Doer.
+func (c *Client) Do(r *http.Request) (*http.Response, error) {
+	switch r.URL.Scheme {
+	case schemeSMTP, schemeSMTPS:
+	default:
+		return c.Doer.Do(r)
+	}
+
+	m, err := newMessage(r)
+	if err != nil {
+		return newResponse(r, http.StatusBadRequest, err), nil
+	}
+	if err := c.send(r, m); err != nil {
+		if _, ok := err.(*textproto.Error); ok {
+			return newResponse(r, http.StatusBadGateway, err), nil
+		}
+		return nil, err
+	}
+	return newResponse(r, http.StatusOK, nil), nil
+}
+
+type message struct {
+	from     string
+	to       []string
+	username string
+	password string
+	data     []byte
+}
+
+func newMessage(r *http.Request) (*message, error) {
+	from, err := mail.ParseAddress(r.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid From header: %v", err)
+	}
+	to, err := mail.ParseAddressList(r.Header.Get("To"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid To header: %v", err)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+
+	var recipients []string
+	for _, addr := range to {
+		recipients = append(recipients, addr.String())
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", r.Header.Get("Subject")))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(&buf, "\r\n")
+	buf.Write(body)
+
+	m := &message{
+		from: from.Address,
+		data: buf.Bytes(),
+	}
+	for _, addr := range to {
+		m.to = append(m.to, addr.Address)
+	}
+	m.username, m.password, _ = r.BasicAuth()
+	return m, nil
+}
+
+func (c *Client) send(r *http.Request, m *message) error {
+	host := r.URL.Hostname()
+	tlsConfig := c.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
+
+	dialer := &net.Dialer{Timeout: c.Timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if r.URL.Scheme == schemeSMTPS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", r.URL.Host, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(r.Context(), "tcp", r.URL.Host)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if c.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
+			return err
+		}
+	}
+
+	client, err := gosmtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if r.URL.Scheme == schemeSMTP {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if m.username != "" {
+		if err := client.Auth(gosmtp.PlainAuth("", m.username, m.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	for _, addr := range m.to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func newResponse(r *http.Request, code int, err error) *http.Response {
+	var body string
+	if err != nil {
+		body = err.Error()
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       r,
+	}
+}
@@ -0,0 +1,199 @@
+package smtp
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// stubServer is an SMTP server that accepts every email, except those to the
+// rejected recipients, and keeps them for inspection.
+type stubServer struct {
+	ln       net.Listener
+	rejected map[string]bool
+
+	mu       sync.Mutex
+	messages []stubMessage
+}
+
+func newStubServer(t *testing.T, rejected ...string) *stubServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	s := &stubServer{ln: ln, rejected: make(map[string]bool)}
+	for _, r := range rejected {
+		s.rejected[r] = true
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *stubServer) url() string {
+	return "smtp://" + s.ln.Addr().String()
+}
+
+func (s *stubServer) serve(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var msg stubMessage
+	tp.PrintfLine("220 localhost ESMTP stub")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(line); {
+		case strings.HasPrefix(cmd, "EHLO"):
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN "):
+			b, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			msg.auth = string(b)
+			tp.PrintfLine("235 Authentication successful")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if s.rejected[to] {
+				tp.PrintfLine("550 No such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			tp.PrintfLine("250 OK")
+		case cmd == "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			b, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(b)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			tp.PrintfLine("250 OK")
+		case cmd == "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s *stubServer) received() []stubMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]stubMessage(nil), s.messages...)
+}
+
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(r *http.Request) (*http.Response, error) { return f(r) }
+
+func newRequest(t *testing.T, url, body string, headers map[string]string) *http.Request {
+	r, err := http.NewRequest("POST", url, strings.NewReader(body))
+	require.NoError(t, err)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestClient_Do(t *testing.T) {
+	s := newStubServer(t)
+	c := NewClient(nil)
+
+	r := newRequest(t, s.url(), "Disk usage is 95%.\n", map[string]string{
+		"From":    "InfluxDB <influxdb@example.com>",
+		"To":      "ops@example.com, Jane <jane@example.com>",
+		"Subject": "Disk usage critical",
+	})
+	r.SetBasicAuth("user", "secret")
+
+	resp, err := c.Do(r)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	msgs := s.received()
+	require.Len(t, msgs, 1)
+	msg := msgs[0]
+	assert.Equal(t, "\x00user\x00secret", msg.auth)
+	assert.Equal(t, "influxdb@example.com", msg.from)
+	assert.Equal(t, []string{"ops@example.com", "jane@example.com"}, msg.to)
+	assert.Contains(t, msg.data, "From: \"InfluxDB\" <influxdb@example.com>\n")
+	assert.Contains(t, msg.data, "To: <ops@example.com>, \"Jane\" <jane@example.com>\n")
+	assert.Contains(t, msg.data, "Subject: Disk usage critical\n")
+	assert.Contains(t, msg.data, "Content-Type: text/plain; charset=utf-8\n")
+	assert.True(t, strings.HasSuffix(msg.data, "\n\nDisk usage is 95%.\n"), msg.data)
+}
+
+func TestClient_Do_Rejected(t *testing.T) {
+	s := newStubServer(t, "nobody@example.com")
+	c := NewClient(nil)
+
+	resp, err := c.Do(newRequest(t, s.url(), "body", map[string]string{
+		"From": "influxdb@example.com",
+		"To":   "nobody@example.com",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "No such user")
+	assert.Empty(t, s.received())
+}
+
+func TestClient_Do_InvalidMessage(t *testing.T) {
+	s := newStubServer(t)
+	c := NewClient(nil)
+
+	for _, headers := range []map[string]string{
+		{"To": "ops@example.com"},
+		{"From": "influxdb@example.com"},
+		{"From": "influxdb@example.com", "To": "not an address"},
+	} {
+		resp, err := c.Do(newRequest(t, s.url(), "body", headers))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+	assert.Empty(t, s.received())
+}
+
+func TestClient_Do_OtherSchemes(t *testing.T) {
+	var got *http.Request
+	c := NewClient(doerFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusNoContent}, nil
+	}))
+
+	r := newRequest(t, "http://localhost:7777", "body", nil)
+	resp, err := c.Do(r)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, r, got)
+}
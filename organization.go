@@ -13,9 +13,40 @@ type Organization struct {
 	ID          platform.ID `json:"id,omitempty"`
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
+	// QueryLimits restricts the queries the organization may run at once.
+	QueryLimits *QueryLimits `json:"queryLimits,omitempty"`
 	CRUDLog
 }
 
+// QueryLimits are the limits the query controller enforces on the queries of
+// a single organization, on top of the limits that apply to the whole
+// instance. A zero value leaves the corresponding resource unlimited.
+type QueryLimits struct {
+	// ConcurrencyQuota is the number of queries of the organization that may
+	// execute concurrently.
+	ConcurrencyQuota int32 `json:"concurrencyQuota,omitempty"`
+
+	// QueueSize is the number of queries of the organization that may wait
+	// for a free execution slot before new queries are rejected. It only
+	// applies when ConcurrencyQuota is set.
+	QueueSize int32 `json:"queueSize,omitempty"`
+
+	// MemoryBytesQuota is the total memory the running queries of the
+	// organization may allocate.
+	MemoryBytesQuota int64 `json:"memoryBytesQuota,omitempty"`
+}
+
+// Valid returns an error if any of the limits is negative.
+func (l *QueryLimits) Valid() error {
+	if l.ConcurrencyQuota < 0 || l.QueueSize < 0 || l.MemoryBytesQuota < 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "query limits must not be negative",
+		}
+	}
+	return nil
+}
+
 // errors of org
 var (
 	// ErrOrgNameisEmpty is error when org name is empty
@@ -64,6 +95,9 @@ type OrganizationService interface {
 type OrganizationUpdate struct {
 	Name        *string
 	Description *string `json:"description,omitempty"`
+	// QueryLimits replaces the query limits of the organization. Setting it
+	// to the zero value removes all limits.
+	QueryLimits *QueryLimits `json:"queryLimits,omitempty"`
 }
 
 // ErrInvalidOrgFilter is the error indicate org filter is empty
@@ -52,17 +52,21 @@ var kindPriorities = map[Kind]int{
 	KindLabel:                         1,
 	KindBucket:                        2,
 	KindCheck:                         3,
-	KindCheckDeadman:                  4,
-	KindCheckThreshold:                5,
-	KindNotificationEndpoint:          6,
-	KindNotificationEndpointHTTP:      7,
-	KindNotificationEndpointPagerDuty: 8,
-	KindNotificationEndpointSlack:     9,
-	KindNotificationRule:              10,
-	KindTask:                          11,
-	KindVariable:                      12,
-	KindDashboard:                     13,
-	KindTelegraf:                      14,
+	KindCheckAnomaly:                  4,
+	KindCheckDeadman:                  5,
+	KindCheckThreshold:                6,
+	KindNotificationEndpoint:          7,
+	KindNotificationEndpointHTTP:      8,
+	KindNotificationEndpointOpsgenie:  9,
+	KindNotificationEndpointPagerDuty: 10,
+	KindNotificationEndpointSMTP:      11,
+	KindNotificationEndpointSlack:     12,
+	KindNotificationEndpointTeams:     13,
+	KindNotificationRule:              14,
+	KindTask:                          15,
+	KindVariable:                      16,
+	KindDashboard:                     17,
+	KindTelegraf:                      18,
 }
 
 type exportKey struct {
@@ -240,7 +244,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		for _, bkt := range bkts {
 			mapResource(bkt.OrgID, bkt.ID, KindBucket, BucketToObject(r.Name, *bkt))
 		}
-	case r.Kind.is(KindCheck), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold):
+	case r.Kind.is(KindCheck), r.Kind.is(KindCheckAnomaly), r.Kind.is(KindCheckDeadman), r.Kind.is(KindCheckThreshold):
 		filter := influxdb.CheckFilter{}
 		if r.ID != platform.ID(0) {
 			filter.ID = &r.ID
@@ -316,8 +320,11 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		}
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
+		r.Kind.is(KindNotificationEndpointOpsgenie),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
-		r.Kind.is(KindNotificationEndpointSlack):
+		r.Kind.is(KindNotificationEndpointSMTP),
+		r.Kind.is(KindNotificationEndpointSlack),
+		r.Kind.is(KindNotificationEndpointTeams):
 		var endpoints []influxdb.NotificationEndpoint
 
 		switch {
@@ -669,6 +676,22 @@ func CheckToObject(name string, ch influxdb.Check) Object {
 			thresholds = append(thresholds, convertThreshold(th))
 		}
 		o.Spec[fieldCheckThresholds] = thresholds
+	case *icheck.Anomaly:
+		o.Kind = KindCheckAnomaly
+		assignBase(cT.Base)
+		o.Spec[fieldCheckMethod] = cT.Method
+		assignNonZeroFluxDurs(o.Spec, map[string]*notification.Duration{
+			fieldCheckBaseline: cT.Baseline,
+			fieldCheckSeason:   cT.Season,
+		})
+		var deviations []Resource
+		for _, d := range cT.Deviations {
+			deviations = append(deviations, Resource{
+				fieldLevel:        d.Level.String(),
+				fieldCheckStdDevs: d.StdDevs,
+			})
+		}
+		o.Spec[fieldCheckDeviations] = deviations
 	}
 	return o
 }
@@ -1261,6 +1284,9 @@ func NotificationEndpointToObject(name string, e influxdb.NotificationEndpoint)
 		o.Spec[fieldNotificationEndpointHTTPMethod] = actual.Method
 		o.Spec[fieldNotificationEndpointURL] = actual.URL
 		o.Spec[fieldType] = actual.AuthMethod
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationEndpointContentTemplate: actual.ContentTemplate,
+		})
 		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
 			fieldNotificationEndpointPassword: actual.Password,
 			fieldNotificationEndpointToken:    actual.Token,
@@ -1278,6 +1304,29 @@ func NotificationEndpointToObject(name string, e influxdb.NotificationEndpoint)
 		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
 			fieldNotificationEndpointToken: actual.Token,
 		})
+	case *endpoint.Opsgenie:
+		o.Kind = KindNotificationEndpointOpsgenie
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldNotificationEndpointURL: actual.URL,
+		})
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointAPIKey: actual.APIKey,
+		})
+	case *endpoint.SMTP:
+		o.Kind = KindNotificationEndpointSMTP
+		o.Spec[fieldNotificationEndpointHost] = actual.Host
+		o.Spec[fieldNotificationEndpointPort] = actual.Port
+		o.Spec[fieldNotificationEndpointFrom] = actual.From
+		assignNonZeroBools(o.Spec, map[string]bool{
+			fieldNotificationEndpointTLS: actual.TLS,
+		})
+		assignNonZeroSecrets(o.Spec, map[string]influxdb.SecretField{
+			fieldNotificationEndpointPassword: actual.Password,
+			fieldNotificationEndpointUsername: actual.Username,
+		})
+	case *endpoint.Teams:
+		o.Kind = KindNotificationEndpointTeams
+		o.Spec[fieldNotificationEndpointURL] = actual.URL
 	}
 
 	return o
@@ -1338,6 +1387,19 @@ func NotificationRuleToObject(name, endpointPkgName string, iRule influxdb.Notif
 		assignBase(t.Base)
 		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
 		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleChannel: t.Channel})
+	case *rule.Opsgenie:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRulePriority: t.Priority})
+	case *rule.SMTP:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleTo] = t.To
+		o.Spec[fieldNotificationRuleSubjectTemplate] = t.SubjectTemplate
+		o.Spec[fieldNotificationRuleBodyTemplate] = t.BodyTemplate
+	case *rule.Teams:
+		assignBase(t.Base)
+		o.Spec[fieldNotificationRuleMessageTemplate] = t.MessageTemplate
+		assignNonZeroStrings(o.Spec, map[string]string{fieldNotificationRuleTitleTemplate: t.TitleTemplate})
 	}
 
 	return o
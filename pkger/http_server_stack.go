@@ -418,7 +418,7 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 	switch r.Kind {
 	case KindBucket:
 		linkResource = "buckets"
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		linkResource = "checks"
 	case KindDashboard:
 		linkResource = "dashboards"
@@ -426,8 +426,11 @@ func stackResLinks(r StackResource) RespStackResourceLinks {
 		linkResource = "labels"
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		linkResource = "notificationEndpoints"
 	case KindNotificationRule:
 		linkResource = "notificationRules"
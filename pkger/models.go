@@ -19,14 +19,18 @@ const (
 	KindUnknown                       Kind = ""
 	KindBucket                        Kind = "Bucket"
 	KindCheck                         Kind = "Check"
+	KindCheckAnomaly                  Kind = "CheckAnomaly"
 	KindCheckDeadman                  Kind = "CheckDeadman"
 	KindCheckThreshold                Kind = "CheckThreshold"
 	KindDashboard                     Kind = "Dashboard"
 	KindLabel                         Kind = "Label"
 	KindNotificationEndpoint          Kind = "NotificationEndpoint"
 	KindNotificationEndpointHTTP      Kind = "NotificationEndpointHTTP"
+	KindNotificationEndpointOpsgenie  Kind = "NotificationEndpointOpsgenie"
 	KindNotificationEndpointPagerDuty Kind = "NotificationEndpointPagerDuty"
+	KindNotificationEndpointSMTP      Kind = "NotificationEndpointSMTP"
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
+	KindNotificationEndpointTeams     Kind = "NotificationEndpointTeams"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
 	KindTask                          Kind = "Task"
@@ -46,14 +50,18 @@ func Kinds() []Kind {
 var kinds = map[Kind]bool{
 	KindBucket:                        true,
 	KindCheck:                         true,
+	KindCheckAnomaly:                  true,
 	KindCheckDeadman:                  true,
 	KindCheckThreshold:                true,
 	KindDashboard:                     true,
 	KindLabel:                         true,
 	KindNotificationEndpoint:          true,
 	KindNotificationEndpointHTTP:      true,
+	KindNotificationEndpointOpsgenie:  true,
 	KindNotificationEndpointPagerDuty: true,
+	KindNotificationEndpointSMTP:      true,
 	KindNotificationEndpointSlack:     true,
+	KindNotificationEndpointTeams:     true,
 	KindNotificationRule:              true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
@@ -90,7 +98,7 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	switch k {
 	case KindBucket:
 		return influxdb.BucketsResourceType
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		return influxdb.ChecksResourceType
 	case KindDashboard:
 		return influxdb.DashboardsResourceType
@@ -98,8 +106,11 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		return influxdb.LabelsResourceType
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		return influxdb.NotificationEndpointResourceType
 	case KindNotificationRule:
 		return influxdb.NotificationRuleResourceType
@@ -381,6 +392,11 @@ type (
 		MessageTemplate string              `json:"messageTemplate"`
 		StatusRules     []SummaryStatusRule `json:"statusRules"`
 		TagRules        []SummaryTagRule    `json:"tagRules"`
+
+		// These fields are only set for rules of SMTP endpoints.
+		To              string `json:"to,omitempty"`
+		SubjectTemplate string `json:"subjectTemplate,omitempty"`
+		BodyTemplate    string `json:"bodyTemplate,omitempty"`
 	}
 )
 
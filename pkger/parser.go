@@ -492,7 +492,7 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 	case KindBucket:
 		_, ok := p.mBuckets[pkgName]
 		return ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		_, ok := p.mChecks[pkgName]
 		return ok
 	case KindLabel:
@@ -500,8 +500,11 @@ func (p *Template) Contains(k Kind, pkgName string) bool {
 		return ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		_, ok := p.mNotificationEndpoints[pkgName]
 		return ok
 	case KindNotificationRule:
@@ -871,6 +874,7 @@ func (p *Template) graphChecks() *parseErr {
 	}{
 		{kind: KindCheckThreshold, checkKind: checkKindThreshold},
 		{kind: KindCheckDeadman, checkKind: checkKindDeadman},
+		{kind: KindCheckAnomaly, checkKind: checkKindAnomaly},
 	}
 	var pErr parseErr
 	for _, checkKind := range checkKinds {
@@ -883,12 +887,15 @@ func (p *Template) graphChecks() *parseErr {
 			ch := &check{
 				kind:          checkKind.checkKind,
 				identity:      ident,
+				baseline:      o.Spec.durationShort(fieldCheckBaseline),
 				description:   o.Spec.stringShort(fieldDescription),
 				every:         o.Spec.durationShort(fieldEvery),
 				level:         o.Spec.stringShort(fieldLevel),
+				method:        normStr(o.Spec.stringShort(fieldCheckMethod)),
 				offset:        o.Spec.durationShort(fieldOffset),
 				query:         strings.TrimSpace(o.Spec.stringShort(fieldQuery)),
 				reportZero:    o.Spec.boolShort(fieldCheckReportZero),
+				season:        o.Spec.durationShort(fieldCheckSeason),
 				staleTime:     o.Spec.durationShort(fieldCheckStaleTime),
 				status:        normStr(o.Spec.stringShort(fieldStatus)),
 				statusMessage: o.Spec.stringShort(fieldCheckStatusMessageTemplate),
@@ -910,6 +917,12 @@ func (p *Template) graphChecks() *parseErr {
 					val:        th.float64Short(fieldValue),
 				})
 			}
+			for _, d := range o.Spec.slcResource(fieldCheckDeviations) {
+				ch.deviations = append(ch.deviations, deviation{
+					level:   strings.TrimSpace(strings.ToUpper(d.stringShort(fieldLevel))),
+					stdDevs: d.float64Short(fieldCheckStdDevs),
+				})
+			}
 
 			failures := p.parseNestedLabels(o.Spec, func(l *label) error {
 				ch.labels = append(ch.labels, l)
@@ -995,6 +1008,18 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			kind:             KindNotificationEndpointSlack,
 			notificationKind: notificationKindSlack,
 		},
+		{
+			kind:             KindNotificationEndpointOpsgenie,
+			notificationKind: notificationKindOpsgenie,
+		},
+		{
+			kind:             KindNotificationEndpointSMTP,
+			notificationKind: notificationKindSMTP,
+		},
+		{
+			kind:             KindNotificationEndpointTeams,
+			notificationKind: notificationKindTeams,
+		},
 	}
 
 	var pErr parseErr
@@ -1006,17 +1031,23 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			}
 
 			endpoint := &notificationEndpoint{
-				kind:        nk.notificationKind,
-				identity:    ident,
-				description: o.Spec.stringShort(fieldDescription),
-				method:      strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationEndpointHTTPMethod))),
-				httpType:    normStr(o.Spec.stringShort(fieldType)),
-				password:    o.Spec.references(fieldNotificationEndpointPassword),
-				routingKey:  o.Spec.references(fieldNotificationEndpointRoutingKey),
-				status:      normStr(o.Spec.stringShort(fieldStatus)),
-				token:       o.Spec.references(fieldNotificationEndpointToken),
-				url:         o.Spec.stringShort(fieldNotificationEndpointURL),
-				username:    o.Spec.references(fieldNotificationEndpointUsername),
+				kind:            nk.notificationKind,
+				identity:        ident,
+				apiKey:          o.Spec.references(fieldNotificationEndpointAPIKey),
+				contentTemplate: o.Spec.stringShort(fieldNotificationEndpointContentTemplate),
+				description:     o.Spec.stringShort(fieldDescription),
+				from:            o.Spec.stringShort(fieldNotificationEndpointFrom),
+				host:            o.Spec.stringShort(fieldNotificationEndpointHost),
+				method:          strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationEndpointHTTPMethod))),
+				httpType:        normStr(o.Spec.stringShort(fieldType)),
+				password:        o.Spec.references(fieldNotificationEndpointPassword),
+				port:            o.Spec.intShort(fieldNotificationEndpointPort),
+				routingKey:      o.Spec.references(fieldNotificationEndpointRoutingKey),
+				status:          normStr(o.Spec.stringShort(fieldStatus)),
+				tls:             o.Spec.boolShort(fieldNotificationEndpointTLS),
+				token:           o.Spec.references(fieldNotificationEndpointToken),
+				url:             o.Spec.stringShort(fieldNotificationEndpointURL),
+				username:        o.Spec.references(fieldNotificationEndpointUsername),
 			}
 			failures := p.parseNestedLabels(o.Spec, func(l *label) error {
 				endpoint.labels = append(endpoint.labels, l)
@@ -1028,6 +1059,7 @@ func (p *Template) graphNotificationEndpoints() *parseErr {
 			p.setRefs(
 				endpoint.name,
 				endpoint.displayName,
+				endpoint.apiKey,
 				endpoint.password,
 				endpoint.routingKey,
 				endpoint.token,
@@ -1057,14 +1089,19 @@ func (p *Template) graphNotificationRules() *parseErr {
 		}
 
 		rule := &notificationRule{
-			identity:     ident,
-			endpointName: p.getRefWithKnownEnvs(o.Spec, fieldNotificationRuleEndpointName),
-			description:  o.Spec.stringShort(fieldDescription),
-			channel:      o.Spec.stringShort(fieldNotificationRuleChannel),
-			every:        o.Spec.durationShort(fieldEvery),
-			msgTemplate:  o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
-			offset:       o.Spec.durationShort(fieldOffset),
-			status:       normStr(o.Spec.stringShort(fieldStatus)),
+			identity:        ident,
+			endpointName:    p.getRefWithKnownEnvs(o.Spec, fieldNotificationRuleEndpointName),
+			description:     o.Spec.stringShort(fieldDescription),
+			bodyTemplate:    o.Spec.stringShort(fieldNotificationRuleBodyTemplate),
+			channel:         o.Spec.stringShort(fieldNotificationRuleChannel),
+			every:           o.Spec.durationShort(fieldEvery),
+			msgTemplate:     o.Spec.stringShort(fieldNotificationRuleMessageTemplate),
+			offset:          o.Spec.durationShort(fieldOffset),
+			priority:        strings.TrimSpace(strings.ToUpper(o.Spec.stringShort(fieldNotificationRulePriority))),
+			status:          normStr(o.Spec.stringShort(fieldStatus)),
+			subjectTemplate: o.Spec.stringShort(fieldNotificationRuleSubjectTemplate),
+			titleTemplate:   o.Spec.stringShort(fieldNotificationRuleTitleTemplate),
+			to:              o.Spec.stringShort(fieldNotificationRuleTo),
 		}
 
 		for _, sRule := range o.Spec.slcResource(fieldNotificationRuleStatusRules) {
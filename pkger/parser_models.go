@@ -2,6 +2,7 @@ package pkger
 
 import (
 	"fmt"
+	"net/mail"
 	"net/url"
 	"regexp"
 	"sort"
@@ -191,13 +192,19 @@ type checkKind int
 const (
 	checkKindDeadman checkKind = iota + 1
 	checkKindThreshold
+	checkKindAnomaly
 )
 
 const (
 	fieldCheckAllValues             = "allValues"
+	fieldCheckBaseline              = "baseline"
+	fieldCheckDeviations            = "deviations"
+	fieldCheckMethod                = "method"
 	fieldCheckReportZero            = "reportZero"
+	fieldCheckSeason                = "season"
 	fieldCheckStaleTime             = "staleTime"
 	fieldCheckStatusMessageTemplate = "statusMessageTemplate"
+	fieldCheckStdDevs               = "stdDevs"
 	fieldCheckTags                  = "tags"
 	fieldCheckThresholds            = "thresholds"
 	fieldCheckTimeSince             = "timeSince"
@@ -209,12 +216,16 @@ type check struct {
 	identity
 
 	kind          checkKind
+	baseline      time.Duration
 	description   string
+	deviations    []deviation
 	every         time.Duration
 	level         string
+	method        string
 	offset        time.Duration
 	query         string
 	reportZero    bool
+	season        time.Duration
 	staleTime     time.Duration
 	status        string
 	statusMessage string
@@ -278,6 +289,18 @@ func (c *check) summarize() SummaryCheck {
 			StaleTime:  toNotificationDuration(c.staleTime),
 			TimeSince:  toNotificationDuration(c.timeSince),
 		}
+	case checkKindAnomaly:
+		sum.Kind = KindCheckAnomaly
+		anomaly := &icheck.Anomaly{
+			Base:       base,
+			Method:     c.method,
+			Baseline:   toNotificationDuration(c.baseline),
+			Deviations: toInfluxDeviations(c.deviations...),
+		}
+		if c.season > 0 {
+			anomaly.Season = toNotificationDuration(c.season)
+		}
+		sum.Check = anomaly
 	}
 	return sum
 }
@@ -326,6 +349,31 @@ func (c *check) valid() []validationErr {
 				vErrs = append(vErrs, fail)
 			}
 		}
+	case checkKindAnomaly:
+		if c.method != icheck.AnomalyMethodStdDev && c.method != icheck.AnomalyMethodSeasonal {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckMethod,
+				Msg:   fmt.Sprintf("must be 1 in [stddev, seasonal]; got=%q", c.method),
+			})
+		}
+		if c.baseline <= c.every {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckBaseline,
+				Msg:   "duration value must be provided that is greater than every",
+			})
+		}
+		if len(c.deviations) == 0 {
+			vErrs = append(vErrs, validationErr{
+				Field: fieldCheckDeviations,
+				Msg:   "must provide at least 1 deviation entry",
+			})
+		}
+		for i, d := range c.deviations {
+			for _, fail := range d.valid() {
+				fail.Index = intPtr(i)
+				vErrs = append(vErrs, fail)
+			}
+		}
 	}
 
 	if len(vErrs) > 0 {
@@ -414,6 +462,39 @@ func toInfluxThresholds(thresholds ...threshold) []icheck.ThresholdConfig {
 	return iThresh
 }
 
+type deviation struct {
+	level   string
+	stdDevs float64
+}
+
+func (d deviation) valid() []validationErr {
+	var vErrs []validationErr
+	if notification.ParseCheckLevel(d.level) == notification.Unknown {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldLevel,
+			Msg:   fmt.Sprintf("must be 1 in [CRIT, WARN, INFO, OK]; got=%q", d.level),
+		})
+	}
+	if d.stdDevs <= 0 {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldCheckStdDevs,
+			Msg:   "must be greater than 0",
+		})
+	}
+	return vErrs
+}
+
+func toInfluxDeviations(deviations ...deviation) []icheck.AnomalyDeviation {
+	var iDevs []icheck.AnomalyDeviation
+	for _, d := range deviations {
+		iDevs = append(iDevs, icheck.AnomalyDeviation{
+			Level:   notification.ParseCheckLevel(d.level),
+			StdDevs: d.stdDevs,
+		})
+	}
+	return iDevs
+}
+
 // chartKind identifies what kind of chart is eluded too. Each
 // chart kind has their own requirements for what constitutes
 // a chart.
@@ -1573,14 +1654,20 @@ const (
 	notificationKindHTTP notificationEndpointKind = iota + 1
 	notificationKindPagerDuty
 	notificationKindSlack
+	notificationKindOpsgenie
+	notificationKindSMTP
+	notificationKindTeams
 )
 
 func (n notificationEndpointKind) String() string {
-	if n > 0 && n < 4 {
+	if n > 0 && n < 7 {
 		return [...]string{
 			endpoint.HTTPType,
 			endpoint.PagerDutyType,
 			endpoint.SlackType,
+			endpoint.OpsgenieType,
+			endpoint.SMTPType,
+			endpoint.TeamsType,
 		}[n-1]
 	}
 	return ""
@@ -1593,27 +1680,39 @@ const (
 )
 
 const (
-	fieldNotificationEndpointHTTPMethod = "method"
-	fieldNotificationEndpointPassword   = "password"
-	fieldNotificationEndpointRoutingKey = "routingKey"
-	fieldNotificationEndpointToken      = "token"
-	fieldNotificationEndpointURL        = "url"
-	fieldNotificationEndpointUsername   = "username"
+	fieldNotificationEndpointAPIKey          = "apiKey"
+	fieldNotificationEndpointContentTemplate = "contentTemplate"
+	fieldNotificationEndpointFrom            = "from"
+	fieldNotificationEndpointHost            = "host"
+	fieldNotificationEndpointHTTPMethod      = "method"
+	fieldNotificationEndpointPassword        = "password"
+	fieldNotificationEndpointPort            = "port"
+	fieldNotificationEndpointRoutingKey      = "routingKey"
+	fieldNotificationEndpointTLS             = "tls"
+	fieldNotificationEndpointToken           = "token"
+	fieldNotificationEndpointURL             = "url"
+	fieldNotificationEndpointUsername        = "username"
 )
 
 type notificationEndpoint struct {
 	identity
 
-	kind        notificationEndpointKind
-	description string
-	method      string
-	password    *references
-	routingKey  *references
-	status      string
-	token       *references
-	httpType    string
-	url         string
-	username    *references
+	kind            notificationEndpointKind
+	apiKey          *references
+	contentTemplate string
+	description     string
+	from            string
+	host            string
+	method          string
+	password        *references
+	port            int
+	routingKey      *references
+	status          string
+	tls             bool
+	token           *references
+	httpType        string
+	url             string
+	username        *references
 
 	labels sortedLabels
 }
@@ -1648,9 +1747,10 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 	case notificationKindHTTP:
 		sum.Kind = KindNotificationEndpointHTTP
 		e := &endpoint.HTTP{
-			Base:   base,
-			URL:    n.url,
-			Method: n.method,
+			Base:            base,
+			URL:             n.url,
+			Method:          n.method,
+			ContentTemplate: n.contentTemplate,
 		}
 		switch n.httpType {
 		case notificationHTTPAuthTypeBasic:
@@ -1678,6 +1778,30 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 			URL:   n.url,
 			Token: n.token.SecretField(),
 		}
+	case notificationKindOpsgenie:
+		sum.Kind = KindNotificationEndpointOpsgenie
+		sum.NotificationEndpoint = &endpoint.Opsgenie{
+			Base:   base,
+			URL:    n.url,
+			APIKey: n.apiKey.SecretField(),
+		}
+	case notificationKindSMTP:
+		sum.Kind = KindNotificationEndpointSMTP
+		sum.NotificationEndpoint = &endpoint.SMTP{
+			Base:     base,
+			Host:     n.host,
+			Port:     n.port,
+			TLS:      n.tls,
+			From:     n.from,
+			Username: n.username.SecretField(),
+			Password: n.password.SecretField(),
+		}
+	case notificationKindTeams:
+		sum.Kind = KindNotificationEndpointTeams
+		sum.NotificationEndpoint = &endpoint.Teams{
+			Base: base,
+			URL:  n.url,
+		}
 	}
 	return sum
 }
@@ -1706,11 +1830,22 @@ func (n *notificationEndpoint) valid() []validationErr {
 		failures = append(failures, err)
 	}
 
-	if _, err := url.Parse(n.url); err != nil || n.url == "" {
-		failures = append(failures, validationErr{
-			Field: fieldNotificationEndpointURL,
-			Msg:   "must be valid url",
-		})
+	switch n.kind {
+	case notificationKindSMTP:
+	case notificationKindOpsgenie:
+		if _, err := url.Parse(n.url); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointURL,
+				Msg:   "must be valid url",
+			})
+		}
+	default:
+		if _, err := url.Parse(n.url); err != nil || n.url == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointURL,
+				Msg:   "must be valid url",
+			})
+		}
 	}
 
 	status := influxdb.Status(n.status)
@@ -1722,6 +1857,13 @@ func (n *notificationEndpoint) valid() []validationErr {
 	}
 
 	switch n.kind {
+	case notificationKindOpsgenie:
+		if !n.apiKey.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointAPIKey,
+				Msg:   "must be provided",
+			})
+		}
 	case notificationKindPagerDuty:
 		if !n.routingKey.hasValue() {
 			failures = append(failures, validationErr{
@@ -1729,6 +1871,31 @@ func (n *notificationEndpoint) valid() []validationErr {
 				Msg:   "must be provide",
 			})
 		}
+	case notificationKindSMTP:
+		if n.host == "" {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointHost,
+				Msg:   "must be provided",
+			})
+		}
+		if n.port <= 0 || n.port > 65535 {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointPort,
+				Msg:   "must be a valid port",
+			})
+		}
+		if _, err := mail.ParseAddress(n.from); err != nil {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointFrom,
+				Msg:   "must be a valid email address",
+			})
+		}
+		if n.username.hasValue() != n.password.hasValue() {
+			failures = append(failures, validationErr{
+				Field: fieldNotificationEndpointPassword,
+				Msg:   "username and password must be provided together",
+			})
+		}
 	case notificationKindHTTP:
 		if !validEndpointHTTPMethods[n.method] {
 			failures = append(failures, validationErr{
@@ -1783,26 +1950,36 @@ func (n *notificationEndpoint) valid() []validationErr {
 }
 
 const (
+	fieldNotificationRuleBodyTemplate    = "bodyTemplate"
 	fieldNotificationRuleChannel         = "channel"
 	fieldNotificationRuleCurrentLevel    = "currentLevel"
 	fieldNotificationRuleEndpointName    = "endpointName"
 	fieldNotificationRuleMessageTemplate = "messageTemplate"
 	fieldNotificationRulePreviousLevel   = "previousLevel"
+	fieldNotificationRulePriority        = "priority"
 	fieldNotificationRuleStatusRules     = "statusRules"
+	fieldNotificationRuleSubjectTemplate = "subjectTemplate"
 	fieldNotificationRuleTagRules        = "tagRules"
+	fieldNotificationRuleTitleTemplate   = "titleTemplate"
+	fieldNotificationRuleTo              = "to"
 )
 
 type notificationRule struct {
 	identity
 
-	channel     string
-	description string
-	every       time.Duration
-	msgTemplate string
-	offset      time.Duration
-	status      string
-	statusRules []struct{ curLvl, prevLvl string }
-	tagRules    []struct{ k, v, op string }
+	bodyTemplate    string
+	channel         string
+	description     string
+	every           time.Duration
+	msgTemplate     string
+	offset          time.Duration
+	priority        string
+	status          string
+	statusRules     []struct{ curLvl, prevLvl string }
+	subjectTemplate string
+	tagRules        []struct{ k, v, op string }
+	titleTemplate   string
+	to              string
 
 	associatedEndpoint *notificationEndpoint
 	endpointName       *references
@@ -1906,6 +2083,25 @@ func (r *notificationRule) toInfluxRule() influxdb.NotificationRule {
 			Channel:         r.channel,
 			MessageTemplate: r.msgTemplate,
 		}
+	case notificationKindOpsgenie:
+		return &rule.Opsgenie{
+			Base:            base,
+			MessageTemplate: r.msgTemplate,
+			Priority:        r.priority,
+		}
+	case notificationKindSMTP:
+		return &rule.SMTP{
+			Base:            base,
+			To:              r.to,
+			SubjectTemplate: r.subjectTemplate,
+			BodyTemplate:    r.bodyTemplate,
+		}
+	case notificationKindTeams:
+		return &rule.Teams{
+			Base:            base,
+			TitleTemplate:   r.titleTemplate,
+			MessageTemplate: r.msgTemplate,
+		}
 	}
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/v2/notification"
 	icheck "github.com/influxdata/influxdb/v2/notification/check"
 	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/notification/rule"
 	"github.com/influxdata/influxdb/v2/task/taskmodel"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -605,11 +606,90 @@ spec:
 			})
 		})
 
+		t.Run("with anomaly check should be successful", func(t *testing.T) {
+			template := newParsedTemplate(t, FromString(`apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-anomaly
+spec:
+  every: 5m
+  method: Seasonal
+  baseline: 1h
+  season: 24h
+  query:  >
+    from(bucket: "rucket_1") |> range(start: -1h) |> filter(fn: (r) => r._field == "usage_idle")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  deviations:
+    - level: warn
+      stdDevs: 2
+    - level: CRIT
+      stdDevs: 3.5
+`), EncodingYAML)
+
+			sum := template.Summary()
+			require.Len(t, sum.Checks, 1)
+
+			actual := sum.Checks[0]
+			assert.Equal(t, KindCheckAnomaly, actual.Kind)
+			anomalyCheck, ok := actual.Check.(*icheck.Anomaly)
+			require.Truef(t, ok, "got: %#v", actual)
+
+			assert.Equal(t, icheck.AnomalyMethodSeasonal, anomalyCheck.Method)
+			assert.Equal(t, mustDuration(t, time.Hour), anomalyCheck.Baseline)
+			assert.Equal(t, mustDuration(t, 24*time.Hour), anomalyCheck.Season)
+			expectedDeviations := []icheck.AnomalyDeviation{
+				{Level: notification.Warn, StdDevs: 2},
+				{Level: notification.Critical, StdDevs: 3.5},
+			}
+			assert.Equal(t, expectedDeviations, anomalyCheck.Deviations)
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
 				resErr testTemplateResourceError
 			}{
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly missing method, baseline and deviations",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckBaseline, fieldCheckDeviations, fieldCheckMethod},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 5m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+`,
+					},
+				},
+				{
+					kind: KindCheckAnomaly,
+					resErr: testTemplateResourceError{
+						name:           "anomaly invalid deviation",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldCheckStdDevs, fieldLevel},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: CheckAnomaly
+metadata:
+  name: check-1
+spec:
+  every: 5m
+  method: stddev
+  baseline: 1h
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  deviations:
+    - level: RANDO
+      stdDevs: 0
+`,
+					},
+				},
 				{
 					kind: KindCheckDeadman,
 					resErr: testTemplateResourceError{
@@ -2847,11 +2927,140 @@ spec:
 			})
 		})
 
+		t.Run("with opsgenie, smtp and teams endpoints should be successful", func(t *testing.T) {
+			template := newParsedTemplate(t, FromString(`apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie-notification-endpoint
+spec:
+  apiKey: secret api key
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSMTP
+metadata:
+  name: smtp-notification-endpoint
+spec:
+  host: smtp.example.com
+  port: 465
+  tls: true
+  from: influxdb@example.com
+  username: secret username
+  password: secret password
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams-notification-endpoint
+spec:
+  url: https://outlook.office.com/webhook/123
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: smtp-rule
+spec:
+  endpointName: smtp-notification-endpoint
+  every: 10m
+  to: ops@example.com
+  subjectTemplate: ${r._level} alert
+  bodyTemplate: ${r._message}
+  statusRules:
+    - currentLevel: CRIT
+`), EncodingYAML)
+
+			expected := map[string]influxdb.NotificationEndpoint{
+				"opsgenie-notification-endpoint": &endpoint.Opsgenie{
+					Base: endpoint.Base{
+						Name:   "opsgenie-notification-endpoint",
+						Status: taskmodel.TaskStatusActive,
+					},
+					APIKey: influxdb.SecretField{Value: strPtr("secret api key")},
+				},
+				"smtp-notification-endpoint": &endpoint.SMTP{
+					Base: endpoint.Base{
+						Name:   "smtp-notification-endpoint",
+						Status: taskmodel.TaskStatusActive,
+					},
+					Host:     "smtp.example.com",
+					Port:     465,
+					TLS:      true,
+					From:     "influxdb@example.com",
+					Username: influxdb.SecretField{Value: strPtr("secret username")},
+					Password: influxdb.SecretField{Value: strPtr("secret password")},
+				},
+				"teams-notification-endpoint": &endpoint.Teams{
+					Base: endpoint.Base{
+						Name:   "teams-notification-endpoint",
+						Status: taskmodel.TaskStatusActive,
+					},
+					URL: "https://outlook.office.com/webhook/123",
+				},
+			}
+
+			sum := template.Summary()
+			require.Len(t, sum.NotificationEndpoints, len(expected))
+			for _, actual := range sum.NotificationEndpoints {
+				assert.Equal(t, expected[actual.MetaName], actual.NotificationEndpoint, actual.MetaName)
+			}
+
+			require.Len(t, sum.NotificationRules, 1)
+			assert.Equal(t, endpoint.SMTPType, sum.NotificationRules[0].EndpointType)
+
+			actualRule := template.mNotificationRules["smtp-rule"].toInfluxRule()
+			require.IsType(t, &rule.SMTP{}, actualRule)
+			smtpRule := actualRule.(*rule.SMTP)
+			assert.Equal(t, "ops@example.com", smtpRule.To)
+			assert.Equal(t, "${r._level} alert", smtpRule.SubjectTemplate)
+			assert.Equal(t, "${r._message}", smtpRule.BodyTemplate)
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
 				resErr testTemplateResourceError
 			}{
+				{
+					kind: KindNotificationEndpointOpsgenie,
+					resErr: testTemplateResourceError{
+						name:           "missing opsgenie api key",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointAPIKey},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointOpsgenie
+metadata:
+  name: opsgenie-notification-endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointSMTP,
+					resErr: testTemplateResourceError{
+						name:           "missing smtp host, port and from",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointFrom, fieldNotificationEndpointHost, fieldNotificationEndpointPort},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSMTP
+metadata:
+  name: smtp-notification-endpoint
+spec:
+`,
+					},
+				},
+				{
+					kind: KindNotificationEndpointTeams,
+					resErr: testTemplateResourceError{
+						name:           "missing teams url",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldNotificationEndpointURL},
+						templateStr: `apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointTeams
+metadata:
+  name: teams-notification-endpoint
+spec:
+`,
+					},
+				},
 				{
 					kind: KindNotificationEndpointSlack,
 					resErr: testTemplateResourceError{
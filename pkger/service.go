@@ -1457,11 +1457,14 @@ func ApplyWithResourceSkip(action ActionSkipResource) ApplyOptFn {
 			opt.ResourcesToSkip = make(map[ActionSkipResource]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSMTP,
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.ResourcesToSkip[action] = true
@@ -1475,11 +1478,14 @@ func ApplyWithKindSkip(action ActionSkipKind) ApplyOptFn {
 			opt.KindsToSkip = make(map[Kind]bool)
 		}
 		switch action.Kind {
-		case KindCheckDeadman, KindCheckThreshold:
+		case KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 			action.Kind = KindCheck
 		case KindNotificationEndpointHTTP,
+			KindNotificationEndpointOpsgenie,
 			KindNotificationEndpointPagerDuty,
-			KindNotificationEndpointSlack:
+			KindNotificationEndpointSMTP,
+			KindNotificationEndpointSlack,
+			KindNotificationEndpointTeams:
 			action.Kind = KindNotificationEndpoint
 		}
 		opt.KindsToSkip[action.Kind] = true
@@ -2392,6 +2398,12 @@ func (s *Service) rollbackNotificationRules(ctx context.Context, userID platform
 				rr.EndpointID = endpointID
 			case *rule.Slack:
 				rr.EndpointID = endpointID
+			case *rule.Opsgenie:
+				rr.EndpointID = endpointID
+			case *rule.SMTP:
+				rr.EndpointID = endpointID
+			case *rule.Teams:
+				rr.EndpointID = endpointID
 			}
 			return r.existing
 		}
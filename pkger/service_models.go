@@ -510,7 +510,7 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 	case KindBucket:
 		v, ok := s.mBuckets[metaName]
 		return v, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		v, ok := s.mChecks[metaName]
 		return v, ok
 	case KindDashboard:
@@ -521,8 +521,11 @@ func (s *stateCoordinator) get(k Kind, metaName string) (interface{}, bool) {
 		return v, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		v, ok := s.mEndpoints[metaName]
 		return v, ok
 	case KindNotificationRule:
@@ -584,7 +587,7 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 			parserBkt:   &bucket{identity: newIdentity},
 			stateStatus: StateStatusRemove,
 		}
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		s.mChecks[metaName] = &stateCheck{
 			id:          id,
 			parserCheck: &check{identity: newIdentity},
@@ -604,8 +607,11 @@ func (s *stateCoordinator) addObjectForRemoval(k Kind, metaName string, id platf
 		}
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		s.mEndpoints[metaName] = &stateEndpoint{
 			id:             id,
 			parserEndpoint: &notificationEndpoint{identity: newIdentity},
@@ -646,7 +652,7 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 			r.id = id
 			r.stateStatus = StateStatusExists
 		}, ok
-	case KindCheck, KindCheckDeadman, KindCheckThreshold:
+	case KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold:
 		r, ok := s.mChecks[metaName]
 		return func(id platform.ID) {
 			r.id = id
@@ -666,8 +672,11 @@ func (s *stateCoordinator) getObjectIDSetter(k Kind, metaName string) (func(plat
 		}, ok
 	case KindNotificationEndpoint,
 		KindNotificationEndpointHTTP,
+		KindNotificationEndpointOpsgenie,
 		KindNotificationEndpointPagerDuty,
-		KindNotificationEndpointSlack:
+		KindNotificationEndpointSMTP,
+		KindNotificationEndpointSlack,
+		KindNotificationEndpointTeams:
 		r, ok := s.mEndpoints[metaName]
 		return func(id platform.ID) {
 			r.id = id
@@ -1222,6 +1231,11 @@ func (r *stateRule) diffRule() DiffNotificationRule {
 			TagRules:        toSummaryTagRules(r.parserRule.tagRules),
 		},
 	}
+	if r.parserRule.associatedEndpoint != nil && r.parserRule.associatedEndpoint.kind == notificationKindSMTP {
+		sum.New.To = r.parserRule.to
+		sum.New.SubjectTemplate = r.parserRule.subjectTemplate
+		sum.New.BodyTemplate = r.parserRule.bodyTemplate
+	}
 
 	if r.existing == nil {
 		return sum
@@ -1267,6 +1281,17 @@ func (r *stateRule) diffRule() DiffNotificationRule {
 	case *rule.PagerDuty:
 		assignBase(p.Base)
 		sum.Old.MessageTemplate = p.MessageTemplate
+	case *rule.Opsgenie:
+		assignBase(p.Base)
+		sum.Old.MessageTemplate = p.MessageTemplate
+	case *rule.SMTP:
+		assignBase(p.Base)
+		sum.Old.To = p.To
+		sum.Old.SubjectTemplate = p.SubjectTemplate
+		sum.Old.BodyTemplate = p.BodyTemplate
+	case *rule.Teams:
+		assignBase(p.Base)
+		sum.Old.MessageTemplate = p.MessageTemplate
 	}
 
 	return sum
@@ -1336,6 +1361,12 @@ func (r *stateRule) toInfluxRule() influxdb.NotificationRule {
 		e.EndpointID = r.associatedEndpoint.ID()
 	case *rule.Slack:
 		e.EndpointID = r.associatedEndpoint.ID()
+	case *rule.Opsgenie:
+		e.EndpointID = r.associatedEndpoint.ID()
+	case *rule.SMTP:
+		e.EndpointID = r.associatedEndpoint.ID()
+	case *rule.Teams:
+		e.EndpointID = r.associatedEndpoint.ID()
 	}
 
 	return influxRule
@@ -290,7 +290,7 @@ func TestService(t *testing.T) {
 			t.Run("with actions applied", func(t *testing.T) {
 				testDryRunActions(t, dryRunTestFields{
 					path:  "testdata/checks.yml",
-					kinds: []Kind{KindCheck, KindCheckDeadman, KindCheckThreshold},
+					kinds: []Kind{KindCheck, KindCheckAnomaly, KindCheckDeadman, KindCheckThreshold},
 					skipResources: []ActionSkipResource{
 						{
 							Kind:     KindCheck,
@@ -601,6 +601,34 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("smtp rule diff", func(t *testing.T) {
+				r := &stateRule{
+					stateStatus: StateStatusExists,
+					parserRule: &notificationRule{
+						identity:           identity{name: &references{val: "smtp-rule"}},
+						to:                 "ops@example.com",
+						subjectTemplate:    "${r._level} alert",
+						bodyTemplate:       "${r._message}",
+						associatedEndpoint: &notificationEndpoint{kind: notificationKindSMTP},
+					},
+					existing: &rule.SMTP{
+						Base:            rule.Base{ID: 1, Name: "smtp-rule"},
+						To:              "oncall@example.com",
+						SubjectTemplate: "alert",
+						BodyTemplate:    "${r._message}",
+					},
+				}
+
+				diff := r.diffRule()
+				assert.Equal(t, "ops@example.com", diff.New.To)
+				assert.Equal(t, "${r._level} alert", diff.New.SubjectTemplate)
+				assert.Equal(t, "${r._message}", diff.New.BodyTemplate)
+				require.NotNil(t, diff.Old)
+				assert.Equal(t, "oncall@example.com", diff.Old.To)
+				assert.Equal(t, "alert", diff.Old.SubjectTemplate)
+				assert.Equal(t, "${r._message}", diff.Old.BodyTemplate)
+			})
+
 			t.Run("with actions applied", func(t *testing.T) {
 				testDryRunActions(t, dryRunTestFields{
 					path:  "testdata/notification_rule.yml",
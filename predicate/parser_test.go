@@ -8,7 +8,6 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/v2"
-	influxtesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxql"
 )
 
@@ -82,7 +81,7 @@ func TestParseNode(t *testing.T) {
 	}
 	for _, c := range cases {
 		node, err := Parse(c.str)
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(node, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -159,7 +158,7 @@ func TestParseTagRule(t *testing.T) {
 		p := new(parser)
 		p.sc = influxql.NewScanner(strings.NewReader(c.str))
 		tr, err := p.parseTagRuleNode()
-		influxtesting.ErrorsEqual(t, err, c.err)
+		errorsEqual(t, err, c.err)
 		if c.err == nil {
 			if diff := cmp.Diff(tr, c.node); diff != "" {
 				t.Errorf("tag rule mismatch:\n  %s", diff)
@@ -167,3 +166,31 @@ func TestParseTagRule(t *testing.T) {
 		}
 	}
 }
+
+// errorsEqual checks errors like ErrorsEqual of the influxdb testing package,
+// which can't be imported by these tests, as it depends on the v1 coordinator
+// importing this package.
+func errorsEqual(t *testing.T, actual, expected error) {
+	t.Helper()
+	if expected == nil && actual == nil {
+		return
+	}
+
+	if expected == nil && actual != nil {
+		t.Errorf("unexpected error %s", actual.Error())
+	}
+
+	if expected != nil && actual == nil {
+		t.Errorf("expected error %s but received nil", expected.Error())
+	}
+
+	if errors.ErrorCode(expected) != errors.ErrorCode(actual) {
+		t.Logf("\nexpected: %v\nactual: %v\n\n", expected, actual)
+		t.Errorf("expected error code %q but received %q", errors.ErrorCode(expected), errors.ErrorCode(actual))
+	}
+
+	if errors.ErrorMessage(expected) != errors.ErrorMessage(actual) {
+		t.Logf("\nexpected: %v\nactual: %v\n\n", expected, actual)
+		t.Errorf("expected error message %q but received %q", errors.ErrorMessage(expected), errors.ErrorMessage(actual))
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
-	influxtesting "github.com/influxdata/influxdb/v2/testing"
 )
 
 func TestDataTypeConversion(t *testing.T) {
@@ -340,7 +339,7 @@ func TestDataTypeConversion(t *testing.T) {
 	for _, c := range cases {
 		if c.node != nil {
 			dataType, err := c.node.ToDataType()
-			influxtesting.ErrorsEqual(t, err, c.err)
+			errorsEqual(t, err, c.err)
 			if c.err != nil {
 				continue
 			}
@@ -0,0 +1,235 @@
+package remote
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+const (
+	// MetricNameLabel is the label holding the name of a metric.
+	MetricNameLabel = "__name__"
+
+	// FieldName is the field holding the value of a sample when the metric
+	// name is used as the measurement.
+	FieldName = "value"
+
+	measurementKey = "_measurement"
+	fieldKey       = "_field"
+)
+
+// Mapping describes how Prometheus series are stored as points.
+//
+// When Measurement is empty, the name of a metric is the measurement of its
+// points and samples are stored in the "value" field. Otherwise all points
+// are stored in Measurement and the name of a metric is their field.
+// The remaining labels of a series are the tags of its points.
+type Mapping struct {
+	Measurement string
+}
+
+// WriteRequestToPoints converts the samples of req to points. Samples that
+// can't be stored, such as NaN or infinite values, are skipped and counted
+// in dropped.
+func (m Mapping) WriteRequestToPoints(req *WriteRequest) (points []models.Point, dropped int, err error) {
+	for _, ts := range req.Timeseries {
+		var name string
+		tags := make(map[string]string, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == MetricNameLabel {
+				name = l.Value
+				continue
+			}
+			if l.Value != "" {
+				tags[l.Name] = l.Value
+			}
+		}
+		if name == "" {
+			return nil, 0, fmt.Errorf("series is missing the %s label", MetricNameLabel)
+		}
+
+		measurement, field := name, FieldName
+		if m.Measurement != "" {
+			measurement, field = m.Measurement, name
+		}
+
+		mtags := models.NewTags(tags)
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				dropped++
+				continue
+			}
+			t := time.Unix(0, s.Timestamp*int64(time.Millisecond))
+			pt, err := models.NewPoint(measurement, mtags, models.Fields{field: s.Value}, t)
+			if err != nil {
+				return nil, 0, err
+			}
+			points = append(points, pt)
+		}
+	}
+	return points, dropped, nil
+}
+
+// QueryToReadFilterRequest translates q to a storage read request. The label
+// matchers of q become the predicate of the request. The caller is expected
+// to set the source of the request.
+func (m Mapping) QueryToReadFilterRequest(q *Query) (*datatypes.ReadFilterRequest, error) {
+	pred, err := m.queryToPredicate(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var req datatypes.ReadFilterRequest
+	req.Predicate = pred
+	req.Range.Start = q.StartTimestampMs * int64(time.Millisecond)
+	// The end of a query is inclusive, the end of a storage range is not.
+	req.Range.End = (q.EndTimestampMs + 1) * int64(time.Millisecond)
+	return &req, nil
+}
+
+func (m Mapping) queryToPredicate(q *Query) (*datatypes.Predicate, error) {
+	var nodes []*datatypes.Node
+	if m.Measurement != "" {
+		nodes = append(nodes, tagComparison(measurementKey, datatypes.ComparisonEqual, m.Measurement))
+	} else {
+		nodes = append(nodes, tagComparison(fieldKey, datatypes.ComparisonEqual, FieldName))
+	}
+
+	for _, lm := range q.Matchers {
+		key := lm.Name
+		if key == MetricNameLabel {
+			key = measurementKey
+			if m.Measurement != "" {
+				key = fieldKey
+			}
+		}
+
+		var node *datatypes.Node
+		switch lm.Type {
+		case MatchEqual:
+			node = tagComparison(key, datatypes.ComparisonEqual, lm.Value)
+		case MatchNotEqual:
+			node = tagComparison(key, datatypes.ComparisonNotEqual, lm.Value)
+		case MatchRegexp:
+			node = regexComparison(key, datatypes.ComparisonRegex, lm.Value)
+		case MatchNotRegexp:
+			node = regexComparison(key, datatypes.ComparisonNotRegex, lm.Value)
+		default:
+			return nil, fmt.Errorf("unknown label matcher type %d", lm.Type)
+		}
+		nodes = append(nodes, node)
+	}
+
+	root := nodes[0]
+	if len(nodes) > 1 {
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: nodes,
+		}
+	}
+	return &datatypes.Predicate{Root: root}, nil
+}
+
+func tagComparison(key string, cmp datatypes.Node_Comparison, value string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: cmp},
+		Children: []*datatypes.Node{
+			{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+			{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: value}},
+		},
+	}
+}
+
+// regexComparison anchors value as Prometheus matches regular expressions
+// against the whole label value.
+func regexComparison(key string, cmp datatypes.Node_Comparison, value string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: cmp},
+		Children: []*datatypes.Node{
+			{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+			{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_RegexValue{RegexValue: "^(?:" + value + ")$"}},
+		},
+	}
+}
+
+// ResultSetToTimeSeries reads the series of rs as Prometheus time series.
+// Series of string or boolean fields are skipped.
+func (m Mapping) ResultSetToTimeSeries(rs reads.ResultSet) ([]*TimeSeries, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	defer rs.Close()
+
+	var series []*TimeSeries
+	for rs.Next() {
+		var (
+			name   string
+			labels []*Label
+		)
+		for _, tag := range rs.Tags() {
+			switch k := string(tag.Key); k {
+			case measurementKey:
+				if m.Measurement == "" {
+					name = string(tag.Value)
+				}
+			case fieldKey:
+				if m.Measurement != "" {
+					name = string(tag.Value)
+				}
+			default:
+				labels = append(labels, &Label{Name: k, Value: string(tag.Value)})
+			}
+		}
+		labels = append(labels, &Label{Name: MetricNameLabel, Value: name})
+		sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+		samples := cursorToSamples(rs.Cursor())
+		if len(samples) == 0 {
+			continue
+		}
+		series = append(series, &TimeSeries{Labels: labels, Samples: samples})
+	}
+	return series, rs.Err()
+}
+
+func cursorToSamples(cur cursors.Cursor) []*Sample {
+	if cur == nil {
+		return nil
+	}
+	defer cur.Close()
+
+	var samples []*Sample
+	appendSample := func(ts int64, v float64) {
+		samples = append(samples, &Sample{Value: v, Timestamp: ts / int64(time.Millisecond)})
+	}
+	switch c := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				appendSample(ts, a.Values[i])
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				appendSample(ts, float64(a.Values[i]))
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := c.Next(); a.Len() > 0; a = c.Next() {
+			for i, ts := range a.Timestamps {
+				appendSample(ts, float64(a.Values[i]))
+			}
+		}
+	}
+	return samples
+}
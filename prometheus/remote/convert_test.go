@@ -0,0 +1,223 @@
+package remote_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/prometheus/remote"
+	"github.com/influxdata/influxdb/v2/storage/reads"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	want := &remote.ReadRequest{
+		Queries: []*remote.Query{{
+			StartTimestampMs: 1000,
+			EndTimestampMs:   2000,
+			Matchers: []*remote.LabelMatcher{
+				{Type: remote.MatchRegexp, Name: "__name__", Value: "cpu_.*"},
+				{Type: remote.MatchNotEqual, Name: "host", Value: "db1"},
+			},
+		}},
+		AcceptedResponseTypes: []int32{0},
+	}
+
+	var buf bytes.Buffer
+	if err := remote.Encode(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	var got remote.ReadRequest
+	if err := remote.Decode(&buf, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, want) {
+		t.Fatalf("unexpected request after round trip\nwant: %v\ngot:  %v", want, &got)
+	}
+
+	if err := remote.Decode(bytes.NewBufferString("not snappy"), &got); err == nil {
+		t.Fatal("expected an error decoding an invalid payload")
+	}
+}
+
+func TestMapping_WriteRequestToPoints(t *testing.T) {
+	req := &remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{
+			{
+				Labels: []*remote.Label{
+					{Name: "__name__", Value: "cpu_seconds"},
+					{Name: "host", Value: "db1"},
+					{Name: "empty", Value: ""},
+				},
+				Samples: []*remote.Sample{
+					{Value: 1.5, Timestamp: 1000},
+					{Value: math.NaN(), Timestamp: 2000},
+					{Value: 2.5, Timestamp: 3000},
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		mapping remote.Mapping
+		want    string
+	}{
+		{
+			name: "metric name as measurement",
+			want: "cpu_seconds,host=db1 value=1.5 1000000000\ncpu_seconds,host=db1 value=2.5 3000000000\n",
+		},
+		{
+			name:    "metric name as field",
+			mapping: remote.Mapping{Measurement: "prometheus"},
+			want:    "prometheus,host=db1 cpu_seconds=1.5 1000000000\nprometheus,host=db1 cpu_seconds=2.5 3000000000\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pts, dropped, err := tt.mapping.WriteRequestToPoints(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dropped != 1 {
+				t.Fatalf("expected the NaN sample to be dropped, dropped %d", dropped)
+			}
+			var got string
+			for _, pt := range pts {
+				got += pt.String() + "\n"
+			}
+			if got != tt.want {
+				t.Fatalf("unexpected points\nwant: %s\ngot:  %s", tt.want, got)
+			}
+		})
+	}
+
+	_, _, err := remote.Mapping{}.WriteRequestToPoints(&remote.WriteRequest{
+		Timeseries: []*remote.TimeSeries{{Labels: []*remote.Label{{Name: "host", Value: "db1"}}}},
+	})
+	if err == nil {
+		t.Fatal("expected an error for a series without a metric name")
+	}
+}
+
+func TestMapping_QueryToReadFilterRequest(t *testing.T) {
+	q := &remote.Query{
+		StartTimestampMs: 1000,
+		EndTimestampMs:   2000,
+		Matchers: []*remote.LabelMatcher{
+			{Type: remote.MatchEqual, Name: "__name__", Value: "cpu_seconds"},
+			{Type: remote.MatchNotEqual, Name: "host", Value: "db1"},
+			{Type: remote.MatchRegexp, Name: "region", Value: "us-.*"},
+			{Type: remote.MatchNotRegexp, Name: "env", Value: "dev|test"},
+		},
+	}
+
+	for _, tt := range []struct {
+		name    string
+		mapping remote.Mapping
+		want    string
+	}{
+		{
+			name: "metric name as measurement",
+			want: `'_field' = "value" AND '_measurement' = "cpu_seconds" AND 'host' != "db1" AND 'region' =~ /^(?:us-.*)$/ AND 'env' !~ /^(?:dev|test)$/`,
+		},
+		{
+			name:    "metric name as field",
+			mapping: remote.Mapping{Measurement: "prometheus"},
+			want:    `'_measurement' = "prometheus" AND '_field' = "cpu_seconds" AND 'host' != "db1" AND 'region' =~ /^(?:us-.*)$/ AND 'env' !~ /^(?:dev|test)$/`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.mapping.QueryToReadFilterRequest(q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reads.PredicateToExprString(req.Predicate); got != tt.want {
+				t.Fatalf("unexpected predicate\nwant: %s\ngot:  %s", tt.want, got)
+			}
+			if req.Range.Start != int64(time.Second) || req.Range.End != int64(2*time.Second+time.Millisecond) {
+				t.Fatalf("unexpected range %v", req.Range)
+			}
+		})
+	}
+}
+
+func TestMapping_ResultSetToTimeSeries(t *testing.T) {
+	rs := &sliceResultSet{series: []series{
+		{
+			tags:       models.ParseTags([]byte("m,_field=value,_measurement=cpu_seconds,host=db1")),
+			timestamps: []int64{int64(time.Second), int64(2 * time.Second)},
+			values:     []float64{1.5, 2.5},
+		},
+		{
+			tags: models.ParseTags([]byte("m,_field=value,_measurement=cpu_seconds,host=db2")),
+		},
+	}}
+
+	got, err := remote.Mapping{}.ResultSetToTimeSeries(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*remote.TimeSeries{{
+		Labels: []*remote.Label{
+			{Name: "__name__", Value: "cpu_seconds"},
+			{Name: "host", Value: "db1"},
+		},
+		Samples: []*remote.Sample{
+			{Value: 1.5, Timestamp: 1000},
+			{Value: 2.5, Timestamp: 2000},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected time series\nwant: %v\ngot:  %v", want, got)
+	}
+	if !rs.closed {
+		t.Fatal("expected the result set to be closed")
+	}
+}
+
+type series struct {
+	tags       models.Tags
+	timestamps []int64
+	values     []float64
+}
+
+// sliceResultSet is a reads.ResultSet of float series.
+type sliceResultSet struct {
+	series []series
+	cur    series
+	closed bool
+}
+
+func (rs *sliceResultSet) Next() bool {
+	if len(rs.series) == 0 {
+		return false
+	}
+	rs.cur, rs.series = rs.series[0], rs.series[1:]
+	return true
+}
+
+func (rs *sliceResultSet) Cursor() cursors.Cursor {
+	return &floatArrayCursor{a: &cursors.FloatArray{Timestamps: rs.cur.timestamps, Values: rs.cur.values}}
+}
+
+func (rs *sliceResultSet) Tags() models.Tags          { return rs.cur.tags }
+func (rs *sliceResultSet) Close()                     { rs.closed = true }
+func (rs *sliceResultSet) Err() error                 { return nil }
+func (rs *sliceResultSet) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type floatArrayCursor struct {
+	a *cursors.FloatArray
+}
+
+func (c *floatArrayCursor) Next() *cursors.FloatArray {
+	a := c.a
+	c.a = cursors.NewFloatArrayLen(0)
+	return a
+}
+
+func (c *floatArrayCursor) Close()                     {}
+func (c *floatArrayCursor) Err() error                 { return nil }
+func (c *floatArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
@@ -38,6 +38,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -60,6 +61,7 @@ type Controller struct {
 	abortOnce  sync.Once
 	abort      chan struct{}
 	memory     *memoryManager
+	quotas     *orgQuotas
 
 	metrics   *controllerMetrics
 	labelKeys []string
@@ -115,6 +117,15 @@ type Config struct {
 	MetricLabelKeys []string
 
 	ExecutorDependencies []flux.Dependency
+
+	// Registry, if set, records running queries so they can be listed
+	// and cancelled alongside queries written in other languages.
+	Registry *registry.Registry
+
+	// OrganizationService, if set, is used to look up the query limits of
+	// the organization running each query. These are enforced in addition
+	// to the limits above.
+	OrganizationService OrganizationService
 }
 
 // complete will fill in the defaults, validate the configuration, and
@@ -212,6 +223,9 @@ func New(config Config, logger *zap.Logger) (*Controller, error) {
 		labelKeys:    metricLabelKeys,
 		dependencies: c.ExecutorDependencies,
 	}
+	if c.OrganizationService != nil {
+		ctrl.quotas = newOrgQuotas(c.OrganizationService, logger)
+	}
 	if c.ConcurrencyQuota != 0 {
 		quota := int(c.ConcurrencyQuota)
 		ctrl.wg.Add(quota)
@@ -242,17 +256,42 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	if feature.QueryTracing().Enabled(ctx) {
 		ctx = flux.WithQueryTracingEnabled(ctx)
 	}
-	q, err := c.query(ctx, req.Compiler)
-	if err != nil {
-		return q, err
+	info := registry.QueryInfo{
+		Language: registry.LanguageFlux,
+		OrgID:    req.OrganizationID,
+		Query:    queryText(req.Compiler),
+	}
+	if req.Authorization != nil {
+		info.UserID = req.Authorization.UserID
+	}
+	return c.query(ctx, req.Compiler, info)
+}
+
+// queryText returns the source text of the query compiled by compiler, if
+// it is known.
+func queryText(compiler flux.Compiler) string {
+	switch c := compiler.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case *lang.FluxCompiler:
+		return c.Query
+	default:
+		return ""
 	}
+}
 
-	return q, nil
+// registeredQuery adapts a Query to the registry.RunningQuery interface.
+type registeredQuery struct {
+	q *Query
 }
 
+func (r registeredQuery) Cancel()            { r.q.Cancel() }
+func (r registeredQuery) MemoryBytes() int64 { return r.q.allocatedMemory() }
+func (r registeredQuery) Status() string     { return r.q.State().String() }
+
 // query submits a query for execution returning immediately.
 // Done must be called on any returned Query objects.
-func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Query, error) {
+func (c *Controller) query(ctx context.Context, compiler flux.Compiler, info registry.QueryInfo) (flux.Query, error) {
 	q, err := c.createQuery(ctx, compiler.CompilerType())
 	if err != nil {
 		return nil, handleFluxError(err)
@@ -264,6 +303,11 @@ func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Qu
 		c.countQueryRequest(q, labelCompileError)
 		return nil, q.Err()
 	}
+	// Register the query before it is enqueued, as it may finish as soon as
+	// it is.
+	if c.config.Registry != nil {
+		_, q.unregister = c.config.Registry.Register(info, registeredQuery{q: q})
+	}
 	if err := c.enqueueQuery(q); err != nil {
 		q.setErr(err)
 		c.finish(q)
@@ -398,6 +442,18 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
+	if c.quotas != nil {
+		if ok, err := c.quotas.admit(q); !ok {
+			// The query is either rejected or it will be dispatched
+			// once another query of its organization finishes.
+			return err
+		}
+	}
+	return c.dispatchQuery(q)
+}
+
+// dispatchQuery hands a queued query to the executors.
+func (c *Controller) dispatchQuery(q *Query) error {
 	if c.queryQueue == nil {
 		// unlimited queries case
 		c.queriesMu.RLock()
@@ -475,7 +531,9 @@ func (c *Controller) executeQuery(q *Query) {
 		return
 	}
 
+	q.stateMu.Lock()
 	q.c.createAllocator(q)
+	q.stateMu.Unlock()
 	// Record unused memory before start.
 	q.recordUnusedMemory()
 	exec, err := q.program.Start(ctx, q.alloc)
@@ -496,6 +554,11 @@ func (c *Controller) waitForQuery(q *Query) {
 }
 
 func (c *Controller) finish(q *Query) {
+	if q.unregister != nil {
+		q.unregister()
+	}
+	c.releaseQuota(q)
+
 	c.queriesMu.Lock()
 	delete(c.queries, q.id)
 	if len(c.queries) == 0 && c.shutdown {
@@ -504,6 +567,22 @@ func (c *Controller) finish(q *Query) {
 	c.queriesMu.Unlock()
 }
 
+// releaseQuota frees the execution slot q holds within the limits of its
+// organization and dispatches the next query waiting for it.
+func (c *Controller) releaseQuota(q *Query) {
+	if c.quotas == nil {
+		return
+	}
+	for next := c.quotas.release(q); next != nil; next = c.quotas.release(next) {
+		err := c.dispatchQuery(next)
+		if err == nil {
+			return
+		}
+		// The query will never execute so it must not keep the slot.
+		next.setErr(err)
+	}
+}
+
 // Queries reports the active queries.
 func (c *Controller) Queries() []*Query {
 	c.queriesMu.RLock()
@@ -610,6 +689,14 @@ type Query struct {
 
 	memoryManager *queryMemoryManager
 	alloc         *memory.Allocator
+
+	// unregister removes the query from the registry, if it was registered.
+	unregister func()
+
+	// quota holds the limits of the organization running the query and
+	// holdsSlot whether the query occupies one of its execution slots.
+	quota     *orgQuota
+	holdsSlot bool
 }
 
 func (q *Query) ProfilerResults() (flux.ResultIterator, error) {
@@ -726,6 +813,16 @@ func (q *Query) Done() {
 	<-q.doneCh
 }
 
+// allocatedMemory reports the memory currently allocated by the query.
+func (q *Query) allocatedMemory() int64 {
+	q.stateMu.RLock()
+	defer q.stateMu.RUnlock()
+	if q.alloc == nil {
+		return 0
+	}
+	return q.alloc.Allocated()
+}
+
 // Statistics reports the statistics for the query.
 //
 // This method must be called after Done. It will block until
@@ -856,7 +953,27 @@ func (q *Query) Err() error {
 	q.stateMu.Lock()
 	err := q.err
 	q.stateMu.Unlock()
-	return handleFluxError(err)
+	return q.quotaError(handleFluxError(err))
+}
+
+// quotaError returns err as the memory quota of the organization being
+// exceeded if that is why the query failed, so clients are told to retry
+// later rather than that their query failed.
+func (q *Query) quotaError(err error) error {
+	if err == nil {
+		return nil
+	}
+	q.stateMu.RLock()
+	mm := q.memoryManager
+	q.stateMu.RUnlock()
+	if mm == nil || atomic.LoadInt32(&mm.orgQuotaExceeded) == 0 {
+		return err
+	}
+	return &errors2.Error{
+		Code: errOrgMemoryQuotaExceeded.Code,
+		Msg:  errOrgMemoryQuotaExceeded.Msg,
+		Err:  err,
+	}
 }
 
 // setErr marks this query with an error. If the query was
@@ -989,7 +1106,7 @@ type errorCollectingTableIterator struct {
 func (ti *errorCollectingTableIterator) Do(f func(t flux.Table) error) error {
 	err := ti.TableIterator.Do(f)
 	if err != nil {
-		err = handleFluxError(err)
+		err = ti.q.quotaError(handleFluxError(err))
 		ti.q.addRuntimeError(err)
 	}
 	return err
@@ -7,6 +7,7 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,11 +22,15 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/influxdb/v2"
 	_ "github.com/influxdata/influxdb/v2/fluxinit/static"
 	"github.com/influxdata/influxdb/v2/kit/feature"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 	pmock "github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/prometheus/client_golang/prometheus"
@@ -55,7 +60,7 @@ var (
 		ConcurrencyQuota:         1,
 		QueueSize:                1,
 	}
-	bothConfigs          = map[string]control.Config{"unlimited": config, "limited": limitedConfig}
+	bothConfigs = map[string]control.Config{"unlimited": config, "limited": limitedConfig}
 )
 
 func setupPromRegistry(c *control.Controller) *prometheus.Registry {
@@ -164,6 +169,65 @@ func TestController_QuerySuccess(t *testing.T) {
 	}
 }
 
+func TestController_Registry(t *testing.T) {
+	for name, config := range bothConfigs {
+		t.Run(name, func(t *testing.T) {
+			reg := registry.New()
+			config.Registry = reg
+			ctrl, err := control.New(config, zaptest.NewLogger(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(t, ctrl)
+
+			orgID := platform.ID(1)
+			req := makeRequest(mockCompiler)
+			req.OrganizationID = orgID
+
+			// Queries finishing as soon as they are enqueued are still
+			// removed from the registry.
+			for i := 0; i < 100; i++ {
+				q, err := ctrl.Query(context.Background(), req)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				for range q.Results() {
+				}
+				q.Done()
+			}
+			if queries := reg.Queries(orgID); len(queries) != 0 {
+				t.Fatalf("expected no registered queries, got %v", queries)
+			}
+
+			// A running query is registered until it is done.
+			release := make(chan struct{})
+			req.Compiler = &mock.Compiler{
+				CompileFn: func(ctx context.Context) (flux.Program, error) {
+					return &mock.Program{
+						ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+							<-release
+						},
+					}, nil
+				},
+			}
+			q, err := ctrl.Query(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if queries := reg.Queries(orgID); len(queries) != 1 {
+				t.Fatalf("expected one registered query, got %v", queries)
+			}
+			close(release)
+			for range q.Results() {
+			}
+			q.Done()
+			if queries := reg.Queries(orgID); len(queries) != 0 {
+				t.Fatalf("expected no registered queries, got %v", queries)
+			}
+		})
+	}
+}
+
 func TestController_QueryCompileError(t *testing.T) {
 	for name, config := range bothConfigs {
 		t.Run(name, func(t *testing.T) {
@@ -868,6 +932,146 @@ func TestController_QueueSize(t *testing.T) {
 	}
 }
 
+func TestController_OrgQueryLimits(t *testing.T) {
+	limitedOrg, otherOrg := platform.ID(1), platform.ID(2)
+
+	config := config
+	config.ConcurrencyQuota = 4
+	config.QueueSize = 4
+	var lookups int32
+	config.OrganizationService = &pmock.OrganizationService{
+		FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*influxdb.Organization, error) {
+			org := &influxdb.Organization{ID: id}
+			if id == limitedOrg {
+				atomic.AddInt32(&lookups, 1)
+				org.QueryLimits = &influxdb.QueryLimits{ConcurrencyQuota: 1, QueueSize: 1}
+			}
+			return org, nil
+		},
+	}
+	ctrl, err := control.New(config, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	// Queries of the limited organization block until they are released.
+	release := make(chan struct{})
+	defer close(release)
+
+	executing := make(chan platform.ID, 4)
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					orgID := query.RequestFromContext(ctx).OrganizationID
+					executing <- orgID
+					if orgID == limitedOrg {
+						<-release
+					}
+				},
+			}, nil
+		},
+	}
+	makeOrgRequest := func(orgID platform.ID) *query.Request {
+		req := makeRequest(compiler)
+		req.OrganizationID = orgID
+		return req
+	}
+	runQuery := func(orgID platform.ID) {
+		q, err := ctrl.Query(context.Background(), makeOrgRequest(orgID))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			for range q.Results() {
+				// discard the results
+			}
+			q.Done()
+		}()
+	}
+
+	// The first query of the limited organization takes its only slot.
+	runQuery(limitedOrg)
+	if got := <-executing; got != limitedOrg {
+		t.Fatalf("expected a query of org %s to execute, got %s", limitedOrg, got)
+	}
+
+	// The second one waits for it and the third one is rejected.
+	runQuery(limitedOrg)
+	_, err = ctrl.Query(context.Background(), makeOrgRequest(limitedOrg))
+	if code := errors2.ErrorCode(err); code != errors2.ETooManyRequests {
+		t.Fatalf("expected error code %q, got %q (%v)", errors2.ETooManyRequests, code, err)
+	}
+
+	// Other organizations are not affected.
+	runQuery(otherOrg)
+	if got := <-executing; got != otherOrg {
+		t.Fatalf("expected a query of org %s to execute, got %s", otherOrg, got)
+	}
+
+	// Finishing the running query lets the waiting one execute.
+	release <- struct{}{}
+	select {
+	case got := <-executing:
+		if got != limitedOrg {
+			t.Fatalf("expected a query of org %s to execute, got %s", limitedOrg, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiting query was not executed")
+	}
+
+	// The limits are looked up once and cached for the following queries.
+	if got := atomic.LoadInt32(&lookups); got != 1 {
+		t.Fatalf("expected the limits to be looked up once, got %d", got)
+	}
+}
+
+func TestController_OrgMemoryQuota(t *testing.T) {
+	config := config
+	config.InitialMemoryBytesQuotaPerQuery = 64
+	config.MemoryBytesQuotaPerQuery = 4096
+	config.OrganizationService = &pmock.OrganizationService{
+		FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*influxdb.Organization, error) {
+			return &influxdb.Organization{
+				ID:          id,
+				QueryLimits: &influxdb.QueryLimits{MemoryBytesQuota: 1024},
+			}, nil
+		},
+	}
+	ctrl, err := control.New(config, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					// Allocate more than the quota of the organization.
+					if err := alloc.Account(2048); err != nil {
+						q.SetErr(err)
+					}
+				},
+			}, nil
+		},
+	}
+
+	q, err := ctrl.Query(context.Background(), makeRequest(compiler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range q.Results() {
+		// discard the results
+	}
+	q.Done()
+
+	if code := errors2.ErrorCode(q.Err()); code != errors2.ETooManyRequests {
+		t.Fatalf("expected error code %q, got %q (%v)", errors2.ETooManyRequests, code, q.Err())
+	}
+}
+
 // Test that rapidly starting and canceling the query and then calling done will correctly
 // cancel the query and not result in a race condition.
 func TestController_CancelDone_Unlimited(t *testing.T) {
@@ -6,8 +6,16 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/flux/memory"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
 )
 
+// errOrgMemoryQuotaExceeded is the error of queries which needed more memory
+// than the quota of their organization allows.
+var errOrgMemoryQuotaExceeded = &errors2.Error{
+	Code: errors2.ETooManyRequests,
+	Msg:  "organization memory quota exceeded",
+}
+
 type memoryManager struct {
 	// initialBytesQuotaPerQuery is the initial amount of memory
 	// allocated for each query. It does not count against the
@@ -47,6 +55,19 @@ func (c *Controller) createAllocator(q *Query) {
 		m:     c.memory,
 		limit: c.memory.initialBytesQuotaPerQuery,
 	}
+	if q.quota != nil {
+		if quota := atomic.LoadInt64(&q.quota.memoryBytesQuota); quota > 0 {
+			// A query never starts with more memory than the quota of its
+			// organization. Its initial memory is always granted, but it
+			// counts against the quota like any memory it requests later.
+			if q.memoryManager.limit > quota {
+				q.memoryManager.limit = quota
+			}
+			q.memoryManager.org = q.quota
+			q.memoryManager.orgGiven = q.memoryManager.limit
+			q.quota.chargeMemory(q.memoryManager.limit)
+		}
+	}
 	q.alloc = &memory.Allocator{
 		// Use an anonymous function to ensure the value is copied.
 		Limit:   func(v int64) *int64 { return &v }(q.memoryManager.limit),
@@ -59,6 +80,16 @@ type queryMemoryManager struct {
 	m     *memoryManager
 	limit int64
 	given int64
+
+	// org is the quota of the organization running the query, if any,
+	// and orgGiven the memory reserved against it.
+	org      *orgQuota
+	orgGiven int64
+
+	// orgQuotaExceeded is set atomically once a request for memory was
+	// denied by the quota of the organization. The allocator replaces the
+	// error of RequestMemory with its own, so the query reports it instead.
+	orgQuotaExceeded int32
 }
 
 // RequestMemory will determine if the query can be given more memory
@@ -94,14 +125,28 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 		// this method.
 		given := q.giveMemory(want, unused)
 
+		// The memory must also fit within the quota of the organization.
+		if q.org != nil {
+			if given = q.org.reserveMemory(want, given); given == 0 {
+				atomic.StoreInt32(&q.orgQuotaExceeded, 1)
+				return 0, errOrgMemoryQuotaExceeded
+			}
+		}
+
 		// Reserve this memory for our own use.
 		if !q.m.unlimited {
 			if !q.m.trySetUnusedMemoryBytes(unused, unused-given) {
 				// The unused value has changed so someone may have taken
 				// the memory that we wanted. Retry.
+				if q.org != nil {
+					q.org.releaseMemory(given)
+				}
 				continue
 			}
 		}
+		if q.org != nil {
+			q.orgGiven += given
+		}
 
 		// Successfully reserved the memory so update our own internal
 		// counter for the limit.
@@ -152,6 +197,10 @@ func (q *queryMemoryManager) Release() {
 	if !q.m.unlimited {
 		q.m.addUnusedMemoryBytes(q.given)
 	}
+	if q.org != nil {
+		q.org.releaseMemory(q.orgGiven)
+		q.orgGiven = 0
+	}
 	q.limit = q.m.initialBytesQuotaPerQuery
 	q.given = 0
 }
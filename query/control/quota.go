@@ -0,0 +1,217 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// OrganizationService finds the organizations whose query limits are
+// enforced by the controller.
+type OrganizationService interface {
+	FindOrganizationByID(ctx context.Context, id platform.ID) (*influxdb.Organization, error)
+}
+
+// orgLimitsRefreshInterval is how long the query limits of an organization
+// are cached, so changes to them apply to new queries within it.
+const orgLimitsRefreshInterval = time.Minute
+
+// orgQuotas enforces the query limits of each organization.
+type orgQuotas struct {
+	orgSvc OrganizationService
+	log    *zap.Logger
+
+	// mu protects the organizations map, the limits, active and waiting
+	// fields of each orgQuota and the holdsSlot field of each Query.
+	mu   sync.Mutex
+	orgs map[platform.ID]*orgQuota
+}
+
+// orgQuota tracks the queries of a single organization.
+type orgQuota struct {
+	// memoryBytes is the memory reserved by the queries of the
+	// organization and memoryBytesQuota the most they may reserve, or zero
+	// if unlimited. Both are accessed atomically and are kept first for
+	// 64-bit alignment.
+	memoryBytes      int64
+	memoryBytesQuota int64
+
+	orgID  platform.ID
+	limits influxdb.QueryLimits
+
+	// refreshedAt is when limits were last looked up.
+	refreshedAt time.Time
+
+	// active is the number of queries holding an execution slot.
+	active int
+
+	// waiting are the queries waiting for an execution slot, in the order
+	// they were submitted.
+	waiting []*Query
+}
+
+func newOrgQuotas(orgSvc OrganizationService, log *zap.Logger) *orgQuotas {
+	return &orgQuotas{
+		orgSvc: orgSvc,
+		log:    log,
+		orgs:   make(map[platform.ID]*orgQuota),
+	}
+}
+
+// lookup returns the quota of the organization. Its limits are looked up
+// from the organization service when they are older than
+// orgLimitsRefreshInterval.
+func (o *orgQuotas) lookup(ctx context.Context, orgID platform.ID) *orgQuota {
+	o.mu.Lock()
+	quota, ok := o.orgs[orgID]
+	if ok && time.Now().Sub(quota.refreshedAt) < orgLimitsRefreshInterval {
+		o.mu.Unlock()
+		return quota
+	}
+	o.mu.Unlock()
+
+	var limits influxdb.QueryLimits
+	org, err := o.orgSvc.FindOrganizationByID(ctx, orgID)
+	if err != nil {
+		o.log.Warn("Failed to look up organization query limits",
+			zap.String("org_id", orgID.String()),
+			zap.Error(err))
+	} else if org.QueryLimits != nil {
+		limits = *org.QueryLimits
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	quota, ok = o.orgs[orgID]
+	if !ok {
+		quota = &orgQuota{orgID: orgID}
+		o.orgs[orgID] = quota
+	}
+	if err != nil {
+		// Keep the limits last looked up, if any, and look them up again
+		// with the next query.
+		return quota
+	}
+	quota.limits = limits
+	quota.refreshedAt = time.Now()
+	atomic.StoreInt64(&quota.memoryBytesQuota, limits.MemoryBytesQuota)
+	return quota
+}
+
+// admit decides whether q may be dispatched for execution. It returns false
+// without an error if q has to wait for another query of its organization
+// to finish first, and an error if the organization has too many queries.
+func (o *orgQuotas) admit(q *Query) (bool, error) {
+	req := query.RequestFromContext(q.parentCtx)
+	if req == nil {
+		return true, nil
+	}
+	quota := o.lookup(q.parentCtx, req.OrganizationID)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	q.quota = quota
+	if quota.limits.ConcurrencyQuota == 0 {
+		return true, nil
+	}
+	if quota.active < int(quota.limits.ConcurrencyQuota) {
+		quota.active++
+		q.holdsSlot = true
+		return true, nil
+	}
+	if len(quota.waiting) < int(quota.limits.QueueSize) {
+		quota.waiting = append(quota.waiting, q)
+		go o.awaitCancel(q)
+		return false, nil
+	}
+	return false, &errors2.Error{
+		Code: errors2.ETooManyRequests,
+		Msg:  fmt.Sprintf("organization %s has reached its limit of %d concurrent queries", quota.orgID, quota.limits.ConcurrencyQuota),
+	}
+}
+
+// release frees the execution slot held by q, if any, and hands it to the
+// next query of the organization that is waiting for one. The query which
+// was given the slot is returned so it can be dispatched.
+func (o *orgQuotas) release(q *Query) *Query {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !q.holdsSlot {
+		return nil
+	}
+	q.holdsSlot = false
+
+	quota := q.quota
+	if len(quota.waiting) == 0 {
+		quota.active--
+		return nil
+	}
+	next := quota.waiting[0]
+	quota.waiting = quota.waiting[1:]
+	next.holdsSlot = true
+	return next
+}
+
+// awaitCancel removes q from the queries waiting for an execution slot if
+// it is canceled before it is given one.
+func (o *orgQuotas) awaitCancel(q *Query) {
+	<-q.parentCtx.Done()
+
+	o.mu.Lock()
+	waiting := q.quota.waiting
+	found := false
+	for i, wq := range waiting {
+		if wq == q {
+			q.quota.waiting = append(waiting[:i:i], waiting[i+1:]...)
+			found = true
+			break
+		}
+	}
+	o.mu.Unlock()
+
+	if found {
+		q.setErr(q.parentCtx.Err())
+	}
+}
+
+// reserveMemory reserves up to given bytes, and at least want bytes, of the
+// memory quota of the organization. It returns the number of bytes reserved,
+// which is zero if the quota does not allow for want more bytes.
+func (o *orgQuota) reserveMemory(want, given int64) int64 {
+	for {
+		used := atomic.LoadInt64(&o.memoryBytes)
+		if limit := atomic.LoadInt64(&o.memoryBytesQuota); limit > 0 {
+			available := limit - used
+			if available < want {
+				return 0
+			}
+			if given > available {
+				given = available
+			}
+		}
+		if atomic.CompareAndSwapInt64(&o.memoryBytes, used, used+given) {
+			return given
+		}
+	}
+}
+
+// chargeMemory counts bytes against the memory quota of the organization
+// regardless of whether the quota allows for them.
+func (o *orgQuota) chargeMemory(bytes int64) {
+	atomic.AddInt64(&o.memoryBytes, bytes)
+}
+
+// releaseMemory returns bytes to the memory quota of the organization.
+func (o *orgQuota) releaseMemory(bytes int64) {
+	atomic.AddInt64(&o.memoryBytes, -bytes)
+}
@@ -0,0 +1,191 @@
+package registry
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	// PrefixQueries is the path prefix of the running queries API.
+	PrefixQueries = "/api/v2/queries"
+)
+
+// Handler serves the running queries API.
+type Handler struct {
+	chi.Router
+	api      *kithttp.API
+	log      *zap.Logger
+	registry *Registry
+}
+
+// NewHTTPHandler constructs a new http server for the running queries of
+// registry.
+func NewHTTPHandler(log *zap.Logger, registry *Registry) *Handler {
+	h := &Handler{
+		api:      kithttp.NewAPI(kithttp.WithLog(log)),
+		log:      log,
+		registry: registry,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Get("/", h.handleGetQueries)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetQuery)
+			r.Delete("/", h.handleDeleteQuery)
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix returns the path prefix the handler is mounted on.
+func (h *Handler) Prefix() string {
+	return PrefixQueries
+}
+
+type queryResponse struct {
+	ID          string    `json:"id"`
+	Language    string    `json:"language"`
+	OrgID       string    `json:"orgID"`
+	UserID      string    `json:"userID,omitempty"`
+	Query       string    `json:"query"`
+	Database    string    `json:"database,omitempty"`
+	StartTime   time.Time `json:"startTime"`
+	Duration    string    `json:"duration"`
+	MemoryBytes int64     `json:"memoryBytes"`
+	Status      string    `json:"status"`
+}
+
+func newQueryResponse(info QueryInfo) queryResponse {
+	resp := queryResponse{
+		ID:          strconv.FormatUint(info.ID, 10),
+		Language:    info.Language,
+		OrgID:       info.OrgID.String(),
+		Query:       info.Query,
+		Database:    info.Database,
+		StartTime:   info.StartTime,
+		Duration:    info.Duration.String(),
+		MemoryBytes: info.MemoryBytes,
+		Status:      info.Status,
+	}
+	if info.UserID.Valid() {
+		resp.UserID = info.UserID.String()
+	}
+	return resp
+}
+
+type getQueriesResponse struct {
+	Queries []queryResponse `json:"queries"`
+}
+
+func (h *Handler) handleGetQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	queries, err := AuthorizedQueries(ctx, h.registry, orgID)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := getQueriesResponse{Queries: []queryResponse{}}
+	for _, info := range queries {
+		resp.Queries = append(resp.Queries, newQueryResponse(info))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) handleGetQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	id, err := decodeQueryID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	info, err := AuthorizeQuery(ctx, h.registry, orgID, id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, newQueryResponse(info))
+}
+
+func (h *Handler) handleDeleteQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID, err := decodeOrgID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	id, err := decodeQueryID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := AuthorizeKill(ctx, h.registry, orgID, id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if err := h.registry.Kill(orgID, id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func decodeOrgID(r *http.Request) (platform.ID, error) {
+	orgID := r.URL.Query().Get("orgID")
+	if orgID == "" {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	id, err := platform.IDFromString(orgID)
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
+
+func decodeQueryID(r *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid query id",
+			Err:  err,
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"go.uber.org/zap/zaptest"
+)
+
+func withAuth(r *http.Request, orgID, userID platform.ID, perms ...influxdb.Permission) *http.Request {
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          platform.ID(1),
+		OrgID:       orgID,
+		UserID:      userID,
+		Status:      influxdb.Active,
+		Permissions: perms,
+	})
+	return r.WithContext(ctx)
+}
+
+func mustNewPermission(t *testing.T, id platform.ID, action influxdb.Action, rt influxdb.ResourceType, orgID platform.ID) *influxdb.Permission {
+	t.Helper()
+	perm, err := influxdb.NewPermissionAtID(id, action, rt, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return perm
+}
+
+func Test_handleGetQueries(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	r := newTestRegistry(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	id, _ := r.Register(QueryInfo{Language: LanguageFlux, OrgID: orgID, UserID: 0xaa00, Query: "q"}, &mockQuery{memory: 5, status: "executing"})
+	otherID, _ := r.Register(QueryInfo{Language: LanguageFlux, OrgID: orgID, UserID: 0xaa01, Query: "other"}, &mockQuery{})
+	h := NewHTTPHandler(zaptest.NewLogger(t), r)
+
+	getQueries := func(t *testing.T, req *http.Request) []queryResponse {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+		}
+		var resp getQueriesResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Queries
+	}
+
+	t.Run("org reader", func(t *testing.T) {
+		req := withAuth(httptest.NewRequest(http.MethodGet, "/?orgID="+orgID.String(), nil), orgID, 0xaa00,
+			*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID))
+		queries := getQueries(t, req)
+		if len(queries) != 1 {
+			t.Fatalf("expected only the own query, got %+v", queries)
+		}
+		got := queries[0]
+		if got.ID != fmt.Sprint(id) || got.Query != "q" || got.MemoryBytes != 5 || got.Status != "executing" || got.UserID != platform.ID(0xaa00).String() {
+			t.Fatalf("unexpected query %+v", got)
+		}
+	})
+
+	t.Run("org writer", func(t *testing.T) {
+		req := withAuth(httptest.NewRequest(http.MethodGet, "/?orgID="+orgID.String(), nil), orgID, 0xaa02,
+			*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID),
+			*mustNewPermission(t, orgID, influxdb.WriteAction, influxdb.OrgsResourceType, orgID))
+		queries := getQueries(t, req)
+		if len(queries) != 2 || queries[0].ID != fmt.Sprint(id) || queries[1].ID != fmt.Sprint(otherID) {
+			t.Fatalf("expected all queries, got %+v", queries)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		req := withAuth(httptest.NewRequest(http.MethodGet, "/?orgID="+orgID.String(), nil), orgID, 0xaa01)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("missing orgID", func(t *testing.T) {
+		req := withAuth(httptest.NewRequest(http.MethodGet, "/", nil), orgID, 0xaa01)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func Test_handleGetQuery(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	owner := platform.ID(0xaa00)
+	r := New()
+	id, _ := r.Register(QueryInfo{OrgID: orgID, UserID: owner, Query: "q"}, &mockQuery{})
+	h := NewHTTPHandler(zaptest.NewLogger(t), r)
+
+	tests := []struct {
+		name       string
+		userID     platform.ID
+		perms      []influxdb.Permission
+		wantStatus int
+	}{
+		{
+			name:       "owner",
+			userID:     owner,
+			perms:      []influxdb.Permission{*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID)},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "org writer",
+			userID: 0xaa01,
+			perms: []influxdb.Permission{
+				*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID),
+				*mustNewPermission(t, orgID, influxdb.WriteAction, influxdb.OrgsResourceType, orgID),
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other user",
+			userID:     0xaa01,
+			perms:      []influxdb.Permission{*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID)},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withAuth(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%d?orgID=%s", id, orgID), nil), orgID, tt.userID, tt.perms...)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_handleDeleteQuery(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	owner := platform.ID(0xaa00)
+
+	tests := []struct {
+		name          string
+		userID        platform.ID
+		perms         []influxdb.Permission
+		wantStatus    int
+		wantCancelled bool
+	}{
+		{
+			name:          "owner",
+			userID:        owner,
+			wantStatus:    http.StatusNoContent,
+			wantCancelled: true,
+		},
+		{
+			name:          "org writer",
+			userID:        0xaa01,
+			perms:         []influxdb.Permission{*mustNewPermission(t, orgID, influxdb.WriteAction, influxdb.OrgsResourceType, orgID)},
+			wantStatus:    http.StatusNoContent,
+			wantCancelled: true,
+		},
+		{
+			name:       "other user",
+			userID:     0xaa01,
+			perms:      []influxdb.Permission{*mustNewPermission(t, orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID)},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			q := &mockQuery{}
+			id, _ := r.Register(QueryInfo{OrgID: orgID, UserID: owner}, q)
+			h := NewHTTPHandler(zaptest.NewLogger(t), r)
+
+			req := withAuth(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/%d?orgID=%s", id, orgID), nil), orgID, tt.userID, tt.perms...)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if q.cancelled != tt.wantCancelled {
+				t.Fatalf("expected cancelled %v, got %v", tt.wantCancelled, q.cancelled)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Package registry keeps track of the queries that are running in the
+// process, regardless of the language they were written in, so that they can
+// be listed and cancelled.
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// Languages of registered queries.
+const (
+	LanguageFlux     = "flux"
+	LanguageInfluxQL = "influxql"
+)
+
+// ErrQueryNotFound is returned when a query is not running.
+var ErrQueryNotFound = &errors.Error{
+	Code: errors.ENotFound,
+	Msg:  "query not found",
+}
+
+// QueryInfo describes a running query.
+type QueryInfo struct {
+	ID        uint64
+	Language  string
+	OrgID     platform.ID
+	UserID    platform.ID
+	Query     string
+	Database  string
+	StartTime time.Time
+
+	// The fields below are filled in when the query is listed.
+	Duration    time.Duration
+	MemoryBytes int64
+	Status      string
+}
+
+// RunningQuery is a query which has been registered.
+type RunningQuery interface {
+	// Cancel interrupts the query.
+	Cancel()
+
+	// MemoryBytes reports the memory currently allocated by the query.
+	MemoryBytes() int64
+
+	// Status reports the execution state of the query.
+	Status() string
+}
+
+// Registry is a registry of running queries. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	lastID  uint64
+	queries map[uint64]*entry
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+type entry struct {
+	info QueryInfo
+	q    RunningQuery
+}
+
+// New returns an empty Registry.
+func New() *Registry {
+	return &Registry{
+		queries: make(map[uint64]*entry),
+		now:     time.Now,
+	}
+}
+
+// Register records q as running and returns the ID assigned to it. The
+// returned function removes the query from the registry and must be called
+// once the query has finished.
+func (r *Registry) Register(info QueryInfo, q RunningQuery) (uint64, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	info.ID = r.lastID
+	if info.StartTime.IsZero() {
+		info.StartTime = r.now().UTC()
+	}
+	r.queries[info.ID] = &entry{info: info, q: q}
+
+	var once sync.Once
+	return info.ID, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.queries, info.ID)
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Queries returns the queries running in the organization, ordered by ID.
+func (r *Registry) Queries(orgID platform.ID) []QueryInfo {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.queries))
+	for _, e := range r.queries {
+		if e.info.OrgID == orgID {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	now := r.now()
+	infos := make([]QueryInfo, 0, len(entries))
+	for _, e := range entries {
+		info := e.info
+		info.Duration = now.Sub(info.StartTime)
+		info.MemoryBytes = e.q.MemoryBytes()
+		info.Status = e.q.Status()
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// Query returns a single running query of the organization.
+func (r *Registry) Query(orgID platform.ID, id uint64) (QueryInfo, error) {
+	r.mu.RLock()
+	e, ok := r.queries[id]
+	r.mu.RUnlock()
+	if !ok || e.info.OrgID != orgID {
+		return QueryInfo{}, ErrQueryNotFound
+	}
+
+	info := e.info
+	info.Duration = r.now().Sub(info.StartTime)
+	info.MemoryBytes = e.q.MemoryBytes()
+	info.Status = e.q.Status()
+	return info, nil
+}
+
+// Kill cancels a running query of the organization.
+func (r *Registry) Kill(orgID platform.ID, id uint64) error {
+	r.mu.RLock()
+	e, ok := r.queries[id]
+	r.mu.RUnlock()
+	if !ok || e.info.OrgID != orgID {
+		return ErrQueryNotFound
+	}
+
+	e.q.Cancel()
+	return nil
+}
+
+// AuthorizeKill allows the user who started a query to cancel it. Other
+// queries of the organization may only be cancelled by users with write
+// access to the organization.
+func AuthorizeKill(ctx context.Context, r *Registry, orgID platform.ID, id uint64) error {
+	info, err := r.Query(orgID, id)
+	if err != nil {
+		return err
+	}
+	return authorizeOthers(ctx, info)
+}
+
+// AuthorizeQuery allows users with read access to the organization to see
+// the queries they started. Other queries of the organization may only be
+// seen by users with write access to the organization.
+func AuthorizeQuery(ctx context.Context, r *Registry, orgID platform.ID, id uint64) (QueryInfo, error) {
+	if _, _, err := authorizer.AuthorizeReadOrg(ctx, orgID); err != nil {
+		return QueryInfo{}, err
+	}
+	info, err := r.Query(orgID, id)
+	if err != nil {
+		return QueryInfo{}, err
+	}
+	if err := authorizeOthers(ctx, info); err != nil {
+		return QueryInfo{}, err
+	}
+	return info, nil
+}
+
+// AuthorizedQueries returns the running queries of the organization the user
+// may see, as allowed by AuthorizeQuery.
+func AuthorizedQueries(ctx context.Context, r *Registry, orgID platform.ID) ([]QueryInfo, error) {
+	if _, _, err := authorizer.AuthorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	queries := r.Queries(orgID)
+	if _, _, err := authorizer.AuthorizeWriteOrg(ctx, orgID); err == nil {
+		return queries, nil
+	}
+
+	own := queries[:0]
+	for _, info := range queries {
+		if isOwnQuery(ctx, info) {
+			own = append(own, info)
+		}
+	}
+	return own, nil
+}
+
+// authorizeOthers allows access to the queries of other users of the
+// organization to users with write access to it.
+func authorizeOthers(ctx context.Context, info QueryInfo) error {
+	if isOwnQuery(ctx, info) {
+		return nil
+	}
+	_, _, err := authorizer.AuthorizeWriteOrg(ctx, info.OrgID)
+	return err
+}
+
+func isOwnQuery(ctx context.Context, info QueryInfo) bool {
+	userID, err := icontext.GetUserID(ctx)
+	return err == nil && info.UserID.Valid() && userID == info.UserID
+}
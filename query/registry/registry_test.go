@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+type mockQuery struct {
+	memory    int64
+	status    string
+	cancelled bool
+}
+
+func (q *mockQuery) Cancel()            { q.cancelled = true }
+func (q *mockQuery) MemoryBytes() int64 { return q.memory }
+func (q *mockQuery) Status() string     { return q.status }
+
+func newTestRegistry(now time.Time) *Registry {
+	r := New()
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestRegistry_Queries(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := newTestRegistry(start)
+
+	orgID := platform.ID(1)
+	id1, _ := r.Register(QueryInfo{Language: LanguageFlux, OrgID: orgID, Query: "q1"}, &mockQuery{memory: 10, status: "executing"})
+	r.Register(QueryInfo{Language: LanguageFlux, OrgID: platform.ID(2), Query: "other"}, &mockQuery{})
+	id3, unregister := r.Register(QueryInfo{Language: LanguageInfluxQL, OrgID: orgID, Query: "q3", Database: "db"}, &mockQuery{status: "running"})
+
+	r.now = func() time.Time { return start.Add(time.Minute) }
+
+	want := []QueryInfo{
+		{ID: id1, Language: LanguageFlux, OrgID: orgID, Query: "q1", StartTime: start, Duration: time.Minute, MemoryBytes: 10, Status: "executing"},
+		{ID: id3, Language: LanguageInfluxQL, OrgID: orgID, Query: "q3", Database: "db", StartTime: start, Duration: time.Minute, Status: "running"},
+	}
+	if diff := cmp.Diff(want, r.Queries(orgID)); diff != "" {
+		t.Fatalf("unexpected queries -want/+got:\n%s", diff)
+	}
+
+	unregister()
+	unregister()
+	if got := r.Queries(orgID); len(got) != 1 || got[0].ID != id1 {
+		t.Fatalf("unexpected queries after unregister: %v", got)
+	}
+}
+
+func TestRegistry_Kill(t *testing.T) {
+	r := newTestRegistry(time.Now())
+
+	q := &mockQuery{}
+	id, _ := r.Register(QueryInfo{OrgID: platform.ID(1)}, q)
+
+	if err := r.Kill(platform.ID(2), id); err != ErrQueryNotFound {
+		t.Fatalf("expected query not found for another organization, got %v", err)
+	}
+	if q.cancelled {
+		t.Fatal("query of another organization was cancelled")
+	}
+
+	if err := r.Kill(platform.ID(1), id); err != nil {
+		t.Fatal(err)
+	}
+	if !q.cancelled {
+		t.Fatal("expected query to be cancelled")
+	}
+
+	if err := r.Kill(platform.ID(1), id+1); err != ErrQueryNotFound {
+		t.Fatalf("expected query not found, got %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/prom"
+	"github.com/influxdata/influxdb/v2/notification/smtp"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/prometheus/client_golang/prometheus"
@@ -101,3 +102,15 @@ func NewDependencies(
 	}
 	return deps, nil
 }
+
+// WithSMTP returns the dependencies with an HTTP client sending email to
+// smtp:// and smtps:// URLs, as SMTP notification rules do with http.post.
+// As any flux query may then send email from the server, it is only used
+// when enabled in the server configuration.
+func (d Dependencies) WithSMTP() Dependencies {
+	if fdeps, ok := d.FluxDeps.(flux.Deps); ok {
+		fdeps.Deps.HTTPClient = smtp.NewClient(fdeps.Deps.HTTPClient)
+		d.FluxDeps = fdeps
+	}
+	return d
+}
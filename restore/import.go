@@ -0,0 +1,308 @@
+package restore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"go.uber.org/zap"
+)
+
+// importBatchSize is the size of the line protocol batches written while
+// importing a time range.
+const importBatchSize = 1 << 20
+
+// rangeRestore writes the points of the backup within the requested time range
+// into existing buckets, leaving the other data of the buckets untouched.
+func (r *restoreRunner) rangeRestore(ctx context.Context, req Request) error {
+	closeKV, err := r.openKV(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeKV()
+
+	var filter influxdb.OrganizationFilter
+	if req.OrgID.Valid() {
+		filter.ID = &req.OrgID
+	}
+	if req.Org != "" {
+		filter.Name = &req.Org
+	}
+
+	orgs, _, err := r.tenantService.FindOrganizations(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		if err := r.importOrganization(ctx, org, req); err != nil {
+			return err
+		}
+	}
+
+	r.log.Info("Time range restore complete")
+	return nil
+}
+
+func (r *restoreRunner) importOrganization(ctx context.Context, org *influxdb.Organization, req Request) error {
+	name := org.Name
+	if req.NewOrgName != "" {
+		name = req.NewOrgName
+	}
+
+	o, err := r.OrgService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &name})
+	if errors.ErrorCode(err) == errors.ENotFound {
+		return fmt.Errorf("organization %q must exist to restore a time range into it", name)
+	} else if err != nil {
+		return fmt.Errorf("failed to find organization %q: %w", name, err)
+	}
+
+	filter := influxdb.BucketFilter{OrganizationID: &org.ID}
+	if req.BucketID.Valid() {
+		filter.ID = &req.BucketID
+	}
+	if req.Bucket != "" {
+		filter.Name = &req.Bucket
+	}
+
+	buckets, _, err := r.tenantService.FindBuckets(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, bkt := range buckets {
+		// Skip internal buckets.
+		if strings.HasPrefix(bkt.Name, "_") {
+			continue
+		}
+
+		if err := r.importBucket(ctx, bkt, o.ID, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restoreRunner) importBucket(ctx context.Context, bkt *influxdb.Bucket, orgID platform.ID, req Request) error {
+	name := bkt.Name
+	if req.NewBucketName != "" {
+		name = req.NewBucketName
+	}
+
+	b, err := r.BucketService.FindBucket(ctx, influxdb.BucketFilter{OrganizationID: &orgID, Name: &name})
+	if errors.ErrorCode(err) == errors.ENotFound {
+		return fmt.Errorf("bucket %q must exist to restore a time range into it", name)
+	} else if err != nil {
+		return fmt.Errorf("failed to find bucket %q: %w", name, err)
+	}
+
+	r.log.Info(
+		"Restoring time range of bucket",
+		zap.String("backup_id", bkt.ID.String()),
+		zap.String("backup_name", bkt.Name),
+		zap.String("restored_name", b.Name),
+		zap.Time("start", req.Start),
+		zap.Time("end", req.End),
+	)
+
+	filter := influxdb.BucketFilter{OrganizationID: &orgID, ID: &b.ID}
+	for _, chain := range r.shardManifests {
+		if bkt.ID.String() != chain[0].BucketID {
+			continue
+		}
+		for _, m := range chain {
+			if err := r.importShard(ctx, req, m, filter); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// importShard writes the points of a shard backup within the time range of
+// req to the bucket matching filter.
+func (r *restoreRunner) importShard(ctx context.Context, req Request, manifest *influxdb.ManifestEntry, filter influxdb.BucketFilter) error {
+	r.log.Info("Importing shard from backup", zap.Uint64("id", manifest.ShardID), zap.String("name", manifest.FileName))
+
+	rc, err := r.source.Open(ctx, manifest.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to open shard backup %q: %w", manifest.FileName, err)
+	}
+	defer rc.Close()
+
+	dir, err := ioutil.TempDir("", "influx-restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tsmFiles, err := extractShardBackup(rc, dir)
+	if err != nil {
+		return fmt.Errorf("failed to extract shard backup %q: %w", manifest.FileName, err)
+	}
+
+	min, max := req.timeRange()
+	w := &lineBatcher{ctx: ctx, svc: r.WriteService, filter: filter}
+	for _, path := range tsmFiles {
+		if err := readTSMPoints(path, min, max, w); err != nil {
+			return fmt.Errorf("failed to import shard backup %q: %w", manifest.FileName, err)
+		}
+	}
+	return w.flush()
+}
+
+// extractShardBackup extracts the files of the gzipped shard archive r into
+// dir and returns the paths of the extracted TSM files, oldest first.
+func extractShardBackup(r io.Reader, dir string) ([]string, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var tsmFiles []string
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// TSM files are extracted next to their tombstones, so deletes are
+		// applied when they are read.
+		name := filepath.Join(dir, filepath.Base(hdr.Name))
+		if err := extractFile(tr, name); err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(name, "."+tsm1.TSMFileExtension) {
+			tsmFiles = append(tsmFiles, name)
+		}
+	}
+
+	// TSM file names start with their generation, so newer data is written last.
+	sort.Strings(tsmFiles)
+	return tsmFiles, nil
+}
+
+func extractFile(r io.Reader, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readTSMPoints writes the points of the TSM file at path with a timestamp
+// within [min, max] to w as line protocol.
+func readTSMPoints(path string, min, max int64, w *lineBatcher) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	tr, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	defer tr.Close()
+
+	for i := 0; i < tr.KeyCount(); i++ {
+		key, _ := tr.KeyAt(i)
+		values, err := tr.ReadAll(key)
+		if err != nil {
+			return err
+		}
+
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+		measurement, tags := seriesFromKey(seriesKey)
+		for _, v := range values {
+			if v.UnixNano() < min || v.UnixNano() > max {
+				continue
+			}
+
+			pt, err := models.NewPoint(measurement, tags, models.Fields{string(field): v.Value()}, time.Unix(0, v.UnixNano()))
+			if err != nil {
+				return err
+			}
+			if err := w.writeLine(pt.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// seriesFromKey returns the measurement and tags of a storage series key,
+// without the tags the storage engine uses to encode the measurement and field.
+func seriesFromKey(seriesKey []byte) (string, models.Tags) {
+	_, tags := models.ParseKeyBytes(seriesKey)
+
+	var measurement string
+	userTags := make(models.Tags, 0, len(tags))
+	for _, t := range tags {
+		switch {
+		case bytes.Equal(t.Key, models.MeasurementTagKeyBytes):
+			measurement = string(t.Value)
+		case bytes.Equal(t.Key, models.FieldKeyTagKeyBytes):
+		default:
+			userTags = append(userTags, t)
+		}
+	}
+	return measurement, userTags
+}
+
+// lineBatcher writes line protocol to a bucket in batches.
+type lineBatcher struct {
+	ctx    context.Context
+	svc    influxdb.WriteService
+	filter influxdb.BucketFilter
+	buf    bytes.Buffer
+}
+
+func (b *lineBatcher) writeLine(line string) error {
+	b.buf.WriteString(line)
+	b.buf.WriteByte('\n')
+	if b.buf.Len() >= importBatchSize {
+		return b.flush()
+	}
+	return nil
+}
+
+func (b *lineBatcher) flush() error {
+	if b.buf.Len() == 0 {
+		return nil
+	}
+	defer b.buf.Reset()
+
+	if err := b.svc.WriteTo(b.ctx, b.filter, &b.buf); err != nil {
+		return fmt.Errorf("failed to write points: %w", err)
+	}
+	return nil
+}
@@ -5,17 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup"
 	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/tenant"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"go.uber.org/zap"
@@ -25,6 +28,10 @@ type Request struct {
 	// Path to local backup data created using `influx backup`
 	Path string
 
+	// Source backup files are read from.
+	// If not set, backup files are read from the local directory at Path.
+	Source backup.Target
+
 	// Original ID/name of the organization to restore.
 	// If not set, all orgs will be restored.
 	OrgID platform.ID
@@ -46,19 +53,49 @@ type Request struct {
 	// If true, replace all data on the server with the local backup.
 	// Otherwise only restore the requested org/bucket, leaving other data untouched.
 	Full bool
+
+	// Time range of the data to restore.
+	// If either bound is set, the points within the range are written into the
+	// existing org/bucket instead of replacing the bucket.
+	Start time.Time
+	End   time.Time
+}
+
+// bounded returns true if only the data within a time range is restored.
+func (r Request) bounded() bool {
+	return !r.Start.IsZero() || !r.End.IsZero()
+}
+
+// timeRange returns the bounds of the time range to restore in nanoseconds.
+func (r Request) timeRange() (min, max int64) {
+	min, max = models.MinNanoTime, models.MaxNanoTime
+	if !r.Start.IsZero() {
+		min = r.Start.UnixNano()
+	}
+	if !r.End.IsZero() {
+		max = r.End.UnixNano()
+	}
+	return min, max
 }
 
 type Services struct {
 	RestoreService influxdb.RestoreService
 	OrgService     influxdb.OrganizationService
 	BucketService  influxdb.BucketService
+
+	// WriteService is used to write the points of a time range restore.
+	WriteService influxdb.WriteService
 }
 
 type restoreRunner struct {
 	Services
 
-	kvManifest     *influxdb.ManifestKVEntry
-	shardManifests map[uint64]*influxdb.ManifestEntry
+	source backup.Target
+
+	kvManifest *influxdb.ManifestKVEntry
+
+	// Backups to restore per shard, in the order they must be applied.
+	shardManifests map[uint64][]*influxdb.ManifestEntry
 
 	tenantService influxdb.TenantService
 	metaClient    *meta.Client
@@ -68,142 +105,246 @@ type restoreRunner struct {
 func RunRestore(ctx context.Context, req Request, svcs Services, log *zap.Logger) error {
 	runner := restoreRunner{
 		Services: svcs,
+		source:   req.Source,
 		log:      log,
 	}
+	if runner.source == nil {
+		runner.source = backup.NewLocalTarget(req.Path)
+	}
 
-	if err := runner.loadManifests(req.Path); err != nil {
+	if err := runner.loadManifests(ctx); err != nil {
 		return err
 	}
 
+	if req.bounded() {
+		if req.Full {
+			return fmt.Errorf("a full restore cannot be bounded by a time range")
+		}
+		if min, max := req.timeRange(); max < min {
+			return fmt.Errorf("restore time range end %s is before start %s", req.End, req.Start)
+		}
+		return runner.rangeRestore(ctx, req)
+	}
+
 	if req.Full {
 		return runner.fullRestore(ctx, req)
 	}
 	return runner.partialRestore(ctx, req)
 }
 
-func (r *restoreRunner) loadManifests(path string) error {
-	// Read all manifest files from path, sort in descending time.
-	manifests, err := filepath.Glob(filepath.Join(path, "*.manifest"))
+func (r *restoreRunner) loadManifests(ctx context.Context) error {
+	// Read all manifest files from the source, sort in descending time.
+	names, err := r.source.List(ctx, ".manifest")
 	if err != nil {
-		return fmt.Errorf("failed to find backup manifests at %q: %w", path, err)
-	} else if len(manifests) == 0 {
+		return fmt.Errorf("failed to find backup manifests: %w", err)
+	} else if len(names) == 0 {
 		return nil
 	}
-	sort.Sort(sort.Reverse(sort.StringSlice(manifests)))
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
 
-	r.shardManifests = make(map[uint64]*influxdb.ManifestEntry)
-	for _, filename := range manifests {
-		// Skip file if it is a directory.
-		if fi, err := os.Stat(filename); err != nil {
-			return fmt.Errorf("failed to inspect local manifest at %q: %w", filename, err)
-		} else if fi.IsDir() {
-			continue
+	// Incremental backups reference their parent manifest by file name.
+	manifests := make(map[string]*influxdb.Manifest, len(names))
+	for _, name := range names {
+		manifest, err := r.readManifest(ctx, name)
+		if err != nil {
+			return err
 		}
+		manifests[name] = manifest
+	}
 
-		// Read manifest file for backup.
-		var manifest influxdb.Manifest
-		if buf, err := ioutil.ReadFile(filename); err != nil {
-			return fmt.Errorf("failed to read local manifest at %q: %w", filename, err)
-		} else if err := json.Unmarshal(buf, &manifest); err != nil {
-			return fmt.Errorf("read manifest: %v", err)
-		}
+	r.shardManifests = make(map[uint64][]*influxdb.ManifestEntry)
+	for _, name := range names {
+		manifest := manifests[name]
 
 		// Save latest KV entry (first in the sorted slice).
 		if r.kvManifest == nil {
 			r.kvManifest = &manifest.KV
 		}
 
-		// Load most recent backup per shard.
+		// Load most recent backup per shard, along with the backups it was
+		// incrementally based on.
 		for i := range manifest.Files {
-			sh := manifest.Files[i]
-			if _, err := os.Stat(filepath.Join(path, sh.FileName)); err != nil {
+			sh := &manifest.Files[i]
+			if _, ok := r.shardManifests[sh.ShardID]; ok {
+				continue
+			}
+			if _, err := r.source.Stat(ctx, sh.FileName); err != nil {
 				continue
 			}
 
-			entry := r.shardManifests[sh.ShardID]
-			if entry == nil || sh.LastModified.After(entry.LastModified) {
-				r.shardManifests[sh.ShardID] = &sh
+			chain, err := r.shardChain(ctx, manifests, name, sh)
+			if err != nil {
+				return err
 			}
+			r.shardManifests[sh.ShardID] = chain
 		}
 	}
 
 	return nil
 }
 
+func (r *restoreRunner) readManifest(ctx context.Context, name string) (*influxdb.Manifest, error) {
+	rc, err := r.source.Open(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest %q: %w", name, err)
+	}
+	defer rc.Close()
+
+	var manifest influxdb.Manifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("read manifest: %v", err)
+	}
+	return &manifest, nil
+}
+
+// shardChain returns the backups of shard sh to restore, in the order they
+// must be applied. For a shard from an incremental backup these are the
+// backups of the shard in the parent manifests, oldest first, followed by sh.
+func (r *restoreRunner) shardChain(ctx context.Context, manifests map[string]*influxdb.Manifest, name string, sh *influxdb.ManifestEntry) ([]*influxdb.ManifestEntry, error) {
+	chain := []*influxdb.ManifestEntry{sh}
+	seen := map[string]bool{name: true}
+	for m := manifests[name]; m.Parent != ""; {
+		parent, ok := manifests[m.Parent]
+		if !ok {
+			return nil, fmt.Errorf("parent manifest %q of incremental backup %q not found", m.Parent, name)
+		} else if seen[m.Parent] {
+			return nil, fmt.Errorf("incremental backup %q has a cyclic chain of parent manifests", name)
+		}
+		name, m = m.Parent, parent
+		seen[name] = true
+
+		for i := range m.Files {
+			e := &m.Files[i]
+			if e.ShardID != sh.ShardID {
+				continue
+			}
+			if _, err := r.source.Stat(ctx, e.FileName); err != nil {
+				return nil, fmt.Errorf("failed to inspect shard backup referenced by manifest %q: %w", name, err)
+			}
+			chain = append(chain, e)
+			break
+		}
+	}
+
+	// Reverse to apply the oldest backup first.
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
 func (r *restoreRunner) fullRestore(ctx context.Context, req Request) error {
-	if err := r.restoreKV(ctx, req.Path); err != nil {
+	if err := r.restoreKV(ctx); err != nil {
 		return err
 	}
 
-	for _, m := range r.shardManifests {
-		if err := r.restoreShard(ctx, req.Path, m); err != nil {
-			return err
+	for _, chain := range r.shardManifests {
+		for _, m := range chain {
+			if err := r.restoreShard(ctx, m); err != nil {
+				return err
+			}
 		}
 	}
 
-	r.log.Info("Full restore complete", zap.String("path", req.Path))
+	r.log.Info("Full restore complete")
 	return nil
 }
 
 func (r *restoreRunner) partialRestore(ctx context.Context, req Request) error {
-	// Open meta store so we can iterate over metadata.
-	kvStore := bolt.NewKVStore(r.log, filepath.Join(req.Path, r.kvManifest.FileName))
-	if err := kvStore.Open(ctx); err != nil {
-		return err
-	}
-	defer kvStore.Close()
-
-	r.tenantService = tenant.NewService(tenant.NewStore(kvStore))
-	r.metaClient = meta.NewClient(meta.NewConfig(), kvStore)
-	if err := r.metaClient.Open(); err != nil {
+	closeKV, err := r.openKV(ctx)
+	if err != nil {
 		return err
 	}
-	defer r.metaClient.Close()
+	defer closeKV()
 
 	if err := r.restoreOrganizations(ctx, req); err != nil {
 		return err
 	}
 
-	r.log.Info("Partial restore complete", zap.String("path", req.Path))
+	r.log.Info("Partial restore complete")
 	return nil
 }
 
-func (r *restoreRunner) restoreKV(ctx context.Context, path string) error {
-	kvPath := filepath.Join(path, r.kvManifest.FileName)
-	r.log.Info("Restoring full metadata from local backup", zap.String("path", kvPath))
+// openKV copies the backed-up metadata to local disk and opens it so we can
+// iterate over it.
+func (r *restoreRunner) openKV(ctx context.Context) (func(), error) {
+	rc, err := r.source.Open(ctx, r.kvManifest.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open KV backup %q: %w", r.kvManifest.FileName, err)
+	}
+	defer rc.Close()
 
-	f, err := os.Open(kvPath)
+	f, err := ioutil.TempFile("", "influx-restore-kv")
 	if err != nil {
-		return fmt.Errorf("failed to open local KV backup at %q: %w", kvPath, err)
+		return nil, err
+	}
+	kvPath := f.Name()
+	if _, err := io.Copy(f, rc); err != nil {
+		_ = f.Close()
+		_ = os.Remove(kvPath)
+		return nil, fmt.Errorf("failed to download KV backup %q: %w", r.kvManifest.FileName, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(kvPath)
+		return nil, err
 	}
-	defer f.Close()
 
-	if err := r.RestoreService.RestoreKVStore(ctx, f); err != nil {
-		return fmt.Errorf("failed to upload local KV backup at %q: %w", kvPath, err)
+	kvStore := bolt.NewKVStore(r.log, kvPath)
+	if err := kvStore.Open(ctx); err != nil {
+		_ = os.Remove(kvPath)
+		return nil, err
 	}
 
-	r.log.Info("Full metadata restored", zap.String("path", kvPath))
+	r.tenantService = tenant.NewService(tenant.NewStore(kvStore))
+	r.metaClient = meta.NewClient(meta.NewConfig(), kvStore)
+	if err := r.metaClient.Open(); err != nil {
+		_ = kvStore.Close()
+		_ = os.Remove(kvPath)
+		return nil, err
+	}
+
+	return func() {
+		_ = r.metaClient.Close()
+		_ = kvStore.Close()
+		_ = os.Remove(kvPath)
+	}, nil
+}
+
+func (r *restoreRunner) restoreKV(ctx context.Context) error {
+	name := r.kvManifest.FileName
+	r.log.Info("Restoring full metadata from backup", zap.String("name", name))
+
+	rc, err := r.source.Open(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to open KV backup %q: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := r.RestoreService.RestoreKVStore(ctx, rc); err != nil {
+		return fmt.Errorf("failed to upload KV backup %q: %w", name, err)
+	}
+
+	r.log.Info("Full metadata restored", zap.String("name", name))
 	return nil
 }
 
-func (r *restoreRunner) restoreShard(ctx context.Context, path string, manifest *influxdb.ManifestEntry) error {
-	shardPath := filepath.Join(path, manifest.FileName)
-	r.log.Info("Restoring shard from local backup", zap.Uint64("id", manifest.ShardID), zap.String("path", shardPath))
+func (r *restoreRunner) restoreShard(ctx context.Context, manifest *influxdb.ManifestEntry) error {
+	r.log.Info("Restoring shard from backup", zap.Uint64("id", manifest.ShardID), zap.String("name", manifest.FileName))
 
-	f, err := os.Open(shardPath)
+	rc, err := r.source.Open(ctx, manifest.FileName)
 	if err != nil {
-		return fmt.Errorf("failed to open local shard backup at %q: %w", shardPath, err)
+		return fmt.Errorf("failed to open shard backup %q: %w", manifest.FileName, err)
 	}
-	defer f.Close()
+	defer rc.Close()
 
-	gr, err := gzip.NewReader(f)
+	gr, err := gzip.NewReader(rc)
 	if err != nil {
-		return fmt.Errorf("failed to open gzip reader for local shard backup: %w", err)
+		return fmt.Errorf("failed to open gzip reader for shard backup: %w", err)
 	}
 	defer gr.Close()
 
 	if err := r.RestoreService.RestoreShard(ctx, manifest.ShardID, gr); err != nil {
-		return fmt.Errorf("failed to upload local shard backup at %q: %w", shardPath, err)
+		return fmt.Errorf("failed to upload shard backup %q: %w", manifest.FileName, err)
 	}
 	return nil
 }
@@ -320,26 +461,28 @@ func (r *restoreRunner) restoreBucket(ctx context.Context, bkt *influxdb.Bucket,
 	}
 
 	// Restore each shard for the bucket.
-	for _, m := range r.shardManifests {
-		if bkt.ID.String() != m.BucketID {
+	for shardID, chain := range r.shardManifests {
+		if bkt.ID.String() != chain[0].BucketID {
 			continue
 		}
 
 		// Skip if shard metadata was not imported.
-		newID, ok := shardIDMap[m.ShardID]
+		newID, ok := shardIDMap[shardID]
 		if !ok {
 			r.log.Warn(
 				"Meta info not found, skipping shard",
-				zap.Uint64("shard_id", m.ShardID),
+				zap.Uint64("shard_id", shardID),
 				zap.String("bucket_id", newBucket.ID.String()),
-				zap.String("path", filepath.Join(req.Path, m.FileName)),
+				zap.String("name", chain[len(chain)-1].FileName),
 			)
 			continue
 		}
 
-		m.ShardID = newID
-		if err := r.restoreShard(ctx, req.Path, m); err != nil {
-			return err
+		for _, m := range chain {
+			m.ShardID = newID
+			if err := r.restoreShard(ctx, m); err != nil {
+				return err
+			}
 		}
 	}
 
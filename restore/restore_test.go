@@ -0,0 +1,166 @@
+package restore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/backup"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb/engine/tsm1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeTestManifest(t *testing.T, dir, name string, m influxdb.Manifest) {
+	t.Helper()
+
+	for _, f := range m.Files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, f.FileName), nil, 0600))
+	}
+	buf, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), buf, 0600))
+}
+
+func shardFiles(chain []*influxdb.ManifestEntry) []string {
+	files := make([]string, 0, len(chain))
+	for _, e := range chain {
+		files = append(files, e.FileName)
+	}
+	return files
+}
+
+func TestLoadManifests_Incremental(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestManifest(t, dir, "20200101T000000Z.manifest", influxdb.Manifest{
+		KV: influxdb.ManifestKVEntry{FileName: "20200101T000000Z.bolt"},
+		Files: []influxdb.ManifestEntry{
+			{ShardID: 1, FileName: "20200101T000000Z.s1.tar.gz"},
+			{ShardID: 2, FileName: "20200101T000000Z.s2.tar.gz"},
+		},
+	})
+	writeTestManifest(t, dir, "20200102T000000Z.manifest", influxdb.Manifest{
+		KV: influxdb.ManifestKVEntry{FileName: "20200102T000000Z.bolt"},
+		Files: []influxdb.ManifestEntry{
+			{ShardID: 1, FileName: "20200102T000000Z.s1.tar.gz"},
+		},
+		Parent: "20200101T000000Z.manifest",
+	})
+	writeTestManifest(t, dir, "20200103T000000Z.manifest", influxdb.Manifest{
+		KV: influxdb.ManifestKVEntry{FileName: "20200103T000000Z.bolt"},
+		Files: []influxdb.ManifestEntry{
+			{ShardID: 1, FileName: "20200103T000000Z.s1.tar.gz"},
+			{ShardID: 3, FileName: "20200103T000000Z.s3.tar.gz"},
+		},
+		Parent: "20200102T000000Z.manifest",
+	})
+
+	r := restoreRunner{source: backup.NewLocalTarget(dir)}
+	require.NoError(t, r.loadManifests(context.Background()))
+
+	require.Equal(t, "20200103T000000Z.bolt", r.kvManifest.FileName)
+	require.Len(t, r.shardManifests, 3)
+	require.Equal(t, []string{
+		"20200101T000000Z.s1.tar.gz",
+		"20200102T000000Z.s1.tar.gz",
+		"20200103T000000Z.s1.tar.gz",
+	}, shardFiles(r.shardManifests[1]))
+	require.Equal(t, []string{"20200101T000000Z.s2.tar.gz"}, shardFiles(r.shardManifests[2]))
+	require.Equal(t, []string{"20200103T000000Z.s3.tar.gz"}, shardFiles(r.shardManifests[3]))
+
+	t.Run("missing parent", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, "20200102T000000Z.manifest")))
+
+		r := restoreRunner{source: backup.NewLocalTarget(dir)}
+		require.Error(t, r.loadManifests(context.Background()))
+	})
+}
+
+type recordingWriteService struct {
+	filter influxdb.BucketFilter
+	lines  bytes.Buffer
+}
+
+func (s *recordingWriteService) WriteTo(_ context.Context, filter influxdb.BucketFilter, r io.Reader) error {
+	s.filter = filter
+	_, err := s.lines.ReadFrom(r)
+	return err
+}
+
+// writeTestShardBackup writes a gzipped shard archive with a single TSM file
+// holding values for the series key of measurement cpu, tag host=a and field usage.
+func writeTestShardBackup(t *testing.T, path string, values tsm1.Values) {
+	t.Helper()
+
+	var tsm bytes.Buffer
+	w, err := tsm1.NewTSMWriter(&tsm)
+	require.NoError(t, err)
+	seriesKey := models.MakeKey([]byte("00000000000000010000000000000002"), models.NewTags(map[string]string{
+		models.MeasurementTagKey: "cpu",
+		"host":                   "a",
+		models.FieldKeyTagKey:    "usage",
+	}))
+	require.NoError(t, w.Write(tsm1.SeriesFieldKeyBytes(string(seriesKey), "usage"), values))
+	require.NoError(t, w.WriteIndex())
+	require.NoError(t, w.Close())
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "1/autogen/1/000000001-000000001.tsm",
+		Mode:     0600,
+		Size:     int64(tsm.Len()),
+		Typeflag: tar.TypeReg,
+	}))
+	_, err = tw.Write(tsm.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+func TestImportShard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestShardBackup(t, filepath.Join(dir, "20200101T000000Z.s1.tar.gz"), tsm1.Values{
+		tsm1.NewValue(10, 1.5),
+		tsm1.NewValue(20, 2.5),
+		tsm1.NewValue(30, 3.5),
+	})
+
+	svc := &recordingWriteService{}
+	r := restoreRunner{
+		Services: Services{WriteService: svc},
+		source:   backup.NewLocalTarget(dir),
+		log:      zap.NewNop(),
+	}
+	orgID, bucketID := platform.ID(1), platform.ID(2)
+	filter := influxdb.BucketFilter{OrganizationID: &orgID, ID: &bucketID}
+	req := Request{
+		Start: time.Unix(0, 15),
+		End:   time.Unix(0, 30),
+	}
+
+	err = r.importShard(context.Background(), req, &influxdb.ManifestEntry{ShardID: 1, FileName: "20200101T000000Z.s1.tar.gz"}, filter)
+	require.NoError(t, err)
+	require.Equal(t, filter, svc.filter)
+	require.Equal(t, "cpu,host=a usage=2.5 20\ncpu,host=a usage=3.5 30\n", svc.lines.String())
+}
@@ -0,0 +1,109 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+// Silence mutes the notifications of the statuses it matches between its
+// start and end time. A status is matched when all of the matchers set on
+// the silence match: the status comes from one of CheckIDs, it is handled
+// by one of RuleIDs, it has all of Tags, and its check or rule carries one
+// of LabelIDs. Matched statuses are not sent to the endpoint of the rule,
+// they are recorded as silenced in the monitoring bucket instead.
+type Silence struct {
+	ID          platform.ID   `json:"id,omitempty"`
+	OrgID       platform.ID   `json:"orgID"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	StartTime   time.Time     `json:"startTime"`
+	EndTime     time.Time     `json:"endTime"`
+	CheckIDs    []platform.ID `json:"checkIDs,omitempty"`
+	RuleIDs     []platform.ID `json:"ruleIDs,omitempty"`
+	Tags        []Tag         `json:"tags,omitempty"`
+	LabelIDs    []platform.ID `json:"labelIDs,omitempty"`
+	CRUDLog
+}
+
+// Valid returns an error if the silence is not valid.
+func (s *Silence) Valid() error {
+	if !s.OrgID.Valid() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Silence OrgID is invalid",
+		}
+	}
+	if s.Name == "" {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Silence Name can't be empty",
+		}
+	}
+	if s.StartTime.IsZero() || s.EndTime.IsZero() {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Silence must have a start and an end time",
+		}
+	}
+	if !s.EndTime.After(s.StartTime) {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Silence end time must be after its start time",
+		}
+	}
+	if len(s.CheckIDs) == 0 && len(s.RuleIDs) == 0 && len(s.Tags) == 0 && len(s.LabelIDs) == 0 {
+		return &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "Silence must match on at least one of checkIDs, ruleIDs, tags or labelIDs",
+		}
+	}
+	for _, ids := range [][]platform.ID{s.CheckIDs, s.RuleIDs, s.LabelIDs} {
+		for _, id := range ids {
+			if !id.Valid() {
+				return &errors.Error{
+					Code: errors.EInvalid,
+					Msg:  "Silence matches an invalid ID",
+				}
+			}
+		}
+	}
+	for _, t := range s.Tags {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActiveAt returns true if the silence has not ended at t.
+func (s *Silence) ActiveAt(t time.Time) bool {
+	return t.Before(s.EndTime)
+}
+
+// SilenceFilter represents a set of filters that restrict the returned silences.
+type SilenceFilter struct {
+	OrgID *platform.ID
+}
+
+// SilenceService represents a service for managing silences.
+type SilenceService interface {
+	// FindSilenceByID returns a single silence by ID.
+	FindSilenceByID(ctx context.Context, id platform.ID) (*Silence, error)
+
+	// FindSilences returns a list of silences that match filter and the total count of matching silences.
+	// Additional options provide pagination & sorting.
+	FindSilences(ctx context.Context, filter SilenceFilter, opt ...FindOptions) ([]*Silence, int, error)
+
+	// CreateSilence creates a new silence and sets s.ID with the new identifier.
+	CreateSilence(ctx context.Context, s *Silence) error
+
+	// UpdateSilence replaces the silence identified by id with s.
+	// Returns the new silence after update.
+	UpdateSilence(ctx context.Context, id platform.ID, s *Silence) (*Silence, error)
+
+	// DeleteSilence removes a silence by ID.
+	DeleteSilence(ctx context.Context, id platform.ID) error
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	_ "github.com/influxdata/influxdb/v2/tsdb/engine"
 	_ "github.com/influxdata/influxdb/v2/tsdb/index/tsi1"
@@ -90,9 +91,13 @@ type MetaClient interface {
 type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
+	DeleteShard(id uint64) error
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	SeriesSketches(database string) (estimator.Sketch, estimator.Sketch, error)
 	ShardGroup(ids []uint64) tsdb.ShardGroup
 	Shards(ids []uint64) []*tsdb.Shard
+	Statistics(tags map[string]string) []models.Statistic
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
 }
@@ -346,6 +351,20 @@ func (e *Engine) BackupShard(ctx context.Context, w io.Writer, shardID uint64, s
 	return e.tsdbStore.BackupShard(shardID, since, w)
 }
 
+func (e *Engine) ExportShard(ctx context.Context, w io.Writer, shardID uint64, start, end time.Time) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	return e.tsdbStore.ExportShard(shardID, start, end, w)
+}
+
 func (e *Engine) RestoreKVStore(ctx context.Context, r io.Reader) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// BucketByIDFinder looks up a single bucket by ID.
+type BucketByIDFinder interface {
+	FindBucketByID(ctx context.Context, id platform.ID) (*influxdb.Bucket, error)
+}
+
+// SchemaPointsWriter wraps an underlying points writer and drops the points
+// written to buckets with an explicit schema which do not match the schema of
+// their measurement.
+//
+// The schema of each bucket is cached. The services returned by
+// WrapBucketService and WrapMeasurementSchemaService invalidate it when the
+// bucket or its measurement schemas change.
+type SchemaPointsWriter struct {
+	// Wrapped points writer. Points matching the schema are written here.
+	Underlying PointsWriter
+
+	// Service used to look up the schema type of buckets.
+	BucketFinder BucketByIDFinder
+
+	// Service used to look up the measurement schemas of explicit buckets.
+	MeasurementSchemaService influxdb.MeasurementSchemaService
+
+	mu      sync.RWMutex
+	buckets map[platform.ID]*bucketSchema
+	// generation is incremented whenever a bucket is invalidated, so a
+	// schema looked up concurrently is not cached after it changed.
+	generation uint64
+}
+
+// bucketSchema is the cached schema of a bucket.
+type bucketSchema struct {
+	explicit     bool
+	measurements map[string]*measurementSchema
+}
+
+// measurementSchema is the cached schema of a measurement.
+type measurementSchema struct {
+	columns   map[string]influxdb.MeasurementSchemaColumn
+	timestamp bool
+}
+
+// WritePoints writes the points matching the bucket schema to the underlying
+// PointsWriter. If any point is dropped a tsdb.PartialWriteError describing the
+// first mismatch is returned.
+func (w *SchemaPointsWriter) WritePoints(ctx context.Context, orgID platform.ID, bucketID platform.ID, p []models.Point) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	schema, err := w.bucketSchema(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	if !schema.explicit {
+		return w.Underlying.WritePoints(ctx, orgID, bucketID, p)
+	}
+	schemas := schema.measurements
+
+	var (
+		valid         = make([]models.Point, 0, len(p))
+		reason        string
+		dropped       int
+		droppedPoints []tsdb.DroppedPoint
+	)
+	for _, pt := range p {
+		if err := validatePointSchema(schemas, pt); err != nil {
+			if dropped == 0 {
+				reason = err.Error()
+			}
+			dropped++
+			droppedPoints = append(droppedPoints, tsdb.DroppedPoint{Point: pt, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, pt)
+	}
+
+	if len(valid) > 0 {
+		if err := w.Underlying.WritePoints(ctx, orgID, bucketID, valid); err != nil {
+			partialErr, ok := err.(tsdb.PartialWriteError)
+			if !ok || dropped == 0 {
+				return err
+			}
+			partialErr.Dropped += dropped
+			partialErr.Reason = reason + "; " + partialErr.Reason
+			partialErr.DroppedPoints = append(droppedPoints, partialErr.DroppedPoints...)
+			return partialErr
+		}
+	}
+
+	if dropped > 0 {
+		return tsdb.PartialWriteError{
+			Reason:        reason,
+			Dropped:       dropped,
+			DroppedPoints: droppedPoints,
+		}
+	}
+	return nil
+}
+
+// bucketSchema returns the schema of a bucket, looking it up if it is not
+// cached.
+func (w *SchemaPointsWriter) bucketSchema(ctx context.Context, bucketID platform.ID) (*bucketSchema, error) {
+	w.mu.RLock()
+	schema, ok := w.buckets[bucketID]
+	generation := w.generation
+	w.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	bkt, err := w.BucketFinder.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return nil, err
+	}
+	schema = &bucketSchema{explicit: bkt.SchemaType == influxdb.SchemaTypeExplicit}
+	if schema.explicit {
+		ms, err := w.MeasurementSchemaService.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: bucketID})
+		if err != nil {
+			return nil, err
+		}
+		schema.measurements = make(map[string]*measurementSchema, len(ms))
+		for _, m := range ms {
+			measurement := &measurementSchema{
+				columns: make(map[string]influxdb.MeasurementSchemaColumn, len(m.Columns)),
+			}
+			for _, c := range m.Columns {
+				measurement.columns[c.Name] = c
+				if c.Type == influxdb.SemanticColumnTypeTimestamp {
+					measurement.timestamp = true
+				}
+			}
+			schema.measurements[m.Name] = measurement
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.generation == generation {
+		if w.buckets == nil {
+			w.buckets = make(map[platform.ID]*bucketSchema)
+		}
+		w.buckets[bucketID] = schema
+	}
+	return schema, nil
+}
+
+// invalidate drops the cached schema of a bucket.
+func (w *SchemaPointsWriter) invalidate(bucketID platform.ID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.buckets, bucketID)
+	w.generation++
+}
+
+// WrapBucketService returns a bucket service invalidating the cached schema
+// of the buckets it updates or deletes.
+func (w *SchemaPointsWriter) WrapBucketService(s influxdb.BucketService) influxdb.BucketService {
+	return &schemaCacheBucketService{BucketService: s, w: w}
+}
+
+// WrapMeasurementSchemaService returns a measurement schema service
+// invalidating the cached schema of the buckets whose measurement schemas it
+// creates or updates.
+func (w *SchemaPointsWriter) WrapMeasurementSchemaService(s influxdb.MeasurementSchemaService) influxdb.MeasurementSchemaService {
+	return &schemaCacheMeasurementSchemaService{MeasurementSchemaService: s, w: w}
+}
+
+type schemaCacheBucketService struct {
+	influxdb.BucketService
+	w *SchemaPointsWriter
+}
+
+func (s *schemaCacheBucketService) UpdateBucket(ctx context.Context, id platform.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+	defer s.w.invalidate(id)
+	return s.BucketService.UpdateBucket(ctx, id, upd)
+}
+
+func (s *schemaCacheBucketService) DeleteBucket(ctx context.Context, id platform.ID) error {
+	defer s.w.invalidate(id)
+	return s.BucketService.DeleteBucket(ctx, id)
+}
+
+type schemaCacheMeasurementSchemaService struct {
+	influxdb.MeasurementSchemaService
+	w *SchemaPointsWriter
+}
+
+func (s *schemaCacheMeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, m *influxdb.MeasurementSchema) error {
+	defer s.w.invalidate(m.BucketID)
+	return s.MeasurementSchemaService.CreateMeasurementSchema(ctx, m)
+}
+
+func (s *schemaCacheMeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, bucketID, id platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	defer s.w.invalidate(bucketID)
+	return s.MeasurementSchemaService.UpdateMeasurementSchema(ctx, bucketID, id, columns)
+}
+
+// validatePointSchema returns an error describing how pt does not match the
+// measurement schemas of a bucket, if it does not.
+func validatePointSchema(schemas map[string]*measurementSchema, pt models.Point) error {
+	name := string(pt.Name())
+	measurement, ok := schemas[name]
+	if !ok {
+		return fmt.Errorf("measurement %q is not defined by the bucket schema", name)
+	}
+	if !measurement.timestamp {
+		return fmt.Errorf("schema of measurement %q does not define a timestamp column", name)
+	}
+	columns := measurement.columns
+
+	for _, tag := range pt.Tags() {
+		c, ok := columns[string(tag.Key)]
+		if !ok {
+			return fmt.Errorf("tag %q is not defined by the schema of measurement %q", tag.Key, name)
+		}
+		if c.Type != influxdb.SemanticColumnTypeTag {
+			return fmt.Errorf("column %q of measurement %q is a %s, not a tag", tag.Key, name, c.Type)
+		}
+	}
+
+	iter := pt.FieldIterator()
+	for iter.Next() {
+		key := iter.FieldKey()
+		c, ok := columns[string(key)]
+		if !ok {
+			return fmt.Errorf("field %q is not defined by the schema of measurement %q", key, name)
+		}
+		if c.Type != influxdb.SemanticColumnTypeField {
+			return fmt.Errorf("column %q of measurement %q is a %s, not a field", key, name, c.Type)
+		}
+		if got := fieldDataType(iter.Type()); got != *c.DataType {
+			return fmt.Errorf("field %q of measurement %q has type %s, expected %s", key, name, got, *c.DataType)
+		}
+	}
+	return nil
+}
+
+func fieldDataType(t models.FieldType) influxdb.SchemaColumnDataType {
+	switch t {
+	case models.Float:
+		return influxdb.SchemaColumnDataTypeFloat
+	case models.Integer:
+		return influxdb.SchemaColumnDataTypeInteger
+	case models.Unsigned:
+		return influxdb.SchemaColumnDataTypeUnsigned
+	case models.String:
+		return influxdb.SchemaColumnDataTypeString
+	case models.Boolean:
+		return influxdb.SchemaColumnDataTypeBoolean
+	default:
+		return influxdb.SchemaColumnDataType("unknown")
+	}
+}
@@ -0,0 +1,202 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPointsWriter struct {
+	points []models.Point
+}
+
+func (w *recordingPointsWriter) WritePoints(_ context.Context, _, _ platform.ID, p []models.Point) error {
+	w.points = append(w.points, p...)
+	return nil
+}
+
+type measurementSchemaFinder struct {
+	influxdb.MeasurementSchemaService
+	schemas []*influxdb.MeasurementSchema
+	finds   int
+}
+
+func (s *measurementSchemaFinder) FindMeasurementSchemas(context.Context, influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	s.finds++
+	return s.schemas, nil
+}
+
+func (s *measurementSchemaFinder) UpdateMeasurementSchema(_ context.Context, _, _ platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	s.schemas[0].Columns = columns
+	return s.schemas[0], nil
+}
+
+func TestSchemaPointsWriter_WritePoints(t *testing.T) {
+	schemas := &measurementSchemaFinder{
+		schemas: []*influxdb.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+				{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+				{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat.Ptr()},
+			},
+		}, {
+			Name: "disk",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "free", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeInteger.Ptr()},
+			},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		schemaType influxdb.SchemaType
+		lines      string
+		wantLines  int
+		wantReason string
+	}{
+		{
+			name:       "implicit bucket",
+			schemaType: influxdb.SchemaTypeImplicit,
+			lines:      "mem,region=west free=1i 1\n",
+			wantLines:  1,
+		},
+		{
+			name:       "matching points",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu,host=a usage=1 1\ncpu usage=2 2\n",
+			wantLines:  2,
+		},
+		{
+			name:       "unknown measurement",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu,host=a usage=1 1\nmem free=1i 1\n",
+			wantLines:  1,
+			wantReason: `measurement "mem" is not defined by the bucket schema`,
+		},
+		{
+			name:       "undefined tag",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu,region=west usage=1 1\n",
+			wantReason: `tag "region" is not defined by the schema of measurement "cpu"`,
+		},
+		{
+			name:       "field as tag",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu,usage=high usage=1 1\n",
+			wantReason: `column "usage" of measurement "cpu" is a field, not a tag`,
+		},
+		{
+			name:       "undefined field",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu usage=1,idle=2 1\n",
+			wantReason: `field "idle" is not defined by the schema of measurement "cpu"`,
+		},
+		{
+			name:       "field type mismatch",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "cpu usage=1i 1\ncpu usage=\"x\" 2\n",
+			wantReason: `field "usage" of measurement "cpu" has type integer, expected float`,
+		},
+		{
+			name:       "no timestamp column",
+			schemaType: influxdb.SchemaTypeExplicit,
+			lines:      "disk free=1i 1\n",
+			wantReason: `schema of measurement "disk" does not define a timestamp column`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := &recordingPointsWriter{}
+			w := &storage.SchemaPointsWriter{
+				Underlying: underlying,
+				BucketFinder: &mock.BucketService{
+					FindBucketByIDFn: func(_ context.Context, id platform.ID) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id, SchemaType: tt.schemaType}, nil
+					},
+				},
+				MeasurementSchemaService: schemas,
+			}
+
+			points, err := models.ParsePointsString(tt.lines)
+			require.NoError(t, err)
+
+			err = w.WritePoints(context.Background(), 1, 2, points)
+			assert.Len(t, underlying.points, tt.wantLines)
+			if tt.wantReason == "" {
+				require.NoError(t, err)
+				return
+			}
+			partialErr, ok := err.(tsdb.PartialWriteError)
+			require.Truef(t, ok, "expected a partial write error, got %v", err)
+			assert.Equal(t, tt.wantReason, partialErr.Reason)
+			assert.Equal(t, len(points)-tt.wantLines, partialErr.Dropped)
+			require.Len(t, partialErr.DroppedPoints, partialErr.Dropped)
+			assert.Equal(t, tt.wantReason, partialErr.DroppedPoints[0].Reason)
+		})
+	}
+}
+
+func TestSchemaPointsWriter_Cache(t *testing.T) {
+	schemas := &measurementSchemaFinder{
+		schemas: []*influxdb.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+				{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat.Ptr()},
+			},
+		}},
+	}
+	var bucketFinds int
+	bucketSvc := &mock.BucketService{
+		FindBucketByIDFn: func(_ context.Context, id platform.ID) (*influxdb.Bucket, error) {
+			bucketFinds++
+			return &influxdb.Bucket{ID: id, SchemaType: influxdb.SchemaTypeExplicit}, nil
+		},
+		UpdateBucketFn: func(_ context.Context, id platform.ID, _ influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+			return &influxdb.Bucket{ID: id, SchemaType: influxdb.SchemaTypeExplicit}, nil
+		},
+	}
+	w := &storage.SchemaPointsWriter{
+		Underlying:               &recordingPointsWriter{},
+		BucketFinder:             bucketSvc,
+		MeasurementSchemaService: schemas,
+	}
+	write := func(lines string) error {
+		points, err := models.ParsePointsString(lines)
+		require.NoError(t, err)
+		return w.WritePoints(context.Background(), 1, 2, points)
+	}
+
+	// The schema is looked up by the first write only.
+	require.Error(t, write("cpu usage=1,idle=2 1\n"))
+	require.Error(t, write("cpu usage=1,idle=2 2\n"))
+	assert.Equal(t, 1, bucketFinds)
+	assert.Equal(t, 1, schemas.finds)
+
+	// Updating the measurement schema applies to the next write.
+	columns := append(schemas.schemas[0].Columns, influxdb.MeasurementSchemaColumn{
+		Name: "idle", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat.Ptr(),
+	})
+	_, err := w.WrapMeasurementSchemaService(schemas).UpdateMeasurementSchema(context.Background(), 2, 3, columns)
+	require.NoError(t, err)
+	require.NoError(t, write("cpu usage=1,idle=2 3\n"))
+	assert.Equal(t, 2, bucketFinds)
+	assert.Equal(t, 2, schemas.finds)
+
+	// So does updating the bucket.
+	_, err = w.WrapBucketService(bucketSvc).UpdateBucket(context.Background(), 2, influxdb.BucketUpdate{})
+	require.NoError(t, err)
+	require.NoError(t, write("cpu usage=1,idle=2 4\n"))
+	assert.Equal(t, 3, bucketFinds)
+	assert.Equal(t, 3, schemas.finds)
+}
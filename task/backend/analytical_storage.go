@@ -26,6 +26,7 @@ const (
 	finishedAtField   = "finishedAt"
 	requestedAtField  = "requestedAt"
 	logField          = "logs"
+	retryField        = "retry"
 
 	taskIDTag = "taskID"
 	statusTag = "status"
@@ -421,6 +422,10 @@ func (re *runReader) readRuns(cr flux.ColReader) error {
 					continue
 				}
 				r.FinishedAt = finished.UTC()
+			case retryField:
+				if col.Type == flux.TInt && cr.Ints(j).IsValid(i) {
+					r.Retry = int(cr.Ints(j).Value(i))
+				}
 			case logField:
 				logBytes := bytes.TrimSpace(cr.Strings(j).Value(i))
 				if len(logBytes) != 0 {
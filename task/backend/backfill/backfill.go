@@ -0,0 +1,295 @@
+// Package backfill runs tasks over historical time ranges.
+package backfill
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/task/backend/executor"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"go.uber.org/zap"
+)
+
+// maxFinishedBackfills is the number of finished backfills kept per task.
+const maxFinishedBackfills = 10
+
+var _ taskmodel.BackfillService = (*Service)(nil)
+
+// Executor is an abstraction of the task executor with only the functions
+// needed to backfill a task.
+type Executor interface {
+	PromisedExecute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) (executor.Promise, error)
+}
+
+// Service backfills tasks through the executor, keeping no more runs of a
+// task in progress than its concurrency option allows.
+//
+// Backfills are held in memory: a backfill interrupted by a restart of the
+// server is not resumed, although the runs it had already created are.
+type Service struct {
+	log  *zap.Logger
+	ts   taskmodel.TaskService
+	ex   Executor
+	lang fluxlang.FluxLanguageService
+
+	IDGen platform.IDGenerator
+
+	mu        sync.Mutex
+	backfills map[platform.ID][]*backfill // by task ID, oldest first
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+type backfill struct {
+	taskmodel.Backfill
+	cancel context.CancelFunc
+}
+
+// NewService returns a Service which looks tasks up in ts and runs them on ex.
+func NewService(log *zap.Logger, ts taskmodel.TaskService, ex Executor, lang fluxlang.FluxLanguageService) *Service {
+	return &Service{
+		log:       log,
+		ts:        ts,
+		ex:        ex,
+		lang:      lang,
+		IDGen:     snowflake.NewDefaultIDGenerator(),
+		backfills: make(map[platform.ID][]*backfill),
+		now:       time.Now,
+	}
+}
+
+// CreateBackfill starts running the task for every tick of its schedule from
+// start to stop, both inclusive. The runs are enqueued in the background.
+func (s *Service) CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*taskmodel.Backfill, error) {
+	t, err := s.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if !start.Before(stop) {
+		return nil, taskmodel.ErrInvalidBackfill("start must be before stop")
+	}
+	if stop.After(now) {
+		return nil, taskmodel.ErrInvalidBackfill("stop must not be in the future")
+	}
+
+	ticks, err := scheduleTicks(t.EffectiveCron(), start, stop)
+	if err != nil {
+		return nil, err
+	}
+	if len(ticks) == 0 {
+		return nil, taskmodel.ErrInvalidBackfill("no runs are scheduled between start and stop")
+	}
+
+	opts, err := options.FromScriptAST(s.lang, t.Flux)
+	if err != nil {
+		return nil, taskmodel.ErrTaskOptionParse(err)
+	}
+	concurrency := 1
+	if opts.Concurrency != nil && *opts.Concurrency > 0 {
+		concurrency = int(*opts.Concurrency)
+	}
+
+	// the runs outlive the request, but keep its authorization
+	runCtx := context.Background()
+	if auth, err := icontext.GetAuthorizer(ctx); err == nil {
+		runCtx = icontext.SetAuthorizer(runCtx, auth)
+	}
+	runCtx, cancel := context.WithCancel(runCtx)
+
+	b := &backfill{
+		Backfill: taskmodel.Backfill{
+			ID:        s.IDGen.ID(),
+			TaskID:    t.ID,
+			Start:     start.UTC(),
+			Stop:      stop.UTC(),
+			Status:    taskmodel.BackfillRunning,
+			Total:     len(ticks),
+			CreatedAt: now,
+		},
+		cancel: cancel,
+	}
+
+	s.mu.Lock()
+	s.backfills[t.ID] = append(s.backfills[t.ID], b)
+	created := b.Backfill
+	s.mu.Unlock()
+
+	go s.run(runCtx, b, ticks, concurrency)
+	return &created, nil
+}
+
+// FindBackfillByID returns a single backfill of a task.
+func (s *Service) FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*taskmodel.Backfill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.backfills[taskID] {
+		if b.ID == id {
+			found := b.Backfill
+			return &found, nil
+		}
+	}
+	return nil, taskmodel.ErrBackfillNotFound
+}
+
+// FindBackfills returns the backfills of a task, latest first.
+func (s *Service) FindBackfills(ctx context.Context, taskID platform.ID) ([]*taskmodel.Backfill, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bs := make([]*taskmodel.Backfill, 0, len(s.backfills[taskID]))
+	for _, b := range s.backfills[taskID] {
+		found := b.Backfill
+		bs = append(bs, &found)
+	}
+	sort.SliceStable(bs, func(i, j int) bool {
+		return bs[i].CreatedAt.After(bs[j].CreatedAt)
+	})
+	return bs, nil
+}
+
+// CancelBackfill stops a backfill, canceling the runs it has in progress.
+func (s *Service) CancelBackfill(ctx context.Context, taskID, id platform.ID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, b := range s.backfills[taskID] {
+		if b.ID == id {
+			if !b.Done() {
+				b.Status = taskmodel.BackfillCanceled
+			}
+			b.cancel()
+			return nil
+		}
+	}
+	return taskmodel.ErrBackfillNotFound
+}
+
+// run enqueues a run for each tick, waiting for a run to finish whenever
+// concurrency runs are in progress.
+func (s *Service) run(ctx context.Context, b *backfill, ticks []time.Time, concurrency int) {
+	log := s.log.With(
+		zap.String("task_id", b.TaskID.String()),
+		zap.String("backfill_id", b.ID.String()),
+	)
+	log.Info("Backfill started", zap.Time("start", b.Start), zap.Time("stop", b.Stop), zap.Int("runs", b.Total))
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+enqueue:
+	for _, tick := range ticks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break enqueue
+		}
+
+		p, err := s.ex.PromisedExecute(ctx, scheduler.ID(b.TaskID), tick, s.now())
+		if err != nil {
+			log.Info("Failed to enqueue backfill run", zap.Time("scheduled_for", tick), zap.Error(err))
+			s.finishRun(ctx, b, err)
+			<-sem
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-p.Done()
+			s.finishRun(ctx, b, p.Error())
+			<-sem
+		}()
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := s.now().UTC()
+	b.FinishedAt = &finished
+	switch {
+	case b.Status == taskmodel.BackfillCanceled:
+	case b.Failed > 0:
+		b.Status = taskmodel.BackfillFailed
+	default:
+		b.Status = taskmodel.BackfillSuccess
+	}
+	b.cancel()
+	s.pruneFinished(b.TaskID)
+
+	log.Info("Backfill finished", zap.String("status", string(b.Status)),
+		zap.Int("succeeded", b.Succeeded), zap.Int("failed", b.Failed))
+}
+
+// finishRun counts a finished run of b. Runs which failed because the
+// backfill was canceled are not counted.
+func (s *Service) finishRun(ctx context.Context, b *backfill, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		b.Failed++
+	} else {
+		b.Succeeded++
+	}
+}
+
+// pruneFinished drops the oldest finished backfills of a task beyond
+// maxFinishedBackfills. It must be called with s.mu held.
+func (s *Service) pruneFinished(taskID platform.ID) {
+	var finished int
+	for _, b := range s.backfills[taskID] {
+		if b.Done() {
+			finished++
+		}
+	}
+
+	kept := s.backfills[taskID][:0]
+	for _, b := range s.backfills[taskID] {
+		if b.Done() && finished > maxFinishedBackfills {
+			finished--
+			continue
+		}
+		kept = append(kept, b)
+	}
+	s.backfills[taskID] = kept
+}
+
+// scheduleTicks returns the times from start to stop, both inclusive, at
+// which the schedule described by cron triggers.
+func scheduleTicks(cron string, start, stop time.Time) ([]time.Time, error) {
+	sch, t, err := scheduler.NewSchedule(cron, start.Add(-time.Second))
+	if err != nil {
+		return nil, taskmodel.ErrInvalidBackfill(err.Error())
+	}
+
+	var ticks []time.Time
+	for {
+		if t, err = sch.Next(t); err != nil {
+			return nil, taskmodel.ErrInvalidBackfill(err.Error())
+		}
+		if t.After(stop) {
+			return ticks, nil
+		}
+		if len(ticks) == taskmodel.MaxBackfillRuns {
+			return nil, taskmodel.ErrInvalidBackfill("the range spans more runs than the limit of a single backfill; split it into smaller ranges")
+		}
+		ticks = append(ticks, t)
+	}
+}
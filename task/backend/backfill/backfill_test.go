@@ -0,0 +1,204 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/query/fluxlang"
+	"github.com/influxdata/influxdb/v2/task/backend/executor"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/taskmodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const taskID = platform.ID(1)
+
+type fakePromise struct {
+	id   platform.ID
+	done chan struct{}
+	err  error
+}
+
+func (p *fakePromise) ID() platform.ID               { return p.id }
+func (p *fakePromise) Cancel(ctx context.Context)    {}
+func (p *fakePromise) Done() <-chan struct{}         { return p.done }
+func (p *fakePromise) Error() error                  { return p.err }
+func (p *fakePromise) finish(err error) *fakePromise { p.err = err; close(p.done); return p }
+
+// fakeExecutor records the runs it is asked to execute. Runs stay in progress
+// until finished by the test.
+type fakeExecutor struct {
+	mu       sync.Mutex
+	promises map[time.Time]*fakePromise
+	running  int
+	maxRun   int
+	started  chan time.Time
+}
+
+func newFakeExecutor() *fakeExecutor {
+	return &fakeExecutor{
+		promises: make(map[time.Time]*fakePromise),
+		started:  make(chan time.Time, 100),
+	}
+}
+
+func (e *fakeExecutor) PromisedExecute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) (executor.Promise, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	p := &fakePromise{id: platform.ID(len(e.promises) + 1), done: make(chan struct{})}
+	e.promises[scheduledFor] = p
+	e.running++
+	if e.running > e.maxRun {
+		e.maxRun = e.running
+	}
+	e.started <- scheduledFor
+	return p, nil
+}
+
+func (e *fakeExecutor) finish(t *testing.T, scheduledFor time.Time, err error) {
+	t.Helper()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p, ok := e.promises[scheduledFor]
+	require.True(t, ok, "no run for %s", scheduledFor)
+	e.running--
+	p.finish(err)
+}
+
+func (e *fakeExecutor) next(t *testing.T) time.Time {
+	t.Helper()
+
+	select {
+	case ts := <-e.started:
+		return ts
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a run")
+		return time.Time{}
+	}
+}
+
+func newTestService(t *testing.T, flux string) (*Service, *fakeExecutor) {
+	ts := mock.NewTaskService()
+	ts.FindTaskByIDFn = func(ctx context.Context, id platform.ID) (*taskmodel.Task, error) {
+		if id != taskID {
+			return nil, taskmodel.ErrTaskNotFound
+		}
+		return &taskmodel.Task{ID: taskID, Every: "1h", Flux: flux}, nil
+	}
+
+	ex := newFakeExecutor()
+	s := NewService(zaptest.NewLogger(t), ts, ex, fluxlang.DefaultService)
+	s.now = func() time.Time { return time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC) }
+	return s, ex
+}
+
+func waitForStatus(t *testing.T, s *Service, id platform.ID, status taskmodel.BackfillStatus) *taskmodel.Backfill {
+	t.Helper()
+
+	var b *taskmodel.Backfill
+	require.Eventually(t, func() bool {
+		var err error
+		b, err = s.FindBackfillByID(context.Background(), taskID, id)
+		require.NoError(t, err)
+		return b.Status == status
+	}, time.Second, 5*time.Millisecond)
+	return b
+}
+
+func TestService_CreateBackfill(t *testing.T) {
+	s, ex := newTestService(t, `option task = {name: "rollup", every: 1h, concurrency: 2} from(bucket: "a") |> to(bucket: "b")`)
+
+	start := time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC)
+	stop := time.Date(2021, 1, 1, 4, 0, 0, 0, time.UTC)
+	b, err := s.CreateBackfill(context.Background(), taskID, start, stop)
+	require.NoError(t, err)
+	assert.Equal(t, taskmodel.BackfillRunning, b.Status)
+	assert.Equal(t, 4, b.Total)
+
+	// no more than the task's concurrency runs are in progress
+	first, second := ex.next(t), ex.next(t)
+	assert.Equal(t, time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2021, 1, 1, 2, 0, 0, 0, time.UTC), second)
+	select {
+	case ts := <-ex.started:
+		t.Fatalf("unexpected run for %s beyond concurrency", ts)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ex.finish(t, first, nil)
+	third := ex.next(t)
+	ex.finish(t, second, errors.New("query failed"))
+	fourth := ex.next(t)
+	assert.Equal(t, time.Date(2021, 1, 1, 4, 0, 0, 0, time.UTC), fourth)
+	ex.finish(t, third, nil)
+	ex.finish(t, fourth, nil)
+
+	got := waitForStatus(t, s, b.ID, taskmodel.BackfillFailed)
+	assert.Equal(t, 3, got.Succeeded)
+	assert.Equal(t, 1, got.Failed)
+	assert.NotNil(t, got.FinishedAt)
+	assert.Equal(t, 2, ex.maxRun)
+
+	bs, err := s.FindBackfills(context.Background(), taskID)
+	require.NoError(t, err)
+	require.Len(t, bs, 1)
+	assert.Equal(t, b.ID, bs[0].ID)
+}
+
+func TestService_CancelBackfill(t *testing.T) {
+	s, ex := newTestService(t, `option task = {name: "rollup", every: 1h} from(bucket: "a") |> to(bucket: "b")`)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := s.CreateBackfill(context.Background(), taskID, start, start.Add(10*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 11, b.Total)
+
+	first := ex.next(t)
+	require.NoError(t, s.CancelBackfill(context.Background(), taskID, b.ID))
+	ex.finish(t, first, taskmodel.ErrRunCanceled)
+
+	got := waitForStatus(t, s, b.ID, taskmodel.BackfillCanceled)
+	require.Eventually(t, func() bool {
+		got, err = s.FindBackfillByID(context.Background(), taskID, b.ID)
+		return err == nil && got.FinishedAt != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Zero(t, got.Succeeded)
+	assert.Zero(t, got.Failed)
+
+	err = s.CancelBackfill(context.Background(), taskID, platform.ID(1234))
+	assert.Equal(t, taskmodel.ErrBackfillNotFound, err)
+}
+
+func TestService_CreateBackfill_Invalid(t *testing.T) {
+	s, _ := newTestService(t, `option task = {name: "rollup", every: 1h} from(bucket: "a") |> to(bucket: "b")`)
+	now := s.now()
+
+	for _, tt := range []struct {
+		name        string
+		start, stop time.Time
+	}{
+		{name: "stop before start", start: now.Add(-time.Hour), stop: now.Add(-2 * time.Hour)},
+		{name: "stop in the future", start: now.Add(-time.Hour), stop: now.Add(time.Hour)},
+		{name: "no runs in range", start: now.Add(-50 * time.Minute), stop: now.Add(-10 * time.Minute)},
+		{name: "too many runs", start: now.Add(-(taskmodel.MaxBackfillRuns + 1) * time.Hour), stop: now},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateBackfill(context.Background(), taskID, tt.start, tt.stop)
+			require.Error(t, err)
+			assert.Equal(t, errors2.EInvalid, errors2.ErrorCode(err))
+		})
+	}
+
+	_, err := s.CreateBackfill(context.Background(), platform.ID(1234), now.Add(-time.Hour), now)
+	assert.Equal(t, taskmodel.ErrTaskNotFound, err)
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -27,11 +28,21 @@ const (
 	maxPromises       = 1000
 	defaultMaxWorkers = 100
 
+	defaultRetryBackoff    = 10 * time.Second
+	defaultMaxRetryBackoff = 10 * time.Minute
+
+	defaultDependencyTimeout = time.Hour
+
 	lastSuccessOption = "tasks.lastSuccessTime"
 )
 
 var _ scheduler.Executor = (*Executor)(nil)
 
+var (
+	errDependencyFailed  = errors.New("a task it depends on failed")
+	errDependencyTimeout = errors.New("timed out waiting for the tasks it depends on")
+)
+
 type PermissionService interface {
 	FindPermissionForUser(ctx context.Context, UserID platform.ID) (influxdb.PermissionSet, error)
 }
@@ -63,6 +74,9 @@ type executorConfig struct {
 	systemBuildCompiler    CompilerBuilderFunc
 	nonSystemBuildCompiler CompilerBuilderFunc
 	flagger                feature.Flagger
+	retryBackoff           time.Duration
+	maxRetryBackoff        time.Duration
+	dependencyTimeout      time.Duration
 }
 
 type executorOption func(*executorConfig)
@@ -74,6 +88,23 @@ func WithMaxWorkers(n int) executorOption {
 	}
 }
 
+// WithRetryBackoff specifies how long the Executor waits before retrying a
+// failed run. The wait doubles with each retry of the same run, up to max.
+func WithRetryBackoff(initial, max time.Duration) executorOption {
+	return func(o *executorConfig) {
+		o.retryBackoff = initial
+		o.maxRetryBackoff = max
+	}
+}
+
+// WithDependencyTimeout specifies how long a run waits for the tasks its task
+// depends on to succeed before it fails.
+func WithDependencyTimeout(d time.Duration) executorOption {
+	return func(o *executorConfig) {
+		o.dependencyTimeout = d
+	}
+}
+
 // CompilerBuilderFunc is a function that yields a new flux.Compiler. The
 // context.Context provided can be assumed to be an authorized context.
 type CompilerBuilderFunc func(ctx context.Context, query string, ts CompilerBuilderTimestamps) (flux.Compiler, error)
@@ -132,6 +163,9 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		maxWorkers:             defaultMaxWorkers,
 		systemBuildCompiler:    NewASTCompiler,
 		nonSystemBuildCompiler: NewASTCompiler,
+		retryBackoff:           defaultRetryBackoff,
+		maxRetryBackoff:        defaultMaxRetryBackoff,
+		dependencyTimeout:      defaultDependencyTimeout,
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -151,6 +185,11 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, us PermissionService, t
 		systemBuildCompiler:    cfg.systemBuildCompiler,
 		nonSystemBuildCompiler: cfg.nonSystemBuildCompiler,
 		flagger:                cfg.flagger,
+		retryBackoff:           cfg.retryBackoff,
+		maxRetryBackoff:        cfg.maxRetryBackoff,
+		dependencyTimeout:      cfg.dependencyTimeout,
+		retryTimers:            make(map[*time.Timer]struct{}),
+		waiting:                make(map[platform.ID][]*promise),
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -189,6 +228,52 @@ type Executor struct {
 	nonSystemBuildCompiler CompilerBuilderFunc
 	systemBuildCompiler    CompilerBuilderFunc
 	flagger                feature.Flagger
+
+	// retryBackoff is the wait before the first retry of a failed run,
+	// doubling with each further retry up to maxRetryBackoff.
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+
+	// dependencyTimeout is how long a run waits for the tasks its task
+	// depends on before it fails.
+	dependencyTimeout time.Duration
+
+	// mu protects retryTimers, the timers of the retries waiting for
+	// their backoff, and closed, which is set once the Executor is closed.
+	// wg tracks the goroutines releasing or failing the runs of dependent
+	// tasks.
+	mu          sync.Mutex
+	retryTimers map[*time.Timer]struct{}
+	closed      bool
+	wg          sync.WaitGroup
+
+	// dependentsMu protects waiting, the runs of tasks waiting for the tasks
+	// they depend on to succeed for their scheduled for time, by task ID.
+	dependentsMu sync.Mutex
+	waiting      map[platform.ID][]*promise
+}
+
+// Close stops the Executor from retrying failed runs and from releasing or
+// failing the runs of dependent tasks. Retries waiting for their backoff are
+// dropped, runs waiting for the tasks they depend on are left to be resumed.
+func (e *Executor) Close() {
+	e.mu.Lock()
+	e.closed = true
+	for t := range e.retryTimers {
+		t.Stop()
+		delete(e.retryTimers, t)
+	}
+	e.mu.Unlock()
+
+	e.dependentsMu.Lock()
+	for _, waiting := range e.waiting {
+		for _, p := range waiting {
+			p.waitTimer.Stop()
+		}
+	}
+	e.dependentsMu.Unlock()
+
+	e.wg.Wait()
 }
 
 // SetLimitFunc sets the limit func for this task executor
@@ -244,7 +329,7 @@ func (e *Executor) ResumeCurrentRun(ctx context.Context, id platform.ID, runID p
 				continue
 			}
 
-			p, err := e.createPromise(ctx, run)
+			p, err := e.schedulePromise(ctx, run)
 
 			e.startWorker()
 			e.metrics.resumeRunsCounter.WithLabelValues(id.String()).Inc()
@@ -259,20 +344,326 @@ func (e *Executor) createRun(ctx context.Context, id platform.ID, scheduledFor t
 	if err != nil {
 		return nil, err
 	}
-	p, err := e.createPromise(ctx, r)
+	p, err := e.schedulePromise(ctx, r)
+	if err != nil {
+		e.failEnqueue(ctx, id, r.ID, err)
+	}
+
+	return p, err
+}
+
+// schedulePromise creates the promise of a scheduled run. The promise is
+// queued once every task its task depends on has succeeded for its scheduled
+// for time, and waits for them to succeed otherwise.
+func (e *Executor) schedulePromise(ctx context.Context, run *taskmodel.Run) (*promise, error) {
+	p, err := e.newPromise(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.task.DependsOn) == 0 {
+		e.enqueuePromise(p)
+		return p, nil
+	}
+
+	e.dependentsMu.Lock()
+	defer e.dependentsMu.Unlock()
+
+	ready, err := e.dependenciesSucceeded(ctx, p.task, run.ScheduledFor)
 	if err != nil {
-		if err := e.tcs.AddRunLog(ctx, id, r.ID, time.Now().UTC(), fmt.Sprintf("Failed to enqueue run: %s", err.Error())); err != nil {
-			e.log.Error("failed to fail create run: AddRunLog:", zap.Error(err))
+		p.cancelFunc()
+		return nil, err
+	}
+	if ready {
+		e.enqueuePromise(p)
+		return p, nil
+	}
+
+	if err := e.tcs.AddRunLog(ctx, p.task.ID, run.ID, time.Now().UTC(), "Waiting for the tasks it depends on to succeed"); err != nil {
+		e.log.Error("Failed to add waiting run log", zap.String("taskID", p.task.ID.String()), zap.Error(err))
+	}
+	e.waiting[p.task.ID] = append(e.waiting[p.task.ID], p)
+	p.waitTimer = time.AfterFunc(e.dependencyTimeout, func() {
+		e.goTracked(func() { e.expireWaiting(p) })
+	})
+	// register the promise so that it can be canceled
+	e.currentPromises.Store(run.ID, p)
+	return p, nil
+}
+
+// retryRun creates and enqueues a run retrying the failed run of p once the
+// backoff has elapsed. The retry keeps the scheduled for time of the failed run.
+func (e *Executor) retryRun(p *promise, backoff time.Duration) {
+	id, scheduledFor, retry := p.task.ID, p.run.ScheduledFor, p.run.Retry+1
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(backoff, func() {
+		e.mu.Lock()
+		_, ok := e.retryTimers[timer]
+		delete(e.retryTimers, timer)
+		e.mu.Unlock()
+		if !ok {
+			// the Executor was closed
+			return
+		}
+
+		// the context of the failed run may be canceled by now
+		ctx := icontext.SetAuthorizer(context.Background(), p.auth)
+		t, err := e.ts.FindTaskByID(ctx, id)
+		if err != nil {
+			e.log.Info("Not retrying run of missing task", zap.String("taskID", id.String()), zap.Error(err))
+			return
+		}
+		if t.Status != string(taskmodel.TaskActive) {
+			return
+		}
+
+		r, err := e.tcs.CreateRetryRun(ctx, id, scheduledFor, time.Now().UTC(), retry)
+		if err != nil {
+			e.log.Error("Failed to create retry run", zap.String("taskID", id.String()), zap.Error(err))
+			return
 		}
-		if err := e.tcs.UpdateRunState(ctx, id, r.ID, time.Now().UTC(), taskmodel.RunFail); err != nil {
-			e.log.Error("failed to fail create run: UpdateRunState:", zap.Error(err))
+		if err := e.tcs.AddRunLog(ctx, id, r.ID, time.Now().UTC(), fmt.Sprintf("Retry %d of %d of failed run %s", retry, t.Retry-1, p.run.ID)); err != nil {
+			e.log.Error("Failed to add retry run log", zap.String("taskID", id.String()), zap.Error(err))
 		}
-		if _, err := e.tcs.FinishRun(ctx, id, r.ID); err != nil {
-			e.log.Error("failed to fail create run: FinishRun:", zap.Error(err))
+		if _, err := e.createPromise(ctx, r); err != nil {
+			e.failEnqueue(ctx, id, r.ID, err)
+			return
 		}
+
+		e.startWorker()
+		e.metrics.retryRunsCounter.WithLabelValues(id.String()).Inc()
+	})
+	e.retryTimers[timer] = struct{}{}
+}
+
+// goTracked runs fn in a goroutine which Close waits for, unless the
+// Executor is closed.
+func (e *Executor) goTracked(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return
 	}
 
-	return p, err
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		fn()
+	}()
+}
+
+// releaseDependents starts releasing the waiting runs of the tasks which
+// depend on the task of the successful run of p, unless the Executor is closed.
+func (e *Executor) releaseDependents(p *promise) {
+	e.goTracked(func() { e.runDependents(p) })
+}
+
+// runDependents queues the waiting runs of the tasks which depend on the task
+// of the successful run of p, once every task they depend on has succeeded for
+// their scheduled for time.
+func (e *Executor) runDependents(p *promise) {
+	id := p.task.ID
+	// the context of the upstream run is canceled once it is finished
+	ctx := icontext.SetAuthorizer(context.Background(), p.auth)
+
+	dependents, err := e.tcs.FindDependentTasks(ctx, id)
+	if err != nil {
+		e.log.Error("Failed to find dependent tasks", zap.String("taskID", id.String()), zap.Error(err))
+		return
+	}
+
+	e.dependentsMu.Lock()
+	defer e.dependentsMu.Unlock()
+
+	for _, t := range dependents {
+		var waiting []*promise
+		for _, dp := range e.waiting[t.ID] {
+			ready, err := e.dependenciesSucceeded(ctx, t, dp.run.ScheduledFor)
+			if err != nil {
+				e.log.Error("Failed to check dependencies of task", zap.String("taskID", t.ID.String()), zap.Error(err))
+			}
+			if !ready {
+				waiting = append(waiting, dp)
+				continue
+			}
+
+			dp.waitTimer.Stop()
+			if err := e.tcs.AddRunLog(ctx, t.ID, dp.run.ID, time.Now().UTC(), fmt.Sprintf("Released by run %s of task %s", p.run.ID, id)); err != nil {
+				e.log.Error("Failed to add dependent run log", zap.String("taskID", t.ID.String()), zap.Error(err))
+			}
+			e.enqueuePromise(dp)
+			e.startWorker()
+			e.metrics.dependentRunsCounter.WithLabelValues(t.ID.String()).Inc()
+		}
+
+		if len(waiting) == 0 {
+			delete(e.waiting, t.ID)
+		} else {
+			e.waiting[t.ID] = waiting
+		}
+	}
+}
+
+// dependenciesSucceeded reports whether every task t depends on has succeeded
+// for scheduledFor. Tasks which no longer exist are not waited for.
+func (e *Executor) dependenciesSucceeded(ctx context.Context, t *taskmodel.Task, scheduledFor time.Time) (bool, error) {
+	for _, id := range t.DependsOn {
+		dep, err := e.ts.FindTaskByID(ctx, id)
+		if err == taskmodel.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if dep.LatestSuccess.Before(scheduledFor) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// failDependents starts failing the waiting runs of the tasks which depend on
+// the task of the failed run of p, unless the Executor is closed.
+func (e *Executor) failDependents(p *promise) {
+	e.goTracked(func() { e.abortDependents(p) })
+}
+
+// abortDependents fails the waiting runs of the tasks which depend on the task
+// of the failed run of p, which are scheduled no later than it and still wait
+// for the task to succeed.
+func (e *Executor) abortDependents(p *promise) {
+	id := p.task.ID
+	// the context of the upstream run is canceled once it is finished
+	ctx := icontext.SetAuthorizer(context.Background(), p.auth)
+
+	dependents, err := e.tcs.FindDependentTasks(ctx, id)
+	if err != nil {
+		e.log.Error("Failed to find dependent tasks", zap.String("taskID", id.String()), zap.Error(err))
+		return
+	}
+	t, err := e.ts.FindTaskByID(ctx, id)
+	if err != nil {
+		e.log.Error("Failed to find task", zap.String("taskID", id.String()), zap.Error(err))
+		return
+	}
+
+	var failed []*promise
+	e.dependentsMu.Lock()
+	for _, dt := range dependents {
+		var waiting []*promise
+		for _, dp := range e.waiting[dt.ID] {
+			if dp.run.ScheduledFor.After(p.run.ScheduledFor) || !t.LatestSuccess.Before(dp.run.ScheduledFor) {
+				waiting = append(waiting, dp)
+				continue
+			}
+			dp.waitTimer.Stop()
+			failed = append(failed, dp)
+		}
+
+		if len(waiting) == 0 {
+			delete(e.waiting, dt.ID)
+		} else {
+			e.waiting[dt.ID] = waiting
+		}
+	}
+	e.dependentsMu.Unlock()
+
+	for _, dp := range failed {
+		e.finishWaiting(ctx, dp, taskmodel.RunFail, fmt.Sprintf("Run %s of task %s it depends on failed", p.run.ID, id), errDependencyFailed)
+	}
+}
+
+// expireWaiting fails the run of p if it is still waiting for the tasks its
+// task depends on.
+func (e *Executor) expireWaiting(p *promise) {
+	if !e.removeWaiting(p) {
+		return
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), p.auth)
+	msg := fmt.Sprintf("Timed out after %s waiting for the tasks it depends on to succeed", e.dependencyTimeout)
+	e.finishWaiting(ctx, p, taskmodel.RunFail, msg, errDependencyTimeout)
+}
+
+// cancelWaiting cancels the run of p if it is waiting for the tasks its task
+// depends on, and reports whether it was.
+func (e *Executor) cancelWaiting(ctx context.Context, p *promise) bool {
+	if !e.removeWaiting(p) {
+		return false
+	}
+
+	e.finishWaiting(ctx, p, taskmodel.RunCanceled, "Run canceled", taskmodel.ErrRunCanceled)
+	return true
+}
+
+// removeWaiting stops the run of p from waiting for the tasks its task depends
+// on, and reports whether it was waiting.
+func (e *Executor) removeWaiting(p *promise) bool {
+	e.dependentsMu.Lock()
+	defer e.dependentsMu.Unlock()
+
+	waiting := e.waiting[p.task.ID]
+	for i, wp := range waiting {
+		if wp == p {
+			if len(waiting) == 1 {
+				delete(e.waiting, p.task.ID)
+			} else {
+				e.waiting[p.task.ID] = append(waiting[:i:i], waiting[i+1:]...)
+			}
+			p.waitTimer.Stop()
+			return true
+		}
+	}
+	return false
+}
+
+// finishWaiting finishes the run of p, which no longer waits for the tasks its
+// task depends on, with the status rs.
+func (e *Executor) finishWaiting(ctx context.Context, p *promise, rs taskmodel.RunStatus, msg string, err error) {
+	p.cancelFunc()
+	if err := e.tcs.AddRunLog(ctx, p.task.ID, p.run.ID, time.Now().UTC(), msg); err != nil {
+		e.log.Error("Failed to add run log", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+	if err := e.tcs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), rs); err != nil {
+		e.log.Error("Failed to update run state", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+	if _, err := e.tcs.FinishRun(ctx, p.task.ID, p.run.ID); err != nil {
+		e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+	p.err = err
+	close(p.done)
+	e.currentPromises.Delete(p.run.ID)
+}
+
+// backoffFor returns how long to wait before the nth retry of a run.
+func (e *Executor) backoffFor(retry int) time.Duration {
+	backoff := e.retryBackoff
+	for i := 1; i < retry && backoff < e.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > e.maxRetryBackoff {
+		backoff = e.maxRetryBackoff
+	}
+	return backoff
+}
+
+// failEnqueue fails a run which could not be enqueued.
+func (e *Executor) failEnqueue(ctx context.Context, id, runID platform.ID, err error) {
+	if err := e.tcs.AddRunLog(ctx, id, runID, time.Now().UTC(), fmt.Sprintf("Failed to enqueue run: %s", err.Error())); err != nil {
+		e.log.Error("failed to fail create run: AddRunLog:", zap.Error(err))
+	}
+	if err := e.tcs.UpdateRunState(ctx, id, runID, time.Now().UTC(), taskmodel.RunFail); err != nil {
+		e.log.Error("failed to fail create run: UpdateRunState:", zap.Error(err))
+	}
+	if _, err := e.tcs.FinishRun(ctx, id, runID); err != nil {
+		e.log.Error("failed to fail create run: FinishRun:", zap.Error(err))
+	}
 }
 
 func (e *Executor) startWorker() {
@@ -307,6 +698,11 @@ func (e *Executor) Cancel(ctx context.Context, runID platform.ID) error {
 	}
 	promise := val.(*promise)
 
+	// runs waiting for the tasks they depend on are not executing
+	if e.cancelWaiting(ctx, promise) {
+		return nil
+	}
+
 	// call cancel on it.
 	promise.Cancel(ctx)
 
@@ -314,6 +710,17 @@ func (e *Executor) Cancel(ctx context.Context, runID platform.ID) error {
 }
 
 func (e *Executor) createPromise(ctx context.Context, run *taskmodel.Run) (*promise, error) {
+	p, err := e.newPromise(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	e.enqueuePromise(p)
+	return p, nil
+}
+
+// newPromise creates the promise of a run without queueing it.
+func (e *Executor) newPromise(ctx context.Context, run *taskmodel.Run) (*promise, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -344,14 +751,17 @@ func (e *Executor) createPromise(ctx context.Context, run *taskmodel.Run) (*prom
 		ctx:        ctx,
 		cancelFunc: cancel,
 	}
+	return p, nil
+}
 
+// enqueuePromise queues a promise to be worked.
+func (e *Executor) enqueuePromise(p *promise) {
 	// insert promise into queue to be worked
 	// when the queue gets full we will hand and apply back pressure to the scheduler
 	e.promiseQueue <- p
 
 	// insert the promise into the registry
-	e.currentPromises.Store(run.ID, p)
-	return p, nil
+	e.currentPromises.Store(p.run.ID, p)
 }
 
 type workerMaker struct {
@@ -481,9 +891,30 @@ func (w *worker) finish(p *promise, rs taskmodel.RunStatus, err error) {
 		w.e.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
 
+	// retry failed runs until they were attempted as often as the retry option of the
+	// task asks, unless they were canceled or cannot succeed without user intervention
+	var backoff time.Duration
+	retry := rs == taskmodel.RunFail && p.run.Retry+1 < int(p.task.Retry) && p.ctx.Err() == nil && !backend.IsUnrecoverable(err)
+	if retry {
+		backoff = w.e.backoffFor(p.run.Retry + 1)
+		if err := w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Retrying run in %s", backoff)); err != nil {
+			w.e.log.Error("Failed to add retry run log", zap.String("taskID", p.task.ID.String()), zap.Error(err))
+		}
+	}
+
 	if _, err := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
 		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
+
+	if retry {
+		w.e.retryRun(p, backoff)
+	}
+	// look up dependent tasks outside of the worker, which has other runs to work
+	if rs == taskmodel.RunSuccess {
+		w.e.releaseDependents(p)
+	} else if rs == taskmodel.RunFail && !retry {
+		w.e.failDependents(p)
+	}
 }
 
 func (w *worker) executeQuery(p *promise) {
@@ -582,6 +1013,10 @@ type promise struct {
 
 	ctx        context.Context
 	cancelFunc context.CancelFunc
+
+	// waitTimer fails the run once it waited too long for the tasks its task
+	// depends on. It is protected by the dependentsMu of the Executor.
+	waitTimer *time.Timer
 }
 
 // ID is the id of the run that was created
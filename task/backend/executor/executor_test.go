@@ -56,7 +56,7 @@ type tes struct {
 	tc      testCreds
 }
 
-func taskExecutorSystem(t *testing.T) tes {
+func taskExecutorSystem(t *testing.T, opts ...executorOption) tes {
 	var (
 		aqs = newFakeQueryService()
 		qs  = query.QueryServiceBridge{
@@ -87,7 +87,7 @@ func taskExecutorSystem(t *testing.T) tes {
 		})
 
 		tcs         = &taskControlService{TaskControlService: svc}
-		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, ps, svc, tcs)
+		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, ps, svc, tcs, opts...)
 	)
 	return tes{
 		svc:     aqs,
@@ -109,6 +109,10 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("RetryFailure", testRetryFailure)
+	t.Run("RetryAfterClose", testRetryAfterClose)
+	t.Run("DependentRun", testDependentRun)
+	t.Run("DependentRunTimeout", testDependentRunTimeout)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -215,6 +219,201 @@ func testQueryFailure(t *testing.T) {
 	}
 }
 
+func testRetryFailure(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+
+	script := fmt.Sprintf(fmtRetryTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), task.Retry)
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	require.NoError(t, err)
+
+	// the first run and both of its retries fail
+	for retry := 0; retry <= 2; retry++ {
+		tes.svc.WaitForQueryLive(t, script)
+
+		runs, err := tes.i.CurrentlyRunning(ctx, task.ID)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, retry, runs[0].Retry)
+		assert.Equal(t, time.Unix(123, 0).UTC(), runs[0].ScheduledFor)
+
+		tes.svc.FailQuery(script, errors.New("transient failure"))
+		if retry == 0 {
+			<-promise.Done()
+			require.Error(t, promise.Error())
+		}
+	}
+
+	// no more retries are scheduled once the retry limit is reached
+	require.Eventually(t, func() bool {
+		return tes.tcs.finishedRun() != nil && tes.tcs.finishedRun().Retry == 2
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	runs, err := tes.i.CurrentlyRunning(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func testRetryAfterClose(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithRetryBackoff(time.Hour, time.Hour))
+
+	script := fmt.Sprintf(fmtRetryTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	require.NoError(t, err)
+
+	prom, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	require.NoError(t, err)
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("transient failure"))
+	<-prom.Done()
+
+	// the retry waiting for its backoff is dropped when the executor is closed
+	require.Eventually(t, func() bool {
+		tes.ex.mu.Lock()
+		defer tes.ex.mu.Unlock()
+		return len(tes.ex.retryTimers) == 1
+	}, time.Second, 5*time.Millisecond)
+	tes.ex.Close()
+	assert.Empty(t, tes.ex.retryTimers)
+
+	// and no retry is scheduled afterwards
+	tes.ex.retryRun(prom.(*promise), 0)
+	assert.Empty(t, tes.ex.retryTimers)
+}
+
+func testDependentRun(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	upstream, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	require.NoError(t, err)
+
+	depScript := fmt.Sprintf(fmtDependentTestScript, t.Name()+"-dependent", upstream.ID.String())
+	dependent, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: depScript})
+	require.NoError(t, err)
+	require.Equal(t, []platform.ID{upstream.ID}, dependent.DependsOn)
+
+	// the scheduled run of the dependent task waits for the upstream task
+	scheduledFor := dependent.LatestCompleted.Add(time.Minute)
+	depPromise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(dependent.ID), scheduledFor, scheduledFor)
+	require.NoError(t, err)
+	runs, err := tes.i.CurrentlyRunning(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, taskmodel.RunScheduled.String(), runs[0].Status)
+
+	// a successful upstream run for an earlier time does not release it
+	earlier := scheduledFor.Add(-time.Minute)
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(upstream.ID), earlier, earlier)
+	require.NoError(t, err)
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+	<-promise.Done()
+	require.NoError(t, promise.Error())
+
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-depPromise.Done():
+		t.Fatal("dependent run executed before the upstream task succeeded for its time")
+	default:
+	}
+
+	// a successful upstream run for its time releases it
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(upstream.ID), scheduledFor, scheduledFor)
+	require.NoError(t, err)
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+	<-promise.Done()
+	require.NoError(t, promise.Error())
+
+	tes.svc.WaitForQueryLive(t, depScript)
+	tes.svc.SucceedQuery(depScript)
+	<-depPromise.Done()
+	require.NoError(t, depPromise.Error())
+	assert.Equal(t, scheduledFor.UTC(), tes.tcs.finishedRun().ScheduledFor)
+
+	// the next scheduled run waits again and can be canceled
+	next := scheduledFor.Add(time.Minute)
+	depPromise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(dependent.ID), next, next)
+	require.NoError(t, err)
+	require.NoError(t, tes.ex.Cancel(ctx, depPromise.ID()))
+	<-depPromise.Done()
+	assert.Equal(t, taskmodel.ErrRunCanceled, depPromise.Error())
+
+	runs, err = tes.i.CurrentlyRunning(ctx, dependent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	// a failed upstream run fails the runs waiting for it
+	next = next.Add(time.Minute)
+	depPromise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(dependent.ID), next, next)
+	require.NoError(t, err)
+	later := next.Add(time.Minute)
+	laterPromise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(dependent.ID), later, later)
+	require.NoError(t, err)
+
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(upstream.ID), next, next)
+	require.NoError(t, err)
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("upstream failure"))
+	<-promise.Done()
+	require.Error(t, promise.Error())
+
+	select {
+	case <-depPromise.Done():
+	case <-time.After(time.Second):
+		t.Fatal("dependent run still waiting after the upstream run failed")
+	}
+	assert.Equal(t, errDependencyFailed, depPromise.Error())
+
+	// but not the runs scheduled after it
+	runs, err = tes.i.CurrentlyRunning(ctx, dependent.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, laterPromise.ID(), runs[0].ID)
+	require.NoError(t, tes.ex.Cancel(ctx, laterPromise.ID()))
+}
+
+func testDependentRunTimeout(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithDependencyTimeout(50*time.Millisecond))
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	upstream, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	require.NoError(t, err)
+
+	depScript := fmt.Sprintf(fmtDependentTestScript, t.Name()+"-dependent", upstream.ID.String())
+	dependent, err := tes.i.CreateTask(ctx, taskmodel.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: depScript})
+	require.NoError(t, err)
+
+	// the upstream task never runs, so the dependent run gives up waiting
+	scheduledFor := dependent.LatestCompleted.Add(time.Minute)
+	depPromise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(dependent.ID), scheduledFor, scheduledFor)
+	require.NoError(t, err)
+
+	select {
+	case <-depPromise.Done():
+	case <-time.After(time.Second):
+		t.Fatal("dependent run did not time out")
+	}
+	assert.Equal(t, errDependencyTimeout, depPromise.Error())
+
+	runs, err := tes.i.CurrentlyRunning(ctx, dependent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+	assert.Equal(t, scheduledFor.UTC(), tes.tcs.finishedRun().ScheduledFor)
+}
+
 func testManualRun(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
@@ -587,9 +786,16 @@ func TestPromiseFailure(t *testing.T) {
 type taskControlService struct {
 	backend.TaskControlService
 
+	mu  sync.Mutex
 	run *taskmodel.Run
 }
 
+func (t *taskControlService) finishedRun() *taskmodel.Run {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.run
+}
+
 func (t *taskControlService) FinishRun(ctx context.Context, taskID platform.ID, runID platform.ID) (*taskmodel.Run, error) {
 	// ensure auth set on context
 	_, err := icontext.GetAuthorizer(ctx)
@@ -597,6 +803,9 @@ func (t *taskControlService) FinishRun(ctx context.Context, taskID platform.ID,
 		panic(err)
 	}
 
-	t.run, err = t.TaskControlService.FinishRun(ctx, taskID, runID)
-	return t.run, err
+	run, err := t.TaskControlService.FinishRun(ctx, taskID, runID)
+	t.mu.Lock()
+	t.run = run
+	t.mu.Unlock()
+	return run, err
 }
@@ -298,3 +298,19 @@ option task = {
 			every: 1m,
 }
 from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
+
+const fmtRetryTestScript = `
+option task = {
+			name: %q,
+			every: 1m,
+			retry: 3,
+}
+from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
+
+const fmtDependentTestScript = `
+option task = {
+			name: %q,
+			every: 1m,
+			dependsOn: [%q],
+}
+from(bucket: "two") |> to(bucket: "three", orgID: "0000000000000000")`
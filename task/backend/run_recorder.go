@@ -49,6 +49,10 @@ func (s *StoragePointsWriterRecorder) Record(ctx context.Context, orgID platform
 	fields[finishedAtField] = run.FinishedAt.Format(time.RFC3339Nano)
 	fields[scheduledForField] = run.ScheduledFor.Format(time.RFC3339)
 	fields[requestedAtField] = run.RequestedAt.Format(time.RFC3339)
+	if run.Retry > 0 {
+		// retries share the scheduled for time of the run they retry
+		fields[retryField] = int64(run.Retry)
+	}
 
 	startedAt := run.StartedAt
 	if startedAt.IsZero() {
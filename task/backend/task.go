@@ -15,6 +15,10 @@ type TaskControlService interface {
 	// CreateRun creates a run with a scheduled for time.
 	CreateRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error)
 
+	// CreateRetryRun creates a run retrying a failed run with the same scheduled for time.
+	// retry is the number of the retry, starting at 1.
+	CreateRetryRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error)
+
 	CurrentlyRunning(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
 	ManualRuns(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error)
 
@@ -29,4 +33,7 @@ type TaskControlService interface {
 
 	// AddRunLog adds a log line to the run.
 	AddRunLog(ctx context.Context, taskID, runID platform.ID, when time.Time, log string) error
+
+	// FindDependentTasks returns the tasks which depend on the task taskID.
+	FindDependentTasks(ctx context.Context, taskID platform.ID) ([]*taskmodel.Task, error)
 }
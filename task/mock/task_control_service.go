@@ -57,7 +57,11 @@ func (d *TaskControlService) SetManualRuns(runs []*taskmodel.Run) {
 	d.manualRuns = runs
 }
 
-func (t *TaskControlService) CreateRun(_ context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
+func (t *TaskControlService) CreateRun(ctx context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time) (*taskmodel.Run, error) {
+	return t.CreateRetryRun(ctx, taskID, scheduledFor, runAt, 0)
+}
+
+func (t *TaskControlService) CreateRetryRun(_ context.Context, taskID platform.ID, scheduledFor time.Time, runAt time.Time, retry int) (*taskmodel.Run, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -69,6 +73,7 @@ func (t *TaskControlService) CreateRun(_ context.Context, taskID platform.ID, sc
 	runs[runID] = &taskmodel.Run{
 		ID:           runID,
 		ScheduledFor: scheduledFor,
+		Retry:        retry,
 	}
 	t.runs[taskID] = runs
 	return runs[runID], nil
@@ -120,6 +125,21 @@ func (t *TaskControlService) CurrentlyRunning(ctx context.Context, taskID platfo
 	return rtn, nil
 }
 
+func (t *TaskControlService) FindDependentTasks(ctx context.Context, taskID platform.ID) ([]*taskmodel.Task, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rtn := []*taskmodel.Task{}
+	for _, task := range t.tasks {
+		for _, dep := range task.DependsOn {
+			if dep == taskID {
+				rtn = append(rtn, task)
+				break
+			}
+		}
+	}
+	return rtn, nil
+}
+
 func (t *TaskControlService) ManualRuns(ctx context.Context, taskID platform.ID) ([]*taskmodel.Run, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
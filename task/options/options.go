@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/flux/ast/edit"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/pkg/pointer"
 )
 
@@ -38,6 +39,10 @@ type Options struct {
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
 	Retry *int64 `json:"retry,omitempty"`
+
+	// DependsOn holds the IDs of the tasks which have to succeed for the
+	// scheduled time of a run of this task before it runs.
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -116,6 +121,7 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.DependsOn = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
@@ -125,7 +131,8 @@ func (o *Options) IsZero() bool {
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		len(o.DependsOn) == 0
 }
 
 // All the task option names we accept.
@@ -136,6 +143,7 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+	optDependsOn   = "dependsOn"
 )
 
 // FluxLanguageService is a service for interacting with flux code.
@@ -224,6 +232,7 @@ var taskOptionExtractors = []extractFn{
 	extractOffsetOption,
 	extractConcurrencyOption,
 	extractRetryOption,
+	extractDependsOnOption,
 }
 
 func extractNameOption(opts *Options, objExpr *ast.ObjectExpression) error {
@@ -323,6 +332,27 @@ func extractRetryOption(opts *Options, objExpr *ast.ObjectExpression) error {
 	return nil
 }
 
+func extractDependsOnOption(opts *Options, objExpr *ast.ObjectExpression) error {
+	dependsOnExpr, err := edit.GetProperty(objExpr, optDependsOn)
+	if err != nil {
+		return nil
+	}
+
+	arrExpr, ok := dependsOnExpr.(*ast.ArrayExpression)
+	if !ok {
+		return errParseTaskOptionField(optDependsOn)
+	}
+	for _, e := range arrExpr.Elements {
+		idStr, ok := e.(*ast.StringLiteral)
+		if !ok {
+			return errParseTaskOptionField(optDependsOn)
+		}
+		opts.DependsOn = append(opts.DependsOn, ast.StringFromLiteral(idStr))
+	}
+
+	return nil
+}
+
 // Validate returns an error if the options aren't valid.
 func (o *Options) Validate() error {
 	now := time.Now()
@@ -377,6 +407,16 @@ func (o *Options) Validate() error {
 		}
 	}
 
+	seen := make(map[string]bool, len(o.DependsOn))
+	for _, id := range o.DependsOn {
+		if _, err := platform.IDFromString(id); err != nil {
+			errs = append(errs, fmt.Sprintf("dependsOn contains invalid task ID %q", id))
+		} else if seen[id] {
+			errs = append(errs, fmt.Sprintf("dependsOn contains task ID %q more than once", id))
+		}
+		seen[id] = true
+	}
+
 	if len(errs) == 0 {
 		return nil
 	}
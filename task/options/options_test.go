@@ -3,6 +3,7 @@ package options_test
 import (
 	"fmt"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,6 +36,13 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != nil && *opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, *opt.Retry)
 	}
+	if len(opt.DependsOn) > 0 {
+		var ids []string
+		for _, id := range opt.DependsOn {
+			ids = append(ids, fmt.Sprintf("%q", id))
+		}
+		taskData = fmt.Sprintf("%s  dependsOn: [%s],\n", taskData, strings.Join(ids, ", "))
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -79,6 +87,10 @@ func TestFromScriptAST(t *testing.T) {
 		{script: scriptGenerator(options.Options{Name: "name7", Retry: pointer.Int64(20), Every: *(options.MustParseDuration("1h"))}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name8\",\n  retry: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name9"}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name12", Every: *(options.MustParseDuration("1h")), DependsOn: []string{"020f755c3c082000", "020f755c3c082001"}}, ""),
+			exp: options.Options{Name: "name12", Every: *(options.MustParseDuration("1h")), Concurrency: pointer.Int64(1), Retry: pointer.Int64(1), DependsOn: []string{"020f755c3c082000", "020f755c3c082001"}}},
+		{script: scriptGenerator(options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), DependsOn: []string{"not an id"}}, ""), shouldErr: true},
+		{script: "option task = {\n  name: \"name14\",\n  dependsOn: \"020f755c3c082000\",\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{}, ""), shouldErr: true},
 		{script: `option task = {
 			name: "name10",
@@ -192,6 +204,12 @@ func TestValidate(t *testing.T) {
 		t.Error("expected error for retry too large")
 	}
 
+	*bad = good
+	bad.DependsOn = []string{"020f755c3c082000", "020f755c3c082000"}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected error for duplicate dependency")
+	}
+
 	notbad := new(options.Options)
 	*notbad = good
 	notbad.Cron = ""
@@ -293,6 +293,7 @@ func testTaskCRUD(t *testing.T, sys *System) {
 		Name:            "task #0",
 		Cron:            "* * * * *",
 		Offset:          5 * time.Second,
+		Retry:           1,
 		Status:          string(taskmodel.DefaultTaskStatus),
 		Flux:            fmt.Sprintf(scriptFmt, 0),
 		Type:            taskmodel.TaskSystemType,
@@ -0,0 +1,61 @@
+package taskmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+)
+
+// MaxBackfillRuns is the largest number of runs a single backfill may enqueue.
+const MaxBackfillRuns = 10000
+
+// BackfillStatus is the state of a backfill.
+type BackfillStatus string
+
+const (
+	BackfillRunning  BackfillStatus = "running"
+	BackfillSuccess  BackfillStatus = "success"
+	BackfillFailed   BackfillStatus = "failed"
+	BackfillCanceled BackfillStatus = "canceled"
+)
+
+// Backfill runs a task for every tick of its schedule within a historical
+// time range, and reports the progress of those runs.
+type Backfill struct {
+	ID     platform.ID    `json:"id"`
+	TaskID platform.ID    `json:"taskID"`
+	Start  time.Time      `json:"start"`
+	Stop   time.Time      `json:"stop"`
+	Status BackfillStatus `json:"status"`
+
+	// Total is the number of runs in the range, of which Succeeded and
+	// Failed have finished.
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done reports whether the backfill has stopped enqueueing runs.
+func (b *Backfill) Done() bool {
+	return b.Status != BackfillRunning
+}
+
+// BackfillService runs tasks over historical time ranges.
+type BackfillService interface {
+	// CreateBackfill starts running the task for every tick of its schedule
+	// from start to stop, both inclusive.
+	CreateBackfill(ctx context.Context, taskID platform.ID, start, stop time.Time) (*Backfill, error)
+
+	// FindBackfillByID returns a single backfill of a task.
+	FindBackfillByID(ctx context.Context, taskID, id platform.ID) (*Backfill, error)
+
+	// FindBackfills returns the backfills of a task, latest first.
+	FindBackfills(ctx context.Context, taskID platform.ID) ([]*Backfill, error)
+
+	// CancelBackfill stops a backfill, canceling the runs it has in progress.
+	CancelBackfill(ctx context.Context, taskID, id platform.ID) error
+}
@@ -45,6 +45,8 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          time.Duration          `json:"offset,omitempty"`
+	Retry           int64                  `json:"retry,omitempty"`     // Retry is the number of attempts of a run, so a failed run is retried Retry-1 times
+	DependsOn       []platform.ID          `json:"dependsOn,omitempty"` // DependsOn holds the tasks which have to succeed for a run's scheduled time before it runs
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	LatestSuccess   time.Time              `json:"latestSuccess,omitempty"`
@@ -82,6 +84,7 @@ type Run struct {
 	FinishedAt   time.Time   `json:"finishedAt,omitempty"`  // FinishedAt is the time the executor finishes running the task
 	RequestedAt  time.Time   `json:"requestedAt,omitempty"` // RequestedAt is the time the coordinator told the scheduler to schedule the task
 	Log          []Log       `json:"log,omitempty"`
+	Retry        int         `json:"retry,omitempty"` // Retry is zero for the first run of ScheduledFor and n for its nth retry
 }
 
 // Log represents a link to a log resource
@@ -3,6 +3,7 @@ package taskmodel
 import (
 	"fmt"
 
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/kit/platform/errors"
 )
 
@@ -90,6 +91,18 @@ var (
 		Code: errors.EInvalid,
 		Msg:  "cannot create task with invalid ownerID",
 	}
+
+	// ErrTaskDependencyCycle is returned when the dependencies of a task would make it depend on itself.
+	ErrTaskDependencyCycle = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "task dependencies form a cycle",
+	}
+
+	// ErrBackfillNotFound is returned when searching for a single backfill that doesn't exist.
+	ErrBackfillNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "backfill not found",
+	}
 )
 
 // ErrFluxParseError is returned when an error is thrown by Flux.Parse in the task executor
@@ -160,6 +173,15 @@ func ErrTaskOptionParse(err error) *errors.Error {
 	}
 }
 
+// ErrInvalidTaskDependency is returned when a task depends on a task it cannot depend on.
+func ErrInvalidTaskDependency(id platform.ID, reason string) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  fmt.Sprintf("invalid dependency on task %s: %s", id, reason),
+		Op:   "taskOptions",
+	}
+}
+
 func ErrRunExecutionError(err error) *errors.Error {
 	return &errors.Error{
 		Code: errors.EInternal,
@@ -176,3 +198,12 @@ func ErrTaskConcurrencyLimitReached(runsInFront int) *errors.Error {
 		Op:   "taskExecutor",
 	}
 }
+
+// ErrInvalidBackfill is returned when a backfill cannot be created for the requested range.
+func ErrInvalidBackfill(msg string) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  fmt.Sprintf("invalid backfill: %s", msg),
+		Op:   "taskBackfill",
+	}
+}
@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var (
+	// ErrMeasurementSchemaNotFound is used when a measurement schema does not
+	// exist.
+	ErrMeasurementSchemaNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "measurement schema not found",
+	}
+
+	errBucketSchemaNotExplicit = &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "measurement schemas can only be defined for buckets with an explicit schema type",
+	}
+)
+
+// MeasurementSchemaAlreadyExistsError is used when a bucket already has a
+// schema for the measurement.
+func MeasurementSchemaAlreadyExistsError(n string) *errors.Error {
+	return &errors.Error{
+		Code: errors.EConflict,
+		Msg:  fmt.Sprintf("measurement schema with name %s already exists", n),
+	}
+}
+
+// ErrCorruptMeasurementSchema is used when the measurement schema cannot be
+// unmarshalled from the bytes stored in the kv.
+func ErrCorruptMeasurementSchema(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Msg:  "measurement schema could not be unmarshalled",
+		Err:  err,
+		Op:   "kv/UnmarshalMeasurementSchema",
+	}
+}
@@ -0,0 +1,105 @@
+package tenant
+
+import (
+	"context"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+)
+
+// MeasurementSchemaClientService connects to Influx via HTTP using tokens to
+// manage the measurement schemas of buckets.
+type MeasurementSchemaClientService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.MeasurementSchemaService = (*MeasurementSchemaClientService)(nil)
+
+func measurementSchemasPath(bucketID platform.ID, elem ...string) string {
+	return path.Join(append([]string{prefixBuckets, bucketID.String(), "schema", "measurements"}, elem...)...)
+}
+
+// FindMeasurementSchemaByID returns a single measurement schema of a bucket by ID.
+func (s *MeasurementSchemaClientService) FindMeasurementSchemaByID(ctx context.Context, bucketID, id platform.ID) (*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp measurementSchemaResponse
+	err := s.Client.
+		Get(measurementSchemasPath(bucketID, id.String())).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toInfluxDB(), nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas of a bucket, ordered by name.
+func (s *MeasurementSchemaClientService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var resp measurementSchemasResponse
+	err := s.Client.
+		Get(measurementSchemasPath(filter.BucketID)).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ms := make([]*influxdb.MeasurementSchema, 0, len(resp.MeasurementSchemas))
+	for _, m := range resp.MeasurementSchemas {
+		ms = append(ms, m.toInfluxDB())
+	}
+	return ms, nil
+}
+
+// CreateMeasurementSchema creates a measurement schema and sets m.ID with the new identifier.
+func (s *MeasurementSchemaClientService) CreateMeasurementSchema(ctx context.Context, m *influxdb.MeasurementSchema) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	req := postMeasurementSchemaRequest{
+		Name:    m.Name,
+		Columns: m.Columns,
+	}
+
+	var resp measurementSchemaResponse
+	err := s.Client.
+		PostJSON(req, measurementSchemasPath(m.BucketID)).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	*m = *resp.toInfluxDB()
+	return nil
+}
+
+// UpdateMeasurementSchema replaces the columns of a measurement schema of a bucket.
+func (s *MeasurementSchemaClientService) UpdateMeasurementSchema(ctx context.Context, bucketID, id platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var resp measurementSchemaResponse
+	err := s.Client.
+		PatchJSON(patchMeasurementSchemaRequest{Columns: columns}, measurementSchemasPath(bucketID, id.String())).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.toInfluxDB(), nil
+}
@@ -0,0 +1,210 @@
+package tenant
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+type measurementSchemaHandler struct {
+	log *zap.Logger
+	svc influxdb.MeasurementSchemaService
+	api *kithttp.API
+}
+
+// NewMeasurementSchemaHandler generates a mountable handler for the
+// measurement schemas of a bucket. It looks up the bucket id with
+// chi.URLParam(r, "id"), so it has to be mounted below `/buckets/{id}`.
+func NewMeasurementSchemaHandler(log *zap.Logger, svc influxdb.MeasurementSchemaService) http.Handler {
+	h := &measurementSchemaHandler{
+		log: log,
+		svc: svc,
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+	}
+
+	r := chi.NewRouter()
+	r.Get("/", h.handleGetMeasurementSchemas)
+	r.Post("/", h.handlePostMeasurementSchema)
+	r.Get("/{measurementID}", h.handleGetMeasurementSchema)
+	r.Patch("/{measurementID}", h.handlePatchMeasurementSchema)
+	return r
+}
+
+type measurementSchemaResponse struct {
+	ID        platform.ID                        `json:"id"`
+	OrgID     platform.ID                        `json:"orgID"`
+	BucketID  platform.ID                        `json:"bucketID"`
+	Name      string                             `json:"name"`
+	Columns   []influxdb.MeasurementSchemaColumn `json:"columns"`
+	CreatedAt time.Time                          `json:"createdAt"`
+	UpdatedAt time.Time                          `json:"updatedAt"`
+}
+
+func newMeasurementSchemaResponse(m *influxdb.MeasurementSchema) *measurementSchemaResponse {
+	return &measurementSchemaResponse{
+		ID:        m.ID,
+		OrgID:     m.OrgID,
+		BucketID:  m.BucketID,
+		Name:      m.Name,
+		Columns:   m.Columns,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+func (m *measurementSchemaResponse) toInfluxDB() *influxdb.MeasurementSchema {
+	return &influxdb.MeasurementSchema{
+		ID:       m.ID,
+		OrgID:    m.OrgID,
+		BucketID: m.BucketID,
+		Name:     m.Name,
+		Columns:  m.Columns,
+		CRUDLog: influxdb.CRUDLog{
+			CreatedAt: m.CreatedAt,
+			UpdatedAt: m.UpdatedAt,
+		},
+	}
+}
+
+type measurementSchemasResponse struct {
+	MeasurementSchemas []*measurementSchemaResponse `json:"measurementSchemas"`
+}
+
+type postMeasurementSchemaRequest struct {
+	Name    string                             `json:"name"`
+	Columns []influxdb.MeasurementSchemaColumn `json:"columns"`
+}
+
+type patchMeasurementSchemaRequest struct {
+	Columns []influxdb.MeasurementSchemaColumn `json:"columns"`
+}
+
+// handleGetMeasurementSchemas is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *measurementSchemaHandler) handleGetMeasurementSchemas(w http.ResponseWriter, r *http.Request) {
+	bucketID, err := decodeSchemaBucketID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	filter := influxdb.MeasurementSchemaFilter{BucketID: bucketID}
+	if name := r.URL.Query().Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	ms, err := h.svc.FindMeasurementSchemas(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	resp := measurementSchemasResponse{MeasurementSchemas: []*measurementSchemaResponse{}}
+	for _, m := range ms {
+		resp.MeasurementSchemas = append(resp.MeasurementSchemas, newMeasurementSchemaResponse(m))
+	}
+	h.api.Respond(w, r, http.StatusOK, resp)
+}
+
+// handlePostMeasurementSchema is the HTTP handler for the POST /api/v2/buckets/:id/schema/measurements route.
+func (h *measurementSchemaHandler) handlePostMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	bucketID, err := decodeSchemaBucketID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req postMeasurementSchemaRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	m := &influxdb.MeasurementSchema{
+		BucketID: bucketID,
+		Name:     req.Name,
+		Columns:  req.Columns,
+	}
+	if err := h.svc.CreateMeasurementSchema(r.Context(), m); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Measurement schema created", zap.String("measurementSchema", fmt.Sprint(m)))
+
+	h.api.Respond(w, r, http.StatusCreated, newMeasurementSchemaResponse(m))
+}
+
+// handleGetMeasurementSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *measurementSchemaHandler) handleGetMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	bucketID, id, err := decodeMeasurementSchemaID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	m, err := h.svc.FindMeasurementSchemaByID(r.Context(), bucketID, id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newMeasurementSchemaResponse(m))
+}
+
+// handlePatchMeasurementSchema is the HTTP handler for the PATCH /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *measurementSchemaHandler) handlePatchMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	bucketID, id, err := decodeMeasurementSchemaID(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var req patchMeasurementSchemaRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	m, err := h.svc.UpdateMeasurementSchema(r.Context(), bucketID, id, req.Columns)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Measurement schema updated", zap.String("measurementSchema", fmt.Sprint(m)))
+
+	h.api.Respond(w, r, http.StatusOK, newMeasurementSchemaResponse(m))
+}
+
+func decodeSchemaBucketID(r *http.Request) (platform.ID, error) {
+	id, err := platform.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		return 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid bucket id",
+			Err:  err,
+		}
+	}
+	return *id, nil
+}
+
+func decodeMeasurementSchemaID(r *http.Request) (platform.ID, platform.ID, error) {
+	bucketID, err := decodeSchemaBucketID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := platform.IDFromString(chi.URLParam(r, "measurementID"))
+	if err != nil {
+		return 0, 0, &errors.Error{
+			Code: errors.EInvalid,
+			Msg:  "invalid measurement schema id",
+			Err:  err,
+		}
+	}
+	return bucketID, *id, nil
+}
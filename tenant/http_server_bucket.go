@@ -30,7 +30,7 @@ const (
 )
 
 // NewHTTPBucketHandler constructs a new http server.
-func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, labelSvc influxdb.LabelService, urmHandler, labelHandler http.Handler) *BucketHandler {
+func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, labelSvc influxdb.LabelService, urmHandler, labelHandler, schemaHandler http.Handler) *BucketHandler {
 	svr := &BucketHandler{
 		api:       kithttp.NewAPI(kithttp.WithLog(log)),
 		log:       log,
@@ -60,6 +60,7 @@ func NewHTTPBucketHandler(log *zap.Logger, bucketSvc influxdb.BucketService, lab
 			mountableRouter.Mount("/members", urmHandler)
 			mountableRouter.Mount("/owners", urmHandler)
 			mountableRouter.Mount("/labels", labelHandler)
+			mountableRouter.Mount("/schema/measurements", schemaHandler)
 		})
 	})
 
@@ -80,6 +81,7 @@ type bucket struct {
 	Name                string          `json:"name"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	SchemaType          string          `json:"schemaType,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -113,6 +115,7 @@ func (b *bucket) toInfluxDB() *influxdb.Bucket {
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     rpDuration,
 		ShardGroupDuration:  sgDuration,
+		SchemaType:          influxdb.SchemaType(b.SchemaType),
 		CRUDLog:             b.CRUDLog,
 	}
 }
@@ -130,6 +133,7 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      []retentionRule{},
+		SchemaType:          string(pb.SchemaType),
 		CRUDLog:             pb.CRUDLog,
 	}
 
@@ -321,6 +325,7 @@ type postBucketRequest struct {
 	Description         string          `json:"description"`
 	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
 	RetentionRules      []retentionRule `json:"retentionRules"`
+	SchemaType          string          `json:"schemaType,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -331,6 +336,10 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := influxdb.SchemaType(b.SchemaType).Valid(); err != nil {
+		return err
+	}
+
 	if len(b.RetentionRules) > 1 {
 		return &errors.Error{
 			Code: errors.EUnprocessableEntity,
@@ -376,6 +385,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     rpDur,
 		ShardGroupDuration:  sgDur,
+		SchemaType:          influxdb.SchemaType(b.SchemaType),
 	}
 }
 
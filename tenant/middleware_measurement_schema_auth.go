@@ -0,0 +1,90 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.MeasurementSchemaService = (*AuthedMeasurementSchemaService)(nil)
+
+// AuthedMeasurementSchemaService wraps a influxdb.MeasurementSchemaService
+// and authorizes actions against it with the permissions on the bucket the
+// schemas belong to.
+type AuthedMeasurementSchemaService struct {
+	s         influxdb.MeasurementSchemaService
+	bucketSvc influxdb.BucketService
+}
+
+// NewAuthedMeasurementSchemaService constructs an instance of an authorizing
+// measurement schema service. The bucket service is used to find the
+// organization of the buckets and must not be authorizing itself.
+func NewAuthedMeasurementSchemaService(s influxdb.MeasurementSchemaService, bucketSvc influxdb.BucketService) *AuthedMeasurementSchemaService {
+	return &AuthedMeasurementSchemaService{
+		s:         s,
+		bucketSvc: bucketSvc,
+	}
+}
+
+// FindMeasurementSchemaByID checks to see if the authorizer on context has read access to the bucket of the schema.
+func (s *AuthedMeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, bucketID, id platform.ID) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindMeasurementSchemaByID(ctx, bucketID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrgID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FindMeasurementSchemas checks to see if the authorizer on context has read access to the bucket.
+func (s *AuthedMeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.bucketSvc.FindBucketByID(ctx, filter.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeRead(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindMeasurementSchemas(ctx, filter)
+}
+
+// CreateMeasurementSchema checks to see if the authorizer on context has write access to the bucket.
+func (s *AuthedMeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, m *influxdb.MeasurementSchema) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.bucketSvc.FindBucketByID(ctx, m.BucketID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, b.ID, b.OrgID); err != nil {
+		return err
+	}
+	return s.s.CreateMeasurementSchema(ctx, m)
+}
+
+// UpdateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *AuthedMeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, bucketID, id platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindMeasurementSchemaByID(ctx, bucketID, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := authorizer.AuthorizeWrite(ctx, influxdb.BucketsResourceType, m.BucketID, m.OrgID); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateMeasurementSchema(ctx, bucketID, id, columns)
+}
@@ -317,8 +317,9 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 		OrgService influxdb.OrganizationService
 	}
 	type args struct {
-		id         platform.ID
-		permission influxdb.Permission
+		id          platform.ID
+		permissions []influxdb.Permission
+		upd         influxdb.OrganizationUpdate
 	}
 	type wants struct {
 		err error
@@ -343,13 +344,13 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 			},
 			args: args{
 				id: 1,
-				permission: influxdb.Permission{
+				permissions: []influxdb.Permission{{
 					Action: "write",
 					Resource: influxdb.Resource{
 						Type: influxdb.OrgsResourceType,
 						ID:   influxdbtesting.IDPtr(1),
 					},
-				},
+				}},
 			},
 			wants: wants{
 				err: nil,
@@ -368,13 +369,13 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 			},
 			args: args{
 				id: 1,
-				permission: influxdb.Permission{
+				permissions: []influxdb.Permission{{
 					Action: "read",
 					Resource: influxdb.Resource{
 						Type: influxdb.OrgsResourceType,
 						ID:   influxdbtesting.IDPtr(1),
 					},
-				},
+				}},
 			},
 			wants: wants{
 				err: &errors.Error{
@@ -383,6 +384,60 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unauthorized to update org query limits",
+			fields: fields{
+				OrgService: &mock.OrganizationService{
+					UpdateOrganizationF: func(ctx context.Context, id platform.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID: 1,
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id: 1,
+				permissions: []influxdb.Permission{{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(1),
+					},
+				}},
+				upd: influxdb.OrganizationUpdate{QueryLimits: &influxdb.QueryLimits{ConcurrencyQuota: 1}},
+			},
+			wants: wants{
+				err: &errors.Error{
+					Msg:  "write:orgs is unauthorized",
+					Code: errors.EUnauthorized,
+				},
+			},
+		},
+		{
+			name: "authorized to update org query limits",
+			fields: fields{
+				OrgService: &mock.OrganizationService{
+					UpdateOrganizationF: func(ctx context.Context, id platform.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID: 1,
+						}, nil
+					},
+				},
+			},
+			args: args{
+				id: 1,
+				permissions: []influxdb.Permission{{
+					Action: "write",
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+					},
+				}},
+				upd: influxdb.OrganizationUpdate{QueryLimits: &influxdb.QueryLimits{ConcurrencyQuota: 1}},
+			},
+			wants: wants{
+				err: nil,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -390,9 +445,9 @@ func TestOrgService_UpdateOrganization(t *testing.T) {
 			s := tenant.NewAuthedOrgService(tt.fields.OrgService)
 
 			ctx := context.Background()
-			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, []influxdb.Permission{tt.args.permission}))
+			ctx = influxdbcontext.SetAuthorizer(ctx, mock.NewMockAuthorizer(false, tt.args.permissions))
 
-			_, err := s.UpdateOrganization(ctx, tt.args.id, influxdb.OrganizationUpdate{})
+			_, err := s.UpdateOrganization(ctx, tt.args.id, tt.args.upd)
 			influxdbtesting.ErrorsEqual(t, err, tt.wants.err)
 		})
 	}
@@ -33,6 +33,7 @@ type Service struct {
 	influxdb.UserResourceMappingService
 	influxdb.OrganizationService
 	influxdb.BucketService
+	influxdb.MeasurementSchemaService
 }
 
 // NewService creates a new base tenant service.
@@ -44,6 +45,7 @@ func NewService(st *Store) *Service {
 	svc.UserResourceMappingService = NewUserResourceMappingSvc(st, svc)
 	svc.OrganizationService = NewOrganizationSvc(st, svc)
 	svc.BucketService = NewBucketSvc(st, svc)
+	svc.MeasurementSchemaService = NewMeasurementSchemaSvc(st, svc)
 
 	return svc
 }
@@ -69,7 +71,8 @@ func (ts *Service) NewOrgHTTPHandler(log *zap.Logger, secretSvc influxdb.SecretS
 func (ts *Service) NewBucketHTTPHandler(log *zap.Logger, labelSvc influxdb.LabelService) *BucketHandler {
 	urmHandler := NewURMHandler(log.With(zap.String("handler", "urm")), influxdb.BucketsResourceType, "id", ts.UserService, NewAuthedURMService(ts.OrganizationService, ts.UserResourceMappingService))
 	labelHandler := label.NewHTTPEmbeddedHandler(log.With(zap.String("handler", "label")), influxdb.BucketsResourceType, labelSvc)
-	return NewHTTPBucketHandler(log.With(zap.String("handler", "bucket")), NewAuthedBucketService(ts.BucketService), labelSvc, urmHandler, labelHandler)
+	schemaHandler := NewMeasurementSchemaHandler(log.With(zap.String("handler", "measurement_schema")), NewAuthedMeasurementSchemaService(ts.MeasurementSchemaService, ts.BucketService))
+	return NewHTTPBucketHandler(log.With(zap.String("handler", "bucket")), NewAuthedBucketService(ts.BucketService), labelSvc, urmHandler, labelHandler, schemaHandler)
 }
 
 func (ts *Service) NewUserHTTPHandler(log *zap.Logger) *UserHandler {
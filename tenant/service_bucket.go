@@ -145,6 +145,10 @@ func (s *BucketSvc) CreateBucket(ctx context.Context, b *influxdb.Bucket) error
 		return err
 	}
 
+	if err := b.SchemaType.Valid(); err != nil {
+		return err
+	}
+
 	// make sure the org exists
 	if _, err := s.svc.FindOrganizationByID(ctx, b.OrgID); err != nil {
 		return err
@@ -190,7 +194,7 @@ func (s *BucketSvc) DeleteBucket(ctx context.Context, id platform.ID) error {
 		if err := s.store.DeleteBucket(ctx, tx, id); err != nil {
 			return err
 		}
-		return nil
+		return s.store.DeleteBucketMeasurementSchemas(ctx, tx, id)
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,102 @@
+package tenant
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+type MeasurementSchemaSvc struct {
+	store *Store
+	svc   *Service
+}
+
+func NewMeasurementSchemaSvc(st *Store, svc *Service) *MeasurementSchemaSvc {
+	return &MeasurementSchemaSvc{
+		store: st,
+		svc:   svc,
+	}
+}
+
+// FindMeasurementSchemaByID returns a single measurement schema of a bucket by ID.
+func (s *MeasurementSchemaSvc) FindMeasurementSchemaByID(ctx context.Context, bucketID, id platform.ID) (*influxdb.MeasurementSchema, error) {
+	var m *influxdb.MeasurementSchema
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		ms, err := s.store.GetMeasurementSchema(ctx, tx, bucketID, id)
+		if err != nil {
+			return err
+		}
+		m = ms
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// FindMeasurementSchemas returns the measurement schemas of a bucket, ordered
+// by name.
+func (s *MeasurementSchemaSvc) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	var ms []*influxdb.MeasurementSchema
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		schemas, err := s.store.ListMeasurementSchemas(ctx, tx, filter.BucketID)
+		if err != nil {
+			return err
+		}
+		for _, m := range schemas {
+			if filter.Name == nil || *filter.Name == m.Name {
+				ms = append(ms, m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// CreateMeasurementSchema creates a measurement schema for a bucket with an
+// explicit schema type.
+func (s *MeasurementSchemaSvc) CreateMeasurementSchema(ctx context.Context, m *influxdb.MeasurementSchema) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		b, err := s.store.GetBucket(ctx, tx, m.BucketID)
+		if err != nil {
+			return err
+		}
+		if b.SchemaType != influxdb.SchemaTypeExplicit {
+			return errBucketSchemaNotExplicit
+		}
+
+		m.OrgID = b.OrgID
+		return s.store.CreateMeasurementSchema(ctx, tx, m)
+	})
+}
+
+// UpdateMeasurementSchema replaces the columns of a measurement schema.
+// Columns can be added but existing columns cannot be changed or removed.
+func (s *MeasurementSchemaSvc) UpdateMeasurementSchema(ctx context.Context, bucketID, id platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	var m *influxdb.MeasurementSchema
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		ms, err := s.store.UpdateMeasurementSchema(ctx, tx, bucketID, id, columns)
+		if err != nil {
+			return err
+		}
+		m = ms
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,108 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasurementSchemaService(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	require.NoError(t, err)
+	defer closeStore()
+
+	ctx := context.Background()
+	svc := tenant.NewService(tenant.NewStore(s))
+
+	org := &influxdb.Organization{Name: "org"}
+	require.NoError(t, svc.CreateOrganization(ctx, org))
+
+	implicit := &influxdb.Bucket{OrgID: org.ID, Name: "implicit"}
+	require.NoError(t, svc.CreateBucket(ctx, implicit))
+	explicit := &influxdb.Bucket{OrgID: org.ID, Name: "explicit", SchemaType: influxdb.SchemaTypeExplicit}
+	require.NoError(t, svc.CreateBucket(ctx, explicit))
+
+	columns := []influxdb.MeasurementSchemaColumn{
+		{Name: "time", Type: influxdb.SemanticColumnTypeTimestamp},
+		{Name: "host", Type: influxdb.SemanticColumnTypeTag},
+		{Name: "usage", Type: influxdb.SemanticColumnTypeField, DataType: influxdb.SchemaColumnDataTypeFloat.Ptr()},
+	}
+
+	t.Run("invalid schema type", func(t *testing.T) {
+		err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: org.ID, Name: "invalid", SchemaType: "strict"})
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("implicit bucket", func(t *testing.T) {
+		err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: implicit.ID, Name: "cpu", Columns: columns})
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("invalid columns", func(t *testing.T) {
+		err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "cpu", Columns: columns[:2]})
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	})
+
+	cpu := &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "cpu", Columns: columns}
+	require.NoError(t, svc.CreateMeasurementSchema(ctx, cpu))
+	assert.True(t, cpu.ID.Valid())
+	assert.Equal(t, org.ID, cpu.OrgID)
+
+	t.Run("duplicate name", func(t *testing.T) {
+		err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "cpu", Columns: columns})
+		assert.Equal(t, errors.EConflict, errors.ErrorCode(err))
+	})
+
+	mem := &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "mem", Columns: columns}
+	require.NoError(t, svc.CreateMeasurementSchema(ctx, mem))
+
+	t.Run("find", func(t *testing.T) {
+		got, err := svc.FindMeasurementSchemaByID(ctx, explicit.ID, cpu.ID)
+		require.NoError(t, err)
+		assert.Equal(t, cpu, got)
+
+		_, err = svc.FindMeasurementSchemaByID(ctx, implicit.ID, cpu.ID)
+		assert.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+
+		ms, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: explicit.ID})
+		require.NoError(t, err)
+		assert.Equal(t, []*influxdb.MeasurementSchema{cpu, mem}, ms)
+
+		name := "mem"
+		ms, err = svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: explicit.ID, Name: &name})
+		require.NoError(t, err)
+		assert.Equal(t, []*influxdb.MeasurementSchema{mem}, ms)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		added := append(append([]influxdb.MeasurementSchemaColumn{}, columns...),
+			influxdb.MeasurementSchemaColumn{Name: "region", Type: influxdb.SemanticColumnTypeTag})
+		got, err := svc.UpdateMeasurementSchema(ctx, explicit.ID, cpu.ID, added)
+		require.NoError(t, err)
+		assert.Equal(t, added, got.Columns)
+
+		changed := append([]influxdb.MeasurementSchemaColumn{}, added...)
+		changed[2].DataType = influxdb.SchemaColumnDataTypeInteger.Ptr()
+		_, err = svc.UpdateMeasurementSchema(ctx, explicit.ID, cpu.ID, changed)
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+
+		_, err = svc.UpdateMeasurementSchema(ctx, explicit.ID, cpu.ID, columns)
+		assert.Equal(t, errors.EInvalid, errors.ErrorCode(err))
+	})
+
+	t.Run("delete bucket", func(t *testing.T) {
+		require.NoError(t, svc.DeleteBucket(ctx, explicit.ID))
+
+		ms, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: explicit.ID})
+		require.NoError(t, err)
+		assert.Empty(t, ms)
+
+		_, err = svc.FindMeasurementSchemaByID(ctx, explicit.ID, cpu.ID)
+		assert.Equal(t, errors.ENotFound, errors.ErrorCode(err))
+	})
+}
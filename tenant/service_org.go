@@ -173,6 +173,12 @@ func (s *OrgSvc) CreateOrganization(ctx context.Context, o *influxdb.Organizatio
 // Updates a single organization with changeset.
 // Returns the new organization state after update.
 func (s *OrgSvc) UpdateOrganization(ctx context.Context, id platform.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+	if upd.QueryLimits != nil {
+		if err := upd.QueryLimits.Valid(); err != nil {
+			return nil, err
+		}
+	}
+
 	var org *influxdb.Organization
 	err := s.store.Update(ctx, func(tx kv.Tx) error {
 		o, err := s.store.UpdateOrg(ctx, tx, id, upd)
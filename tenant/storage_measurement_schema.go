@@ -0,0 +1,230 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+var (
+	measurementSchemaBucket = []byte("measurementschemasv1")
+	measurementSchemaIndex  = []byte("measurementschemaindexv1")
+)
+
+// measurementSchemaIndexKey returns the key of a measurement schema in the
+// index of schemas by bucket and name. The key of an empty name is the
+// prefix of all schemas of the bucket.
+func measurementSchemaIndexKey(bucketID platform.ID, name string) ([]byte, error) {
+	encodedID, err := bucketID.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+	k := make([]byte, platform.IDLength+len(name))
+	copy(k, encodedID)
+	copy(k[platform.IDLength:], name)
+	return k, nil
+}
+
+func unmarshalMeasurementSchema(v []byte) (*influxdb.MeasurementSchema, error) {
+	m := &influxdb.MeasurementSchema{}
+	if err := json.Unmarshal(v, m); err != nil {
+		return nil, ErrCorruptMeasurementSchema(err)
+	}
+	return m, nil
+}
+
+// GetMeasurementSchema returns a measurement schema of a bucket.
+func (s *Store) GetMeasurementSchema(ctx context.Context, tx kv.Tx, bucketID, id platform.ID) (*influxdb.MeasurementSchema, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, &errors.Error{
+			Code: errors.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrMeasurementSchemaNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	m, err := unmarshalMeasurementSchema(v)
+	if err != nil {
+		return nil, err
+	}
+	if m.BucketID != bucketID {
+		return nil, ErrMeasurementSchemaNotFound
+	}
+	return m, nil
+}
+
+// ListMeasurementSchemas returns the measurement schemas of a bucket ordered
+// by name.
+func (s *Store) ListMeasurementSchemas(ctx context.Context, tx kv.Tx, bucketID platform.ID) ([]*influxdb.MeasurementSchema, error) {
+	prefix, err := measurementSchemaIndexKey(bucketID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := tx.Bucket(measurementSchemaIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := idx.ForwardCursor(prefix, kv.WithCursorPrefix(prefix))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+
+	ms := []*influxdb.MeasurementSchema{}
+	for k, v := cursor.Next(); k != nil; k, v = cursor.Next() {
+		var id platform.ID
+		if err := id.Decode(v); err != nil {
+			return nil, &errors.Error{
+				Err: err,
+			}
+		}
+		m, err := s.GetMeasurementSchema(ctx, tx, bucketID, id)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+
+	return ms, cursor.Err()
+}
+
+func (s *Store) CreateMeasurementSchema(ctx context.Context, tx kv.Tx, m *influxdb.MeasurementSchema) (err error) {
+	ikey, err := measurementSchemaIndexKey(m.BucketID, m.Name)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(measurementSchemaIndex)
+	if err != nil {
+		return err
+	}
+
+	if _, err := idx.Get(ikey); err == nil {
+		return MeasurementSchemaAlreadyExistsError(m.Name)
+	} else if !kv.IsNotFound(err) {
+		return ErrInternalServiceError(err)
+	}
+
+	m.ID, err = s.generateSafeID(ctx, tx, measurementSchemaBucket, s.IDGen)
+	if err != nil {
+		return err
+	}
+	m.SetCreatedAt(s.now())
+	m.SetUpdatedAt(s.now())
+
+	encodedID, err := m.ID.Encode()
+	if err != nil {
+		return err
+	}
+
+	if err := idx.Put(ikey, encodedID); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return s.putMeasurementSchema(tx, m)
+}
+
+func (s *Store) UpdateMeasurementSchema(ctx context.Context, tx kv.Tx, bucketID, id platform.ID, columns []influxdb.MeasurementSchemaColumn) (*influxdb.MeasurementSchema, error) {
+	m, err := s.GetMeasurementSchema(ctx, tx, bucketID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := influxdb.ValidateMeasurementSchemaUpdate(m.Columns, columns); err != nil {
+		return nil, err
+	}
+
+	m.Columns = columns
+	m.SetUpdatedAt(s.now())
+	if err := s.putMeasurementSchema(tx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DeleteBucketMeasurementSchemas removes all measurement schemas of a bucket.
+func (s *Store) DeleteBucketMeasurementSchemas(ctx context.Context, tx kv.Tx, bucketID platform.ID) error {
+	ms, err := s.ListMeasurementSchemas(ctx, tx, bucketID)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(measurementSchemaIndex)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		ikey, err := measurementSchemaIndexKey(m.BucketID, m.Name)
+		if err != nil {
+			return err
+		}
+		if err := idx.Delete(ikey); err != nil {
+			return ErrInternalServiceError(err)
+		}
+
+		encodedID, err := m.ID.Encode()
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalServiceError(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) putMeasurementSchema(tx kv.Tx, m *influxdb.MeasurementSchema) error {
+	encodedID, err := m.ID.Encode()
+	if err != nil {
+		return err
+	}
+
+	v, err := json.Marshal(m)
+	if err != nil {
+		return &errors.Error{
+			Code: errors.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(measurementSchemaBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	return nil
+}
@@ -237,6 +237,15 @@ func (s *Store) UpdateOrg(ctx context.Context, tx kv.Tx, id platform.ID, upd inf
 		u.Description = *upd.Description
 	}
 
+	if upd.QueryLimits != nil {
+		if *upd.QueryLimits == (influxdb.QueryLimits{}) {
+			u.QueryLimits = nil
+		} else {
+			limits := *upd.QueryLimits
+			u.QueryLimits = &limits
+		}
+	}
+
 	v, err := marshalOrg(u)
 	if err != nil {
 		return nil, err
@@ -177,6 +177,35 @@ func TestOrg(t *testing.T) {
 				require.Equal(t, expected, orgs)
 			},
 		},
+		{
+			name:  "update query limits",
+			setup: simpleSetup,
+			update: func(t *testing.T, store *tenant.Store, tx kv.Tx) {
+				_, err := store.UpdateOrg(context.Background(), tx, firstOrgID, influxdb.OrganizationUpdate{
+					QueryLimits: &influxdb.QueryLimits{ConcurrencyQuota: 2, QueueSize: 4},
+				})
+				require.NoError(t, err)
+
+				_, err = store.UpdateOrg(context.Background(), tx, thirdOrgID, influxdb.OrganizationUpdate{
+					QueryLimits: &influxdb.QueryLimits{MemoryBytesQuota: 1024},
+				})
+				require.NoError(t, err)
+
+				// The zero value removes the limits.
+				_, err = store.UpdateOrg(context.Background(), tx, thirdOrgID, influxdb.OrganizationUpdate{
+					QueryLimits: &influxdb.QueryLimits{},
+				})
+				require.NoError(t, err)
+			},
+			results: func(t *testing.T, store *tenant.Store, tx kv.Tx) {
+				orgs, err := store.ListOrgs(context.Background(), tx)
+				require.NoError(t, err)
+
+				expected := testOrgs(10, withCrudLog)
+				expected[0].QueryLimits = &influxdb.QueryLimits{ConcurrencyQuota: 2, QueueSize: 4}
+				require.Equal(t, expected, orgs)
+			},
+		},
 		{
 			name:  "delete",
 			setup: simpleSetup,
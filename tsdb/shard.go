@@ -115,6 +115,15 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// The points that were dropped and why, when they are known.
+	DroppedPoints []DroppedPoint
+}
+
+// DroppedPoint is a point dropped by a partial write.
+type DroppedPoint struct {
+	Point  models.Point
+	Reason string
 }
 
 func (e PartialWriteError) Error() string {
@@ -551,6 +560,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		err            error
 		dropped        int
 		reason         string // only first error reason is set unless returned from CreateSeriesListIfNotExists
+		droppedPoints  []DroppedPoint
 	)
 
 	// Create all series against the index in bulk.
@@ -568,20 +578,24 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		// Drop any series w/ a "time" tag, these are illegal
 		if v := tags.Get(timeBytes); v != nil {
 			dropped++
+			pointReason := fmt.Sprintf(
+				"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name()))
 			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid tag key: input tag \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
+				reason = pointReason
 			}
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			continue
 		}
 
 		// Drop any series with invalid unicode characters in the key.
 		if validateKeys && !models.ValidKeyTokens(string(p.Name()), tags) {
 			dropped++
+			pointReason := fmt.Sprintf("key contains invalid unicode: \"%s\"", string(p.Key()))
 			if reason == "" {
-				reason = fmt.Sprintf("key contains invalid unicode: \"%s\"", string(p.Key()))
+				reason = pointReason
 			}
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			continue
 		}
 
@@ -599,7 +613,10 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 
 	// Add new series. Check for partial writes.
-	var droppedKeys [][]byte
+	var (
+		droppedKeys       [][]byte
+		droppedKeysReason string
+	)
 	if err := engine.CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
 		switch err := err.(type) {
 		// TODO(jmw): why is this a *PartialWriteError when everything else is not a pointer?
@@ -607,6 +624,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		// the places that construct it.
 		case *PartialWriteError:
 			reason = err.Reason
+			droppedKeysReason = err.Reason
 			dropped += err.Dropped
 			droppedKeys = err.DroppedKeys
 			atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
@@ -628,17 +646,20 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 			break
 		}
 		if !validField {
+			pointReason := fmt.Sprintf(
+				"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
+				"time", string(p.Name()))
 			if reason == "" {
-				reason = fmt.Sprintf(
-					"invalid field name: input field \"%s\" on measurement \"%s\" is invalid",
-					"time", string(p.Name()))
+				reason = pointReason
 			}
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: pointReason})
 			dropped++
 			continue
 		}
 
 		// Skip any points whos keys have been dropped. Dropped has already been incremented for them.
 		if len(droppedKeys) > 0 && bytesutil.Contains(droppedKeys, keys[i]) {
+			droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: droppedKeysReason})
 			continue
 		}
 
@@ -653,6 +674,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 					reason = err.Reason
 				}
 				dropped += err.Dropped
+				droppedPoints = append(droppedPoints, DroppedPoint{Point: p, Reason: err.Reason})
 				atomic.AddInt64(&s.stats.WritePointsDropped, int64(err.Dropped))
 			default:
 				return nil, nil, err
@@ -693,7 +715,7 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 	}
 
 	if dropped > 0 {
-		err = PartialWriteError{Reason: reason, Dropped: dropped}
+		err = PartialWriteError{Reason: reason, Dropped: dropped, DroppedPoints: droppedPoints}
 	}
 
 	return points[:j], fieldsToCreate, err
@@ -360,6 +360,73 @@ func TestShardWriteAddNewField(t *testing.T) {
 	}
 }
 
+func TestShard_WritePoints_DroppedPoints(t *testing.T) {
+	tmpDir, _ := ioutil.TempDir("", "shard_test")
+	defer os.RemoveAll(tmpDir)
+	tmpShard := filepath.Join(tmpDir, "shard")
+	tmpWal := filepath.Join(tmpDir, "wal")
+
+	sfile := MustOpenSeriesFile(t)
+	defer sfile.Close()
+
+	opts := tsdb.NewEngineOptions()
+	opts.Config.WALDir = filepath.Join(tmpDir, "wal")
+
+	sh := tsdb.NewShard(1, tmpShard, tmpWal, sfile.SeriesFile, opts)
+	if err := sh.Open(); err != nil {
+		t.Fatalf("error opening shard: %s", err.Error())
+	}
+	defer sh.Close()
+
+	if err := sh.WritePoints([]models.Point{models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conflict := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": "one"},
+		time.Unix(2, 2),
+	)
+	timeTag := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"time": "now"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(2, 2),
+	)
+	valid := models.MustNewPoint(
+		"cpu",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 2.0},
+		time.Unix(3, 2),
+	)
+
+	err := sh.WritePoints([]models.Point{conflict, timeTag, valid})
+	partialErr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("expected a partial write error, got %v", err)
+	}
+	if partialErr.Dropped != 2 || len(partialErr.DroppedPoints) != 2 {
+		t.Fatalf("expected 2 dropped points, got %+v", partialErr)
+	}
+
+	reasons := make(map[models.Point]string)
+	for _, p := range partialErr.DroppedPoints {
+		reasons[p.Point] = p.Reason
+	}
+	if got := reasons[conflict]; !strings.HasPrefix(got, tsdb.ErrFieldTypeConflict.Error()) {
+		t.Errorf("unexpected reason for the field type conflict: %q", got)
+	}
+	if got := reasons[timeTag]; !strings.Contains(got, "invalid tag key") {
+		t.Errorf("unexpected reason for the time tag: %q", got)
+	}
+}
+
 // Tests concurrently writing to the same shard with different field types which
 // can trigger a panic when the shard is snapshotted to TSM files.
 func TestShard_WritePoints_FieldConflictConcurrent(t *testing.T) {
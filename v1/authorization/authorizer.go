@@ -75,7 +75,7 @@ func (v *Authorizer) checkAuthError(ctx context.Context, auth *influxdb.Authoriz
 		return nil, influxdb.ErrCredentialsUnauthorized
 	}
 
-	if auth.Status != influxdb.Active {
+	if auth.Status != influxdb.Active || auth.Expired() != nil {
 		return nil, influxdb.ErrCredentialsUnauthorized
 	}
 
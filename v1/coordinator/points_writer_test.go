@@ -428,6 +428,47 @@ func TestPointsWriter_WritePoints_Dropped(t *testing.T) {
 	}
 }
 
+func TestPointsWriter_WritePoints_PartialAcrossShards(t *testing.T) {
+	pr := &coordinator.WritePointsRequest{
+		Database:        "mydb",
+		RetentionPolicy: "myrp",
+	}
+	ms := NewPointsWriterMetaClient()
+
+	// Three points mapped to two distinct shards, every shard drops its points.
+	pr.AddPoint("cpu", 1.0, time.Now(), nil)
+	pr.AddPoint("cpu", 2.0, time.Now().Add(time.Hour), nil)
+	pr.AddPoint("cpu", 3.0, time.Now().Add(time.Hour+time.Second), nil)
+
+	store := &fakeStore{
+		WriteFn: func(shardID uint64, points []models.Point) error {
+			dropped := make([]tsdb.DroppedPoint, len(points))
+			for i, p := range points {
+				dropped[i] = tsdb.DroppedPoint{Point: p, Reason: "bad point"}
+			}
+			return tsdb.PartialWriteError{Reason: "bad point", Dropped: len(points), DroppedPoints: dropped}
+		},
+	}
+	ms.NodeIDFn = func() uint64 { return 1 }
+
+	c := coordinator.NewPointsWriter()
+	c.MetaClient = ms
+	c.TSDBStore = store
+	c.Node = &influxdb.Node{ID: 1}
+
+	c.Open()
+	defer c.Close()
+
+	err := c.WritePointsPrivileged(pr.Database, pr.RetentionPolicy, models.ConsistencyLevelOne, pr.Points)
+	partialErr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatalf("PointsWriter.WritePoints(): got %v, exp %v", err, tsdb.PartialWriteError{})
+	}
+	if partialErr.Dropped != 3 || len(partialErr.DroppedPoints) != 3 {
+		t.Errorf("PointsWriter.WritePoints(): got %d dropped points, exp 3", len(partialErr.DroppedPoints))
+	}
+}
+
 var shardID uint64
 
 type fakeStore struct {
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,12 +15,18 @@ import (
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	iql "github.com/influxdata/influxdb/v2/influxql"
 	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/kit/platform"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
 	"github.com/influxdata/influxdb/v2/pkg/tracing"
 	"github.com/influxdata/influxdb/v2/pkg/tracing/fields"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
 )
@@ -25,6 +35,13 @@ import (
 // when a database has not been provided.
 var ErrDatabaseNameRequired = errors.New("database name required")
 
+// DefaultIntoWriteBufferSize is the number of points buffered by a SELECT INTO
+// statement before they are written to the target bucket.
+const DefaultIntoWriteBufferSize = 10000
+
+// startTime is used to report the uptime of the process in SHOW DIAGNOSTICS.
+var startTime = time.Now().UTC()
+
 // StatementExecutor executes a statement in the query.
 type StatementExecutor struct {
 	MetaClient MetaClient
@@ -37,6 +54,22 @@ type StatementExecutor struct {
 
 	DBRP influxdb.DBRPMappingServiceV2
 
+	// BucketService manages the buckets backing databases and retention policies.
+	BucketService influxdb.BucketService
+
+	// DeleteService deletes data from buckets for DELETE statements.
+	DeleteService influxdb.DeleteService
+
+	// PointsWriter writes the results of SELECT INTO statements to buckets.
+	PointsWriter BucketPointsWriter
+
+	// ContinuousQueries stores and schedules continuous queries.
+	ContinuousQueries ContinuousQueryService
+
+	// Queries tracks the running Flux and InfluxQL queries for SHOW QUERIES
+	// and KILL QUERY.
+	Queries *registry.Registry
+
 	// Select statement limits
 	MaxSelectPointN   int
 	MaxSelectSeriesN  int
@@ -55,31 +88,31 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	var err error
 	switch stmt := stmt.(type) {
 	case *influxql.AlterRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("ALTER RETENTION POLICY")
+		err = e.executeAlterRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateContinuousQueryStatement:
-		err = iql.ErrNotImplemented("CREATE CONTINUOUS QUERY")
+		err = e.executeCreateContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.CreateDatabaseStatement:
-		err = iql.ErrNotImplemented("CREATE DATABASE")
+		err = e.executeCreateDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.CreateRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("CREATE RETENTION POLICY")
+		err = e.executeCreateRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.CreateSubscriptionStatement:
 		err = iql.ErrNotImplemented("CREATE SUBSCRIPTION")
 	case *influxql.CreateUserStatement:
 		err = iql.ErrNotImplemented("CREATE USER")
 	case *influxql.DeleteSeriesStatement:
-		return e.executeDeleteSeriesStatement(ctx, stmt, ectx.Database, ectx)
+		err = e.executeDeleteSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropContinuousQueryStatement:
-		err = iql.ErrNotImplemented("DROP CONTINUOUS QUERY")
+		err = e.executeDropContinuousQueryStatement(ctx, stmt, ectx)
 	case *influxql.DropDatabaseStatement:
-		err = iql.ErrNotImplemented("DROP DATABASE")
+		err = e.executeDropDatabaseStatement(ctx, stmt, ectx)
 	case *influxql.DropMeasurementStatement:
 		return e.executeDropMeasurementStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropSeriesStatement:
-		err = iql.ErrNotImplemented("DROP SERIES")
+		err = e.executeDropSeriesStatement(ctx, stmt, ectx.Database, ectx)
 	case *influxql.DropRetentionPolicyStatement:
-		err = iql.ErrNotImplemented("DROP RETENTION POLICY")
+		err = e.executeDropRetentionPolicyStatement(ctx, stmt, ectx)
 	case *influxql.DropShardStatement:
-		err = iql.ErrNotImplemented("DROP SHARD")
+		err = e.executeDropShardStatement(ctx, stmt, ectx)
 	case *influxql.DropSubscriptionStatement:
 		err = iql.ErrNotImplemented("DROP SUBSCRIPTION")
 	case *influxql.DropUserStatement:
@@ -99,27 +132,27 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	case *influxql.RevokeAdminStatement:
 		err = iql.ErrNotImplemented("REVOKE ALL")
 	case *influxql.ShowContinuousQueriesStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW CONTINUOUS QUERIES")
+		rows, err = e.executeShowContinuousQueriesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDatabasesStatement:
 		rows, err = e.executeShowDatabasesStatement(ctx, stmt, ectx)
 	case *influxql.ShowDiagnosticsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW DIAGNOSTICS")
+		rows, err = e.executeShowDiagnosticsStatement(ctx, stmt, ectx)
 	case *influxql.ShowGrantsForUserStatement:
 		rows, err = nil, iql.ErrNotImplemented("SHOW GRANTS")
 	case *influxql.ShowMeasurementsStatement:
 		return e.executeShowMeasurementsStatement(ctx, stmt, ectx)
 	case *influxql.ShowMeasurementCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW MEASUREMENT CARDINALITY")
+		rows, err = e.executeShowMeasurementCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowRetentionPoliciesStatement:
 		rows, err = e.executeShowRetentionPoliciesStatement(ctx, stmt, ectx)
 	case *influxql.ShowSeriesCardinalityStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SERIES CARDINALITY")
+		rows, err = e.executeShowSeriesCardinalityStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARDS")
+		rows, err = e.executeShowShardsStatement(ctx, stmt, ectx)
 	case *influxql.ShowShardGroupsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW SHARD GROUPS")
+		rows, err = e.executeShowShardGroupsStatement(ctx, stmt, ectx)
 	case *influxql.ShowStatsStatement:
-		rows, err = nil, iql.ErrNotImplemented("SHOW STATS")
+		rows, err = e.executeShowStatsStatement(ctx, stmt, ectx)
 	case *influxql.ShowSubscriptionsStatement:
 		rows, err = nil, iql.ErrNotImplemented("SHOW SUBSCRIPTIONS")
 	case *influxql.ShowTagKeysStatement:
@@ -130,8 +163,10 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 		rows, err = nil, iql.ErrNotImplemented("SHOW USERS")
 	case *influxql.SetPasswordUserStatement:
 		err = iql.ErrNotImplemented("SET PASSWORD")
-	case *influxql.ShowQueriesStatement, *influxql.KillQueryStatement:
-		err = iql.ErrNotImplemented("SHOW QUERIES")
+	case *influxql.KillQueryStatement:
+		err = e.executeKillQueryStatement(ctx, stmt, ectx)
+	case *influxql.ShowQueriesStatement:
+		rows, err = e.executeShowQueriesStatement(ctx, ectx)
 	default:
 		return query.ErrInvalidQuery
 	}
@@ -146,6 +181,341 @@ func (e *StatementExecutor) ExecuteStatement(ctx context.Context, stmt influxql.
 	})
 }
 
+func (e *StatementExecutor) executeAlterRetentionPolicyStatement(ctx context.Context, stmt *influxql.AlterRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("ALTER RETENTION POLICY")
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.Name)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		return meta.ErrRetentionPolicyNotFound
+	}
+
+	if stmt.Duration != nil || stmt.ShardGroupDuration != nil {
+		if _, err := e.BucketService.UpdateBucket(ctx, mapping.BucketID, influxdb.BucketUpdate{
+			RetentionPeriod:    stmt.Duration,
+			ShardGroupDuration: stmt.ShardGroupDuration,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if stmt.Default && !mapping.Default {
+		mapping.Default = true
+		if err := e.DBRP.Update(ctx, mapping); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeCreateDatabaseStatement(ctx context.Context, stmt *influxql.CreateDatabaseStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("CREATE DATABASE")
+	}
+
+	rpName := stmt.RetentionPolicyName
+	if rpName == "" {
+		rpName = meta.DefaultRetentionPolicyName
+	}
+	var duration time.Duration
+	if stmt.RetentionPolicyDuration != nil {
+		duration = *stmt.RetentionPolicyDuration
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(mappings) > 0 {
+		// Creating a database that already exists is a no-op
+		// unless a conflicting retention policy was requested.
+		if !stmt.RetentionPolicyCreate {
+			return nil
+		}
+		for _, m := range mappings {
+			if m.RetentionPolicy != rpName {
+				continue
+			}
+			b, err := e.BucketService.FindBucketByID(ctx, m.BucketID)
+			if err != nil {
+				return err
+			}
+			if b.RetentionPeriod != duration ||
+				(stmt.RetentionPolicyShardGroupDuration != 0 && b.ShardGroupDuration != stmt.RetentionPolicyShardGroupDuration) {
+				return meta.ErrRetentionPolicyConflict
+			}
+			return nil
+		}
+	}
+
+	return e.createRetentionPolicy(ctx, ectx.OrgID, stmt.Name, rpName, duration, stmt.RetentionPolicyShardGroupDuration, true)
+}
+
+func (e *StatementExecutor) executeCreateRetentionPolicyStatement(ctx context.Context, stmt *influxql.CreateRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("CREATE RETENTION POLICY")
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Database,
+	})
+	if err != nil {
+		return err
+	} else if len(mappings) == 0 {
+		return query.ErrDatabaseNotFound(stmt.Database)
+	}
+	for _, m := range mappings {
+		if m.RetentionPolicy == stmt.Name {
+			return meta.ErrRetentionPolicyExists
+		}
+	}
+
+	return e.createRetentionPolicy(ctx, ectx.OrgID, stmt.Database, stmt.Name, stmt.Duration, stmt.ShardGroupDuration, stmt.Default)
+}
+
+func (e *StatementExecutor) executeDropDatabaseStatement(ctx context.Context, stmt *influxql.DropDatabaseStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("DROP DATABASE")
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &stmt.Name,
+	})
+	if err != nil {
+		return err
+	} else if len(mappings) == 0 {
+		// Dropping a database that does not exist is not an error.
+		return nil
+	}
+
+	// Nothing is dropped unless all the retention policies of the database
+	// can be.
+	for _, m := range mappings {
+		if err := checkWritePermission(ctx, m, stmt.Name); err != nil {
+			return err
+		}
+	}
+
+	// Continuous queries of the database would keep running without it.
+	if e.ContinuousQueries != nil {
+		cqs, err := e.ContinuousQueries.FindContinuousQueries(ctx, continuous_querier.ContinuousQueryFilter{
+			OrgID:    &ectx.OrgID,
+			Database: &stmt.Name,
+		})
+		if err != nil {
+			return err
+		}
+		for _, cq := range cqs {
+			if err := e.ContinuousQueries.DeleteContinuousQuery(ctx, ectx.OrgID, cq.Database, cq.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, m := range mappings {
+		if err := e.dropRetentionPolicy(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeDropRetentionPolicyStatement(ctx context.Context, stmt *influxql.DropRetentionPolicyStatement, ectx *query.ExecutionContext) error {
+	if e.BucketService == nil {
+		return iql.ErrNotImplemented("DROP RETENTION POLICY")
+	}
+
+	mapping, err := e.findMapping(ctx, ectx.OrgID, stmt.Database, stmt.Name)
+	if err != nil {
+		return err
+	} else if mapping == nil {
+		// Dropping a retention policy that does not exist is not an error.
+		return nil
+	}
+	return e.dropRetentionPolicy(ctx, mapping)
+}
+
+// createRetentionPolicy creates a bucket named "database/rp" together with a
+// DBRP mapping for it.
+func (e *StatementExecutor) createRetentionPolicy(ctx context.Context, orgID platform.ID, database, rp string, duration, shardGroupDuration time.Duration, makeDefault bool) error {
+	b := &influxdb.Bucket{
+		OrgID:               orgID,
+		Type:                influxdb.BucketTypeUser,
+		Name:                database + "/" + rp,
+		Description:         fmt.Sprintf("Created by InfluxQL for database %s with retention policy %s", database, rp),
+		RetentionPolicyName: rp,
+		RetentionPeriod:     duration,
+		ShardGroupDuration:  shardGroupDuration,
+	}
+	if err := e.BucketService.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+
+	mapping := &influxdb.DBRPMappingV2{
+		Database:        database,
+		RetentionPolicy: rp,
+		Default:         makeDefault,
+		OrganizationID:  orgID,
+		BucketID:        b.ID,
+		OwnsBucket:      true,
+	}
+	if err := e.DBRP.Create(ctx, mapping); err != nil {
+		// Remove the bucket so a retry does not conflict with it.
+		if derr := e.BucketService.DeleteBucket(ctx, b.ID); derr != nil {
+			return fmt.Errorf("creating DBRP mapping: %v; removing bucket: %v", err, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// dropRetentionPolicy deletes a DBRP mapping. The bucket it maps to is deleted
+// as well if it was created by InfluxQL and no other mapping still refers to
+// it; buckets mapped by hand are left alone.
+func (e *StatementExecutor) dropRetentionPolicy(ctx context.Context, mapping *influxdb.DBRPMappingV2) error {
+	if err := e.DBRP.Delete(ctx, mapping.OrganizationID, mapping.ID); err != nil {
+		return err
+	} else if !mapping.OwnsBucket {
+		return nil
+	}
+
+	others, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &mapping.OrganizationID,
+		BucketID: &mapping.BucketID,
+	})
+	if err != nil {
+		return err
+	} else if len(others) > 0 {
+		return nil
+	}
+
+	if err := e.BucketService.DeleteBucket(ctx, mapping.BucketID); err != nil && errors2.ErrorCode(err) != errors2.ENotFound {
+		return err
+	}
+	return nil
+}
+
+// findMapping returns the DBRP mapping for the database and retention policy,
+// or nil if there is none.
+func (e *StatementExecutor) findMapping(ctx context.Context, orgID platform.ID, database, rp string) (*influxdb.DBRPMappingV2, error) {
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:           &orgID,
+		Database:        &database,
+		RetentionPolicy: &rp,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(mappings) == 0 {
+		return nil, nil
+	}
+	return mappings[0], nil
+}
+
+func (e *StatementExecutor) executeCreateContinuousQueryStatement(ctx context.Context, q *influxql.CreateContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if e.ContinuousQueries == nil {
+		return iql.ErrNotImplemented("CREATE CONTINUOUS QUERY")
+	}
+
+	if _, err := e.findWritableDefaultRP(ctx, q.Database, ectx); err != nil {
+		return err
+	}
+	if _, err := e.findTargetMapping(ctx, q.Source.Target, ectx); err != nil {
+		return err
+	}
+
+	// The query runs with the authorization it is created with, which a
+	// session does not have.
+	auth, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+	if auth.Kind() != influxdb.AuthorizationKind {
+		return &errors2.Error{
+			Code: errors2.EForbidden,
+			Msg:  "continuous queries must be created with a token",
+		}
+	}
+
+	return e.ContinuousQueries.CreateContinuousQuery(ctx, &continuous_querier.ContinuousQuery{
+		OrganizationID:  ectx.OrgID,
+		OwnerID:         auth.GetUserID(),
+		AuthorizationID: auth.Identifier(),
+		Database:        q.Database,
+		Name:            q.Name,
+		Query:           q.String(),
+	})
+}
+
+func (e *StatementExecutor) executeDropContinuousQueryStatement(ctx context.Context, q *influxql.DropContinuousQueryStatement, ectx *query.ExecutionContext) error {
+	if e.ContinuousQueries == nil {
+		return iql.ErrNotImplemented("DROP CONTINUOUS QUERY")
+	}
+
+	if _, err := e.findWritableDefaultRP(ctx, q.Database, ectx); err != nil {
+		return err
+	}
+	return e.ContinuousQueries.DeleteContinuousQuery(ctx, ectx.OrgID, q.Database, q.Name)
+}
+
+func (e *StatementExecutor) executeShowContinuousQueriesStatement(ctx context.Context, q *influxql.ShowContinuousQueriesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.ContinuousQueries == nil {
+		return nil, iql.ErrNotImplemented("SHOW CONTINUOUS QUERIES")
+	}
+
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cqs, err := e.ContinuousQueries.FindContinuousQueries(ctx, continuous_querier.ContinuousQueryFilter{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*models.Row{}
+	seenDbs := make(map[string]struct{}, len(dbrps))
+	for _, dbrp := range dbrps {
+		if _, ok := seenDbs[dbrp.Database]; ok {
+			continue
+		}
+
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if errors2.ErrorCode(err) == errors2.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		seenDbs[dbrp.Database] = struct{}{}
+
+		row := &models.Row{Columns: []string{"name", "query"}, Name: dbrp.Database}
+		for _, cq := range cqs {
+			if cq.Database == dbrp.Database {
+				row.Values = append(row.Values, []interface{}{cq.Name, cq.Query})
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
 func (e *StatementExecutor) executeExplainStatement(ctx context.Context, q *influxql.ExplainStatement, ectx *query.ExecutionContext) (models.Rows, error) {
 	opt := query.SelectOptions{
 		OrgID:       ectx.OrgID,
@@ -160,222 +530,1026 @@ func (e *StatementExecutor) executeExplainStatement(ctx context.Context, q *infl
 	if err != nil {
 		return nil, err
 	}
-	defer p.Close()
+	defer p.Close()
+
+	plan, err := p.Explain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan = strings.TrimSpace(plan)
+
+	row := &models.Row{
+		Columns: []string{"QUERY PLAN"},
+	}
+	for _, s := range strings.Split(plan, "\n") {
+		row.Values = append(row.Values, []interface{}{s})
+	}
+	return models.Rows{row}, nil
+}
+
+func (e *StatementExecutor) executeExplainAnalyzeStatement(ctx context.Context, q *influxql.ExplainStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	stmt := q.Statement
+	t, span := tracing.NewTrace("select")
+	ctx = tracing.NewContextWithTrace(ctx, t)
+	ctx = tracing.NewContextWithSpan(ctx, span)
+	var aux query.Iterators
+	ctx = query.NewContextWithIterators(ctx, &aux)
+	start := time.Now()
+
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	if err != nil {
+		return nil, err
+	}
+
+	iterTime := time.Since(start)
+
+	// Generate a row emitter from the iterator set.
+	em := query.NewEmitter(cur, ectx.ChunkSize)
+
+	// Emit rows to the results channel.
+	var writeN int64
+	for {
+		var row *models.Row
+		row, _, err = em.Emit()
+		if err != nil {
+			goto CLEANUP
+		} else if row == nil {
+			// Check if the query was interrupted while emitting.
+			if err = ctx.Err(); err != nil {
+				goto CLEANUP
+			}
+			break
+		}
+
+		writeN += int64(len(row.Values))
+	}
+
+CLEANUP:
+	em.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// close auxiliary iterators deterministically to finalize any captured measurements
+	aux.Close()
+
+	totalTime := time.Since(start)
+	span.MergeFields(
+		fields.Duration("total_time", totalTime),
+		fields.Duration("planning_time", iterTime),
+		fields.Duration("execution_time", totalTime-iterTime),
+	)
+	span.Finish()
+
+	row := &models.Row{
+		Columns: []string{"EXPLAIN ANALYZE"},
+	}
+	for _, s := range strings.Split(t.Tree().String(), "\n") {
+		row.Values = append(row.Values, []interface{}{s})
+	}
+
+	return models.Rows{row}, nil
+}
+
+func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
+	// Resolve the target bucket and verify write access before any data is read.
+	var pointsWriter *bufferedPointsWriter
+	if stmt.Target != nil {
+		if e.PointsWriter == nil {
+			return iql.ErrNotImplemented("SELECT INTO")
+		}
+
+		mapping, err := e.findTargetMapping(ctx, stmt.Target, ectx)
+		if err != nil {
+			return err
+		}
+		pointsWriter = newBufferedPointsWriter(e.PointsWriter, mapping.OrganizationID, mapping.BucketID, DefaultIntoWriteBufferSize)
+	}
+
+	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	if err != nil {
+		return err
+	}
+
+	// Generate a row emitter from the iterator set.
+	em := query.NewEmitter(cur, ectx.ChunkSize)
+	defer em.Close()
+
+	// Emit rows to the results channel.
+	var writeN int64
+	var emitted bool
+
+	for {
+		row, partial, err := em.Emit()
+		if err != nil {
+			return err
+		} else if row == nil {
+			// Check if the query was interrupted while emitting.
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			break
+		}
+
+		// Write points back into the target bucket for INTO statements.
+		if stmt.Target != nil {
+			n, err := e.writeInto(ctx, pointsWriter, stmt, row)
+			if err != nil {
+				return err
+			}
+			writeN += n
+			continue
+		}
+
+		result := &query.Result{
+			Series:  []*models.Row{row},
+			Partial: partial,
+		}
+
+		// Send results or exit if closing.
+		if err := ectx.Send(ctx, result); err != nil {
+			return err
+		}
+
+		emitted = true
+	}
+
+	// Flush remaining points and emit write count if an INTO statement.
+	if stmt.Target != nil {
+		if err := pointsWriter.Flush(ctx); err != nil {
+			return err
+		}
+
+		return ectx.Send(ctx, &query.Result{
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), writeN}},
+			}},
+		})
+	}
+
+	// Always emit at least one result.
+	if !emitted {
+		return ectx.Send(ctx, &query.Result{
+			Series: make([]*models.Row, 0),
+		})
+	}
+
+	return nil
+}
+
+// findTargetMapping returns the DBRP mapping for the target of a SELECT INTO
+// statement. It returns an error if the caller is not permitted to write to
+// the mapped bucket.
+func (e *StatementExecutor) findTargetMapping(ctx context.Context, target *influxql.Target, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	m := target.Measurement
+	if m.Database == "" {
+		return nil, errNoDatabaseInTarget
+	}
+
+	filter := influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &m.Database,
+	}
+	if m.RetentionPolicy != "" {
+		filter.RetentionPolicy = &m.RetentionPolicy
+	} else {
+		defaultRP := true
+		filter.Default = &defaultRP
+	}
+
+	mappings, n, err := e.DBRP.FindMany(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		if m.RetentionPolicy != "" {
+			return nil, fmt.Errorf("retention policy not found: %s", m.RetentionPolicy)
+		}
+		return nil, query.ErrDatabaseNotFound(m.Database)
+	}
+	mapping := mappings[0]
+
+	perm, err := influxdb.NewPermissionAtID(mapping.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, mapping.OrganizationID)
+	if err != nil {
+		return nil, &errors2.Error{
+			Code: errors2.EInternal,
+			Msg:  fmt.Sprintf("unable to create permission for bucket: %v", err),
+			Err:  err,
+		}
+	}
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return nil, &errors2.Error{
+			Code: errors2.EForbidden,
+			Msg:  "insufficient permissions for write",
+			Err:  err,
+		}
+	}
+	return mapping, nil
+}
+
+func (e *StatementExecutor) writeInto(ctx context.Context, w *bufferedPointsWriter, stmt *influxql.SelectStatement, row *models.Row) (n int64, err error) {
+	// It might seem a bit weird that this is where we do this, since we will have to
+	// convert rows back to points. The Executors (both aggregate and raw) are complex
+	// enough that changing them to write back to the DB is going to be clumsy.
+	name := stmt.Target.Measurement.Name
+	if name == "" {
+		name = row.Name
+	}
+
+	points, err := convertRowToPoints(name, row)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.WritePoints(ctx, points); err != nil {
+		return 0, err
+	}
+
+	return int64(len(points)), nil
+}
+
+var errNoDatabaseInTarget = errors.New("no database in target")
+
+// convertRowToPoints will convert a query result Row into Points that can be written back in.
+func convertRowToPoints(measurementName string, row *models.Row) ([]models.Point, error) {
+	// figure out which parts of the result are the time and which are the fields
+	timeIndex := -1
+	fieldIndexes := make(map[string]int)
+	for i, c := range row.Columns {
+		if c == "time" {
+			timeIndex = i
+		} else {
+			fieldIndexes[c] = i
+		}
+	}
+
+	if timeIndex == -1 {
+		return nil, errors.New("error finding time index in result")
+	}
+
+	points := make([]models.Point, 0, len(row.Values))
+	for _, v := range row.Values {
+		vals := make(map[string]interface{})
+		for fieldName, fieldIndex := range fieldIndexes {
+			val := v[fieldIndex]
+			// Check specifically for nil or a NullFloat. This is because
+			// the NullFloat represents float numbers that don't have an internal representation
+			// (like NaN) that cannot be written back, but will not equal nil so there will be
+			// an attempt to write them if we do not check for it.
+			if val != nil && val != query.NullFloat {
+				vals[fieldName] = v[fieldIndex]
+			}
+		}
+
+		p, err := models.NewPoint(measurementName, models.NewTags(row.Tags), vals, v[timeIndex].(time.Time))
+		if err != nil {
+			// Drop points that can't be stored
+			continue
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
+	defer func(start time.Time) {
+		dur := time.Since(start)
+		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
+	}(time.Now())
+
+	sopt := query.SelectOptions{
+		OrgID:              opt.OrgID,
+		NodeID:             opt.NodeID,
+		MaxSeriesN:         e.MaxSelectSeriesN,
+		MaxPointN:          e.MaxSelectPointN,
+		MaxBucketsN:        e.MaxSelectBucketsN,
+		StatisticsGatherer: gatherer,
+	}
+
+	// Create a set of iterators from a selection.
+	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
+	if err != nil {
+		return nil, err
+	}
+	return cur, nil
+}
+
+func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	row := &models.Row{Name: "databases", Columns: []string{"name"}}
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seenDbs := make(map[string]struct{}, len(dbrps))
+	for _, dbrp := range dbrps {
+		if _, ok := seenDbs[dbrp.Database]; ok {
+			continue
+		}
+
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if errors2.ErrorCode(err) == errors2.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		seenDbs[dbrp.Database] = struct{}{}
+		row.Values = append(row.Values, []interface{}{dbrp.Database})
+	}
+	return []*models.Row{row}, nil
+}
+
+// readableMappings returns the DBRP mappings of the organization whose buckets
+// the caller is permitted to read, keyed by bucket ID.
+func (e *StatementExecutor) readableMappings(ctx context.Context, ectx *query.ExecutionContext) (map[platform.ID][]*influxdb.DBRPMappingV2, error) {
+	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID: &ectx.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[platform.ID][]*influxdb.DBRPMappingV2, len(dbrps))
+	for _, dbrp := range dbrps {
+		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		err = authorizer.IsAllowed(ctx, *perm)
+		if err != nil {
+			if errors2.ErrorCode(err) == errors2.EUnauthorized {
+				continue
+			}
+			return nil, err
+		}
+		readable[dbrp.BucketID] = append(readable[dbrp.BucketID], dbrp)
+	}
+	return readable, nil
+}
+
+func (e *StatementExecutor) executeShowShardsStatement(ctx context.Context, stmt *influxql.ShowShardsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	readable, err := e.readableMappings(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shards are reported under each database mapped to their bucket.
+	rowsByDatabase := make(map[string]*models.Row)
+	for bucketID, mappings := range readable {
+		di := e.MetaClient.Database(bucketID.String())
+		if di == nil {
+			continue
+		}
+
+		for _, mapping := range mappings {
+			row, ok := rowsByDatabase[mapping.Database]
+			if !ok {
+				row = &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners"}, Name: mapping.Database}
+				rowsByDatabase[mapping.Database] = row
+			}
+
+			for _, rpi := range di.RetentionPolicies {
+				for _, sgi := range rpi.ShardGroups {
+					// Shards associated with deleted shard groups are effectively deleted.
+					// Don't list them.
+					if sgi.Deleted() {
+						continue
+					}
+
+					for _, si := range sgi.Shards {
+						ownerIDs := make([]uint64, len(si.Owners))
+						for i, owner := range si.Owners {
+							ownerIDs[i] = owner.NodeID
+						}
+
+						row.Values = append(row.Values, []interface{}{
+							si.ID,
+							mapping.Database,
+							mapping.RetentionPolicy,
+							sgi.ID,
+							sgi.StartTime.UTC().Format(time.RFC3339),
+							sgi.EndTime.UTC().Format(time.RFC3339),
+							sgi.EndTime.Add(rpi.Duration).UTC().Format(time.RFC3339),
+							joinUint64(ownerIDs),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(rowsByDatabase))
+	for name := range rowsByDatabase {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make(models.Rows, 0, len(names))
+	for _, name := range names {
+		row := rowsByDatabase[name]
+		sort.SliceStable(row.Values, func(i, j int) bool {
+			return row.Values[i][0].(uint64) < row.Values[j][0].(uint64)
+		})
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowShardGroupsStatement(ctx context.Context, stmt *influxql.ShowShardGroupsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	readable, err := e.readableMappings(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"id", "database", "retention_policy", "start_time", "end_time", "expiry_time"}, Name: "shard groups"}
+	for bucketID, mappings := range readable {
+		di := e.MetaClient.Database(bucketID.String())
+		if di == nil {
+			continue
+		}
+
+		for _, mapping := range mappings {
+			for _, rpi := range di.RetentionPolicies {
+				for _, sgi := range rpi.ShardGroups {
+					// Shards associated with deleted shard groups are effectively deleted.
+					// Don't list them.
+					if sgi.Deleted() {
+						continue
+					}
+
+					row.Values = append(row.Values, []interface{}{
+						sgi.ID,
+						mapping.Database,
+						mapping.RetentionPolicy,
+						sgi.StartTime.UTC().Format(time.RFC3339),
+						sgi.EndTime.UTC().Format(time.RFC3339),
+						sgi.EndTime.Add(rpi.Duration).UTC().Format(time.RFC3339),
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(row.Values, func(i, j int) bool {
+		vi, vj := row.Values[i], row.Values[j]
+		if vi[0].(uint64) != vj[0].(uint64) {
+			return vi[0].(uint64) < vj[0].(uint64)
+		}
+		if vi[1].(string) != vj[1].(string) {
+			return vi[1].(string) < vj[1].(string)
+		}
+		return vi[2].(string) < vj[2].(string)
+	})
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeShowStatsStatement(ctx context.Context, stmt *influxql.ShowStatsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	readable, err := e.readableMappings(ctx, ectx)
+	if err != nil {
+		return nil, err
+	}
+	serverStats := authorizeServerInfo(ctx, ectx.OrgID) == nil
+
+	var rows []*models.Row
+	for _, stat := range e.TSDBStore.Statistics(nil) {
+		if stmt.Module != "" && stat.Name != stmt.Module {
+			continue
+		}
+
+		// The storage engine tags statistics with the bucket ID as the database.
+		if db, ok := stat.Tags["database"]; ok {
+			bucketID, err := platform.IDFromString(db)
+			if err != nil {
+				continue
+			}
+			if _, ok := readable[*bucketID]; !ok {
+				continue
+			}
+		} else if !serverStats {
+			// Statistics of the whole server are shown like diagnostics.
+			continue
+		}
+
+		row := &models.Row{Name: stat.Name, Tags: stat.Tags}
+
+		row.Columns = make([]string, 0, len(stat.Values))
+		for k := range stat.Values {
+			row.Columns = append(row.Columns, k)
+		}
+		sort.Strings(row.Columns)
+
+		values := make([]interface{}, 0, len(stat.Values))
+		for _, k := range row.Columns {
+			values = append(values, stat.Values[k])
+		}
+		row.Values = [][]interface{}{values}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (e *StatementExecutor) executeShowQueriesStatement(ctx context.Context, ectx *query.ExecutionContext) (models.Rows, error) {
+	if e.Queries == nil {
+		return nil, iql.ErrNotImplemented("SHOW QUERIES")
+	}
+	queries, err := registry.AuthorizedQueries(ctx, e.Queries, ectx.OrgID)
+	if err != nil {
+		return nil, err
+	}
+
+	row := &models.Row{Columns: []string{"qid", "language", "query", "database", "user", "duration", "memory_bytes", "status"}}
+	for _, info := range queries {
+		var user string
+		if info.UserID.Valid() {
+			user = info.UserID.String()
+		}
+		row.Values = append(row.Values, []interface{}{
+			info.ID,
+			info.Language,
+			info.Query,
+			info.Database,
+			user,
+			influxql.FormatDuration(info.Duration.Truncate(time.Second)),
+			info.MemoryBytes,
+			info.Status,
+		})
+	}
+	return []*models.Row{row}, nil
+}
+
+func (e *StatementExecutor) executeKillQueryStatement(ctx context.Context, stmt *influxql.KillQueryStatement, ectx *query.ExecutionContext) error {
+	if e.Queries == nil {
+		return iql.ErrNotImplemented("KILL QUERY")
+	}
+	if stmt.Host != "" {
+		return iql.ErrNotImplemented("KILL QUERY ON")
+	}
+	if err := registry.AuthorizeKill(ctx, e.Queries, ectx.OrgID, stmt.QueryID); err != nil {
+		return err
+	}
+	return e.Queries.Kill(ectx.OrgID, stmt.QueryID)
+}
+
+// authorizeServerInfo checks that information describing the server process
+// rather than the organization may be shown, which is only the case for
+// operators and all-access tokens.
+func authorizeServerInfo(ctx context.Context, orgID platform.ID) error {
+	if err := authorizer.IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return authorizer.IsAllowedAll(ctx, influxdb.OwnerPermissions(orgID))
+	}
+	return nil
+}
+
+func (e *StatementExecutor) executeShowDiagnosticsStatement(ctx context.Context, stmt *influxql.ShowDiagnosticsStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if err := authorizeServerInfo(ctx, ectx.OrgID); err != nil {
+		return nil, err
+	}
+
+	build := influxdb.GetBuildInfo()
+	now := time.Now().UTC()
+
+	diags := map[string]*models.Row{
+		"build": {
+			Columns: []string{"Commit", "Date", "Version"},
+			Values:  [][]interface{}{{build.Commit, build.Date, build.Version}},
+		},
+		"runtime": {
+			Columns: []string{"GOARCH", "GOMAXPROCS", "GOOS", "version"},
+			Values:  [][]interface{}{{runtime.GOARCH, runtime.GOMAXPROCS(0), runtime.GOOS, runtime.Version()}},
+		},
+		"system": {
+			Columns: []string{"PID", "currentTime", "started", "uptime"},
+			Values:  [][]interface{}{{os.Getpid(), now, startTime, now.Sub(startTime).String()}},
+		},
+	}
+
+	names := make([]string, 0, len(diags))
+	for name := range diags {
+		if stmt.Module != "" && name != stmt.Module {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([]*models.Row, 0, len(names))
+	for _, name := range names {
+		row := diags[name]
+		row.Name = name
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// executeShowMeasurementCardinalityStatement returns the estimated measurement
+// cardinality of a database. The exact and filtered forms of the statement are
+// rewritten into SELECT statements by the query executor.
+func (e *StatementExecutor) executeShowMeasurementCardinalityStatement(ctx context.Context, stmt *influxql.ShowMeasurementCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	n, err := e.estimateCardinality(ctx, stmt.Database, ectx, e.TSDBStore.MeasurementsSketches)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Row{{
+		Columns: []string{"cardinality estimation"},
+		Values:  [][]interface{}{{n}},
+	}}, nil
+}
+
+// executeShowSeriesCardinalityStatement returns the estimated series
+// cardinality of a database. The exact and filtered forms of the statement are
+// rewritten into SELECT statements by the query executor.
+func (e *StatementExecutor) executeShowSeriesCardinalityStatement(ctx context.Context, stmt *influxql.ShowSeriesCardinalityStatement, ectx *query.ExecutionContext) (models.Rows, error) {
+	if stmt.Database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	n, err := e.estimateCardinality(ctx, stmt.Database, ectx, e.TSDBStore.SeriesSketches)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Row{{
+		Columns: []string{"cardinality estimation"},
+		Values:  [][]interface{}{{n}},
+	}}, nil
+}
+
+// estimateCardinality merges the sketches of every readable bucket mapped to
+// database and returns the estimated cardinality of the union.
+func (e *StatementExecutor) estimateCardinality(ctx context.Context, database string, ectx *query.ExecutionContext, sketches func(database string) (estimator.Sketch, estimator.Sketch, error)) (int64, error) {
+	readable, err := e.readableMappings(ctx, ectx)
+	if err != nil {
+		return 0, err
+	}
+
+	var ss, ts estimator.Sketch
+	var found bool
+	for bucketID, mappings := range readable {
+		if !hasDatabase(mappings, database) {
+			continue
+		}
+		found = true
+
+		s, t, err := sketches(bucketID.String())
+		if err != nil {
+			return 0, err
+		}
+		if ss == nil {
+			ss, ts = s.Clone(), t.Clone()
+			continue
+		}
+		if err := ss.Merge(s); err != nil {
+			return 0, err
+		}
+		if err := ts.Merge(t); err != nil {
+			return 0, err
+		}
+	}
+
+	if !found {
+		return 0, query.ErrDatabaseNotFound(database)
+	} else if ss == nil {
+		return 0, nil
+	}
+	return int64(ss.Count() - ts.Count()), nil
+}
+
+func hasDatabase(mappings []*influxdb.DBRPMappingV2, database string) bool {
+	for _, m := range mappings {
+		if m.Database == database {
+			return true
+		}
+	}
+	return false
+}
+
+// joinUint64 returns a comma-delimited string of uint64 numbers.
+func joinUint64(a []uint64) string {
+	var buf strings.Builder
+	for i, x := range a {
+		if i > 0 {
+			buf.WriteRune(',')
+		}
+		buf.WriteString(strconv.FormatUint(x, 10))
+	}
+	return buf.String()
+}
+
+func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	defaultRP := true
+	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+		Default:  &defaultRP,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
+	} else if n == 0 {
+		return nil, fmt.Errorf("default retention policy not set for: %s", database)
+	} else if n != 1 {
+		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+	}
+	return mappings[0], nil
+}
 
-	plan, err := p.Explain(ctx)
+// findWritableDefaultRP returns the default DBRP mapping for database. It
+// returns an error if the caller is not permitted to write to the mapped bucket.
+func (e *StatementExecutor) findWritableDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
+	mapping, err := e.getDefaultRP(ctx, database, ectx)
 	if err != nil {
 		return nil, err
 	}
-	plan = strings.TrimSpace(plan)
-
-	row := &models.Row{
-		Columns: []string{"QUERY PLAN"},
-	}
-	for _, s := range strings.Split(plan, "\n") {
-		row.Values = append(row.Values, []interface{}{s})
+	if err := checkWritePermission(ctx, mapping, database); err != nil {
+		return nil, err
 	}
-	return models.Rows{row}, nil
+	return mapping, nil
 }
 
-func (e *StatementExecutor) executeExplainAnalyzeStatement(ctx context.Context, q *influxql.ExplainStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	stmt := q.Statement
-	t, span := tracing.NewTrace("select")
-	ctx = tracing.NewContextWithTrace(ctx, t)
-	ctx = tracing.NewContextWithSpan(ctx, span)
-	var aux query.Iterators
-	ctx = query.NewContextWithIterators(ctx, &aux)
-	start := time.Now()
-
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+// checkWritePermission returns an error if the caller is not permitted to
+// write to the bucket of mapping.
+func checkWritePermission(ctx context.Context, mapping *influxdb.DBRPMappingV2, database string) error {
+	perm, err := influxdb.NewPermissionAtID(mapping.BucketID, influxdb.WriteAction, influxdb.BucketsResourceType, mapping.OrganizationID)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	iterTime := time.Since(start)
-
-	// Generate a row emitter from the iterator set.
-	em := query.NewEmitter(cur, ectx.ChunkSize)
-
-	// Emit rows to the results channel.
-	var writeN int64
-	for {
-		var row *models.Row
-		row, _, err = em.Emit()
-		if err != nil {
-			goto CLEANUP
-		} else if row == nil {
-			// Check if the query was interrupted while emitting.
-			if err = ctx.Err(); err != nil {
-				goto CLEANUP
-			}
-			break
+	if err := authorizer.IsAllowed(ctx, *perm); err != nil {
+		return &errors2.Error{
+			Code: errors2.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions for database %s", database),
+			Err:  err,
 		}
+	}
+	return nil
+}
 
-		writeN += int64(len(row.Values))
+func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	if e.DeleteService == nil {
+		return iql.ErrNotImplemented("DELETE")
 	}
 
-CLEANUP:
-	em.Close()
+	mappings, err := e.findWritableMappings(ctx, database, ectx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// close auxiliary iterators deterministically to finalize any captured measurements
-	aux.Close()
-
-	totalTime := time.Since(start)
-	span.MergeFields(
-		fields.Duration("total_time", totalTime),
-		fields.Duration("planning_time", iterTime),
-		fields.Duration("execution_time", totalTime-iterTime),
-	)
-	span.Finish()
+	// Convert "now()" to current time.
+	condition := influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
 
-	row := &models.Row{
-		Columns: []string{"EXPLAIN ANALYZE"},
+	// Determine deletion time range.
+	tagCond, timeRange, err := influxql.ConditionExpr(condition, nil)
+	if err != nil {
+		return err
 	}
-	for _, s := range strings.Split(t.Tree().String(), "\n") {
-		row.Values = append(row.Values, []interface{}{s})
+	min, max := int64(influxql.MinTime), int64(influxql.MaxTime)
+	if !timeRange.Min.IsZero() {
+		min = timeRange.Min.UnixNano()
+	}
+	if !timeRange.Max.IsZero() {
+		max = timeRange.Max.UnixNano()
 	}
 
-	return models.Rows{row}, nil
-}
-
-func (e *StatementExecutor) executeSelectStatement(ctx context.Context, stmt *influxql.SelectStatement, ectx *query.ExecutionContext) error {
-	cur, err := e.createIterators(ctx, stmt, ectx.ExecutionOptions, ectx.StatisticsGatherer)
+	tagPred, err := deletePredicateNode(tagCond)
 	if err != nil {
-		return err
+		// Conditions the delete service can't express, such as regular
+		// expressions or OR, are evaluated by the store instead.
+		for _, mapping := range mappings {
+			if err := e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, condition); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	// Generate a row emitter from the iterator set.
-	em := query.NewEmitter(cur, ectx.ChunkSize)
-	defer em.Close()
-
-	// Emit rows to the results channel.
-	var emitted bool
-
-	if stmt.Target != nil {
-		// SELECT INTO is unsupported
-		return iql.ErrNotImplemented("SELECT INTO")
-	}
+	for _, mapping := range mappings {
+		names, err := e.deleteMeasurementNames(mapping.BucketID, q.Sources)
+		if err != nil {
+			return err
+		} else if len(q.Sources) > 0 && len(names) == 0 {
+			continue
+		}
 
-	for {
-		row, partial, err := em.Emit()
+		preds, err := deletePredicates(tagPred, names)
 		if err != nil {
 			return err
-		} else if row == nil {
-			// Check if the query was interrupted while emitting.
-			if err := ctx.Err(); err != nil {
+		}
+		for _, pred := range preds {
+			if err := e.DeleteService.DeleteBucketRangePredicate(ctx, mapping.OrganizationID, mapping.BucketID, min, max, pred); err != nil {
 				return err
 			}
-			break
 		}
+	}
+	return nil
+}
 
-		result := &query.Result{
-			Series:  []*models.Row{row},
-			Partial: partial,
+// deleteMeasurementNames returns the measurement names in the bucket selected
+// by sources. Regular expressions are matched against the measurements which
+// exist in the bucket.
+func (e *StatementExecutor) deleteMeasurementNames(bucketID platform.ID, sources influxql.Sources) ([]string, error) {
+	var names []string
+	var all [][]byte
+	for _, src := range sources {
+		m, ok := src.(*influxql.Measurement)
+		if !ok {
+			return nil, fmt.Errorf("invalid source for DELETE: %s", src)
 		}
-
-		// Send results or exit if closing.
-		if err := ectx.Send(ctx, result); err != nil {
-			return err
+		if m.Regex == nil {
+			names = append(names, m.Name)
+			continue
 		}
 
-		emitted = true
+		if all == nil {
+			var err error
+			if all, err = e.TSDBStore.MeasurementNames(query.OpenAuthorizer, bucketID.String(), nil); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range all {
+			if m.Regex.Val.Match(name) {
+				names = append(names, string(name))
+			}
+		}
 	}
+	return names, nil
+}
 
-	// Always emit at least one result.
-	if !emitted {
-		return ectx.Send(ctx, &query.Result{
-			Series: make([]*models.Row, 0),
-		})
+// deletePredicates combines the tag predicate with a measurement predicate for
+// each of names. A single predicate is returned when names is empty.
+func deletePredicates(tagPred predicate.Node, names []string) ([]influxdb.Predicate, error) {
+	if len(names) == 0 {
+		pred, err := predicate.New(tagPred)
+		if err != nil {
+			return nil, err
+		}
+		return []influxdb.Predicate{pred}, nil
 	}
 
-	return nil
+	preds := make([]influxdb.Predicate, 0, len(names))
+	for _, name := range names {
+		var n predicate.Node = predicate.TagRuleNode{
+			Tag:      influxdb.Tag{Key: "_measurement", Value: name},
+			Operator: influxdb.Equal,
+		}
+		if tagPred != nil {
+			n = predicate.LogicalNode{
+				Operator: predicate.LogicalAnd,
+				Children: [2]predicate.Node{n, tagPred},
+			}
+		}
+		pred, err := predicate.New(n)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+	}
+	return preds, nil
 }
 
-func (e *StatementExecutor) createIterators(ctx context.Context, stmt *influxql.SelectStatement, opt query.ExecutionOptions, gatherer *iql.StatisticsGatherer) (query.Cursor, error) {
-	defer func(start time.Time) {
-		dur := time.Since(start)
-		gatherer.Append(iql.NewImmutableCollector(iql.Statistics{PlanDuration: dur}))
-	}(time.Now())
-
-	sopt := query.SelectOptions{
-		OrgID:              opt.OrgID,
-		NodeID:             opt.NodeID,
-		MaxSeriesN:         e.MaxSelectSeriesN,
-		MaxPointN:          e.MaxSelectPointN,
-		MaxBucketsN:        e.MaxSelectBucketsN,
-		StatisticsGatherer: gatherer,
+// deletePredicateNode converts an InfluxQL condition without time expressions
+// into a delete predicate. Only tag comparisons using = and != combined with
+// AND are supported, matching the predicates accepted by /api/v2/delete; an
+// error is returned for any other condition.
+func deletePredicateNode(expr influxql.Expr) (predicate.Node, error) {
+	switch expr := expr.(type) {
+	case nil:
+		return nil, nil
+	case *influxql.ParenExpr:
+		return deletePredicateNode(expr.Expr)
+	case *influxql.BinaryExpr:
+		switch expr.Op {
+		case influxql.AND:
+			lhs, err := deletePredicateNode(expr.LHS)
+			if err != nil {
+				return nil, err
+			}
+			rhs, err := deletePredicateNode(expr.RHS)
+			if err != nil {
+				return nil, err
+			}
+			if lhs == nil {
+				return rhs, nil
+			} else if rhs == nil {
+				return lhs, nil
+			}
+			return predicate.LogicalNode{
+				Operator: predicate.LogicalAnd,
+				Children: [2]predicate.Node{lhs, rhs},
+			}, nil
+		case influxql.EQ, influxql.NEQ:
+			ref, ok := expr.LHS.(*influxql.VarRef)
+			if !ok {
+				break
+			}
+			lit, ok := expr.RHS.(*influxql.StringLiteral)
+			if !ok {
+				break
+			}
+			op := influxdb.Equal
+			if expr.Op == influxql.NEQ {
+				op = influxdb.NotEqual
+			}
+			return predicate.TagRuleNode{
+				Tag:      influxdb.Tag{Key: ref.Val, Value: lit.Val},
+				Operator: op,
+			}, nil
+		}
+	case *influxql.BooleanLiteral:
+		if expr.Val {
+			return nil, nil
+		}
 	}
-
-	// Create a set of iterators from a selection.
-	cur, err := query.Select(ctx, stmt, e.ShardMapper, sopt)
-	if err != nil {
-		return nil, err
+	return nil, &errors2.Error{
+		Code: errors2.EInvalid,
+		Msg:  fmt.Sprintf("unsupported delete predicate: %s", expr),
 	}
-	return cur, nil
 }
 
-func (e *StatementExecutor) executeShowDatabasesStatement(ctx context.Context, q *influxql.ShowDatabasesStatement, ectx *query.ExecutionContext) (models.Rows, error) {
-	row := &models.Row{Name: "databases", Columns: []string{"name"}}
-	dbrps, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
-		OrgID: &ectx.OrgID,
-	})
+func (e *StatementExecutor) executeDropSeriesStatement(ctx context.Context, q *influxql.DropSeriesStatement, database string, ectx *query.ExecutionContext) error {
+	mappings, err := e.findWritableMappings(ctx, database, ectx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	seenDbs := make(map[string]struct{}, len(dbrps))
-	for _, dbrp := range dbrps {
-		if _, ok := seenDbs[dbrp.Database]; ok {
-			continue
-		}
+	// Check for time in WHERE clause (not supported).
+	if influxql.HasTimeExpr(q.Condition) {
+		return errors.New("DROP SERIES doesn't support time in WHERE clause")
+	}
 
-		perm, err := influxdb.NewPermissionAtID(dbrp.BucketID, influxdb.ReadAction, influxdb.BucketsResourceType, dbrp.OrganizationID)
-		if err != nil {
-			return nil, err
-		}
-		err = authorizer.IsAllowed(ctx, *perm)
-		if err != nil {
-			if errors2.ErrorCode(err) == errors2.EUnauthorized {
-				continue
-			}
-			return nil, err
+	for _, mapping := range mappings {
+		if err := e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, q.Condition); err != nil {
+			return err
 		}
-		seenDbs[dbrp.Database] = struct{}{}
-		row.Values = append(row.Values, []interface{}{dbrp.Database})
 	}
-	return []*models.Row{row}, nil
+	return nil
 }
 
-func (e *StatementExecutor) getDefaultRP(ctx context.Context, database string, ectx *query.ExecutionContext) (*influxdb.DBRPMappingV2, error) {
-	defaultRP := true
-	mappings, n, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+func (e *StatementExecutor) executeDropShardStatement(ctx context.Context, q *influxql.DropShardStatement, ectx *query.ExecutionContext) error {
+	database, _, sgi := e.MetaClient.ShardOwner(q.ID)
+	if sgi == nil {
+		return fmt.Errorf("shard %d not found", q.ID)
+	}
+
+	// The storage engine names its databases after the bucket ID.
+	bucketID, err := platform.IDFromString(database)
+	if err != nil {
+		return err
+	}
+
+	// Only allow shards of buckets in the organization to be dropped.
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
 		OrgID:    &ectx.OrgID,
-		Database: &database,
-		Default:  &defaultRP,
+		BucketID: bucketID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("finding DBRP mappings: %v", err)
-	} else if n == 0 {
-		return nil, fmt.Errorf("default retention policy not set for: %s", database)
-	} else if n != 1 {
-		return nil, fmt.Errorf("finding DBRP mappings: expected 1, found %d", n)
+		return err
+	} else if len(mappings) == 0 {
+		return fmt.Errorf("shard %d not found", q.ID)
+	}
+	if err := checkWritePermission(ctx, mappings[0], mappings[0].Database); err != nil {
+		return err
 	}
-	return mappings[0], nil
-}
 
-func (e *StatementExecutor) executeDeleteSeriesStatement(ctx context.Context, q *influxql.DeleteSeriesStatement, database string, ectx *query.ExecutionContext) error {
-	mapping, err := e.getDefaultRP(ctx, database, ectx)
-	if err != nil {
+	if err := e.MetaClient.DropShard(q.ID); err != nil {
 		return err
 	}
+	return e.TSDBStore.DeleteShard(q.ID)
+}
 
-	// Convert "now()" to current time.
-	q.Condition = influxql.Reduce(q.Condition, &influxql.NowValuer{Now: time.Now().UTC()})
+// findWritableMappings returns all DBRP mappings of database. It returns an
+// error if the caller is not permitted to write to any of the mapped buckets.
+func (e *StatementExecutor) findWritableMappings(ctx context.Context, database string, ectx *query.ExecutionContext) ([]*influxdb.DBRPMappingV2, error) {
+	if database == "" {
+		return nil, ErrDatabaseNameRequired
+	}
+
+	mappings, _, err := e.DBRP.FindMany(ctx, influxdb.DBRPMappingFilterV2{
+		OrgID:    &ectx.OrgID,
+		Database: &database,
+	})
+	if err != nil {
+		return nil, err
+	} else if len(mappings) == 0 {
+		return nil, query.ErrDatabaseNotFound(database)
+	}
 
-	return e.TSDBStore.DeleteSeries(mapping.BucketID.String(), q.Sources, q.Condition)
+	for _, mapping := range mappings {
+		if err := checkWritePermission(ctx, mapping, database); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
 }
 
 func (e *StatementExecutor) executeDropMeasurementStatement(ctx context.Context, q *influxql.DropMeasurementStatement, database string, ectx *query.ExecutionContext) error {
@@ -656,6 +1830,11 @@ func (e *StatementExecutor) executeShowTagValues(ctx context.Context, q *influxq
 // NormalizeStatement adds a default database and policy to the measurements in statement.
 // Parameter defaultRetentionPolicy can be "".
 func (e *StatementExecutor) NormalizeStatement(ctx context.Context, stmt influxql.Statement, defaultDatabase, defaultRetentionPolicy string, ectx *query.ExecutionContext) (err error) {
+	// Measurements in a continuous query default to the database it is created on.
+	if cq, ok := stmt.(*influxql.CreateContinuousQueryStatement); ok {
+		defaultDatabase, defaultRetentionPolicy = cq.Database, ""
+	}
+
 	influxql.WalkFunc(stmt, func(node influxql.Node) {
 		if err != nil {
 			return
@@ -759,13 +1938,85 @@ func (m mappings) DefaultRetentionPolicy(db string) string {
 type TSDBStore interface {
 	DeleteMeasurement(database, name string) error
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
+	DeleteShard(id uint64) error
 	MeasurementNames(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementsSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	SeriesSketches(database string) (estimator.Sketch, estimator.Sketch, error)
+	Statistics(tags map[string]string) []models.Statistic
 	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
 	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
 }
 
 var _ TSDBStore = LocalTSDBStore{}
 
+// ContinuousQueryService is an interface for managing continuous queries.
+type ContinuousQueryService interface {
+	CreateContinuousQuery(ctx context.Context, cq *continuous_querier.ContinuousQuery) error
+	FindContinuousQueries(ctx context.Context, filter continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error)
+	DeleteContinuousQuery(ctx context.Context, orgID platform.ID, database, name string) error
+}
+
+// BucketPointsWriter is an interface for writing points to a bucket.
+// It is satisfied by storage.PointsWriter.
+type BucketPointsWriter interface {
+	WritePoints(ctx context.Context, orgID platform.ID, bucketID platform.ID, points []models.Point) error
+}
+
+// bufferedPointsWriter adds buffering to a BucketPointsWriter so that the
+// results of a SELECT INTO statement are written in batches.
+type bufferedPointsWriter struct {
+	w        BucketPointsWriter
+	buf      []models.Point
+	orgID    platform.ID
+	bucketID platform.ID
+}
+
+// newBufferedPointsWriter returns a new bufferedPointsWriter which writes
+// to the given bucket.
+func newBufferedPointsWriter(w BucketPointsWriter, orgID, bucketID platform.ID, capacity int) *bufferedPointsWriter {
+	return &bufferedPointsWriter{
+		w:        w,
+		buf:      make([]models.Point, 0, capacity),
+		orgID:    orgID,
+		bucketID: bucketID,
+	}
+}
+
+// WritePoints buffers points, flushing to the underlying writer
+// whenever the buffer fills up.
+func (w *bufferedPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	for len(points) > 0 {
+		n := cap(w.buf) - len(w.buf)
+		if n > len(points) {
+			n = len(points)
+		}
+		w.buf = append(w.buf, points[:n]...)
+		points = points[n:]
+
+		if len(w.buf) == cap(w.buf) {
+			if err := w.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes all buffered points to the underlying writer.
+func (w *bufferedPointsWriter) Flush(ctx context.Context) error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	if err := w.w.WritePoints(ctx, w.orgID, w.bucketID, w.buf); err != nil {
+		return err
+	}
+
+	// Clear the buffer.
+	w.buf = w.buf[:0]
+	return nil
+}
+
 // LocalTSDBStore embeds a tsdb.Store and implements IteratorCreator
 // to satisfy the TSDBStore interface.
 type LocalTSDBStore struct {
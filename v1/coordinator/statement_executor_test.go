@@ -20,10 +20,16 @@ import (
 	"github.com/influxdata/influxdb/v2/influxql/control"
 	"github.com/influxdata/influxdb/v2/influxql/query"
 	"github.com/influxdata/influxdb/v2/internal"
+	errors2 "github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/mock"
 	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/pkg/estimator"
+	"github.com/influxdata/influxdb/v2/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/v2/query/registry"
 	itesting "github.com/influxdata/influxdb/v2/testing"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/v1/coordinator"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
 	"github.com/influxdata/influxdb/v2/v1/services/meta"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap/zaptest"
@@ -161,6 +167,128 @@ func TestQueryExecutor_ExecuteQuery_MaxSelectBucketsN(t *testing.T) {
 	}
 }
 
+// Ensure query executor can write the results of a SELECT INTO statement to the target bucket.
+func TestQueryExecutor_ExecuteQuery_SelectInto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	srcBucketID := platform.ID(0xffe0)
+	dstBucketID := platform.ID(0xffe1)
+	srcDB, srcRP := "db0", "rp0"
+	dstDB, dstRP := "db1", "rp1"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &dstDB, RetentionPolicy: &dstRP}).
+		Return([]*influxdb.DBRPMappingV2{{Database: dstDB, RetentionPolicy: dstRP, OrganizationID: orgID, BucketID: dstBucketID}}, 1, nil)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &srcDB, RetentionPolicy: &srcRP}).
+		Return([]*influxdb.DBRPMappingV2{{Database: srcDB, RetentionPolicy: srcRP, OrganizationID: orgID, BucketID: srcBucketID}}, 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+
+	var written []models.Point
+	e.StatementExecutor.PointsWriter = &PointsWriterMock{
+		WritePointsFn: func(_ context.Context, gotOrgID, gotBucketID platform.ID, points []models.Point) error {
+			if gotOrgID != orgID || gotBucketID != dstBucketID {
+				t.Fatalf("unexpected target: org %s, bucket %s", gotOrgID, gotBucketID)
+			}
+			written = append(written, points...)
+			return nil
+		},
+	}
+
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		var sh MockShard
+		sh.CreateIteratorFn = func(_ context.Context, _ *influxql.Measurement, _ query.IteratorOptions) (query.Iterator, error) {
+			return &FloatIterator{Points: []query.FloatPoint{
+				{Name: "cpu", Time: int64(0 * time.Second), Aux: []interface{}{float64(100)}},
+				{Name: "cpu", Time: int64(1 * time.Second), Aux: []interface{}{float64(200)}},
+			}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"value": influxql.Float}, nil, nil
+		}
+		return &sh
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(srcBucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(dstBucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	if a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM db0.rp0.cpu`, "db0", 0, orgID)); !reflect.DeepEqual(a, []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "result",
+				Columns: []string{"time", "written"},
+				Values:  [][]interface{}{{time.Unix(0, 0).UTC(), int64(2)}},
+			}},
+		},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(a))
+	}
+
+	var got []string
+	for _, p := range written {
+		got = append(got, p.String())
+	}
+	if exp := []string{"cpu_copy value=100 0", "cpu_copy value=200 1000000000"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("unexpected points written: exp %v, got %v", exp, got)
+	}
+}
+
+// Ensure query executor rejects a SELECT INTO statement without write access to the target bucket.
+func TestQueryExecutor_ExecuteQuery_SelectInto_Forbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	dstBucketID := platform.ID(0xffe1)
+	dstDB, dstRP := "db1", "rp1"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &dstDB, RetentionPolicy: &dstRP}).
+		Return([]*influxdb.DBRPMappingV2{{Database: dstDB, RetentionPolicy: dstRP, OrganizationID: orgID, BucketID: dstBucketID}}, 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.PointsWriter = &PointsWriterMock{
+		WritePointsFn: func(_ context.Context, _, _ platform.ID, _ []models.Point) error {
+			t.Fatal("points should not be written")
+			return nil
+		},
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(dstBucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	a := ReadAllResults(e.ExecuteQuery(ctx, `SELECT * INTO db1.rp1.cpu_copy FROM db0.rp0.cpu`, "db0", 0, orgID))
+	if len(a) != 1 || a[0].Err == nil {
+		t.Fatalf("expected error, got %s", spew.Sdump(a))
+	}
+	if code := errors2.ErrorCode(a[0].Err); code != errors2.EForbidden {
+		t.Fatalf("unexpected error code: exp %s, got %s", errors2.EForbidden, code)
+	}
+}
+
 func TestStatementExecutor_NormalizeStatement(t *testing.T) {
 
 	testCases := []struct {
@@ -386,147 +514,1278 @@ func TestQueryExecutor_ExecuteQuery_ShowDatabases(t *testing.T) {
 	}
 }
 
-// QueryExecutor is a test wrapper for coordinator.QueryExecutor.
-type QueryExecutor struct {
-	*query.Executor
-
-	MetaClient        MetaClient
-	TSDBStore         *internal.TSDBStoreMock
-	DBRP              *mocks.MockDBRPMappingServiceV2
-	StatementExecutor *coordinator.StatementExecutor
-	LogOutput         bytes.Buffer
-}
+func TestQueryExecutor_ExecuteQuery_ShowContinuousQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-// NewQueryExecutor returns a new instance of Executor.
-// This query executor always has a node id of 0.
-func NewQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
-	e := &QueryExecutor{
-		Executor:  query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{})),
-		TSDBStore: &internal.TSDBStoreMock{},
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	filt := influxdb.DBRPMappingFilterV2{OrgID: &orgID}
+	res := []*influxdb.DBRPMappingV2{
+		{Database: "db1", OrganizationID: orgID, BucketID: 0xffe0},
+		{Database: "db2", OrganizationID: orgID, BucketID: 0xffe1},
 	}
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), filt).
+		Return(res, 2, nil)
 
-	for _, opt := range opts {
-		opt(e)
+	qe := query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{}))
+	qe.StatementExecutor = &coordinator.StatementExecutor{
+		DBRP: dbrp,
+		ContinuousQueries: &ContinuousQueryServiceMock{
+			FindContinuousQueriesFn: func(_ context.Context, filter continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error) {
+				return []*continuous_querier.ContinuousQuery{
+					{OrganizationID: orgID, Database: "db1", Name: "cq0", Query: "CREATE CONTINUOUS QUERY cq0 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END"},
+					{OrganizationID: orgID, Database: "db2", Name: "cq1", Query: "CREATE CONTINUOUS QUERY cq1 ON db2 BEGIN SELECT mean(value) INTO mem_1h FROM mem GROUP BY time(1h) END"},
+				}, nil
+			},
+		},
 	}
 
-	e.TSDBStore.CreateShardFn = func(database, policy string, shardID uint64, enabled bool) error {
-		return nil
-	}
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
 
-	e.TSDBStore.MeasurementNamesFn = func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error) {
-		return nil, nil
+	results := ReadAllResults(qe.ExecuteQuery(ctx, MustParseQuery("SHOW CONTINUOUS QUERIES"), query.ExecutionOptions{OrgID: orgID}))
+	exp := []*query.Result{
+		{
+			StatementID: 0,
+			Series: []*models.Row{{
+				Name:    "db1",
+				Columns: []string{"name", "query"},
+				Values: [][]interface{}{
+					{"cq0", "CREATE CONTINUOUS QUERY cq0 ON db1 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END"},
+				},
+			}},
+		},
 	}
-
-	e.TSDBStore.TagValuesFn = func(_ query.Authorizer, _ []uint64, _ influxql.Expr) ([]tsdb.TagValues, error) {
-		return nil, nil
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
 	}
+}
 
-	e.StatementExecutor = &coordinator.StatementExecutor{
-		MetaClient: &e.MetaClient,
-		TSDBStore:  e.TSDBStore,
-		DBRP:       e.DBRP,
-		ShardMapper: &coordinator.LocalShardMapper{
-			MetaClient: &e.MetaClient,
-			TSDBStore:  e.TSDBStore,
-			DBRP:       e.DBRP,
-		},
+func TestQueryExecutor_ExecuteQuery_CreateDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		Create(gomock.Any(), &influxdb.DBRPMappingV2{
+			Database:        "db0",
+			RetentionPolicy: "rp0",
+			Default:         true,
+			OrganizationID:  orgID,
+			BucketID:        0xffe0,
+			OwnsBucket:      true,
+		}).
+		Return(nil)
+
+	var created *influxdb.Bucket
+	buckets := mock.NewBucketService()
+	buckets.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+		b.ID = 0xffe0
+		created = b
+		return nil
 	}
-	e.Executor.StatementExecutor = e.StatementExecutor
 
-	return e
-}
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = buckets
 
-type optFn func(qe *QueryExecutor)
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `CREATE DATABASE db0 WITH DURATION 1d NAME rp0`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
 
-func WithDBRP(dbrp *mocks.MockDBRPMappingServiceV2) optFn {
-	return func(qe *QueryExecutor) {
-		qe.DBRP = dbrp
+	if created == nil {
+		t.Fatal("expected bucket to be created")
+	}
+	if created.Name != "db0/rp0" || created.OrgID != orgID || created.RetentionPolicyName != "rp0" || created.RetentionPeriod != 24*time.Hour {
+		t.Fatalf("unexpected bucket: %s", spew.Sdump(created))
 	}
 }
 
-// DefaultQueryExecutor returns a Executor with a database (db0) and retention policy (rp0).
-func DefaultQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
-	e := NewQueryExecutor(t, opts...)
-	e.MetaClient.DatabaseFn = DefaultMetaClientDatabaseFn
-	return e
-}
+func TestQueryExecutor_ExecuteQuery_CreateRetentionPolicy_Exists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-// ExecuteQuery parses query and executes against the database.
-func (e *QueryExecutor) ExecuteQuery(ctx context.Context, q, database string, chunkSize int, orgID platform.ID) (<-chan *query.Result, *influxql2.Statistics) {
-	return e.Executor.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{
-		OrgID:     orgID,
-		Database:  database,
-		ChunkSize: chunkSize,
-	})
-}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil)
 
-type MockShard struct {
-	Measurements             []string
-	FieldDimensionsFn        func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
-	FieldKeysByMeasurementFn func(name []byte) []string
-	CreateIteratorFn         func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error)
-	IteratorCostFn           func(m string, opt query.IteratorOptions) (query.IteratorCost, error)
-	ExpandSourcesFn          func(sources influxql.Sources) (influxql.Sources, error)
-}
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = mock.NewBucketService()
 
-func (sh *MockShard) MeasurementsByRegex(re *regexp.Regexp) []string {
-	names := make([]string, 0, len(sh.Measurements))
-	for _, name := range sh.Measurements {
-		if re.MatchString(name) {
-			names = append(names, name)
-		}
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `CREATE RETENTION POLICY rp0 ON db0 DURATION 1d REPLICATION 1`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0, Err: meta.ErrRetentionPolicyExists}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
 	}
-	return names
 }
 
-func (sh *MockShard) FieldKeysByMeasurement(name []byte) []string {
-	return sh.FieldKeysByMeasurementFn(name)
-}
+func TestQueryExecutor_ExecuteQuery_DropRetentionPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-func (sh *MockShard) FieldDimensions(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
-	return sh.FieldDimensionsFn(measurements)
-}
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID, OwnsBucket: true},
+		}, 1, nil)
+	dbrp.EXPECT().
+		Delete(gomock.Any(), orgID, platform.ID(0xfff0)).
+		Return(nil)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, BucketID: &bucketID}).
+		Return(nil, 0, nil)
 
-func (sh *MockShard) MapType(measurement, field string) influxql.DataType {
-	f, d, err := sh.FieldDimensions([]string{measurement})
-	if err != nil {
-		return influxql.Unknown
+	var deleted platform.ID
+	buckets := mock.NewBucketService()
+	buckets.DeleteBucketFn = func(_ context.Context, id platform.ID) error {
+		deleted = id
+		return nil
 	}
 
-	if typ, ok := f[field]; ok {
-		return typ
-	} else if _, ok := d[field]; ok {
-		return influxql.Tag
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = buckets
+
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `DROP RETENTION POLICY rp0 ON db0`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if deleted != bucketID {
+		t.Fatalf("expected bucket %s to be deleted, got %s", bucketID, deleted)
 	}
-	return influxql.Unknown
 }
 
-func (sh *MockShard) CreateIterator(ctx context.Context, measurement *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
-	return sh.CreateIteratorFn(ctx, measurement, opt)
-}
+func TestQueryExecutor_ExecuteQuery_DropRetentionPolicy_MappedBucket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-func (sh *MockShard) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
-	return sh.IteratorCostFn(measurement, opt)
-}
+	// The bucket was mapped by hand rather than created by InfluxQL.
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil)
+	dbrp.EXPECT().
+		Delete(gomock.Any(), orgID, platform.ID(0xfff0)).
+		Return(nil)
 
-func (sh *MockShard) ExpandSources(sources influxql.Sources) (influxql.Sources, error) {
-	return sh.ExpandSourcesFn(sources)
-}
+	buckets := mock.NewBucketService()
+	buckets.DeleteBucketFn = func(_ context.Context, id platform.ID) error {
+		t.Errorf("unexpected deletion of bucket %s", id)
+		return nil
+	}
 
-// MustParseQuery parses s into a query. Panic on error.
-func MustParseQuery(s string) *influxql.Query {
-	q, err := influxql.ParseQuery(s)
-	if err != nil {
-		panic(err)
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = buckets
+
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `DROP RETENTION POLICY rp0 ON db0`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
 	}
-	return q
 }
 
-// ReadAllResults reads all results from c and returns as a slice.
-func ReadAllResults(c <-chan *query.Result, _ *influxql2.Statistics) []*query.Result {
-	var a []*query.Result
-	for result := range c {
-		a = append(a, result)
+func TestQueryExecutor_ExecuteQuery_AlterRetentionPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil)
+	dbrp.EXPECT().
+		Update(gomock.Any(), &influxdb.DBRPMappingV2{
+			ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: bucketID,
+		}).
+		Return(nil)
+
+	var update influxdb.BucketUpdate
+	buckets := mock.NewBucketService()
+	buckets.UpdateBucketFn = func(_ context.Context, id platform.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+		if id != bucketID {
+			t.Errorf("unexpected bucket updated: %s", id)
+		}
+		update = upd
+		return &influxdb.Bucket{ID: id}, nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = buckets
+
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `ALTER RETENTION POLICY rp0 ON db0 DURATION 2d DEFAULT`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if update.RetentionPeriod == nil || *update.RetentionPeriod != 48*time.Hour || update.ShardGroupDuration != nil {
+		t.Fatalf("unexpected bucket update: %s", spew.Sdump(update))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_AlterRetentionPolicy_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	db, rp := "db0", "rp0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db, RetentionPolicy: &rp}).
+		Return(nil, 0, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = mock.NewBucketService()
+
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `ALTER RETENTION POLICY rp0 ON db0 DURATION 2d`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0, Err: meta.ErrRetentionPolicyNotFound}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	ownedID, mappedID := platform.ID(0xffe0), platform.ID(0xffe1)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: ownedID, OwnsBucket: true},
+			{ID: 0xfff1, Database: "db0", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: mappedID},
+		}, 2, nil)
+	dbrp.EXPECT().
+		Delete(gomock.Any(), orgID, platform.ID(0xfff0)).
+		Return(nil)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, BucketID: &ownedID}).
+		Return(nil, 0, nil)
+	dbrp.EXPECT().
+		Delete(gomock.Any(), orgID, platform.ID(0xfff1)).
+		Return(nil)
+
+	var deleted []platform.ID
+	buckets := mock.NewBucketService()
+	buckets.DeleteBucketFn = func(_ context.Context, id platform.ID) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = buckets
+
+	writeCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(ownedID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(mappedID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(writeCtx, `DROP DATABASE db0`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if exp := []platform.ID{ownedID}; !reflect.DeepEqual(deleted, exp) {
+		t.Fatalf("unexpected deleted buckets: exp %v, got %v", exp, deleted)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_DropDatabase_ContinuousQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{ID: 0xfff0, Database: "db0", RetentionPolicy: "rp0", OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil).
+		AnyTimes()
+
+	var deleted []string
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = mock.NewBucketService()
+	e.StatementExecutor.ContinuousQueries = &ContinuousQueryServiceMock{
+		FindContinuousQueriesFn: func(_ context.Context, filter continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error) {
+			if *filter.OrgID != orgID || *filter.Database != db {
+				t.Errorf("unexpected filter: %s", spew.Sdump(filter))
+			}
+			return []*continuous_querier.ContinuousQuery{
+				{OrganizationID: orgID, Database: db, Name: "cq0"},
+				{OrganizationID: orgID, Database: db, Name: "cq1"},
+			}, nil
+		},
+		DeleteContinuousQueryFn: func(_ context.Context, id platform.ID, database, name string) error {
+			if id != orgID || database != db {
+				t.Errorf("unexpected continuous query: %s %s %s", id, database, name)
+			}
+			deleted = append(deleted, name)
+			return nil
+		},
+	}
+
+	t.Run("read-only token", func(t *testing.T) {
+		readCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+			ID:     orgID,
+			OrgID:  orgID,
+			Status: influxdb.Active,
+			Permissions: []influxdb.Permission{
+				*itesting.MustNewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			},
+		})
+
+		deleted = nil
+		results := ReadAllResults(e.ExecuteQuery(readCtx, `DROP DATABASE db0`, "", 0, orgID))
+		if len(results) != 1 || errors2.ErrorCode(results[0].Err) != errors2.EForbidden {
+			t.Fatalf("expected forbidden error, got %s", spew.Sdump(results))
+		}
+		if len(deleted) != 0 {
+			t.Fatalf("unexpected deleted continuous queries: %v", deleted)
+		}
+	})
+
+	t.Run("write token", func(t *testing.T) {
+		dbrp.EXPECT().
+			Delete(gomock.Any(), orgID, platform.ID(0xfff0)).
+			Return(nil)
+
+		writeCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+			ID:     orgID,
+			OrgID:  orgID,
+			Status: influxdb.Active,
+			Permissions: []influxdb.Permission{
+				*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+			},
+		})
+
+		deleted = nil
+		results := ReadAllResults(e.ExecuteQuery(writeCtx, `DROP DATABASE db0`, "", 0, orgID))
+		if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+			t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+		}
+		if exp := []string{"cq0", "cq1"}; !reflect.DeepEqual(deleted, exp) {
+			t.Fatalf("unexpected deleted continuous queries: exp %v, got %v", exp, deleted)
+		}
+	})
+}
+
+func TestQueryExecutor_ExecuteQuery_DropDatabase_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return(nil, 0, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.BucketService = mock.NewBucketService()
+	e.StatementExecutor.ContinuousQueries = &ContinuousQueryServiceMock{
+		FindContinuousQueriesFn: func(context.Context, continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error) {
+			t.Error("unexpected lookup of continuous queries")
+			return nil, nil
+		},
+	}
+
+	// A token that can't see the database must not drop its continuous
+	// queries either.
+	results := ReadAllResults(e.ExecuteQuery(context.Background(), `DROP DATABASE db0`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_Delete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil).
+		AnyTimes()
+
+	type deleteCall struct {
+		orgID, bucketID platform.ID
+		min, max        int64
+	}
+	var calls []deleteCall
+	deleteSvc := mock.NewDeleteService()
+	deleteSvc.DeleteBucketRangePredicateF = func(_ context.Context, orgID, bucketID platform.ID, min, max int64, pred influxdb.Predicate) error {
+		if pred == nil {
+			t.Error("expected a predicate")
+		}
+		calls = append(calls, deleteCall{orgID, bucketID, min, max})
+		return nil
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.StatementExecutor.DeleteService = deleteSvc
+
+	writeCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	t.Run("tag predicate", func(t *testing.T) {
+		calls = nil
+		results := ReadAllResults(e.ExecuteQuery(writeCtx, `DELETE FROM cpu WHERE host = 'a' AND time >= '2000-01-01T00:00:00Z' AND time < '2000-01-02T00:00:00Z'`, "db0", 0, orgID))
+		if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+			t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+		}
+
+		exp := []deleteCall{{
+			orgID:    orgID,
+			bucketID: bucketID,
+			min:      time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano(),
+			max:      time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano() - 1,
+		}}
+		if !reflect.DeepEqual(calls, exp) {
+			t.Fatalf("unexpected delete calls: exp %s, got %s", spew.Sdump(exp), spew.Sdump(calls))
+		}
+	})
+
+	for _, tt := range []struct {
+		name, q, condition string
+	}{
+		{
+			name:      "regex predicate",
+			q:         `DELETE FROM cpu WHERE host =~ /^a/ AND region !~ /west/`,
+			condition: "host =~ /^a/ AND region !~ /west/",
+		},
+		{
+			name:      "or predicate",
+			q:         `DELETE FROM cpu WHERE (host = 'a' OR host = 'b') AND time < '2000-01-01T00:00:00Z'`,
+			condition: "(host = 'a' OR host = 'b') AND time < '2000-01-01T00:00:00Z'",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			type storeCall struct {
+				database, sources, condition string
+			}
+			var storeCalls []storeCall
+			e.TSDBStore.DeleteSeriesFn = func(database string, sources []influxql.Source, condition influxql.Expr) error {
+				storeCalls = append(storeCalls, storeCall{database, influxql.Sources(sources).String(), condition.String()})
+				return nil
+			}
+			defer func() { e.TSDBStore.DeleteSeriesFn = nil }()
+
+			calls = nil
+			results := ReadAllResults(e.ExecuteQuery(writeCtx, tt.q, "db0", 0, orgID))
+			if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+				t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+			}
+			if len(calls) != 0 {
+				t.Fatalf("unexpected delete calls: %s", spew.Sdump(calls))
+			}
+
+			exp := []storeCall{{database: bucketID.String(), sources: "cpu", condition: tt.condition}}
+			if !reflect.DeepEqual(storeCalls, exp) {
+				t.Fatalf("unexpected store calls: exp %s, got %s", spew.Sdump(exp), spew.Sdump(storeCalls))
+			}
+		})
+	}
+
+	t.Run("forbidden", func(t *testing.T) {
+		calls = nil
+		results := ReadAllResults(e.ExecuteQuery(context.Background(), `DELETE FROM cpu`, "db0", 0, orgID))
+		if len(results) != 1 || errors2.ErrorCode(results[0].Err) != errors2.EForbidden {
+			t.Fatalf("expected forbidden error, got %s", spew.Sdump(results))
+		}
+		if len(calls) != 0 {
+			t.Fatalf("unexpected delete calls: %s", spew.Sdump(calls))
+		}
+	})
+}
+
+func TestQueryExecutor_ExecuteQuery_DropSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	db := "db0"
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, Database: &db}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil).
+		AnyTimes()
+
+	type dropCall struct {
+		database  string
+		sources   string
+		condition string
+	}
+	var calls []dropCall
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.TSDBStore.DeleteSeriesFn = func(database string, sources []influxql.Source, condition influxql.Expr) error {
+		call := dropCall{database: database, sources: influxql.Sources(sources).String()}
+		if condition != nil {
+			call.condition = condition.String()
+		}
+		calls = append(calls, call)
+		return nil
+	}
+
+	writeCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	t.Run("tag predicate", func(t *testing.T) {
+		calls = nil
+		results := ReadAllResults(e.ExecuteQuery(writeCtx, `DROP SERIES FROM cpu WHERE host = 'a'`, "db0", 0, orgID))
+		if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+			t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+		}
+
+		exp := []dropCall{{database: bucketID.String(), sources: "cpu", condition: "host = 'a'"}}
+		if !reflect.DeepEqual(calls, exp) {
+			t.Fatalf("unexpected drop calls: exp %s, got %s", spew.Sdump(exp), spew.Sdump(calls))
+		}
+	})
+
+	t.Run("time predicate", func(t *testing.T) {
+		calls = nil
+		results := ReadAllResults(e.ExecuteQuery(writeCtx, `DROP SERIES FROM cpu WHERE time > '2000-01-01T00:00:00Z'`, "db0", 0, orgID))
+		if len(results) != 1 || results[0].Err == nil {
+			t.Fatalf("expected error, got %s", spew.Sdump(results))
+		}
+		if len(calls) != 0 {
+			t.Fatalf("unexpected drop calls: %s", spew.Sdump(calls))
+		}
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		calls = nil
+		results := ReadAllResults(e.ExecuteQuery(context.Background(), `DROP SERIES FROM cpu`, "db0", 0, orgID))
+		if len(results) != 1 || errors2.ErrorCode(results[0].Err) != errors2.EForbidden {
+			t.Fatalf("expected forbidden error, got %s", spew.Sdump(results))
+		}
+		if len(calls) != 0 {
+			t.Fatalf("unexpected drop calls: %s", spew.Sdump(calls))
+		}
+	})
+}
+
+func TestQueryExecutor_ExecuteQuery_DropShard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	bucketID := platform.ID(0xffe0)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID, BucketID: &bucketID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: bucketID},
+		}, 1, nil)
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.MetaClient.ShardOwnerFn = func(shardID uint64) (string, string, *meta.ShardGroupInfo) {
+		if shardID != 100 {
+			t.Fatalf("unexpected shard id: %d", shardID)
+		}
+		return bucketID.String(), meta.DefaultRetentionPolicyName, &meta.ShardGroupInfo{ID: 1}
+	}
+	var metaDropped, storeDropped bool
+	e.MetaClient.DropShardFn = func(id uint64) error {
+		metaDropped = true
+		return nil
+	}
+	e.TSDBStore.DeleteShardFn = func(id uint64) error {
+		storeDropped = true
+		return nil
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(bucketID, influxdb.WriteAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(ctx, `DROP SHARD 100`, "", 0, orgID))
+	if exp := []*query.Result{{StatementID: 0}}; !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+	if !metaDropped || !storeDropped {
+		t.Fatalf("expected shard to be dropped from meta (%v) and store (%v)", metaDropped, storeDropped)
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+			{Database: "db1", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe1},
+		}, 2, nil)
+
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewQueryExecutor(t, WithDBRP(dbrp))
+	e.MetaClient.DatabaseFn = func(name string) *meta.DatabaseInfo {
+		if name != platform.ID(0xffe0).String() {
+			t.Fatalf("unexpected database: %s", name)
+		}
+		return &meta.DatabaseInfo{
+			Name:                   name,
+			DefaultRetentionPolicy: meta.DefaultRetentionPolicyName,
+			RetentionPolicies: []meta.RetentionPolicyInfo{{
+				Name:     meta.DefaultRetentionPolicyName,
+				Duration: 24 * time.Hour,
+				ShardGroups: []meta.ShardGroupInfo{
+					{
+						ID:        1,
+						StartTime: start,
+						EndTime:   start.Add(time.Hour),
+						Shards:    []meta.ShardInfo{{ID: 10, Owners: []meta.ShardOwner{{NodeID: 0}}}},
+					},
+					{
+						ID:        2,
+						StartTime: start.Add(time.Hour),
+						EndTime:   start.Add(2 * time.Hour),
+						DeletedAt: start,
+						Shards:    []meta.ShardInfo{{ID: 11}},
+					},
+				},
+			}},
+		}
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(ctx, `SHOW SHARDS`, "", 0, orgID))
+	exp := []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Name:    "db0",
+			Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners"},
+			Values: [][]interface{}{
+				{uint64(10), "db0", "rp0", uint64(1), "2000-01-01T00:00:00Z", "2000-01-01T01:00:00Z", "2000-01-02T01:00:00Z", "0"},
+			},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil).
+		AnyTimes()
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.TSDBStore.StatisticsFn = func(tags map[string]string) []models.Statistic {
+		return []models.Statistic{
+			{
+				Name:   "shard",
+				Tags:   map[string]string{"database": platform.ID(0xffe0).String(), "id": "1"},
+				Values: map[string]interface{}{"writeReq": int64(2), "diskBytes": int64(10)},
+			},
+			{
+				Name:   "tsm1_wal",
+				Tags:   map[string]string{"database": platform.ID(0xffe0).String(), "id": "1"},
+				Values: map[string]interface{}{"currentSegmentDiskBytes": int64(5)},
+			},
+			{
+				Name:   "shard",
+				Tags:   map[string]string{"database": platform.ID(0xffe1).String(), "id": "2"},
+				Values: map[string]interface{}{"writeReq": int64(3), "diskBytes": int64(20)},
+			},
+			{
+				Name:   "runtime",
+				Values: map[string]interface{}{"NumGoroutine": int64(8)},
+			},
+		}
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe1, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(ctx, `SHOW STATS FOR 'shard'`, "", 0, orgID))
+	exp := []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Name:    "shard",
+			Tags:    map[string]string{"database": platform.ID(0xffe0).String(), "id": "1"},
+			Columns: []string{"diskBytes", "writeReq"},
+			Values:  [][]interface{}{{int64(10), int64(2)}},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	// Statistics without a database are only shown to operators and
+	// all-access tokens.
+	results = ReadAllResults(e.ExecuteQuery(ctx, `SHOW STATS FOR 'runtime'`, "", 0, orgID))
+	exp = []*query.Result{{StatementID: 0}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	ctx = icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:          orgID,
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.OperPermissions(),
+	})
+	results = ReadAllResults(e.ExecuteQuery(ctx, `SHOW STATS FOR 'runtime'`, "", 0, orgID))
+	exp = []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Name:    "runtime",
+			Columns: []string{"NumGoroutine"},
+			Values:  [][]interface{}{{int64(8)}},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowDiagnostics(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	e := DefaultQueryExecutor(t)
+
+	for _, tt := range []struct {
+		name        string
+		permissions []influxdb.Permission
+		allowed     bool
+	}{
+		{name: "operator", permissions: influxdb.OperPermissions(), allowed: true},
+		{name: "all access", permissions: influxdb.OwnerPermissions(orgID), allowed: true},
+		{name: "read only", permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				ID:          orgID,
+				OrgID:       orgID,
+				Status:      influxdb.Active,
+				Permissions: tt.permissions,
+			})
+
+			results := ReadAllResults(e.ExecuteQuery(ctx, `SHOW DIAGNOSTICS FOR 'runtime'`, "", 0, orgID))
+			if len(results) != 1 {
+				t.Fatalf("unexpected results: %s", spew.Sdump(results))
+			}
+			if !tt.allowed {
+				if errors2.ErrorCode(results[0].Err) != errors2.EUnauthorized {
+					t.Fatalf("expected unauthorized error, got %s", spew.Sdump(results))
+				}
+				return
+			}
+			if results[0].Err != nil {
+				t.Fatalf("unexpected error: %v", results[0].Err)
+			}
+			if len(results[0].Series) != 1 || results[0].Series[0].Name != "runtime" {
+				t.Fatalf("unexpected series: %s", spew.Sdump(results[0].Series))
+			}
+		})
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowMeasurementCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), gomock.Any()).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+		}, 1, nil).
+		AnyTimes()
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.TSDBStore.MeasurementsSketchesFn = func(database string) (estimator.Sketch, estimator.Sketch, error) {
+		if database != platform.ID(0xffe0).String() {
+			t.Fatalf("unexpected database: %s", database)
+		}
+		ss, ts := hll.NewDefaultPlus(), hll.NewDefaultPlus()
+		for _, name := range []string{"cpu", "mem", "disk"} {
+			ss.Add([]byte(name))
+		}
+		return ss, ts, nil
+	}
+
+	// The exact and filtered forms are executed as a SELECT counting the
+	// distinct measurement names of the shards.
+	e.MetaClient.ShardGroupsByTimeRangeFn = func(database, policy string, min, max time.Time) (a []meta.ShardGroupInfo, err error) {
+		return []meta.ShardGroupInfo{
+			{ID: 1, Shards: []meta.ShardInfo{
+				{ID: 100, Owners: []meta.ShardOwner{{NodeID: 0}}},
+			}},
+		}, nil
+	}
+	var conditions []string
+	e.TSDBStore.ShardGroupFn = func(ids []uint64) tsdb.ShardGroup {
+		sh := &MockShard{Measurements: []string{"cpu", "mem"}}
+		sh.CreateIteratorFn = func(_ context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+			if opt.Condition != nil {
+				conditions = append(conditions, opt.Condition.String())
+			}
+			// The storage engine emits the name of the measurement as _name.
+			return &StringIterator{Points: []query.StringPoint{{Value: m.Name}}}, nil
+		}
+		sh.FieldDimensionsFn = func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+			return map[string]influxql.DataType{"_name": influxql.String}, map[string]struct{}{"host": {}}, nil
+		}
+		return sh
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	for _, tt := range []struct {
+		name      string
+		q         string
+		exp       *models.Row
+		condition string
+	}{
+		{
+			name: "estimated",
+			q:    `SHOW MEASUREMENT CARDINALITY ON db0`,
+			exp:  &models.Row{Columns: []string{"cardinality estimation"}, Values: [][]interface{}{{int64(3)}}},
+		},
+		{
+			name: "exact",
+			q:    `SHOW MEASUREMENT EXACT CARDINALITY ON db0`,
+			exp:  &models.Row{Columns: []string{"count"}, Values: [][]interface{}{{int64(2)}}},
+		},
+		{
+			name: "from",
+			q:    `SHOW MEASUREMENT CARDINALITY ON db0 FROM cpu`,
+			exp:  &models.Row{Columns: []string{"count"}, Values: [][]interface{}{{int64(1)}}},
+		},
+		{
+			name:      "where",
+			q:         `SHOW MEASUREMENT CARDINALITY ON db0 WHERE host = 'a'`,
+			exp:       &models.Row{Columns: []string{"count"}, Values: [][]interface{}{{int64(2)}}},
+			condition: `host::tag = 'a'`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions = nil
+			results := ReadAllResults(e.ExecuteQuery(ctx, tt.q, "", 0, orgID))
+			exp := []*query.Result{{StatementID: 0, Series: []*models.Row{tt.exp}}}
+			if !reflect.DeepEqual(results, exp) {
+				t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+			}
+			if tt.condition != "" && len(conditions) == 0 {
+				t.Fatalf("expected condition %q to be used", tt.condition)
+			}
+			for _, cond := range conditions {
+				if cond != tt.condition {
+					t.Fatalf("unexpected condition: exp %q, got %q", tt.condition, cond)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowSeriesCardinality(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dbrp := mocks.NewMockDBRPMappingServiceV2(ctrl)
+	orgID := platform.ID(0xff00)
+	dbrp.EXPECT().
+		FindMany(gomock.Any(), influxdb.DBRPMappingFilterV2{OrgID: &orgID}).
+		Return([]*influxdb.DBRPMappingV2{
+			{Database: "db0", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe0},
+			{Database: "db0", RetentionPolicy: "rp1", OrganizationID: orgID, BucketID: 0xffe1},
+			{Database: "db1", RetentionPolicy: "rp0", Default: true, OrganizationID: orgID, BucketID: 0xffe2},
+		}, 3, nil)
+
+	sketches := map[string][]string{
+		platform.ID(0xffe0).String(): {"cpu,host=a", "cpu,host=b"},
+		platform.ID(0xffe1).String(): {"cpu,host=b", "cpu,host=c"},
+	}
+
+	e := DefaultQueryExecutor(t, WithDBRP(dbrp))
+	e.TSDBStore.SeriesSketchesFn = func(database string) (estimator.Sketch, estimator.Sketch, error) {
+		keys, ok := sketches[database]
+		if !ok {
+			t.Fatalf("unexpected database: %s", database)
+		}
+		ss, ts := hll.NewDefaultPlus(), hll.NewDefaultPlus()
+		for _, k := range keys {
+			ss.Add([]byte(k))
+		}
+		return ss, ts, nil
+	}
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(0xffe0, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe1, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(0xffe2, influxdb.ReadAction, influxdb.BucketsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(ctx, `SHOW SERIES CARDINALITY ON db0`, "", 0, orgID))
+	exp := []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"cardinality estimation"},
+			Values:  [][]interface{}{{int64(3)}},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+}
+
+func TestQueryExecutor_ExecuteQuery_ShowQueries(t *testing.T) {
+	orgID := platform.ID(0xff00)
+	userID := platform.ID(0xaa00)
+
+	reg := registry.New()
+	e := DefaultQueryExecutor(t)
+	e.Executor.Registry = reg
+	e.StatementExecutor.Queries = reg
+
+	flux := &runningQueryMock{status: "executing", memory: 1024}
+	fluxID, unregister := reg.Register(registry.QueryInfo{
+		Language:  registry.LanguageFlux,
+		OrgID:     orgID,
+		UserID:    userID,
+		Query:     `from(bucket: "b") |> range(start: -1h)`,
+		StartTime: time.Now().UTC(),
+	}, flux)
+	defer unregister()
+	reg.Register(registry.QueryInfo{Language: registry.LanguageFlux, OrgID: 0xff01}, &runningQueryMock{})
+	// Queries of other users are only shown to users with write access.
+	reg.Register(registry.QueryInfo{Language: registry.LanguageFlux, OrgID: orgID, UserID: 0xaa01, Query: "other"}, &runningQueryMock{})
+
+	ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		UserID: userID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID),
+		},
+	})
+
+	results := ReadAllResults(e.ExecuteQuery(ctx, `SHOW QUERIES`, "", 0, orgID))
+	exp := []*query.Result{{
+		StatementID: 0,
+		Series: []*models.Row{{
+			Columns: []string{"qid", "language", "query", "database", "user", "duration", "memory_bytes", "status"},
+			Values: [][]interface{}{
+				{fluxID, "flux", `from(bucket: "b") |> range(start: -1h)`, "", userID.String(), "0s", int64(1024), "executing"},
+				{fluxID + 3, "influxql", "SHOW QUERIES", "", userID.String(), "0s", int64(0), "running"},
+			},
+		}},
+	}}
+	if !reflect.DeepEqual(results, exp) {
+		t.Fatalf("unexpected results: exp %s, got %s", spew.Sdump(exp), spew.Sdump(results))
+	}
+
+	writeCtx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+		ID:     orgID,
+		OrgID:  orgID,
+		UserID: userID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			*itesting.MustNewPermissionAtID(orgID, influxdb.ReadAction, influxdb.OrgsResourceType, orgID),
+			*itesting.MustNewPermissionAtID(orgID, influxdb.WriteAction, influxdb.OrgsResourceType, orgID),
+		},
+	})
+	results = ReadAllResults(e.ExecuteQuery(writeCtx, `SHOW QUERIES`, "", 0, orgID))
+	if len(results) != 1 || len(results[0].Series) != 1 || len(results[0].Series[0].Values) != 3 || results[0].Series[0].Values[1][2] != "other" {
+		t.Fatalf("expected queries of other users, got %s", spew.Sdump(results))
+	}
+
+	results = ReadAllResults(e.ExecuteQuery(ctx, fmt.Sprintf(`KILL QUERY %d`, fluxID), "", 0, orgID))
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %s", spew.Sdump(results))
+	}
+	if !flux.cancelled {
+		t.Fatal("expected query to be cancelled")
+	}
+
+	results = ReadAllResults(e.ExecuteQuery(ctx, fmt.Sprintf(`KILL QUERY %d`, fluxID+1), "", 0, orgID))
+	if len(results) != 1 || errors2.ErrorCode(results[0].Err) != errors2.ENotFound {
+		t.Fatalf("expected query not found, got %s", spew.Sdump(results))
+	}
+}
+
+type runningQueryMock struct {
+	status    string
+	memory    int64
+	cancelled bool
+}
+
+func (q *runningQueryMock) Cancel()            { q.cancelled = true }
+func (q *runningQueryMock) MemoryBytes() int64 { return q.memory }
+func (q *runningQueryMock) Status() string     { return q.status }
+
+// QueryExecutor is a test wrapper for coordinator.QueryExecutor.
+type QueryExecutor struct {
+	*query.Executor
+
+	MetaClient        MetaClient
+	TSDBStore         *internal.TSDBStoreMock
+	DBRP              *mocks.MockDBRPMappingServiceV2
+	StatementExecutor *coordinator.StatementExecutor
+	LogOutput         bytes.Buffer
+}
+
+// NewQueryExecutor returns a new instance of Executor.
+// This query executor always has a node id of 0.
+func NewQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
+	e := &QueryExecutor{
+		Executor:  query.NewExecutor(zaptest.NewLogger(t), control.NewControllerMetrics([]string{})),
+		TSDBStore: &internal.TSDBStoreMock{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	e.TSDBStore.CreateShardFn = func(database, policy string, shardID uint64, enabled bool) error {
+		return nil
+	}
+
+	e.TSDBStore.MeasurementNamesFn = func(auth query.Authorizer, database string, cond influxql.Expr) ([][]byte, error) {
+		return nil, nil
+	}
+
+	e.TSDBStore.TagValuesFn = func(_ query.Authorizer, _ []uint64, _ influxql.Expr) ([]tsdb.TagValues, error) {
+		return nil, nil
+	}
+
+	e.StatementExecutor = &coordinator.StatementExecutor{
+		MetaClient: &e.MetaClient,
+		TSDBStore:  e.TSDBStore,
+		DBRP:       e.DBRP,
+		ShardMapper: &coordinator.LocalShardMapper{
+			MetaClient: &e.MetaClient,
+			TSDBStore:  e.TSDBStore,
+			DBRP:       e.DBRP,
+		},
+	}
+	e.Executor.StatementExecutor = e.StatementExecutor
+
+	return e
+}
+
+type optFn func(qe *QueryExecutor)
+
+func WithDBRP(dbrp *mocks.MockDBRPMappingServiceV2) optFn {
+	return func(qe *QueryExecutor) {
+		qe.DBRP = dbrp
+	}
+}
+
+// DefaultQueryExecutor returns a Executor with a database (db0) and retention policy (rp0).
+func DefaultQueryExecutor(t *testing.T, opts ...optFn) *QueryExecutor {
+	e := NewQueryExecutor(t, opts...)
+	e.MetaClient.DatabaseFn = DefaultMetaClientDatabaseFn
+	return e
+}
+
+// ExecuteQuery parses query and executes against the database.
+func (e *QueryExecutor) ExecuteQuery(ctx context.Context, q, database string, chunkSize int, orgID platform.ID) (<-chan *query.Result, *influxql2.Statistics) {
+	return e.Executor.ExecuteQuery(ctx, MustParseQuery(q), query.ExecutionOptions{
+		OrgID:     orgID,
+		Database:  database,
+		ChunkSize: chunkSize,
+	})
+}
+
+type MockShard struct {
+	Measurements             []string
+	FieldDimensionsFn        func(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error)
+	FieldKeysByMeasurementFn func(name []byte) []string
+	CreateIteratorFn         func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error)
+	IteratorCostFn           func(m string, opt query.IteratorOptions) (query.IteratorCost, error)
+	ExpandSourcesFn          func(sources influxql.Sources) (influxql.Sources, error)
+}
+
+func (sh *MockShard) MeasurementsByRegex(re *regexp.Regexp) []string {
+	names := make([]string, 0, len(sh.Measurements))
+	for _, name := range sh.Measurements {
+		if re.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (sh *MockShard) FieldKeysByMeasurement(name []byte) []string {
+	return sh.FieldKeysByMeasurementFn(name)
+}
+
+func (sh *MockShard) FieldDimensions(measurements []string) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
+	return sh.FieldDimensionsFn(measurements)
+}
+
+func (sh *MockShard) MapType(measurement, field string) influxql.DataType {
+	f, d, err := sh.FieldDimensions([]string{measurement})
+	if err != nil {
+		return influxql.Unknown
+	}
+
+	if typ, ok := f[field]; ok {
+		return typ
+	} else if _, ok := d[field]; ok {
+		return influxql.Tag
+	}
+	return influxql.Unknown
+}
+
+func (sh *MockShard) CreateIterator(ctx context.Context, measurement *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error) {
+	return sh.CreateIteratorFn(ctx, measurement, opt)
+}
+
+func (sh *MockShard) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
+	return sh.IteratorCostFn(measurement, opt)
+}
+
+func (sh *MockShard) ExpandSources(sources influxql.Sources) (influxql.Sources, error) {
+	return sh.ExpandSourcesFn(sources)
+}
+
+// PointsWriterMock is a mock implementation of coordinator.BucketPointsWriter.
+type PointsWriterMock struct {
+	WritePointsFn func(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error
+}
+
+func (w *PointsWriterMock) WritePoints(ctx context.Context, orgID, bucketID platform.ID, points []models.Point) error {
+	return w.WritePointsFn(ctx, orgID, bucketID, points)
+}
+
+// ContinuousQueryServiceMock is a mock implementation of coordinator.ContinuousQueryService.
+type ContinuousQueryServiceMock struct {
+	CreateContinuousQueryFn func(ctx context.Context, cq *continuous_querier.ContinuousQuery) error
+	FindContinuousQueriesFn func(ctx context.Context, filter continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error)
+	DeleteContinuousQueryFn func(ctx context.Context, orgID platform.ID, database, name string) error
+}
+
+func (s *ContinuousQueryServiceMock) CreateContinuousQuery(ctx context.Context, cq *continuous_querier.ContinuousQuery) error {
+	return s.CreateContinuousQueryFn(ctx, cq)
+}
+
+func (s *ContinuousQueryServiceMock) FindContinuousQueries(ctx context.Context, filter continuous_querier.ContinuousQueryFilter) ([]*continuous_querier.ContinuousQuery, error) {
+	return s.FindContinuousQueriesFn(ctx, filter)
+}
+
+func (s *ContinuousQueryServiceMock) DeleteContinuousQuery(ctx context.Context, orgID platform.ID, database, name string) error {
+	return s.DeleteContinuousQueryFn(ctx, orgID, database, name)
+}
+
+// MustParseQuery parses s into a query. Panic on error.
+func MustParseQuery(s string) *influxql.Query {
+	q, err := influxql.ParseQuery(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+// ReadAllResults reads all results from c and returns as a slice.
+func ReadAllResults(c <-chan *query.Result, _ *influxql2.Statistics) []*query.Result {
+	var a []*query.Result
+	for result := range c {
+		a = append(a, result)
 	}
 	return a
 }
@@ -550,3 +1809,23 @@ func (itr *FloatIterator) Next() (*query.FloatPoint, error) {
 	itr.Points = itr.Points[1:]
 	return v, nil
 }
+
+// StringIterator is an iterator that reads strings from a slice.
+type StringIterator struct {
+	Points []query.StringPoint
+	stats  query.IteratorStats
+}
+
+func (itr *StringIterator) Stats() query.IteratorStats { return itr.stats }
+func (itr *StringIterator) Close() error               { return nil }
+
+// Next returns the next value and shifts it off the beginning of the points slice.
+func (itr *StringIterator) Next() (*query.StringPoint, error) {
+	if len(itr.Points) == 0 {
+		return nil, nil
+	}
+
+	v := &itr.Points[0]
+	itr.Points = itr.Points[1:]
+	return v, nil
+}
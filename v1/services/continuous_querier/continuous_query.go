@@ -0,0 +1,166 @@
+// Package continuous_querier provides InfluxQL continuous queries. Continuous
+// query definitions are stored in the kv store and executed on the cadence
+// given by their RESAMPLE EVERY clause using the task scheduler.
+package continuous_querier
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxql"
+)
+
+// ContinuousQuery is a stored InfluxQL continuous query.
+type ContinuousQuery struct {
+	ID             platform.ID `json:"id"`
+	OrganizationID platform.ID `json:"orgID"`
+	// OwnerID is the user who created the query.
+	OwnerID platform.ID `json:"ownerID,omitempty"`
+	// AuthorizationID is the authorization the query was created with and
+	// runs with.
+	AuthorizationID platform.ID `json:"authorizationID,omitempty"`
+	Database        string      `json:"database"`
+	Name            string      `json:"name"`
+	// Query is the full CREATE CONTINUOUS QUERY statement.
+	Query string `json:"query"`
+	// LatestScheduled is the last time the query was scheduled for execution.
+	LatestScheduled time.Time `json:"latestScheduled,omitempty"`
+}
+
+// ContinuousQueryFilter represents a set of filters that restrict the
+// returned continuous queries.
+type ContinuousQueryFilter struct {
+	OrgID    *platform.ID
+	Database *string
+	Name     *string
+}
+
+// Match returns true if the continuous query matches the filter.
+func (f ContinuousQueryFilter) Match(cq *ContinuousQuery) bool {
+	if f.OrgID != nil && *f.OrgID != cq.OrganizationID {
+		return false
+	}
+	if f.Database != nil && *f.Database != cq.Database {
+		return false
+	}
+	if f.Name != nil && *f.Name != cq.Name {
+		return false
+	}
+	return true
+}
+
+// Statement parses the stored query into a CreateContinuousQueryStatement.
+func (cq *ContinuousQuery) Statement() (*influxql.CreateContinuousQueryStatement, error) {
+	stmt, err := influxql.ParseStatement(cq.Query)
+	if err != nil {
+		return nil, err
+	}
+	cqStmt, ok := stmt.(*influxql.CreateContinuousQueryStatement)
+	if !ok {
+		return nil, fmt.Errorf("expected CREATE CONTINUOUS QUERY statement, got %T", stmt)
+	}
+	return cqStmt, nil
+}
+
+// Valid returns an error if the continuous query cannot be scheduled.
+func (cq *ContinuousQuery) Valid() error {
+	if cq.Name == "" {
+		return errors.New("continuous query name required")
+	}
+	if cq.Database == "" {
+		return errors.New("continuous query database required")
+	}
+	stmt, err := cq.Statement()
+	if err != nil {
+		return err
+	}
+	if stmt.Source.Target == nil {
+		return errors.New("continuous query must contain an INTO clause")
+	}
+	interval, err := stmt.Source.GroupByInterval()
+	if err != nil {
+		return err
+	}
+	if interval < time.Second {
+		return errors.New("continuous query must have a GROUP BY time() interval of at least 1s")
+	}
+	if stmt.ResampleEvery != 0 && stmt.ResampleEvery < time.Second {
+		return errors.New("continuous query RESAMPLE EVERY interval must be at least 1s")
+	}
+	return nil
+}
+
+// intervals returns the GROUP BY interval and offset of the query, together
+// with its RESAMPLE EVERY and FOR durations. Unset resample durations default
+// to the GROUP BY interval.
+func intervals(stmt *influxql.CreateContinuousQueryStatement) (interval, offset, every, resampleFor time.Duration, err error) {
+	if interval, err = stmt.Source.GroupByInterval(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	if offset, err = stmt.Source.GroupByOffset(); err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	every, resampleFor = interval, interval
+	if stmt.ResampleEvery != 0 {
+		every = stmt.ResampleEvery
+	}
+	if stmt.ResampleFor != 0 {
+		resampleFor = stmt.ResampleFor
+	}
+	return interval, offset, every, resampleFor, nil
+}
+
+// timeRange returns the time range a run of the query at now should
+// compute, as [start, end).
+func timeRange(stmt *influxql.CreateContinuousQueryStatement, now time.Time) (start, end time.Time, err error) {
+	interval, offset, every, resampleFor, err := intervals(stmt)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	start = now.Add(interval - resampleFor - offset - 1).Truncate(interval).Add(offset)
+	end = now.Add(interval - every - offset).Truncate(interval).Add(offset)
+	return start, end, nil
+}
+
+var _ scheduler.Schedulable = (*schedulable)(nil)
+
+// schedulable adapts a ContinuousQuery to the scheduler.Schedulable interface.
+type schedulable struct {
+	id            scheduler.ID
+	schedule      scheduler.Schedule
+	offset        time.Duration
+	lastScheduled time.Time
+}
+
+func newSchedulable(cq *ContinuousQuery) (*schedulable, error) {
+	stmt, err := cq.Statement()
+	if err != nil {
+		return nil, err
+	}
+	_, offset, every, _, err := intervals(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	sch, lastScheduled, err := scheduler.NewSchedule(fmt.Sprintf("@every %ds", int64(every/time.Second)), cq.LatestScheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schedulable{
+		id:            scheduler.ID(cq.ID),
+		schedule:      sch,
+		offset:        offset,
+		lastScheduled: lastScheduled,
+	}, nil
+}
+
+func (s *schedulable) ID() scheduler.ID             { return s.id }
+func (s *schedulable) Schedule() scheduler.Schedule { return s.schedule }
+func (s *schedulable) Offset() time.Duration        { return s.offset }
+func (s *schedulable) LastScheduled() time.Time     { return s.lastScheduled }
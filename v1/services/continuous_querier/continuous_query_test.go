@@ -0,0 +1,110 @@
+package continuous_querier
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+func TestTimeRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		now   string
+		start string
+		end   string
+	}{
+		{
+			name:  "group by interval",
+			query: `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+			now:   "2000-01-01T10:00:00Z",
+			start: "2000-01-01T09:00:00Z",
+			end:   "2000-01-01T10:00:00Z",
+		},
+		{
+			name:  "group by offset",
+			query: `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h, 15m) END`,
+			now:   "2000-01-01T10:15:00Z",
+			start: "2000-01-01T09:15:00Z",
+			end:   "2000-01-01T10:15:00Z",
+		},
+		{
+			name:  "resample for",
+			query: `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE FOR 3h BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+			now:   "2000-01-01T10:00:00Z",
+			start: "2000-01-01T07:00:00Z",
+			end:   "2000-01-01T10:00:00Z",
+		},
+		{
+			name:  "resample every and for",
+			query: `CREATE CONTINUOUS QUERY cq0 ON db0 RESAMPLE EVERY 30m FOR 2h BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+			now:   "2000-01-01T10:30:00Z",
+			start: "2000-01-01T09:00:00Z",
+			end:   "2000-01-01T11:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cq := &ContinuousQuery{Name: "cq0", Database: "db0", Query: tt.query}
+			if err := cq.Valid(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			stmt, err := cq.Statement()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			start, end, err := timeRange(stmt, mustParseTime(t, tt.now))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exp := mustParseTime(t, tt.start); !start.Equal(exp) {
+				t.Errorf("unexpected start: exp %s, got %s", exp, start)
+			}
+			if exp := mustParseTime(t, tt.end); !end.Equal(exp) {
+				t.Errorf("unexpected end: exp %s, got %s", exp, end)
+			}
+		})
+	}
+}
+
+func TestContinuousQuery_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			query: `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h) END`,
+		},
+		{
+			name:    "not a continuous query",
+			query:   `SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(1h)`,
+			wantErr: true,
+		},
+		{
+			name:    "sub-second interval",
+			query:   `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO cpu_1h FROM cpu GROUP BY time(100ms) END`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cq := &ContinuousQuery{Name: "cq0", Database: "db0", Query: tt.query}
+			if err := cq.Valid(); (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
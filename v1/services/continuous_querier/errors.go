@@ -0,0 +1,37 @@
+package continuous_querier
+
+import (
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+)
+
+var (
+	// ErrContinuousQueryNotFound is used when the specified continuous query cannot be found.
+	ErrContinuousQueryNotFound = &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "continuous query not found",
+	}
+
+	// ErrContinuousQueryExists is used when creating a continuous query with a
+	// name that is already in use on the database.
+	ErrContinuousQueryExists = &errors.Error{
+		Code: errors.EConflict,
+		Msg:  "continuous query already exists",
+	}
+)
+
+// ErrInvalidContinuousQuery is used when a continuous query definition is invalid.
+func ErrInvalidContinuousQuery(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInvalid,
+		Msg:  "invalid continuous query",
+		Err:  err,
+	}
+}
+
+// ErrInternalServiceError is used when the error comes from an internal system.
+func ErrInternalServiceError(err error) *errors.Error {
+	return &errors.Error{
+		Code: errors.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,154 @@
+package continuous_querier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	iql "github.com/influxdata/influxdb/v2/influxql"
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+// QueryExecutor executes InfluxQL queries.
+type QueryExecutor interface {
+	ExecuteQuery(ctx context.Context, query *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics)
+}
+
+// AuthorizationFinder finds the authorization a continuous query was created
+// with.
+type AuthorizationFinder interface {
+	FindAuthorizationByID(ctx context.Context, id platform.ID) (*influxdb.Authorization, error)
+}
+
+// AuthorizationFinders finds authorizations with each of its finders in turn,
+// as continuous queries can be created with both v1 and v2 tokens.
+type AuthorizationFinders []AuthorizationFinder
+
+// FindAuthorizationByID returns the first authorization found with id.
+func (fs AuthorizationFinders) FindAuthorizationByID(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+	for _, f := range fs {
+		a, err := f.FindAuthorizationByID(ctx, id)
+		if err == nil {
+			return a, nil
+		} else if errors.ErrorCode(err) != errors.ENotFound {
+			return nil, err
+		}
+	}
+	return nil, &errors.Error{
+		Code: errors.ENotFound,
+		Msg:  "authorization not found",
+	}
+}
+
+var _ scheduler.Executor = (*Executor)(nil)
+
+// Executor runs continuous queries on behalf of the scheduler.
+type Executor struct {
+	log   *zap.Logger
+	store *Store
+	qe    QueryExecutor
+	af    AuthorizationFinder
+}
+
+// NewExecutor returns an Executor which runs the continuous queries held in
+// store through qe, using the authorization each query was created with.
+func NewExecutor(log *zap.Logger, store *Store, qe QueryExecutor, af AuthorizationFinder) *Executor {
+	return &Executor{
+		log:   log,
+		store: store,
+		qe:    qe,
+		af:    af,
+	}
+}
+
+// Execute runs the continuous query identified by id over the interval it
+// was scheduled for. Late runs, such as those catching up after downtime,
+// compute the interval of their scheduled time rather than the latest one.
+func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
+	cq, err := e.store.FindContinuousQueryByID(ctx, platform.ID(id))
+	if err != nil {
+		return err
+	}
+
+	stmt, err := cq.Statement()
+	if err != nil {
+		return err
+	}
+
+	// The query is scheduled its offset before it is due to run.
+	_, offset, _, _, err := intervals(stmt)
+	if err != nil {
+		return err
+	}
+	start, end, err := timeRange(stmt, scheduledFor.Add(offset))
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		// There is no time interval to compute.
+		return nil
+	}
+
+	q := stmt.Source.Clone()
+	if err := q.SetTimeRange(start, end); err != nil {
+		return fmt.Errorf("unable to set time range: %s", err)
+	}
+
+	// The query stops running once its authorization is deleted, disabled
+	// or expired.
+	auth, err := e.af.FindAuthorizationByID(ctx, cq.AuthorizationID)
+	if err != nil {
+		return fmt.Errorf("unable to find authorization of continuous query: %w", err)
+	}
+	if _, err := auth.PermissionSet(); err != nil {
+		return fmt.Errorf("invalid authorization of continuous query: %w", err)
+	}
+	ctx = icontext.SetAuthorizer(ctx, auth)
+
+	log := e.log.With(
+		zap.String("org_id", cq.OrganizationID.String()),
+		zap.String("database", cq.Database),
+		zap.String("name", cq.Name),
+		zap.Time("start", start),
+		zap.Time("end", end),
+	)
+
+	results, _ := e.qe.ExecuteQuery(ctx, &influxql.Query{Statements: influxql.Statements{q}}, query.ExecutionOptions{
+		OrgID:      cq.OrganizationID,
+		Database:   cq.Database,
+		Authorizer: query.OpenAuthorizer,
+	})
+
+	var runErr error
+	var written int64
+	for res := range results {
+		if res.Err != nil {
+			if runErr == nil {
+				runErr = res.Err
+			}
+			continue
+		}
+		for _, row := range res.Series {
+			if row.Name != "result" || len(row.Values) == 0 || len(row.Values[0]) < 2 {
+				continue
+			}
+			if n, ok := row.Values[0][1].(int64); ok {
+				written += n
+			}
+		}
+	}
+
+	if runErr != nil {
+		log.Info("Continuous query execution failed", zap.Error(runErr))
+		return runErr
+	}
+	log.Debug("Executed continuous query", zap.Int64("written", written))
+	return nil
+}
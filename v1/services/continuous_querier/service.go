@@ -0,0 +1,113 @@
+package continuous_querier
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+// Service manages continuous queries, keeping the scheduler in sync with the
+// definitions held in the Store.
+type Service struct {
+	log       *zap.Logger
+	store     *Store
+	scheduler scheduler.Scheduler
+
+	// now returns the current time. It can be overridden in tests.
+	now func() time.Time
+}
+
+// NewService returns a Service which persists continuous queries in store
+// and schedules them on sch.
+func NewService(log *zap.Logger, store *Store, sch scheduler.Scheduler) *Service {
+	return &Service{
+		log:       log,
+		store:     store,
+		scheduler: sch,
+		now:       time.Now,
+	}
+}
+
+// Open schedules all existing continuous queries.
+func (s *Service) Open(ctx context.Context) error {
+	cqs, err := s.store.FindContinuousQueries(ctx, ContinuousQueryFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, cq := range cqs {
+		if err := s.schedule(cq); err != nil {
+			s.log.Error("Failed to schedule continuous query",
+				zap.String("org_id", cq.OrganizationID.String()),
+				zap.String("database", cq.Database),
+				zap.String("name", cq.Name),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// CreateContinuousQuery validates, stores and schedules a continuous query.
+func (s *Service) CreateContinuousQuery(ctx context.Context, cq *ContinuousQuery) error {
+	if err := cq.Valid(); err != nil {
+		return ErrInvalidContinuousQuery(err)
+	}
+
+	if cq.LatestScheduled.IsZero() {
+		cq.LatestScheduled = s.now().UTC()
+	}
+
+	if err := s.store.CreateContinuousQuery(ctx, cq); err != nil {
+		return err
+	}
+
+	if err := s.schedule(cq); err != nil {
+		// Do not leave behind a query which will never run.
+		if derr := s.store.DeleteContinuousQuery(ctx, cq.ID); derr != nil {
+			s.log.Error("Failed to remove unschedulable continuous query", zap.Error(derr))
+		}
+		return ErrInvalidContinuousQuery(err)
+	}
+	return nil
+}
+
+// FindContinuousQueries returns all continuous queries matching filter.
+func (s *Service) FindContinuousQueries(ctx context.Context, filter ContinuousQueryFilter) ([]*ContinuousQuery, error) {
+	return s.store.FindContinuousQueries(ctx, filter)
+}
+
+// DeleteContinuousQuery unschedules and removes the named continuous query.
+func (s *Service) DeleteContinuousQuery(ctx context.Context, orgID platform.ID, database, name string) error {
+	cqs, err := s.store.FindContinuousQueries(ctx, ContinuousQueryFilter{
+		OrgID:    &orgID,
+		Database: &database,
+		Name:     &name,
+	})
+	if err != nil {
+		return err
+	}
+	if len(cqs) == 0 {
+		return ErrContinuousQueryNotFound
+	}
+
+	for _, cq := range cqs {
+		if err := s.scheduler.Release(scheduler.ID(cq.ID)); err != nil {
+			return err
+		}
+		if err := s.store.DeleteContinuousQuery(ctx, cq.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) schedule(cq *ContinuousQuery) error {
+	sch, err := newSchedulable(cq)
+	if err != nil {
+		return err
+	}
+	return s.scheduler.Schedule(sch)
+}
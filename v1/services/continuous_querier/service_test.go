@@ -0,0 +1,261 @@
+package continuous_querier_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	iql "github.com/influxdata/influxdb/v2/influxql"
+	"github.com/influxdata/influxdb/v2/influxql/query"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/platform/errors"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/kv/migration/all"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/v1/services/continuous_querier"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap/zaptest"
+)
+
+const testQuery = `CREATE CONTINUOUS QUERY cq0 ON db0 BEGIN SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu GROUP BY time(1h) END`
+
+func NewTestInmemStore(t *testing.T) kv.Store {
+	t.Helper()
+
+	store := inmem.NewKVStore()
+	if err := all.Up(context.Background(), zaptest.NewLogger(t), store); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+type fakeScheduler struct {
+	scheduled map[scheduler.ID]scheduler.Schedulable
+}
+
+func (s *fakeScheduler) Schedule(sch scheduler.Schedulable) error {
+	s.scheduled[sch.ID()] = sch
+	return nil
+}
+
+func (s *fakeScheduler) Release(id scheduler.ID) error {
+	delete(s.scheduled, id)
+	return nil
+}
+
+func TestService_CreateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	store := continuous_querier.NewStore(NewTestInmemStore(t))
+	sch := &fakeScheduler{scheduled: make(map[scheduler.ID]scheduler.Schedulable)}
+	svc := continuous_querier.NewService(zaptest.NewLogger(t), store, sch)
+
+	orgID := platform.ID(0xff00)
+	cq := &continuous_querier.ContinuousQuery{
+		OrganizationID: orgID,
+		Database:       "db0",
+		Name:           "cq0",
+		Query:          testQuery,
+	}
+	if err := svc.CreateContinuousQuery(ctx, cq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := sch.scheduled[scheduler.ID(cq.ID)]; !ok {
+		t.Fatal("expected continuous query to be scheduled")
+	}
+
+	dup := *cq
+	if err := svc.CreateContinuousQuery(ctx, &dup); errors.ErrorCode(err) != errors.EConflict {
+		t.Fatalf("expected conflict creating duplicate, got %v", err)
+	}
+
+	cqs, err := svc.FindContinuousQueries(ctx, continuous_querier.ContinuousQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cqs) != 1 || cqs[0].Name != "cq0" || cqs[0].Query != testQuery {
+		t.Fatalf("unexpected continuous queries: %+v", cqs)
+	}
+
+	if err := svc.DeleteContinuousQuery(ctx, orgID, "db0", "cq0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sch.scheduled) != 0 {
+		t.Fatal("expected continuous query to be released")
+	}
+	if err := svc.DeleteContinuousQuery(ctx, orgID, "db0", "cq0"); errors.ErrorCode(err) != errors.ENotFound {
+		t.Fatalf("expected not found deleting twice, got %v", err)
+	}
+}
+
+func TestService_Open(t *testing.T) {
+	ctx := context.Background()
+	store := continuous_querier.NewStore(NewTestInmemStore(t))
+	cq := &continuous_querier.ContinuousQuery{
+		OrganizationID:  platform.ID(0xff00),
+		Database:        "db0",
+		Name:            "cq0",
+		Query:           testQuery,
+		LatestScheduled: time.Unix(3600, 0).UTC(),
+	}
+	if err := store.CreateContinuousQuery(ctx, cq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sch := &fakeScheduler{scheduled: make(map[scheduler.ID]scheduler.Schedulable)}
+	if err := continuous_querier.NewService(zaptest.NewLogger(t), store, sch).Open(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, ok := sch.scheduled[scheduler.ID(cq.ID)]
+	if !ok {
+		t.Fatal("expected continuous query to be scheduled")
+	}
+	next, err := got.Schedule().Next(got.LastScheduled())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := time.Unix(7200, 0).UTC(); !next.Equal(exp) {
+		t.Fatalf("unexpected next run: exp %s, got %s", exp, next)
+	}
+}
+
+type queryExecutorFunc func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics)
+
+func (fn queryExecutorFunc) ExecuteQuery(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+	return fn(ctx, q, opt)
+}
+
+type authorizationFinderFunc func(ctx context.Context, id platform.ID) (*influxdb.Authorization, error)
+
+func (fn authorizationFinderFunc) FindAuthorizationByID(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+	return fn(ctx, id)
+}
+
+func notFoundAuthorizations(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+	return nil, &errors.Error{Code: errors.ENotFound, Msg: "authorization not found"}
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	ctx := context.Background()
+	store := continuous_querier.NewStore(NewTestInmemStore(t))
+	orgID, ownerID, authID := platform.ID(0xff00), platform.ID(0xff01), platform.ID(0xff02)
+	cq := &continuous_querier.ContinuousQuery{
+		OrganizationID:  orgID,
+		OwnerID:         ownerID,
+		AuthorizationID: authID,
+		Database:        "db0",
+		Name:            "cq0",
+		Query:           testQuery,
+	}
+	if err := store.CreateContinuousQuery(ctx, cq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var executed string
+	qe := queryExecutorFunc(func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+		auth, err := icontext.GetAuthorizer(ctx)
+		if err != nil {
+			t.Fatalf("expected authorizer on context: %v", err)
+		}
+		if auth.Identifier() != authID || auth.GetUserID() != ownerID {
+			t.Errorf("unexpected authorization: %+v", auth)
+		}
+		if opt.OrgID != orgID || opt.Database != "db0" {
+			t.Errorf("unexpected execution options: %+v", opt)
+		}
+		executed = q.String()
+
+		results := make(chan *query.Result, 1)
+		results <- &query.Result{}
+		close(results)
+		return results, &iql.Statistics{}
+	})
+	// The authorization is found with the second finder, like a v2 token.
+	af := continuous_querier.AuthorizationFinders{
+		authorizationFinderFunc(notFoundAuthorizations),
+		authorizationFinderFunc(func(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+			if id != authID {
+				return notFoundAuthorizations(ctx, id)
+			}
+			return &influxdb.Authorization{ID: authID, UserID: ownerID, OrgID: orgID, Status: influxdb.Active}, nil
+		}),
+	}
+
+	e := continuous_querier.NewExecutor(zaptest.NewLogger(t), store, qe, af)
+	runAt := time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC)
+	if err := e.Execute(ctx, scheduler.ID(cq.ID), runAt, runAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp := `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T09:00:00Z' AND time < '2000-01-01T10:00:00Z' GROUP BY time(1h)`
+	if executed != exp {
+		t.Fatalf("unexpected query:\nexp: %s\ngot: %s", exp, executed)
+	}
+
+	// A run catching up after downtime computes the interval it was
+	// scheduled for, not the latest one.
+	scheduledFor := runAt.Add(-time.Hour)
+	if err := e.Execute(ctx, scheduler.ID(cq.ID), scheduledFor, runAt.Add(5*time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exp = `SELECT mean(value) INTO db0.rp0.cpu_1h FROM db0.rp0.cpu WHERE time >= '2000-01-01T08:00:00Z' AND time < '2000-01-01T09:00:00Z' GROUP BY time(1h)`
+	if executed != exp {
+		t.Fatalf("unexpected query:\nexp: %s\ngot: %s", exp, executed)
+	}
+}
+
+func TestExecutor_Execute_InvalidAuthorization(t *testing.T) {
+	ctx := context.Background()
+	store := continuous_querier.NewStore(NewTestInmemStore(t))
+	cq := &continuous_querier.ContinuousQuery{
+		OrganizationID:  platform.ID(0xff00),
+		OwnerID:         platform.ID(0xff01),
+		AuthorizationID: platform.ID(0xff02),
+		Database:        "db0",
+		Name:            "cq0",
+		Query:           testQuery,
+	}
+	if err := store.CreateContinuousQuery(ctx, cq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	qe := queryExecutorFunc(func(ctx context.Context, q *influxql.Query, opt query.ExecutionOptions) (<-chan *query.Result, *iql.Statistics) {
+		t.Fatal("query must not run without a valid authorization")
+		return nil, nil
+	})
+
+	expired := time.Now().Add(-time.Hour)
+	for _, tt := range []struct {
+		name string
+		af   continuous_querier.AuthorizationFinder
+	}{
+		{
+			name: "deleted",
+			af:   authorizationFinderFunc(notFoundAuthorizations),
+		},
+		{
+			name: "inactive",
+			af: authorizationFinderFunc(func(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{ID: id, Status: influxdb.Inactive}, nil
+			}),
+		},
+		{
+			name: "expired",
+			af: authorizationFinderFunc(func(ctx context.Context, id platform.ID) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{ID: id, Status: influxdb.Active, ExpiresAt: &expired}, nil
+			}),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := continuous_querier.NewExecutor(zaptest.NewLogger(t), store, qe, tt.af)
+			runAt := time.Date(2000, 1, 1, 10, 0, 0, 0, time.UTC)
+			if err := e.Execute(ctx, scheduler.ID(cq.ID), runAt, runAt); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package continuous_querier
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/platform"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+var cqBucket = []byte("continuousqueriesv1")
+
+var _ scheduler.SchedulableService = (*Store)(nil)
+
+// Store persists continuous queries in a kv.Store.
+type Store struct {
+	kvStore kv.Store
+	IDGen   platform.IDGenerator
+}
+
+// NewStore returns a Store backed by kvStore.
+func NewStore(kvStore kv.Store) *Store {
+	return &Store{
+		kvStore: kvStore,
+		IDGen:   snowflake.NewDefaultIDGenerator(),
+	}
+}
+
+// CreateContinuousQuery stores a new continuous query, assigning it an ID.
+// The name of the query must be unique within its organization and database.
+func (s *Store) CreateContinuousQuery(ctx context.Context, cq *ContinuousQuery) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		existing, err := findContinuousQueries(tx, ContinuousQueryFilter{
+			OrgID:    &cq.OrganizationID,
+			Database: &cq.Database,
+			Name:     &cq.Name,
+		})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return ErrContinuousQueryExists
+		}
+
+		cq.ID = s.IDGen.ID()
+		return putContinuousQuery(tx, cq)
+	})
+}
+
+// FindContinuousQueryByID returns a single continuous query by ID.
+func (s *Store) FindContinuousQueryByID(ctx context.Context, id platform.ID) (*ContinuousQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var cq *ContinuousQuery
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		var err error
+		cq, err = findContinuousQueryByID(tx, id)
+		return err
+	})
+	return cq, err
+}
+
+// FindContinuousQueries returns all continuous queries matching filter.
+func (s *Store) FindContinuousQueries(ctx context.Context, filter ContinuousQueryFilter) ([]*ContinuousQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var cqs []*ContinuousQuery
+	err := s.kvStore.View(ctx, func(tx kv.Tx) error {
+		var err error
+		cqs, err = findContinuousQueries(tx, filter)
+		return err
+	})
+	return cqs, err
+}
+
+// DeleteContinuousQuery removes a continuous query by ID.
+func (s *Store) DeleteContinuousQuery(ctx context.Context, id platform.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		if _, err := findContinuousQueryByID(tx, id); err != nil {
+			return err
+		}
+
+		encodedID, err := id.Encode()
+		if err != nil {
+			return ErrInvalidContinuousQuery(err)
+		}
+		b, err := tx.Bucket(cqBucket)
+		if err != nil {
+			return ErrInternalServiceError(err)
+		}
+		if err := b.Delete(encodedID); err != nil {
+			return ErrInternalServiceError(err)
+		}
+		return nil
+	})
+}
+
+// UpdateLastScheduled records the last time a continuous query was scheduled
+// for execution.
+func (s *Store) UpdateLastScheduled(ctx context.Context, id scheduler.ID, t time.Time) error {
+	return s.kvStore.Update(ctx, func(tx kv.Tx) error {
+		cq, err := findContinuousQueryByID(tx, platform.ID(id))
+		if err != nil {
+			return err
+		}
+		cq.LatestScheduled = t.UTC()
+		return putContinuousQuery(tx, cq)
+	})
+}
+
+func findContinuousQueryByID(tx kv.Tx, id platform.ID) (*ContinuousQuery, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidContinuousQuery(err)
+	}
+
+	b, err := tx.Bucket(cqBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrContinuousQueryNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	cq := &ContinuousQuery{}
+	if err := json.Unmarshal(v, cq); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return cq, nil
+}
+
+func findContinuousQueries(tx kv.Tx, filter ContinuousQueryFilter) ([]*ContinuousQuery, error) {
+	b, err := tx.Bucket(cqBucket)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	defer cur.Close()
+
+	var cqs []*ContinuousQuery
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		cq := &ContinuousQuery{}
+		if err := json.Unmarshal(v, cq); err != nil {
+			return nil, ErrInternalServiceError(err)
+		}
+		if filter.Match(cq) {
+			cqs = append(cqs, cq)
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return nil, ErrInternalServiceError(err)
+	}
+	return cqs, nil
+}
+
+func putContinuousQuery(tx kv.Tx, cq *ContinuousQuery) error {
+	encodedID, err := cq.ID.Encode()
+	if err != nil {
+		return ErrInvalidContinuousQuery(err)
+	}
+
+	v, err := json.Marshal(cq)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+
+	b, err := tx.Bucket(cqBucket)
+	if err != nil {
+		return ErrInternalServiceError(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalServiceError(err)
+	}
+	return nil
+}